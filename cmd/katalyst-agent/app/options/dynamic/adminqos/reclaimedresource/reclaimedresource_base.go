@@ -34,6 +34,7 @@ type ReclaimedResourceOptions struct {
 	MinReclaimedResourceForReport     general.ResourceList
 	ReservedResourceForAllocate       general.ResourceList
 	ReservedResourceForReclaimedCores general.ResourceList
+	ReclaimSuppressionWindows         []string
 
 	*cpuheadroom.CPUHeadroomOptions
 	*memoryheadroom.MemoryHeadroomOptions
@@ -77,6 +78,9 @@ func (o *ReclaimedResourceOptions) AddFlags(fss *cliflag.NamedFlagSets) {
 		"reserved reclaimed resource actually not allocate to reclaimed resource")
 	fs.Var(&o.ReservedResourceForReclaimedCores, "reserved-resource-for-reclaimed-cores",
 		"reserved resources for reclaimed_cores pods")
+	fs.StringSliceVar(&o.ReclaimSuppressionWindows, "reclaim-suppression-windows", o.ReclaimSuppressionWindows,
+		"time windows in local time during which reclaim is suppressed even if enabled, should be formatted as "+
+			"'mon-fri@09:00-18:00,sat@10:00-12:00', and window without weekdays such as '22:00-06:00' applies to every day")
 
 	o.CPUHeadroomOptions.AddFlags(fss)
 	o.MemoryHeadroomOptions.AddFlags(fss)
@@ -91,6 +95,16 @@ func (o *ReclaimedResourceOptions) ApplyTo(c *reclaimedresource.ReclaimedResourc
 	c.ReservedResourceForAllocate = v1.ResourceList(o.ReservedResourceForAllocate)
 	c.MinReclaimedResourceForAllocate = v1.ResourceList(o.ReservedResourceForReclaimedCores)
 
+	c.ReclaimSuppressionWindows = nil
+	for _, windowStr := range o.ReclaimSuppressionWindows {
+		window, err := reclaimedresource.ParseReclaimSuppressionWindow(windowStr)
+		if err != nil {
+			errList = append(errList, err)
+			continue
+		}
+		c.ReclaimSuppressionWindows = append(c.ReclaimSuppressionWindows, window)
+	}
+
 	errList = append(errList, o.CPUHeadroomOptions.ApplyTo(c.CPUHeadroomConfiguration))
 	errList = append(errList, o.MemoryHeadroomOptions.ApplyTo(c.MemoryHeadroomConfiguration))
 	return errors.NewAggregate(errList)
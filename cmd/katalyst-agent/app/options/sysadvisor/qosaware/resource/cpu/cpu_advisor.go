@@ -17,7 +17,9 @@ limitations under the License.
 package cpu
 
 import (
+	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
 	"k8s.io/apimachinery/pkg/util/errors"
@@ -25,6 +27,7 @@ import (
 	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options/sysadvisor/qosaware/resource/cpu/headroom"
 	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options/sysadvisor/qosaware/resource/cpu/provision"
 	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options/sysadvisor/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
 	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu"
 )
@@ -36,10 +39,50 @@ type CPUAdvisorOptions struct {
 	CPUProvisionAssembler      string
 	CPUHeadroomAssembler       string
 
+	ProvisionCircuitBreakerThreshold int
+	ProvisionCircuitBreakerCooldown  time.Duration
+
+	// HeadroomConfidenceWindow is the duration for headroom confidence to recover or decay
+	HeadroomConfidenceWindow time.Duration
+
+	// ReclaimPoolName is the name of reclaim pool in provision results and pool info from cpu plugin
+	ReclaimPoolName string
+
+	// ProvisionForcePushInterval is the interval to push unchanged provision results as heartbeats
+	ProvisionForcePushInterval time.Duration
+
+	// ReclaimEventThreshold and ReclaimEventInterval control events of reclaim decisions on the node
+	ReclaimEventThreshold int
+	ReclaimEventInterval  time.Duration
+
+	// ReclaimClassLabelKey and ReclaimClassQuotas cap reclaimed cores allocated by each reclaim workload class
+	ReclaimClassLabelKey string
+	ReclaimClassQuotas   map[string]int
+
+	// EvictionRiskWindow is the number of usage samples to estimate eviction risk of reclaimed workloads
+	EvictionRiskWindow int
+
+	// HeadroomTrendRetention is the duration of headroom history retained to estimate headroom trend
+	HeadroomTrendRetention time.Duration
+
+	// ProvisionAssemblyTimeout bounds each provision assembling, and zero means no timeout
+	ProvisionAssemblyTimeout time.Duration
+
+	// ReclaimHeadroomChunkSize is the chunk advertised reclaim headroom is rounded down to a multiple of
+	ReclaimHeadroomChunkSize int
+
+	// EnableThrottleAwareHeadroom scales headroom by the effective cpu capacity ratio
+	EnableThrottleAwareHeadroom bool
+
+	// EnableResultDiffLogging logs pool entries changed between consecutive provision results
+	EnableResultDiffLogging bool
+
 	*headroom.CPUHeadroomPolicyOptions
 	*provision.CPUProvisionPolicyOptions
 	*region.CPURegionOptions
 	*CPUIsolationOptions
+	*CPUProvisionAssemblerOptions
+	*CPUHeadroomAssemblerOptions
 }
 
 // NewCPUAdvisorOptions creates a new Options with a default config
@@ -55,12 +98,23 @@ func NewCPUAdvisorOptions() *CPUAdvisorOptions {
 			string(types.QoSRegionTypeIsolation):              string(types.CPUHeadroomPolicyCanonical),
 			string(types.QoSRegionTypeDedicatedNumaExclusive): string(types.CPUHeadroomPolicyCanonical),
 		},
-		CPUProvisionAssembler:     string(types.CPUProvisionAssemblerCommon),
-		CPUHeadroomAssembler:      string(types.CPUHeadroomAssemblerCommon),
-		CPUHeadroomPolicyOptions:  headroom.NewCPUHeadroomPolicyOptions(),
-		CPUProvisionPolicyOptions: provision.NewCPUProvisionPolicyOptions(),
-		CPURegionOptions:          region.NewCPURegionOptions(),
-		CPUIsolationOptions:       NewCPUIsolationOptions(),
+		CPUProvisionAssembler:           string(types.CPUProvisionAssemblerCommon),
+		CPUHeadroomAssembler:            string(types.CPUHeadroomAssemblerCommon),
+		ProvisionCircuitBreakerCooldown: time.Minute,
+		HeadroomConfidenceWindow:        5 * time.Minute,
+		ReclaimPoolName:                 state.PoolNameReclaim,
+		ProvisionForcePushInterval:      time.Minute,
+		ReclaimEventInterval:            5 * time.Minute,
+		ReclaimClassLabelKey:            "katalyst.kubewharf.io/reclaim-class",
+		ReclaimClassQuotas:              map[string]int{},
+		EvictionRiskWindow:              10,
+		HeadroomTrendRetention:          10 * time.Minute,
+		CPUHeadroomPolicyOptions:        headroom.NewCPUHeadroomPolicyOptions(),
+		CPUProvisionPolicyOptions:       provision.NewCPUProvisionPolicyOptions(),
+		CPURegionOptions:                region.NewCPURegionOptions(),
+		CPUIsolationOptions:             NewCPUIsolationOptions(),
+		CPUProvisionAssemblerOptions:    NewCPUProvisionAssemblerOptions(),
+		CPUHeadroomAssemblerOptions:     NewCPUHeadroomAssemblerOptions(),
 	}
 }
 
@@ -76,11 +130,46 @@ func (o *CPUAdvisorOptions) AddFlags(fs *pflag.FlagSet) {
 		"cpu provision assembler for cpu advisor to generate node provision result from region provision results")
 	fs.StringVar(&o.CPUHeadroomAssembler, "cpu-headroom-assembler", o.CPUHeadroomAssembler,
 		"cpu headroom assembler for cpu advisor to generate node headroom from region headroom or node level policy")
+	fs.IntVar(&o.ProvisionCircuitBreakerThreshold, "cpu-provision-circuit-breaker-threshold", o.ProvisionCircuitBreakerThreshold,
+		"consecutive provision assembling failures to freeze the last-known-good result, 0 means circuit breaker disabled")
+	fs.DurationVar(&o.ProvisionCircuitBreakerCooldown, "cpu-provision-circuit-breaker-cooldown", o.ProvisionCircuitBreakerCooldown,
+		"duration to keep serving the frozen provision result before testing recovery")
+	fs.DurationVar(&o.HeadroomConfidenceWindow, "cpu-headroom-confidence-window", o.HeadroomConfidenceWindow,
+		"duration for cpu headroom confidence to recover after regions are created or topology changes, "+
+			"and to decay after provision stops being assembled")
+	fs.StringVar(&o.ReclaimPoolName, "cpu-reclaim-pool-name", o.ReclaimPoolName,
+		"name of reclaim pool in provision results and pool info from cpu plugin")
+	fs.DurationVar(&o.ProvisionForcePushInterval, "cpu-provision-force-push-interval", o.ProvisionForcePushInterval,
+		"interval to push provision results to cpu server even if pool entries are unchanged, 0 means pushing every result")
+	fs.IntVar(&o.ReclaimEventThreshold, "cpu-reclaim-event-threshold", o.ReclaimEventThreshold,
+		"total reclaim pool size below which a warning event is emitted on the node, 0 means disabled")
+	fs.DurationVar(&o.ReclaimEventInterval, "cpu-reclaim-event-interval", o.ReclaimEventInterval,
+		"min interval between events of the same reason on cpu reclaim decisions")
+	fs.StringVar(&o.ReclaimClassLabelKey, "cpu-reclaim-class-label-key", o.ReclaimClassLabelKey,
+		"pod label key identifying the reclaim workload class of reclaimed pods")
+	fs.StringToIntVar(&o.ReclaimClassQuotas, "cpu-reclaim-class-quotas", o.ReclaimClassQuotas,
+		"max reclaimed cores allocated by each reclaim workload class, should be formatted as 'batch=16,flink=8'")
+	fs.IntVar(&o.EvictionRiskWindow, "cpu-eviction-risk-window", o.EvictionRiskWindow,
+		"number of recent share and isolation usage samples to estimate eviction risk of reclaimed workloads")
+	fs.DurationVar(&o.HeadroomTrendRetention, "cpu-headroom-trend-retention", o.HeadroomTrendRetention,
+		"duration of cpu headroom history retained to estimate headroom trend")
+	fs.DurationVar(&o.ProvisionAssemblyTimeout, "cpu-provision-assembly-timeout", o.ProvisionAssemblyTimeout,
+		"timeout of each cpu provision assembling, after which the last-known-good result is served, 0 means no timeout")
+	fs.IntVar(&o.ReclaimHeadroomChunkSize, "cpu-reclaim-headroom-chunk-size", o.ReclaimHeadroomChunkSize,
+		"minimum viable cores of reclaimed workloads, and advertised reclaim headroom is rounded down to a multiple of it, "+
+			"0 means no rounding")
+	fs.BoolVar(&o.EnableThrottleAwareHeadroom, "cpu-enable-throttle-aware-headroom", o.EnableThrottleAwareHeadroom,
+		"if set as true, cpu headroom is scaled by the ratio of effective cpu capacity to the nominal one, "+
+			"which is reduced by cpufreq scaling or thermal throttling")
+	fs.BoolVar(&o.EnableResultDiffLogging, "cpu-enable-result-diff-logging", o.EnableResultDiffLogging,
+		"if set as true, pool entries added, removed or changed between consecutive cpu provision results are logged")
 
 	o.CPUHeadroomPolicyOptions.AddFlags(fs)
 	o.CPUProvisionPolicyOptions.AddFlags(fs)
 	o.CPURegionOptions.AddFlags(fs)
 	o.CPUIsolationOptions.AddFlags(fs)
+	o.CPUProvisionAssemblerOptions.AddFlags(fs)
+	o.CPUHeadroomAssemblerOptions.AddFlags(fs)
 }
 
 // ApplyTo fills up config with options
@@ -105,9 +194,60 @@ func (o *CPUAdvisorOptions) ApplyTo(c *cpu.CPUAdvisorConfiguration) error {
 	c.HeadroomAssembler = types.CPUHeadroomAssemblerName(o.CPUHeadroomAssembler)
 
 	var errList []error
+	if o.ProvisionCircuitBreakerThreshold < 0 || o.ProvisionCircuitBreakerCooldown < 0 {
+		errList = append(errList, fmt.Errorf("provision circuit breaker threshold and cooldown must not be negative"))
+	}
+	c.ProvisionCircuitBreakerThreshold = o.ProvisionCircuitBreakerThreshold
+	c.ProvisionCircuitBreakerCooldown = o.ProvisionCircuitBreakerCooldown
+	if o.HeadroomConfidenceWindow < 0 {
+		errList = append(errList, fmt.Errorf("headroom confidence window must not be negative"))
+	}
+	c.HeadroomConfidenceWindow = o.HeadroomConfidenceWindow
+	if o.ReclaimPoolName == "" {
+		errList = append(errList, fmt.Errorf("reclaim pool name must not be empty"))
+	}
+	c.ReclaimPoolName = o.ReclaimPoolName
+	if o.ProvisionForcePushInterval < 0 {
+		errList = append(errList, fmt.Errorf("provision force push interval must not be negative"))
+	}
+	c.ProvisionForcePushInterval = o.ProvisionForcePushInterval
+	if o.ReclaimEventThreshold < 0 || o.ReclaimEventInterval < 0 {
+		errList = append(errList, fmt.Errorf("reclaim event threshold and interval must not be negative"))
+	}
+	c.ReclaimEventThreshold = o.ReclaimEventThreshold
+	c.ReclaimEventInterval = o.ReclaimEventInterval
+	c.ReclaimClassLabelKey = o.ReclaimClassLabelKey
+	c.ReclaimClassQuotas = make(map[string]int)
+	for className, quota := range o.ReclaimClassQuotas {
+		if quota < 0 {
+			errList = append(errList, fmt.Errorf("reclaim quota of class %v must not be negative", className))
+			continue
+		}
+		c.ReclaimClassQuotas[className] = quota
+	}
+	if o.EvictionRiskWindow < 1 {
+		errList = append(errList, fmt.Errorf("eviction risk window must be positive"))
+	}
+	c.EvictionRiskWindow = o.EvictionRiskWindow
+	if o.HeadroomTrendRetention <= 0 {
+		errList = append(errList, fmt.Errorf("headroom trend retention must be positive"))
+	}
+	c.HeadroomTrendRetention = o.HeadroomTrendRetention
+	if o.ProvisionAssemblyTimeout < 0 {
+		errList = append(errList, fmt.Errorf("provision assembly timeout must not be negative"))
+	}
+	c.ProvisionAssemblyTimeout = o.ProvisionAssemblyTimeout
+	if o.ReclaimHeadroomChunkSize < 0 {
+		errList = append(errList, fmt.Errorf("reclaim headroom chunk size must not be negative"))
+	}
+	c.ReclaimHeadroomChunkSize = o.ReclaimHeadroomChunkSize
+	c.EnableThrottleAwareHeadroom = o.EnableThrottleAwareHeadroom
+	c.EnableResultDiffLogging = o.EnableResultDiffLogging
 	errList = append(errList, o.CPUHeadroomPolicyOptions.ApplyTo(c.CPUHeadroomPolicyConfiguration))
 	errList = append(errList, o.CPUProvisionPolicyOptions.ApplyTo(c.CPUProvisionPolicyConfiguration))
 	errList = append(errList, o.CPURegionOptions.ApplyTo(c.CPURegionConfiguration))
 	errList = append(errList, o.CPUIsolationOptions.ApplyTo(c.CPUIsolationConfiguration))
+	errList = append(errList, o.CPUProvisionAssemblerOptions.ApplyTo(c.CPUProvisionAssemblerConfiguration))
+	errList = append(errList, o.CPUHeadroomAssemblerOptions.ApplyTo(c.CPUHeadroomAssemblerConfiguration))
 	return errors.NewAggregate(errList)
 }
@@ -0,0 +1,58 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu"
+)
+
+// CPUHeadroomAssemblerOptions holds the configurations for cpu headroom assembler
+type CPUHeadroomAssemblerOptions struct {
+	// SpikeBufferWindowSize and SpikeBufferSensitivity define the buffer subtracted from cpu headroom
+	// according to volatility of recent node cpu utilization
+	SpikeBufferWindowSize  int
+	SpikeBufferSensitivity float64
+}
+
+// NewCPUHeadroomAssemblerOptions creates a new Options with a default config
+func NewCPUHeadroomAssemblerOptions() *CPUHeadroomAssemblerOptions {
+	return &CPUHeadroomAssemblerOptions{
+		SpikeBufferSensitivity: 2,
+	}
+}
+
+// AddFlags adds flags to the specified FlagSet.
+func (o *CPUHeadroomAssemblerOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.IntVar(&o.SpikeBufferWindowSize, "cpu-headroom-spike-buffer-window-size", o.SpikeBufferWindowSize,
+		"number of recent node cpu utilization samples to estimate spike buffer subtracted from cpu headroom, 0 disables the buffer")
+	fs.Float64Var(&o.SpikeBufferSensitivity, "cpu-headroom-spike-buffer-sensitivity", o.SpikeBufferSensitivity,
+		"multiple of standard deviation of node cpu utilization reserved as spike buffer")
+}
+
+// ApplyTo fills up config with options
+func (o *CPUHeadroomAssemblerOptions) ApplyTo(c *cpu.CPUHeadroomAssemblerConfiguration) error {
+	if o.SpikeBufferWindowSize < 0 || o.SpikeBufferSensitivity < 0 {
+		return fmt.Errorf("spike buffer window size and sensitivity must not be negative")
+	}
+	c.SpikeBufferWindowSize = o.SpikeBufferWindowSize
+	c.SpikeBufferSensitivity = o.SpikeBufferSensitivity
+	return nil
+}
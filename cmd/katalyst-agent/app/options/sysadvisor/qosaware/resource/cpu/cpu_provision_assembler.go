@@ -0,0 +1,529 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu"
+)
+
+// CPUProvisionAssemblerOptions holds the configurations for cpu provision assembler
+type CPUProvisionAssemblerOptions struct {
+	// SharePoolMinSizes defines the min size for each share pool,
+	// key indicates the owner pool name and val indicates the min size of it
+	SharePoolMinSizes map[string]int
+
+	// SharePoolPriorities defines the priority for each share pool, key indicates the owner pool name
+	SharePoolPriorities map[string]int
+
+	// ReclaimRampUpStep and ReclaimRampUpRatio limit the growth of each reclaim pool entry
+	// between consecutive updates, and the larger one takes effect if both are set
+	ReclaimRampUpStep  int
+	ReclaimRampUpRatio float64
+
+	// ErrorOnCapacityOvercommit returns error for assembling if the sum of all pool entries
+	// exceeds node capacity
+	ErrorOnCapacityOvercommit bool
+
+	// RegionWarmUpWindow is the duration after a region is first seen, during which the
+	// provision of share and isolation regions is replaced by RegionWarmUpSize
+	RegionWarmUpWindow time.Duration
+	RegionWarmUpSize   int
+
+	// EnablePoolSizeDriftCheck compares share and isolation pool sizes from assembling against
+	// those recorded in metacache, and emits metrics if they differ beyond PoolSizeDriftThreshold
+	EnablePoolSizeDriftCheck bool
+	PoolSizeDriftThreshold   int
+
+	// ReclaimCeiling is the max total cores of reclaim pool across all numas
+	ReclaimCeiling int
+
+	// ExcludedReclaimNumas are numas never offered for reclaim
+	ExcludedReclaimNumas []int
+
+	// SharePoolGrowthCooldownCycles is the number of consecutive cycles a share pool requirement
+	// must stay elevated before the pool grows
+	SharePoolGrowthCooldownCycles int
+
+	// SharePoolGrowStep and SharePoolShrinkStep limit how many cores each share pool can grow or shrink between cycles
+	SharePoolGrowStep   int
+	SharePoolShrinkStep int
+
+	// EnableReclaimPressureFeedback adjusts reclaim pool of non-binding numas by the pressure of reclaim pool
+	EnableReclaimPressureFeedback  bool
+	ReclaimPressureHighThreshold   float64
+	ReclaimPressureLowThreshold    float64
+	ReclaimPressureSustainedCycles int
+	ReclaimPressureAdjustStep      int
+	SharePoolIdleRatio             float64
+
+	// ReservePoolGrowthStep limits the growth of reserve pool on each numa between consecutive updates
+	ReservePoolGrowthStep int
+
+	// DisableNonBindingReclaim suppresses reclaim pool entry of non-binding numas
+	DisableNonBindingReclaim bool
+
+	// EnablePartialAssembly skips regions failing in assembling instead of failing the whole assembly
+	EnablePartialAssembly bool
+
+	// EnableSocketReclaimView rolls up reclaim pool entries of numas into their sockets
+	EnableSocketReclaimView bool
+
+	// RegionProvisionStalenessThreshold is the duration provision of a region may keep unchanged before reported as stale
+	RegionProvisionStalenessThreshold time.Duration
+
+	// EnableReservedForReclaimHandOff hands off reserved for reclaim unsatisfiable on saturated numas to non-binding numas
+	EnableReservedForReclaimHandOff bool
+
+	// CriticalIsolationRegions are names of isolation regions always keeping their upper sizes under contention
+	CriticalIsolationRegions []string
+
+	// RegionGroupPools and RegionGroupBudgets define share pools of each region group and the combined budget of it
+	RegionGroupPools   map[string]string
+	RegionGroupBudgets map[string]int
+
+	// SharePoolNUMAAffinity pins share pools to the given non-binding numa
+	SharePoolNUMAAffinity map[string]int
+
+	// ReservedForReclaimPercentage is the percentage of numa capacity reserved for reclaim
+	ReservedForReclaimPercentage float64
+	ReservedForReclaimRoundUp    bool
+	// ReservedForReclaimOverrides are absolute reserved for reclaim of given numas overriding the percentage
+	ReservedForReclaimOverrides map[string]int
+
+	// ReservePoolComposition is the size of each named sub-reserve on each numa composing reserve pool
+	ReservePoolComposition map[string]int
+
+	// EnablePodScaledReservePool scales reserve pool on each numa with the number of pods on node
+	EnablePodScaledReservePool bool
+	PodScaledReservePoolBase   int
+	PodScaledReservePoolPerPod float64
+	PodScaledReservePoolMax    int
+
+	// BootReservePoolSize is the conservative reserve pool on each numa applied in boot phase
+	BootReservePoolSize int
+	BootPhaseMinPods    int
+	BootPhaseMinUptime  time.Duration
+
+	// EnableReclaimFairness trims reclaim pool of binding numas exceeding the least one by more than the max skew
+	EnableReclaimFairness  bool
+	ReclaimFairnessMaxSkew int
+
+	// EnableSharePoolForecast pre-shrinks reclaim pool by forecasting share pool requirement
+	EnableSharePoolForecast   bool
+	SharePoolForecastModel    string
+	SharePoolForecastWindow   int
+	SharePoolForecastHorizon  int
+	SharePoolForecastEMAAlpha float64
+
+	// ReclaimReservationName, ReclaimReservationSize and ReclaimReservationPreferredNumas define a named reclaim
+	// reservation carved out of numas available resource before slack is donated to reclaim pool
+	ReclaimReservationName           string
+	ReclaimReservationSize           int
+	ReclaimReservationPreferredNumas []int
+
+	// ReclaimCriticalReservationSize is cores of reclaim pool protected for system-critical batch
+	ReclaimCriticalReservationSize int
+
+	// IsolationContentionMargin is the margin in cores to enter or leave isolation contention
+	IsolationContentionMargin int
+
+	// EnableUtilizationWeightedRegulation shrinks share pools under contention inversely to their utilization
+	EnableUtilizationWeightedRegulation bool
+
+	// ReclaimSmoothingTimeConstant is the time constant of low-pass filter smoothing reclaim pool growth
+	ReclaimSmoothingTimeConstant time.Duration
+}
+
+// NewCPUProvisionAssemblerOptions creates a new Options with a default config
+func NewCPUProvisionAssemblerOptions() *CPUProvisionAssemblerOptions {
+	return &CPUProvisionAssemblerOptions{
+		SharePoolMinSizes:              map[string]int{},
+		SharePoolPriorities:            map[string]int{},
+		RegionGroupPools:               map[string]string{},
+		RegionGroupBudgets:             map[string]int{},
+		SharePoolNUMAAffinity:          map[string]int{},
+		ReservedForReclaimOverrides:    map[string]int{},
+		ReservePoolComposition:         map[string]int{},
+		SharePoolForecastModel:         cpu.SharePoolForecastModelEMA,
+		SharePoolForecastWindow:        6,
+		SharePoolForecastHorizon:       1,
+		SharePoolForecastEMAAlpha:      0.5,
+		ReclaimPressureHighThreshold:   1.5,
+		ReclaimPressureLowThreshold:    0.5,
+		ReclaimPressureSustainedCycles: 3,
+		ReclaimPressureAdjustStep:      2,
+		SharePoolIdleRatio:             0.3,
+	}
+}
+
+// AddFlags adds flags to the specified FlagSet.
+func (o *CPUProvisionAssemblerOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.StringToIntVar(&o.SharePoolMinSizes, "cpu-provision-share-pool-min-sizes", o.SharePoolMinSizes,
+		"min size of each share pool kept by provision assembler even if its requirement is lower, "+
+			"should be formatted as 'share=4,batch=2'")
+	fs.StringToIntVar(&o.SharePoolPriorities, "cpu-provision-share-pool-priorities", o.SharePoolPriorities,
+		"priority of each share pool, and pools with higher priority are satisfied first under contention, "+
+			"should be formatted as 'share=1,batch=0'")
+	fs.IntVar(&o.ReclaimRampUpStep, "cpu-provision-reclaim-ramp-up-step", o.ReclaimRampUpStep,
+		"max cores each reclaim pool entry can grow between consecutive updates, 0 means no limitation")
+	fs.Float64Var(&o.ReclaimRampUpRatio, "cpu-provision-reclaim-ramp-up-ratio", o.ReclaimRampUpRatio,
+		"max ratio each reclaim pool entry can grow between consecutive updates, 0 means no limitation")
+	fs.BoolVar(&o.ErrorOnCapacityOvercommit, "cpu-provision-error-on-capacity-overcommit", o.ErrorOnCapacityOvercommit,
+		"if set as true, provision result will be dropped if the sum of all pool entries exceeds node capacity")
+	fs.DurationVar(&o.RegionWarmUpWindow, "cpu-provision-region-warm-up-window", o.RegionWarmUpWindow,
+		"duration after a share or isolation region is created, during which its provision is replaced by warm-up size, "+
+			"0 means no warm-up")
+	fs.IntVar(&o.RegionWarmUpSize, "cpu-provision-region-warm-up-size", o.RegionWarmUpSize,
+		"conservative size used by share and isolation regions in warm-up window")
+	fs.BoolVar(&o.EnablePoolSizeDriftCheck, "cpu-provision-enable-pool-size-drift-check", o.EnablePoolSizeDriftCheck,
+		"if set as true, share and isolation pool sizes will be checked against those recorded in metacache after assembling")
+	fs.IntVar(&o.PoolSizeDriftThreshold, "cpu-provision-pool-size-drift-threshold", o.PoolSizeDriftThreshold,
+		"max cores a pool size can drift from metacache without emitting drift metrics")
+	fs.IntVar(&o.ReclaimCeiling, "cpu-provision-reclaim-ceiling", o.ReclaimCeiling,
+		"max total cores of reclaim pool across all numas, 0 means no ceiling")
+	fs.IntSliceVar(&o.ExcludedReclaimNumas, "cpu-provision-excluded-reclaim-numas", o.ExcludedReclaimNumas,
+		"numas never offered for reclaim, while they can still host share and isolation pools")
+	fs.IntVar(&o.SharePoolGrowthCooldownCycles, "cpu-provision-share-pool-growth-cooldown-cycles", o.SharePoolGrowthCooldownCycles,
+		"consecutive cycles a share pool requirement must stay elevated before the pool grows, 0 means growing immediately")
+	fs.IntVar(&o.SharePoolGrowStep, "cpu-provision-share-pool-grow-step", o.SharePoolGrowStep,
+		"max cores each share pool can grow between consecutive cycles, 0 means no limitation")
+	fs.IntVar(&o.SharePoolShrinkStep, "cpu-provision-share-pool-shrink-step", o.SharePoolShrinkStep,
+		"max cores each share pool can shrink between consecutive cycles, 0 means no limitation")
+	fs.BoolVar(&o.EnableReclaimPressureFeedback, "cpu-provision-enable-reclaim-pressure-feedback", o.EnableReclaimPressureFeedback,
+		"if set as true, reclaim pool of non-binding numas will be adjusted by the pressure of reclaim pool")
+	fs.Float64Var(&o.ReclaimPressureHighThreshold, "cpu-provision-reclaim-pressure-high-threshold", o.ReclaimPressureHighThreshold,
+		"1-min load of reclaim cgroup per reclaim core, above which growth of reclaim pool is held back")
+	fs.Float64Var(&o.ReclaimPressureLowThreshold, "cpu-provision-reclaim-pressure-low-threshold", o.ReclaimPressureLowThreshold,
+		"1-min load of reclaim cgroup per reclaim core, below which idle cores of share pool are moved to reclaim pool")
+	fs.IntVar(&o.ReclaimPressureSustainedCycles, "cpu-provision-reclaim-pressure-sustained-cycles", o.ReclaimPressureSustainedCycles,
+		"consecutive cycles reclaim pressure must stay high or low before taking effect")
+	fs.IntVar(&o.ReclaimPressureAdjustStep, "cpu-provision-reclaim-pressure-adjust-step", o.ReclaimPressureAdjustStep,
+		"max idle cores of share pool moved to reclaim pool in each cycle under low reclaim pressure")
+	fs.Float64Var(&o.SharePoolIdleRatio, "cpu-provision-share-pool-idle-ratio", o.SharePoolIdleRatio,
+		"usage ratio of share pool below which it is regarded as idle")
+	fs.IntVar(&o.ReservePoolGrowthStep, "cpu-provision-reserve-pool-growth-step", o.ReservePoolGrowthStep,
+		"max cores reserve pool on each numa can grow between consecutive updates, 0 means no limitation")
+	fs.BoolVar(&o.DisableNonBindingReclaim, "cpu-provision-disable-non-binding-reclaim", o.DisableNonBindingReclaim,
+		"if set as true, reclaim pool is only offered on binding numas, and share pools keep all slack of non-binding numas")
+	fs.BoolVar(&o.EnablePartialAssembly, "cpu-provision-enable-partial-assembly", o.EnablePartialAssembly,
+		"if set as true, regions failing in assembling are skipped with their capacity reserved, instead of failing the whole assembly")
+	fs.BoolVar(&o.EnableSocketReclaimView, "cpu-provision-enable-socket-reclaim-view", o.EnableSocketReclaimView,
+		"if set as true, reclaim pool entries of numas are rolled up into their sockets after each assembling")
+	fs.DurationVar(&o.RegionProvisionStalenessThreshold, "cpu-provision-region-provision-staleness-threshold", o.RegionProvisionStalenessThreshold,
+		"duration provision of a region may keep unchanged while being refreshed before reported as stale, 0 disables the check")
+	fs.BoolVar(&o.EnableReservedForReclaimHandOff, "cpu-provision-enable-reserved-for-reclaim-hand-off", o.EnableReservedForReclaimHandOff,
+		"if set as true, reserved for reclaim unsatisfiable on saturated dedicated numas is handed off to non-binding numas")
+	fs.StringSliceVar(&o.CriticalIsolationRegions, "cpu-provision-critical-isolation-regions", o.CriticalIsolationRegions,
+		"names of isolation regions always keeping their upper sizes under contention, "+
+			"and names of non-exclusive isolation regions are their origin owner pool names")
+	fs.StringToStringVar(&o.RegionGroupPools, "cpu-provision-region-group-pools", o.RegionGroupPools,
+		"share pools of each region group regulated against a combined budget, "+
+			"should be formatted as 'tenant-a=share-a1/share-a2,tenant-b=share-b'")
+	fs.StringToIntVar(&o.RegionGroupBudgets, "cpu-provision-region-group-budgets", o.RegionGroupBudgets,
+		"combined budget of each region group, should be formatted as 'tenant-a=8,tenant-b=4'")
+	fs.StringToIntVar(&o.SharePoolNUMAAffinity, "cpu-provision-share-pool-numa-affinity", o.SharePoolNUMAAffinity,
+		"numa each share pool is pinned to, and pinned pools are carved out of the numa instead of the share budget, "+
+			"should be formatted as 'share-cache=0'")
+	fs.Float64Var(&o.ReservedForReclaimPercentage, "cpu-provision-reserved-for-reclaim-percentage", o.ReservedForReclaimPercentage,
+		"percentage of each numa capacity reserved for reclaim, 0 means keeping reserved for reclaim from dynamic configuration")
+	fs.BoolVar(&o.ReservedForReclaimRoundUp, "cpu-provision-reserved-for-reclaim-round-up", o.ReservedForReclaimRoundUp,
+		"round up reserved for reclaim resolved by percentage, otherwise it's rounded down")
+	fs.StringToIntVar(&o.ReservedForReclaimOverrides, "cpu-provision-reserved-for-reclaim-overrides", o.ReservedForReclaimOverrides,
+		"absolute reserved for reclaim of given numas overriding the percentage, should be formatted as '0=2,1=4'")
+	fs.StringToIntVar(&o.ReservePoolComposition, "cpu-provision-reserve-pool-composition", o.ReservePoolComposition,
+		"size of each named sub-reserve on each numa composing reserve pool, empty means reserve pool from qrm is used as is, "+
+			"should be formatted as 'system=1,kubelet=1'")
+	fs.BoolVar(&o.EnablePodScaledReservePool, "cpu-provision-enable-pod-scaled-reserve-pool", o.EnablePodScaledReservePool,
+		"if set, reserve pool on each numa is the base plus the per-pod increment times the number of pods on node, "+
+			"capped at the max, instead of reserve pool from qrm or composition")
+	fs.IntVar(&o.PodScaledReservePoolBase, "cpu-provision-pod-scaled-reserve-pool-base", o.PodScaledReservePoolBase,
+		"base size of pod scaled reserve pool on each numa")
+	fs.Float64Var(&o.PodScaledReservePoolPerPod, "cpu-provision-pod-scaled-reserve-pool-per-pod", o.PodScaledReservePoolPerPod,
+		"cores added to pod scaled reserve pool on each numa per pod on node")
+	fs.IntVar(&o.PodScaledReservePoolMax, "cpu-provision-pod-scaled-reserve-pool-max", o.PodScaledReservePoolMax,
+		"max size of pod scaled reserve pool on each numa, 0 means no cap")
+	fs.IntVar(&o.BootReservePoolSize, "cpu-provision-boot-reserve-pool-size", o.BootReservePoolSize,
+		"min reserve pool on each numa in boot phase, until the node has enough healthy pods or uptime, 0 means disabled")
+	fs.IntVar(&o.BootPhaseMinPods, "cpu-provision-boot-phase-min-pods", o.BootPhaseMinPods,
+		"number of healthy pods ending boot phase, 0 means not ended by pods")
+	fs.DurationVar(&o.BootPhaseMinUptime, "cpu-provision-boot-phase-min-uptime", o.BootPhaseMinUptime,
+		"uptime of provision assembler ending boot phase, 0 means not ended by uptime")
+	fs.BoolVar(&o.EnableReclaimFairness, "cpu-provision-enable-reclaim-fairness", o.EnableReclaimFairness,
+		"if set, reclaim pool of binding numas is rebalanced so that none exceeds the least one by more than the max skew")
+	fs.IntVar(&o.ReclaimFairnessMaxSkew, "cpu-provision-reclaim-fairness-max-skew", o.ReclaimFairnessMaxSkew,
+		"max cores by which reclaim pool of a binding numa may exceed the least one if reclaim fairness is enabled")
+	fs.BoolVar(&o.EnableSharePoolForecast, "cpu-provision-enable-share-pool-forecast", o.EnableSharePoolForecast,
+		"pre-shrink reclaim pool by forecasting share pool requirement from recent provision values")
+	fs.StringVar(&o.SharePoolForecastModel, "cpu-provision-share-pool-forecast-model", o.SharePoolForecastModel,
+		"model to estimate trend of share pool requirement, either ema or linear")
+	fs.IntVar(&o.SharePoolForecastWindow, "cpu-provision-share-pool-forecast-window", o.SharePoolForecastWindow,
+		"number of recent provision values to forecast share pool requirement from")
+	fs.IntVar(&o.SharePoolForecastHorizon, "cpu-provision-share-pool-forecast-horizon", o.SharePoolForecastHorizon,
+		"number of cycles ahead to forecast share pool requirement")
+	fs.Float64Var(&o.SharePoolForecastEMAAlpha, "cpu-provision-share-pool-forecast-ema-alpha", o.SharePoolForecastEMAAlpha,
+		"smoothing factor of ema model for share pool forecast, within (0, 1]")
+	fs.StringVar(&o.ReclaimReservationName, "cpu-provision-reclaim-reservation-name", o.ReclaimReservationName,
+		"name of the reclaim reservation guaranteed regardless of share pool pressure")
+	fs.IntVar(&o.ReclaimReservationSize, "cpu-provision-reclaim-reservation-size", o.ReclaimReservationSize,
+		"cores of the reclaim reservation carved out before slack is donated to reclaim pool, 0 means disabled")
+	fs.IntSliceVar(&o.ReclaimReservationPreferredNumas, "cpu-provision-reclaim-reservation-preferred-numas", o.ReclaimReservationPreferredNumas,
+		"numas the reclaim reservation is carved out of before other numas")
+	fs.IntVar(&o.ReclaimCriticalReservationSize, "cpu-provision-reclaim-critical-reservation-size", o.ReclaimCriticalReservationSize,
+		"cores of reclaim pool protected for system-critical batch and never regarded as donatable, 0 means disabled")
+	fs.IntVar(&o.IsolationContentionMargin, "cpu-provision-isolation-contention-margin", o.IsolationContentionMargin,
+		"cores by which shares plus isolation upper sizes must exceed available to turn isolation to lower sizes, "+
+			"and drop below available to turn back to upper sizes, 0 means no hysteresis")
+	fs.BoolVar(&o.EnableUtilizationWeightedRegulation, "cpu-provision-enable-utilization-weighted-regulation",
+		o.EnableUtilizationWeightedRegulation, "if set, share pools are shrunk inversely to their recent utilization "+
+			"under contention instead of proportionally, so that busy pools keep more of their requirement")
+	fs.DurationVar(&o.ReclaimSmoothingTimeConstant, "cpu-provision-reclaim-smoothing-time-constant", o.ReclaimSmoothingTimeConstant,
+		"time constant of low-pass filter smoothing the growth of each reclaim pool entry, while shrinking takes effect "+
+			"immediately, 0 means no smoothing")
+}
+
+// ApplyTo fills up config with options
+func (o *CPUProvisionAssemblerOptions) ApplyTo(c *cpu.CPUProvisionAssemblerConfiguration) error {
+	sharePoolMinSizes := make(map[string]int)
+	for poolName, size := range o.SharePoolMinSizes {
+		if size < 0 {
+			return fmt.Errorf("min size of share pool %v must not be negative", poolName)
+		}
+		sharePoolMinSizes[poolName] = size
+	}
+	c.SharePoolMinSizes = sharePoolMinSizes
+
+	sharePoolPriorities := make(map[string]int)
+	for poolName, priority := range o.SharePoolPriorities {
+		sharePoolPriorities[poolName] = priority
+	}
+	c.SharePoolPriorities = sharePoolPriorities
+
+	if o.ReclaimRampUpStep < 0 {
+		return fmt.Errorf("reclaim ramp up step must not be negative")
+	}
+	c.ReclaimRampUpStep = o.ReclaimRampUpStep
+
+	if o.ReclaimRampUpRatio < 0 {
+		return fmt.Errorf("reclaim ramp up ratio must not be negative")
+	}
+	c.ReclaimRampUpRatio = o.ReclaimRampUpRatio
+	c.ErrorOnCapacityOvercommit = o.ErrorOnCapacityOvercommit
+
+	if o.RegionWarmUpWindow < 0 || o.RegionWarmUpSize < 0 {
+		return fmt.Errorf("region warm up window and size must not be negative")
+	}
+	c.RegionWarmUpWindow = o.RegionWarmUpWindow
+	c.RegionWarmUpSize = o.RegionWarmUpSize
+
+	if o.PoolSizeDriftThreshold < 0 {
+		return fmt.Errorf("pool size drift threshold must not be negative")
+	}
+	c.EnablePoolSizeDriftCheck = o.EnablePoolSizeDriftCheck
+	c.PoolSizeDriftThreshold = o.PoolSizeDriftThreshold
+
+	if o.ReclaimCeiling < 0 {
+		return fmt.Errorf("reclaim ceiling must not be negative")
+	}
+	c.ReclaimCeiling = o.ReclaimCeiling
+
+	for _, numaID := range o.ExcludedReclaimNumas {
+		if numaID < 0 {
+			return fmt.Errorf("excluded reclaim numa %v must not be negative", numaID)
+		}
+	}
+	c.ExcludedReclaimNumas = o.ExcludedReclaimNumas
+
+	if o.SharePoolGrowthCooldownCycles < 0 {
+		return fmt.Errorf("share pool growth cooldown cycles must not be negative")
+	}
+	c.SharePoolGrowthCooldownCycles = o.SharePoolGrowthCooldownCycles
+
+	if o.SharePoolGrowStep < 0 || o.SharePoolShrinkStep < 0 {
+		return fmt.Errorf("share pool grow and shrink steps must not be negative")
+	}
+	c.SharePoolGrowStep = o.SharePoolGrowStep
+	c.SharePoolShrinkStep = o.SharePoolShrinkStep
+
+	if o.ReclaimPressureLowThreshold < 0 || o.ReclaimPressureHighThreshold < o.ReclaimPressureLowThreshold {
+		return fmt.Errorf("reclaim pressure thresholds must satisfy 0 <= low <= high")
+	}
+	if o.ReclaimPressureSustainedCycles < 0 || o.ReclaimPressureAdjustStep < 0 || o.SharePoolIdleRatio < 0 {
+		return fmt.Errorf("reclaim pressure sustained cycles, adjust step and share pool idle ratio must not be negative")
+	}
+	c.EnableReclaimPressureFeedback = o.EnableReclaimPressureFeedback
+	c.ReclaimPressureHighThreshold = o.ReclaimPressureHighThreshold
+	c.ReclaimPressureLowThreshold = o.ReclaimPressureLowThreshold
+	c.ReclaimPressureSustainedCycles = o.ReclaimPressureSustainedCycles
+	c.ReclaimPressureAdjustStep = o.ReclaimPressureAdjustStep
+	c.SharePoolIdleRatio = o.SharePoolIdleRatio
+
+	if o.ReservePoolGrowthStep < 0 {
+		return fmt.Errorf("reserve pool growth step must not be negative")
+	}
+	c.ReservePoolGrowthStep = o.ReservePoolGrowthStep
+	c.DisableNonBindingReclaim = o.DisableNonBindingReclaim
+	c.EnablePartialAssembly = o.EnablePartialAssembly
+	c.EnableSocketReclaimView = o.EnableSocketReclaimView
+
+	if o.RegionProvisionStalenessThreshold < 0 {
+		return fmt.Errorf("region provision staleness threshold must not be negative")
+	}
+	c.RegionProvisionStalenessThreshold = o.RegionProvisionStalenessThreshold
+	c.EnableReservedForReclaimHandOff = o.EnableReservedForReclaimHandOff
+	c.CriticalIsolationRegions = sets.NewString(o.CriticalIsolationRegions...)
+
+	regionGroups := make(map[string]cpu.RegionGroup)
+	groupedPools := sets.NewString()
+	for groupName, pools := range o.RegionGroupPools {
+		budget, ok := o.RegionGroupBudgets[groupName]
+		if !ok || budget <= 0 {
+			return fmt.Errorf("budget of region group %v must be positive", groupName)
+		}
+
+		ownerPoolNames := sets.NewString()
+		for _, poolName := range strings.Split(pools, "/") {
+			if poolName == "" {
+				continue
+			}
+			if groupedPools.Has(poolName) {
+				return fmt.Errorf("share pool %v belongs to more than one region group", poolName)
+			}
+			groupedPools.Insert(poolName)
+			ownerPoolNames.Insert(poolName)
+		}
+		if ownerPoolNames.Len() == 0 {
+			return fmt.Errorf("region group %v has no share pool", groupName)
+		}
+		regionGroups[groupName] = cpu.RegionGroup{OwnerPoolNames: ownerPoolNames, Budget: budget}
+	}
+	for groupName := range o.RegionGroupBudgets {
+		if _, ok := o.RegionGroupPools[groupName]; !ok {
+			return fmt.Errorf("region group %v has budget but no share pool", groupName)
+		}
+	}
+	c.RegionGroups = regionGroups
+
+	sharePoolNUMAAffinity := make(map[string]int)
+	for poolName, numaID := range o.SharePoolNUMAAffinity {
+		if numaID < 0 {
+			return fmt.Errorf("numa affinity of share pool %v must not be negative", poolName)
+		}
+		sharePoolNUMAAffinity[poolName] = numaID
+	}
+	c.SharePoolNUMAAffinity = sharePoolNUMAAffinity
+
+	if o.ReservedForReclaimPercentage < 0 || o.ReservedForReclaimPercentage > 100 {
+		return fmt.Errorf("reserved for reclaim percentage %v must be within [0, 100]", o.ReservedForReclaimPercentage)
+	}
+	c.ReservedForReclaimPercentage = o.ReservedForReclaimPercentage
+	c.ReservedForReclaimRoundUp = o.ReservedForReclaimRoundUp
+	reservedForReclaimOverrides := make(map[int]int)
+	for numaStr, reserved := range o.ReservedForReclaimOverrides {
+		numaID, err := strconv.Atoi(numaStr)
+		if err != nil || numaID < 0 {
+			return fmt.Errorf("invalid numa %v of reserved for reclaim overrides", numaStr)
+		}
+		if reserved < 0 {
+			return fmt.Errorf("reserved for reclaim override of numa %v must not be negative", numaID)
+		}
+		reservedForReclaimOverrides[numaID] = reserved
+	}
+	c.ReservedForReclaimOverrides = reservedForReclaimOverrides
+
+	reservePoolComposition := make(map[string]int)
+	for name, size := range o.ReservePoolComposition {
+		if size < 0 {
+			return fmt.Errorf("size of sub-reserve %v must not be negative", name)
+		}
+		reservePoolComposition[name] = size
+	}
+	c.ReservePoolComposition = reservePoolComposition
+
+	if o.PodScaledReservePoolBase < 0 || o.PodScaledReservePoolPerPod < 0 || o.PodScaledReservePoolMax < 0 {
+		return fmt.Errorf("base, per-pod increment and max of pod scaled reserve pool must not be negative")
+	}
+	c.EnablePodScaledReservePool = o.EnablePodScaledReservePool
+	c.PodScaledReservePoolBase = o.PodScaledReservePoolBase
+	c.PodScaledReservePoolPerPod = o.PodScaledReservePoolPerPod
+	c.PodScaledReservePoolMax = o.PodScaledReservePoolMax
+
+	if o.BootReservePoolSize < 0 || o.BootPhaseMinPods < 0 || o.BootPhaseMinUptime < 0 {
+		return fmt.Errorf("boot reserve pool size, min pods and min uptime of boot phase must not be negative")
+	}
+	c.BootReservePoolSize = o.BootReservePoolSize
+	c.BootPhaseMinPods = o.BootPhaseMinPods
+	c.BootPhaseMinUptime = o.BootPhaseMinUptime
+
+	if o.ReclaimFairnessMaxSkew < 0 {
+		return fmt.Errorf("reclaim fairness max skew must not be negative")
+	}
+	c.EnableReclaimFairness = o.EnableReclaimFairness
+	c.ReclaimFairnessMaxSkew = o.ReclaimFairnessMaxSkew
+
+	if o.SharePoolForecastModel != cpu.SharePoolForecastModelEMA && o.SharePoolForecastModel != cpu.SharePoolForecastModelLinear {
+		return fmt.Errorf("unsupported share pool forecast model %v", o.SharePoolForecastModel)
+	}
+	if o.SharePoolForecastWindow < 2 || o.SharePoolForecastHorizon < 1 {
+		return fmt.Errorf("share pool forecast window must be at least 2 and horizon must be at least 1")
+	}
+	if o.SharePoolForecastEMAAlpha <= 0 || o.SharePoolForecastEMAAlpha > 1 {
+		return fmt.Errorf("share pool forecast ema alpha %v must be within (0, 1]", o.SharePoolForecastEMAAlpha)
+	}
+	c.EnableSharePoolForecast = o.EnableSharePoolForecast
+	c.SharePoolForecastModel = o.SharePoolForecastModel
+	c.SharePoolForecastWindow = o.SharePoolForecastWindow
+	c.SharePoolForecastHorizon = o.SharePoolForecastHorizon
+	c.SharePoolForecastEMAAlpha = o.SharePoolForecastEMAAlpha
+
+	if o.ReclaimReservationSize < 0 {
+		return fmt.Errorf("reclaim reservation size must not be negative")
+	}
+	for _, numaID := range o.ReclaimReservationPreferredNumas {
+		if numaID < 0 {
+			return fmt.Errorf("reclaim reservation preferred numa %v must not be negative", numaID)
+		}
+	}
+	c.ReclaimReservationName = o.ReclaimReservationName
+	c.ReclaimReservationSize = o.ReclaimReservationSize
+	c.ReclaimReservationPreferredNumas = o.ReclaimReservationPreferredNumas
+
+	if o.ReclaimCriticalReservationSize < 0 {
+		return fmt.Errorf("reclaim critical reservation size must not be negative")
+	}
+	c.ReclaimCriticalReservationSize = o.ReclaimCriticalReservationSize
+
+	if o.IsolationContentionMargin < 0 {
+		return fmt.Errorf("isolation contention margin must not be negative")
+	}
+	c.IsolationContentionMargin = o.IsolationContentionMargin
+	c.EnableUtilizationWeightedRegulation = o.EnableUtilizationWeightedRegulation
+
+	if o.ReclaimSmoothingTimeConstant < 0 {
+		return fmt.Errorf("reclaim smoothing time constant must not be negative")
+	}
+	c.ReclaimSmoothingTimeConstant = o.ReclaimSmoothingTimeConstant
+
+	return nil
+}
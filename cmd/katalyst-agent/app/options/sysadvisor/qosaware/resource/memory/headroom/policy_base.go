@@ -25,20 +25,24 @@ import (
 
 type MemoryHeadroomPolicyOptions struct {
 	MemoryPolicyCanonicalOptions *MemoryPolicyCanonicalOptions
+	MemoryPolicyNUMAAwareOptions *MemoryPolicyNUMAAwareOptions
 }
 
 func NewMemoryHeadroomPolicyOptions() *MemoryHeadroomPolicyOptions {
 	return &MemoryHeadroomPolicyOptions{
 		MemoryPolicyCanonicalOptions: NewMemoryPolicyCanonicalOptions(),
+		MemoryPolicyNUMAAwareOptions: NewMemoryPolicyNUMAAwareOptions(),
 	}
 }
 
 func (o *MemoryHeadroomPolicyOptions) AddFlags(fs *pflag.FlagSet) {
 	o.MemoryPolicyCanonicalOptions.AddFlags(fs)
+	o.MemoryPolicyNUMAAwareOptions.AddFlags(fs)
 }
 
 func (o *MemoryHeadroomPolicyOptions) ApplyTo(c *headroom.MemoryHeadroomPolicyConfiguration) error {
 	var errList []error
 	errList = append(errList, o.MemoryPolicyCanonicalOptions.ApplyTo(c.MemoryPolicyCanonicalConfiguration))
+	errList = append(errList, o.MemoryPolicyNUMAAwareOptions.ApplyTo(c.MemoryPolicyNUMAAwareConfiguration))
 	return errors.NewAggregate(errList)
 }
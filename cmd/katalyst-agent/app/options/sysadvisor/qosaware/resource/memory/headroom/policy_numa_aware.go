@@ -0,0 +1,55 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package headroom
+
+import (
+	"fmt"
+
+	"github.com/spf13/pflag"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/memory/headroom"
+)
+
+type MemoryPolicyNUMAAwareOptions struct {
+	InterleaveNUMAs []int
+}
+
+func NewMemoryPolicyNUMAAwareOptions() *MemoryPolicyNUMAAwareOptions {
+	return &MemoryPolicyNUMAAwareOptions{}
+}
+
+func (o *MemoryPolicyNUMAAwareOptions) AddFlags(fs *pflag.FlagSet) {
+	fs.IntSliceVar(&o.InterleaveNUMAs, "memory-headroom-numa-aware-interleave-numas", o.InterleaveNUMAs,
+		"numas that reclaimed workloads interleave memory allocations among, and headroom of them is bounded "+
+			"by the one with the least headroom; empty means reclaimed workloads are not interleaved")
+}
+
+func (o *MemoryPolicyNUMAAwareOptions) ApplyTo(c *headroom.MemoryPolicyNUMAAwareConfiguration) error {
+	numas := sets.NewInt()
+	for _, numaID := range o.InterleaveNUMAs {
+		if numaID < 0 {
+			return fmt.Errorf("illegal interleave numa %v", numaID)
+		}
+		if numas.Has(numaID) {
+			return fmt.Errorf("duplicated interleave numa %v", numaID)
+		}
+		numas.Insert(numaID)
+	}
+	c.InterleaveNUMAs = o.InterleaveNUMAs
+	return nil
+}
@@ -17,6 +17,9 @@ limitations under the License.
 package resource
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/spf13/pflag"
 	"k8s.io/apimachinery/pkg/util/errors"
 
@@ -27,7 +30,9 @@ import (
 
 // ResourceAdvisorOptions holds the configurations for resource advisors in qos aware plugin
 type ResourceAdvisorOptions struct {
-	ResourceAdvisors []string
+	ResourceAdvisors            []string
+	AbsentAdvisorAsZeroHeadroom bool
+	ResourceUpdateIntervals     map[string]string
 
 	*cpu.CPUAdvisorOptions
 	*memory.MemoryAdvisorOptions
@@ -36,15 +41,21 @@ type ResourceAdvisorOptions struct {
 // NewResourceAdvisorOptions creates a new Options with a default config
 func NewResourceAdvisorOptions() *ResourceAdvisorOptions {
 	return &ResourceAdvisorOptions{
-		ResourceAdvisors:     []string{"cpu", "memory"},
-		CPUAdvisorOptions:    cpu.NewCPUAdvisorOptions(),
-		MemoryAdvisorOptions: memory.NewMemoryAdvisorOptions(),
+		ResourceAdvisors:        []string{"cpu", "memory"},
+		ResourceUpdateIntervals: map[string]string{},
+		CPUAdvisorOptions:       cpu.NewCPUAdvisorOptions(),
+		MemoryAdvisorOptions:    memory.NewMemoryAdvisorOptions(),
 	}
 }
 
 // AddFlags adds flags to the specified FlagSet.
 func (o *ResourceAdvisorOptions) AddFlags(fs *pflag.FlagSet) {
 	fs.StringSliceVar(&o.ResourceAdvisors, "resource-advisors", o.ResourceAdvisors, "active dimensions for resource advisors")
+	fs.BoolVar(&o.AbsentAdvisorAsZeroHeadroom, "resource-absent-advisor-as-zero-headroom", o.AbsentAdvisorAsZeroHeadroom,
+		"if set as true, headroom of resources without active advisors is regarded as zero instead of error")
+	fs.StringToStringVar(&o.ResourceUpdateIntervals, "resource-update-intervals", o.ResourceUpdateIntervals,
+		"update interval of each sub resource advisor, should be formatted as 'memory=30s'; "+
+			"sub advisors not specified are updated every sync period")
 
 	o.CPUAdvisorOptions.AddFlags(fs)
 	o.MemoryAdvisorOptions.AddFlags(fs)
@@ -53,6 +64,16 @@ func (o *ResourceAdvisorOptions) AddFlags(fs *pflag.FlagSet) {
 // ApplyTo fills up config with options
 func (o *ResourceAdvisorOptions) ApplyTo(c *resource.ResourceAdvisorConfiguration) error {
 	c.ResourceAdvisors = o.ResourceAdvisors
+	c.AbsentAdvisorAsZeroHeadroom = o.AbsentAdvisorAsZeroHeadroom
+	for resourceName, intervalStr := range o.ResourceUpdateIntervals {
+		interval, err := time.ParseDuration(intervalStr)
+		if err != nil {
+			return fmt.Errorf("parse update interval of resource %v failed: %v", resourceName, err)
+		} else if interval <= 0 {
+			return fmt.Errorf("update interval of resource %v must be positive", resourceName)
+		}
+		c.ResourceUpdateIntervals[resourceName] = interval
+	}
 
 	var errList []error
 	errList = append(errList, o.CPUAdvisorOptions.ApplyTo(c.CPUAdvisorConfiguration))
@@ -19,15 +19,20 @@ package cpu
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sort"
 	"strconv"
 	"sync"
 	"time"
 
+	"go.uber.org/atomic"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/client-go/tools/events"
 	"k8s.io/klog/v2"
+	"k8s.io/utils/clock"
 
 	"github.com/kubewharf/katalyst-api/pkg/consts"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
@@ -40,6 +45,7 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region/provisionpolicy"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
 	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic"
 	"github.com/kubewharf/katalyst-core/pkg/metaserver"
 	"github.com/kubewharf/katalyst-core/pkg/metrics"
 	"github.com/kubewharf/katalyst-core/pkg/util/general"
@@ -54,6 +60,10 @@ const (
 	metricCPUAdvisorPoolSize           = "cpu_advisor_pool_size"
 	metricCPUAdvisorUpdateLag          = "cpu_advisor_update_lag"
 	metricCPUAdvisorUpdateDuration     = "cpu_advisor_update_duration"
+	metricCPUAdvisorCircuitOpen        = "cpu_advisor_provision_circuit_open"
+	metricCPUAdvisorHeadroomConfidence = "cpu_advisor_headroom_confidence"
+	metricCPUAdvisorProvisionNoChange  = "cpu_advisor_provision_no_change"
+	metricCPUAdvisorEvictionRisk       = "cpu_advisor_eviction_risk"
 	metricRegionStatus                 = "region_status"
 	metricRegionIndicatorTargetPrefix  = "region_indicator_target_"
 	metricRegionIndicatorCurrentPrefix = "region_indicator_current_"
@@ -75,6 +85,14 @@ func init() {
 	headroomassembler.RegisterInitializer(types.CPUHeadroomAssemblerDedicated, headroomassembler.NewHeadroomAssemblerDedicated)
 }
 
+// RegionInfo is a snapshot of the basic identity of a qos region tracked by cpu advisor
+type RegionInfo struct {
+	Name          string
+	Type          types.QoSRegionType
+	OwnerPoolName string
+	BindingNumas  machine.CPUSet
+}
+
 // cpuResourceAdvisor is the entrance of updating cpu resource provision advice for
 // all qos regions, and merging them into cpu provision result to notify cpu server.
 // Smart algorithms and calculators could be adopted to give accurate realtime resource
@@ -88,6 +106,7 @@ type cpuResourceAdvisor struct {
 	sendCh         chan types.InternalCPUCalculationResult
 	startTime      time.Time
 	advisorUpdated bool
+	lastUpdatedAt  time.Time // the last time update is triggered, used to respect update interval
 
 	regionMap          map[string]region.QoSRegion // map[regionName]region
 	reservedForReclaim map[int]int                 // map[numaID]reservedForReclaim
@@ -95,8 +114,55 @@ type cpuResourceAdvisor struct {
 	numRegionsPerNuma  map[int]int                 // map[numaID]regionQuantity
 	nonBindingNumas    machine.CPUSet              // numas without numa binding pods
 
+	preferredReclaimNumas []int // numas ordered by reclaim pool size of the last assembling
+
+	// lastCalculationResult is the latest provision result notified, from which headroom is calculated
+	lastCalculationResult types.InternalCPUCalculationResult
+
+	// regionFirstSeen, topologyChangedAt and lastAssembledAt are used to estimate headroom confidence
+	regionFirstSeen   map[string]time.Time // map[regionName]firstSeenTime
+	topologyChangedAt time.Time            // the last time non-binding numas changed
+	lastAssembledAt   time.Time            // the last time provision is assembled successfully
+
+	// lastDynamicConf is dynamic configuration seen by the last update, to tell whether it changes since then
+	lastDynamicConf *dynamic.Configuration
+
+	// lastBoundUpper is bound upper of the last successful assembling, indicating cpu contention of node
+	lastBoundUpper atomic.Bool
+
+	// paused skips updates triggered, while the last results are still served
+	paused atomic.Bool
+
+	// lastPushedPoolEntries and lastPushedAt are used to skip notifying cpu server of unchanged results
+	lastPushedPoolEntries map[string]map[int]int // map[poolName][numaId]cpuSize
+	lastPushedAt          time.Time              // the last time result is pushed to cpu server
+
+	// nonBindingUsageHistory keeps recent share and isolation usage samples on non-binding numas,
+	// from which evictionRisk of reclaimed workloads is estimated
+	nonBindingUsageHistory []float64
+	evictionRisk           float64
+
+	// headroomHistory keeps headroom of results published within HeadroomTrendRetention in time order,
+	// from which headroom trend is estimated
+	headroomHistory []headroomSample
+
 	provisionAssembler provisionassembler.ProvisionAssembler
 	headroomAssembler  headroomassembler.HeadroomAssembler
+	headroomCalculator HeadroomCalculator // custom calculator injected, and nil means the built-in one
+	circuitBreaker     *provisionCircuitBreaker
+	resultSinks        []*resultSinkPublisher
+	subscribers        map[int]chan types.InternalCPUCalculationResult // map[subscriberID]channel
+	nextSubscriberID   int
+	reclaimEvents      *reclaimEventEmitter
+
+	// inFlightAssembly receives the outcome of the timed out assembling still running in background
+	inFlightAssembly chan assembledProvision
+
+	// generation is that of the latest assembled result, and pushedGeneration and ackedGeneration are those
+	// of the latest result pushed to and acknowledged by cpu server, from which generation lag is derived
+	generation       uint64
+	pushedGeneration atomic.Uint64
+	ackedGeneration  atomic.Uint64
 
 	isolator        isolation.Isolator
 	isolationSafety bool
@@ -105,11 +171,16 @@ type cpuResourceAdvisor struct {
 	metaCache  metacache.MetaCache
 	metaServer *metaserver.MetaServer
 	emitter    metrics.MetricEmitter
+
+	// clock is an interface that provides time related functionality in a way that makes it
+	// easy to test the code.
+	clock clock.PassiveClock
 }
 
-// NewCPUResourceAdvisor returns a cpuResourceAdvisor instance
+// NewCPUResourceAdvisor returns a cpuResourceAdvisor instance; events of reclaim decisions are emitted
+// on the node object if recorder is not nil
 func NewCPUResourceAdvisor(conf *config.Configuration, extraConf interface{}, metaCache metacache.MetaCache,
-	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter) *cpuResourceAdvisor {
+	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter, recorder events.EventRecorder) *cpuResourceAdvisor {
 
 	cra := &cpuResourceAdvisor{
 		conf:      conf,
@@ -118,7 +189,6 @@ func NewCPUResourceAdvisor(conf *config.Configuration, extraConf interface{}, me
 
 		recvCh:         make(chan types.TriggerInfo, 1),
 		sendCh:         make(chan types.InternalCPUCalculationResult, 1),
-		startTime:      time.Now(),
 		advisorUpdated: false,
 
 		regionMap:          make(map[string]region.QoSRegion),
@@ -126,12 +196,21 @@ func NewCPUResourceAdvisor(conf *config.Configuration, extraConf interface{}, me
 		numaAvailable:      make(map[int]int),
 		numRegionsPerNuma:  make(map[int]int),
 		nonBindingNumas:    machine.NewCPUSet(),
+		regionFirstSeen:    make(map[string]time.Time),
 
 		isolator: isolation.NewLoadIsolator(conf, extraConf, emitter, metaCache, metaServer),
 
 		metaCache:  metaCache,
 		metaServer: metaServer,
 		emitter:    emitter,
+
+		clock: clock.RealClock{},
+	}
+	cra.startTime = cra.clock.Now()
+	cra.circuitBreaker = newProvisionCircuitBreaker(conf.ProvisionCircuitBreakerThreshold, conf.ProvisionCircuitBreakerCooldown, cra.clock)
+	if recorder != nil {
+		cra.reclaimEvents = newReclaimEventEmitter(recorder, conf.NodeName, conf.ReclaimPoolName,
+			conf.ReclaimEventThreshold, conf.ReclaimEventInterval, cra.clock)
 	}
 
 	coreNumReservedForReclaim := conf.DynamicAgentConfiguration.GetDynamicConfiguration().MinReclaimedResourceForAllocate[v1.ResourceCPU]
@@ -151,7 +230,7 @@ func (cra *cpuResourceAdvisor) Run(ctx context.Context) {
 	for {
 		select {
 		case v := <-cra.recvCh:
-			lag := time.Since(v.TimeStamp)
+			lag := cra.clock.Since(v.TimeStamp)
 			klog.Infof("[qosaware-cpu] receive update trigger, checkpoint at %v", v.TimeStamp)
 			cra.emitter.StoreFloat64(metricCPUAdvisorUpdateLag, float64(lag/time.Millisecond), metrics.MetricTypeNameRaw)
 
@@ -160,6 +239,15 @@ func (cra *cpuResourceAdvisor) Run(ctx context.Context) {
 				klog.Errorf("[qosaware-cpu] skip update: checkpoint is outdated, lag %v", lag)
 				continue
 			}
+			if cra.paused.Load() {
+				klog.Infof("[qosaware-cpu] skip update: advisor is paused")
+				continue
+			}
+			if !cra.updateDue() {
+				klog.Infof("[qosaware-cpu] skip update: last update at %v is within update interval", cra.lastUpdatedAt)
+				continue
+			}
+			cra.lastUpdatedAt = cra.clock.Now()
 			cra.update()
 
 		case <-ctx.Done():
@@ -168,34 +256,203 @@ func (cra *cpuResourceAdvisor) Run(ctx context.Context) {
 	}
 }
 
+// updateDue returns whether update interval has elapsed since the last update. Since update is
+// triggered every sync period, the effective interval is rounded up to multiples of sync period.
+func (cra *cpuResourceAdvisor) updateDue() bool {
+	interval, ok := cra.conf.ResourceUpdateIntervals[string(types.QoSResourceCPU)]
+	if !ok || cra.lastUpdatedAt.IsZero() {
+		return true
+	}
+	return cra.clock.Since(cra.lastUpdatedAt) >= interval
+}
+
+// SetPaused pauses or resumes updating, and the last results are still served while paused
+func (cra *cpuResourceAdvisor) SetPaused(paused bool) {
+	cra.paused.Store(paused)
+}
+
 func (cra *cpuResourceAdvisor) GetChannels() (interface{}, interface{}) {
 	return cra.recvCh, cra.sendCh
 }
 
+// LastBoundUpper returns whether the last successful assembling reached bound upper, i.e. node is in
+// cpu contention; results served by the open circuit breaker are not regarded as assembling.
+func (cra *cpuResourceAdvisor) LastBoundUpper() bool {
+	return cra.lastBoundUpper.Load()
+}
+
+// GetHeadroom returns reclaim headroom rounded down to a multiple of ReclaimHeadroomChunkSize,
+// see GetRawHeadroom for that before rounding
 func (cra *cpuResourceAdvisor) GetHeadroom() (resource.Quantity, error) {
 	klog.Infof("[qosaware-cpu] receive get headroom request")
 
 	cra.mutex.RLock()
 	defer cra.mutex.RUnlock()
 
+	headroom, err := cra.getHeadroom()
+	if err != nil {
+		return resource.Quantity{}, err
+	}
+	return cra.roundHeadroomToChunk(headroom), nil
+}
+
+// GetDonatableHeadroom returns reclaim headroom excluding the reserved-for-reclaim floor applied by the last
+// successful assembling and the reclaim critical reservation protected for system-critical batch, i.e. the
+// slack that can be admitted against without double-counting reserved capacity; it's rounded down to a
+// multiple of ReclaimHeadroomChunkSize as GetHeadroom is
+func (cra *cpuResourceAdvisor) GetDonatableHeadroom() (resource.Quantity, error) {
+	klog.Infof("[qosaware-cpu] receive get donatable headroom request")
+
+	cra.mutex.RLock()
+	defer cra.mutex.RUnlock()
+
+	headroom, err := cra.getHeadroom()
+	if err != nil {
+		return resource.Quantity{}, err
+	}
+
+	reservedForReclaim, criticalReservation := 0, 0
+	if cra.provisionAssembler != nil {
+		reservedForReclaim = cra.provisionAssembler.AppliedReservedForReclaim()
+		criticalReservation = cra.provisionAssembler.ReclaimCriticalReservation()
+	}
+	reserved := resource.NewQuantity(int64(reservedForReclaim+criticalReservation), resource.DecimalSI)
+	headroom.Sub(*reserved)
+	if headroom.Sign() < 0 {
+		headroom = *resource.NewQuantity(0, resource.DecimalSI)
+	}
+	headroom = cra.roundHeadroomToChunk(headroom)
+	klog.Infof("[qosaware-cpu] get donatable headroom: %v, reserved for reclaim: %v, critical reservation: %v",
+		headroom.String(), reservedForReclaim, criticalReservation)
+
+	return headroom, nil
+}
+
+// GetHeadroomAttribution returns the part of reclaim headroom attributable to each dedicated pod keyed by pod uid,
+// i.e. slack donated by the pod on its numa in the last successful assembling, and zero if it donates nothing
+func (cra *cpuResourceAdvisor) GetHeadroomAttribution() map[string]resource.Quantity {
+	cra.mutex.RLock()
+	defer cra.mutex.RUnlock()
+
+	attribution := make(map[string]resource.Quantity)
+	if cra.provisionAssembler == nil {
+		return attribution
+	}
+	for podUID, slack := range cra.provisionAssembler.HeadroomAttribution() {
+		attribution[podUID] = *resource.NewQuantity(int64(slack), resource.DecimalSI)
+	}
+	return attribution
+}
+
+// DrainNumaReclaim drains reclaim of the numa toward zero gradually over the duration, e.g. for planned
+// maintenance of the numa, while reclaim of other numas is untouched
+func (cra *cpuResourceAdvisor) DrainNumaReclaim(numaID int, over time.Duration) error {
+	cra.mutex.Lock()
+	defer cra.mutex.Unlock()
+
+	if cra.provisionAssembler == nil {
+		return fmt.Errorf("no legal assembler")
+	}
+	return cra.provisionAssembler.DrainNumaReclaim(numaID, over)
+}
+
+// UndrainNumaReclaim stops draining reclaim of the numa
+func (cra *cpuResourceAdvisor) UndrainNumaReclaim(numaID int) {
+	cra.mutex.Lock()
+	defer cra.mutex.Unlock()
+
+	if cra.provisionAssembler != nil {
+		cra.provisionAssembler.UndrainNumaReclaim(numaID)
+	}
+}
+
+// SetRegionReservedForReclaim overrides reserved for reclaim on the binding numa of the dedicated numa exclusive
+// region, taking precedence over node-level reserved for reclaim of the numa
+func (cra *cpuResourceAdvisor) SetRegionReservedForReclaim(regionName string, reserved int) error {
+	cra.mutex.Lock()
+	defer cra.mutex.Unlock()
+
+	r, ok := cra.regionMap[regionName]
+	if !ok {
+		return fmt.Errorf("region %v not found", regionName)
+	}
+	if r.Type() != types.QoSRegionTypeDedicatedNumaExclusive {
+		return fmt.Errorf("region %v of type %v is not dedicated numa exclusive", regionName, r.Type())
+	}
+	if cra.provisionAssembler == nil {
+		return fmt.Errorf("no legal assembler")
+	}
+	return cra.provisionAssembler.SetRegionReservedForReclaim(regionName, reserved)
+}
+
+// ClearRegionReservedForReclaim clears the reserved for reclaim override of the region
+func (cra *cpuResourceAdvisor) ClearRegionReservedForReclaim(regionName string) {
+	cra.mutex.Lock()
+	defer cra.mutex.Unlock()
+
+	if cra.provisionAssembler != nil {
+		cra.provisionAssembler.ClearRegionReservedForReclaim(regionName)
+	}
+}
+
+func (cra *cpuResourceAdvisor) getHeadroom() (resource.Quantity, error) {
 	if !cra.advisorUpdated {
 		klog.Infof("[qosaware-cpu] skip getting headroom: advisor not updated")
 		return resource.Quantity{}, fmt.Errorf("advisor not updated")
 	}
 
-	if cra.headroomAssembler == nil {
-		klog.Errorf("[qosaware-cpu] get headroom failed: no legal assembler")
-		return resource.Quantity{}, fmt.Errorf("no legal assembler")
+	// serve headroom from the frozen provision result if circuit is not closed
+	result, calculator := cra.lastCalculationResult, cra.getHeadroomCalculator()
+	if frozenResult, ok := cra.circuitBreaker.frozenResult(); ok {
+		klog.Infof("[qosaware-cpu] get headroom from frozen provision result at %v", frozenResult.TimeStamp)
+		result = frozenResult
+		if cra.headroomCalculator == nil {
+			calculator = &reclaimPoolHeadroomCalculator{reclaimPoolName: cra.conf.ReclaimPoolName}
+		}
 	}
 
-	headroom, err := cra.headroomAssembler.GetHeadroom()
+	headroom, err := calculator.Calculate(result, cra.reservedForReclaim)
 	if err != nil {
 		klog.Errorf("[qosaware-cpu] get headroom failed: %v", err)
-	} else {
-		klog.Infof("[qosaware-cpu] get headroom: %v", headroom)
+		return headroom, err
 	}
+	headroom = cra.scaleHeadroomByEffectiveCapacity(headroom)
+	klog.Infof("[qosaware-cpu] get headroom: %v", headroom)
 
-	return headroom, err
+	return headroom, nil
+}
+
+// ListRegions returns a snapshot of all regions tracked currently, sorted by region name;
+// the result is a copy and safe to be used concurrently with advisor updates
+func (cra *cpuResourceAdvisor) ListRegions() []RegionInfo {
+	cra.mutex.RLock()
+	defer cra.mutex.RUnlock()
+
+	regions := make([]RegionInfo, 0, len(cra.regionMap))
+	for _, r := range cra.regionMap {
+		regions = append(regions, RegionInfo{
+			Name:          r.Name(),
+			Type:          r.Type(),
+			OwnerPoolName: r.OwnerPoolName(),
+			BindingNumas:  r.GetBindingNumas(),
+		})
+	}
+	sort.Slice(regions, func(i, j int) bool {
+		return regions[i].Name < regions[j].Name
+	})
+
+	return regions
+}
+
+// PreferredReclaimNumas returns numas ordered by descending reclaim pool size of the last assembling,
+// with ties broken by numa id; numas without reclaim pool are not included
+func (cra *cpuResourceAdvisor) PreferredReclaimNumas() []int {
+	cra.mutex.RLock()
+	defer cra.mutex.RUnlock()
+
+	numas := make([]int, len(cra.preferredReclaimNumas))
+	copy(numas, cra.preferredReclaimNumas)
+	return numas
 }
 
 // update works in a monolithic way to maintain lifecycle and triggers update actions for all regions;
@@ -203,6 +460,13 @@ func (cra *cpuResourceAdvisor) GetHeadroom() (resource.Quantity, error) {
 func (cra *cpuResourceAdvisor) update() {
 	cra.mutex.Lock()
 	defer cra.mutex.Unlock()
+
+	// regions are never changed while the timed out assembling still reads them in background
+	if cra.assemblyInFlight() {
+		klog.Warningf("[qosaware-cpu] skip updating: the timed out assembling is still in flight")
+		_ = cra.emitter.StoreInt64(metricCPUAdvisorAssemblyTimedOut, 1, metrics.MetricTypeNameRaw)
+		return
+	}
 	if !cra.updateWithIsolationGuardian(true) {
 		cra.updateWithIsolationGuardian(false)
 	}
@@ -212,9 +476,9 @@ func (cra *cpuResourceAdvisor) update() {
 // otherwise, we should retry with the isolation disabled
 // todo: we should re-design the mechanism of isolation instead of disabling this functionality
 func (cra *cpuResourceAdvisor) updateWithIsolationGuardian(tryIsolation bool) bool {
-	startTime := time.Now()
+	startTime := cra.clock.Now()
 	defer func(t time.Time) {
-		elapsed := time.Since(t)
+		elapsed := cra.clock.Since(t)
 		_ = cra.emitter.StoreFloat64(metricCPUAdvisorUpdateDuration, float64(elapsed/time.Millisecond), metrics.MetricTypeNameRaw)
 		klog.Infof("[qosaware-cpu] update duration %v", elapsed)
 	}(startTime)
@@ -242,6 +506,7 @@ func (cra *cpuResourceAdvisor) updateWithIsolationGuardian(tryIsolation bool) bo
 	}
 
 	cra.gcRegionMap()
+	cra.updateRegionFirstSeen()
 	cra.updateAdvisorEssentials()
 	if tryIsolation && isolationExists && !cra.checkIsolationSafety() {
 		return false
@@ -261,7 +526,9 @@ func (cra *cpuResourceAdvisor) updateWithIsolationGuardian(tryIsolation bool) bo
 		r.TryUpdateHeadroom()
 	}
 	cra.updateRegionEntries()
+	cra.updateEvictionRisk()
 
+	reason := cra.getUpdateReason(startTime)
 	cra.advisorUpdated = true
 
 	klog.Infof("[qosaware-cpu] region map: %v", general.ToString(cra.regionMap))
@@ -272,17 +539,83 @@ func (cra *cpuResourceAdvisor) updateWithIsolationGuardian(tryIsolation bool) bo
 		klog.Errorf("[qosaware-cpu] assemble provision failed: %v", err)
 		return true
 	}
+	calculationResult.Reason = reason
+	cra.notifyProvision(calculationResult, boundUpper)
+	return true
+}
+
+// RefreshRegion refreshes provision of the given region and re-assembles provision result immediately,
+// without waiting for the next update; it holds the same lock as update, so results of them never interleave
+func (cra *cpuResourceAdvisor) RefreshRegion(regionName string) error {
+	cra.mutex.Lock()
+	defer cra.mutex.Unlock()
+
+	if _, ok := cra.regionMap[regionName]; !ok {
+		return fmt.Errorf("region %v not found", regionName)
+	}
+
+	// provision of the region is got only once by assembler, and the failure skipped in partial assembly
+	// mode is still returned instead of pushing a result without the region
+	calculationResult, boundUpper, err := cra.assembleProvisionPartial([]string{regionName})
+	if err != nil {
+		return fmt.Errorf("assemble provision for region %v failed: %v", regionName, err)
+	}
+	if regionErr, ok := calculationResult.RegionErrors[regionName]; ok {
+		return fmt.Errorf("get provision of region %v failed: %v", regionName, regionErr)
+	}
+	klog.Infof("[qosaware-cpu] refresh region %v", regionName)
+	calculationResult.Reason = types.AssemblyReasonRegionRefresh
+	cra.notifyProvision(calculationResult, boundUpper)
+
+	return nil
+}
+
+// notifyProvision updates states derived from the assembled provision result, publishes it to
+// result sinks and notifies cpu server; must be called with lock held
+func (cra *cpuResourceAdvisor) notifyProvision(calculationResult types.InternalCPUCalculationResult, boundUpper bool) {
+	calculationResult.Generation = cra.nextGeneration()
 	cra.updateRegionStatus(boundUpper)
+	cra.logResultDiff(calculationResult)
+	cra.lastCalculationResult = calculationResult
+	cra.preferredReclaimNumas = getPreferredReclaimNumas(calculationResult, cra.conf.ReclaimPoolName,
+		cra.nonBindingNumas.Difference(machine.NewCPUSet(cra.conf.ExcludedReclaimNumas...)))
 	cra.emitMetrics(calculationResult)
+	cra.publishResult(calculationResult)
+	cra.recordHeadroomSample(calculationResult)
+	cra.reclaimEvents.observe(calculationResult, boundUpper)
 
 	// notify cpu server
+	if !cra.provisionPushDue(calculationResult) {
+		klog.Infof("[qosaware-cpu] skip notifying cpu server: pool entries unchanged since %v", cra.lastPushedAt)
+		_ = cra.emitter.StoreInt64(metricCPUAdvisorProvisionNoChange, 1, metrics.MetricTypeNameRaw)
+		return
+	}
 	select {
 	case cra.sendCh <- calculationResult:
 		klog.Infof("[qosaware-cpu] notify cpu server: %+v", calculationResult)
+		// pool entries are copied in case the pushed result is mutated by receivers
+		cra.lastPushedPoolEntries = make(map[string]map[int]int, len(calculationResult.PoolEntries))
+		for poolName, entries := range calculationResult.PoolEntries {
+			cra.lastPushedPoolEntries[poolName] = make(map[int]int, len(entries))
+			for numaID, size := range entries {
+				cra.lastPushedPoolEntries[poolName][numaID] = size
+			}
+		}
+		cra.lastPushedAt = cra.clock.Now()
+		cra.pushedGeneration.Store(calculationResult.Generation)
+		cra.emitGenerationLag()
 	default:
 		klog.Errorf("[qosaware-cpu] channel is full")
 	}
-	return true
+}
+
+// provisionPushDue returns whether the result should be pushed to cpu server, i.e. pool entries are changed
+// since the last push, or force push interval has elapsed; timestamps of the result are never compared
+func (cra *cpuResourceAdvisor) provisionPushDue(calculationResult types.InternalCPUCalculationResult) bool {
+	if cra.lastPushedPoolEntries == nil || !reflect.DeepEqual(cra.lastPushedPoolEntries, calculationResult.PoolEntries) {
+		return true
+	}
+	return cra.clock.Since(cra.lastPushedAt) >= cra.conf.ProvisionForcePushInterval
 }
 
 // setIsolatedContainers get isolation status from isolator and update into containers
@@ -318,6 +651,9 @@ func (cra *cpuResourceAdvisor) checkIsolationSafety() bool {
 				return false
 			}
 			shareAndIsolationPoolSize += int(controlKnob[types.ControlKnobNonReclaimedCPUSize].Value)
+		} else if r.Type() == types.QoSRegionTypeIsolation && !r.GetBindingNumas().IsEmpty() {
+			// isolation region pinned to numas is carved out of its numas instead of non-binding numas
+			nonBindingNumas = nonBindingNumas.Difference(r.GetBindingNumas())
 		} else if r.Type() == types.QoSRegionTypeIsolation {
 			pods := r.GetPods()
 			cra.metaCache.RangeContainer(func(podUID string, _ string, containerInfo *types.ContainerInfo) bool {
@@ -487,18 +823,30 @@ func (cra *cpuResourceAdvisor) gcRegionMap() {
 }
 
 // updateAdvisorEssentials updates following essentials after assigning containers to regions:
-// 1. non-binding numas, i.e. numas without numa binding containers
-// 2. binding numas of non numa binding regions
-// 3. region quantity of each numa
+// 1. binding numas of isolation regions pinned to numas
+// 2. non-binding numas, i.e. numas without numa binding containers
+// 3. binding numas of non numa binding regions
+// 4. region quantity of each numa
 func (cra *cpuResourceAdvisor) updateAdvisorEssentials() {
+	for _, r := range cra.regionMap {
+		if r.Type() == types.QoSRegionTypeIsolation {
+			r.SetBindingNumas(cra.getIsolationRegionBindingNumas(r))
+		}
+	}
+
+	lastNonBindingNumas := cra.nonBindingNumas
 	cra.nonBindingNumas = cra.metaServer.CPUDetails.NUMANodes()
 
-	// update non-binding numas
+	// update non-binding numas; isolation regions with numa binding are carved out of their numas too
 	for _, r := range cra.regionMap {
-		if r.Type() == types.QoSRegionTypeDedicatedNumaExclusive {
+		if r.Type() == types.QoSRegionTypeDedicatedNumaExclusive || r.Type() == types.QoSRegionTypeIsolation {
 			cra.nonBindingNumas = cra.nonBindingNumas.Difference(r.GetBindingNumas())
 		}
 	}
+	if !cra.nonBindingNumas.Equals(lastNonBindingNumas) {
+		klog.Infof("[qosaware-cpu] non-binding numas change from %v to %v", lastNonBindingNumas.String(), cra.nonBindingNumas.String())
+		cra.topologyChangedAt = cra.clock.Now()
+	}
 
 	// reset region quantity
 	for _, numaID := range cra.metaServer.CPUDetails.NUMANodes().ToSliceInt() {
@@ -525,12 +873,55 @@ func (cra *cpuResourceAdvisor) assembleProvision() (types.InternalCPUCalculation
 	if cra.provisionAssembler == nil {
 		return types.InternalCPUCalculationResult{}, false, fmt.Errorf("no legal provision assembler")
 	}
+	return cra.assembleProvisionWithCircuitBreaker(cra.provisionAssembler.AssembleProvisionWithContext)
+}
 
-	calculationResult, boundUpper, err := cra.provisionAssembler.AssembleProvision()
+// assembleProvisionPartial works like assembleProvision, but only refreshes provision of the changed regions
+func (cra *cpuResourceAdvisor) assembleProvisionPartial(changedRegions []string) (types.InternalCPUCalculationResult, bool, error) {
+	if cra.provisionAssembler == nil {
+		return types.InternalCPUCalculationResult{}, false, fmt.Errorf("no legal provision assembler")
+	}
+	// partial assembling is not cancellable, but still bounded by timeout
+	return cra.assembleProvisionWithCircuitBreaker(func(_ context.Context) (types.InternalCPUCalculationResult, bool, error) {
+		return cra.provisionAssembler.AssembleProvisionPartial(changedRegions)
+	})
+}
+
+// assembleProvisionWithCircuitBreaker assembles with timeout and circuit breaker; timed out assembling
+// is served with the last-known-good result, and counted as neither success nor failure of the circuit
+func (cra *cpuResourceAdvisor) assembleProvisionWithCircuitBreaker(assemble assembleFunc) (types.InternalCPUCalculationResult, bool, error) {
+	if !cra.circuitBreaker.allow() {
+		return cra.serveFrozenProvision()
+	}
+
+	calculationResult, boundUpper, timedOut, err := cra.assembleWithTimeout(assemble)
+	if timedOut {
+		return cra.serveTimedOutProvision()
+	}
+	if err != nil {
+		cra.circuitBreaker.onFailure()
+		if _, ok := cra.circuitBreaker.frozenResult(); ok {
+			klog.Errorf("[qosaware-cpu] assemble provision failed %v times: %v", cra.circuitBreaker.consecutiveFailures, err)
+			return cra.serveFrozenProvision()
+		}
+		return calculationResult, boundUpper, err
+	}
+	cra.circuitBreaker.onSuccess(calculationResult)
+	cra.lastAssembledAt = cra.clock.Now()
+	cra.lastBoundUpper.Store(boundUpper)
 
 	return calculationResult, boundUpper, err
 }
 
+// serveFrozenProvision returns the last-known-good provision result when circuit is open
+func (cra *cpuResourceAdvisor) serveFrozenProvision() (types.InternalCPUCalculationResult, bool, error) {
+	frozenResult, _ := cra.circuitBreaker.frozenResult()
+	klog.Warningf("[qosaware-cpu] provision circuit is open, serve frozen result at %v", frozenResult.TimeStamp)
+	_ = cra.emitter.StoreInt64(metricCPUAdvisorCircuitOpen, 1, metrics.MetricTypeNameRaw)
+
+	return frozenResult, false, nil
+}
+
 func (cra *cpuResourceAdvisor) emitMetrics(calculationResult types.InternalCPUCalculationResult) {
 	// emit region indicator related metrics
 	for _, r := range cra.regionMap {
@@ -18,12 +18,14 @@ package cpu
 
 import (
 	"fmt"
+	"sort"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/sets"
 	"k8s.io/klog/v2"
 	"k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
 
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/assembler/headroomassembler"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/assembler/provisionassembler"
@@ -31,6 +33,7 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
 	"github.com/kubewharf/katalyst-core/pkg/util/general"
 	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+	qosutil "github.com/kubewharf/katalyst-core/pkg/util/qos"
 )
 
 func (cra *cpuResourceAdvisor) getRegionsByRegionNames(names sets.String) []region.QoSRegion {
@@ -127,7 +130,7 @@ func (cra *cpuResourceAdvisor) initializeProvisionAssembler() error {
 	if !ok {
 		return fmt.Errorf("unsupported provision assembler %v", assemblerName)
 	}
-	cra.provisionAssembler = initializer(cra.conf, cra.extraConf, &cra.regionMap, &cra.reservedForReclaim, &cra.numaAvailable, &cra.nonBindingNumas, cra.metaCache, cra.metaServer, cra.emitter)
+	cra.provisionAssembler = initializer(cra.conf, cra.extraConf, &cra.regionMap, &cra.reservedForReclaim, provisionassembler.NewMapAvailabilityProvider(&cra.numaAvailable), &cra.nonBindingNumas, cra.metaCache, cra.metaServer, cra.emitter)
 
 	return nil
 }
@@ -214,6 +217,32 @@ func (cra *cpuResourceAdvisor) getRegionMinRequirement(r region.QoSRegion) float
 	}
 }
 
+// getIsolationRegionBindingNumas returns numas of the isolation region if all its containers are pinned to numas,
+// i.e. annotated with numa binding and assigned to one numa; otherwise the region is regarded as non-binding
+func (cra *cpuResourceAdvisor) getIsolationRegionBindingNumas(r region.QoSRegion) machine.CPUSet {
+	pods := r.GetPods()
+	bindingNumas := machine.NewCPUSet()
+	pinned := true
+	cra.metaCache.RangeContainer(func(podUID string, containerName string, ci *types.ContainerInfo) bool {
+		if containers, ok := pods[podUID]; !ok || !containers.Has(containerName) {
+			return true
+		}
+		if !qosutil.AnnotationsIndicateNUMABinding(ci.Annotations) || len(ci.TopologyAwareAssignments) != 1 {
+			pinned = false
+			return false
+		}
+		for numaID := range ci.TopologyAwareAssignments {
+			bindingNumas = bindingNumas.Union(machine.NewCPUSet(numaID))
+		}
+		return true
+	})
+
+	if !pinned {
+		return machine.NewCPUSet()
+	}
+	return bindingNumas
+}
+
 func (cra *cpuResourceAdvisor) getRegionReservedForReclaim(r region.QoSRegion) float64 {
 	res := 0.0
 	for _, numaID := range r.GetBindingNumas().ToSliceInt() {
@@ -292,3 +321,37 @@ func (cra *cpuResourceAdvisor) updateRegionStatus(boundUpper bool) {
 		_ = cra.metaCache.SetRegionInfo(regionName, regionInfo)
 	}
 }
+
+// getPreferredReclaimNumas orders numas by descending reclaim pool size, and ties are broken by numa id.
+// reclaim pool entry of non-binding numas is regarded as evenly distributed among the given numas.
+func getPreferredReclaimNumas(calculationResult types.InternalCPUCalculationResult, reclaimPoolName string,
+	nonBindingReclaimNumas machine.CPUSet) []int {
+	reclaimPoolSizes := make(map[int]float64)
+	for numaID, size := range calculationResult.PoolEntries[reclaimPoolName] {
+		if numaID == cpuadvisor.FakedNUMAID {
+			if nonBindingReclaimNumas.Size() == 0 {
+				continue
+			}
+			for _, id := range nonBindingReclaimNumas.ToSliceInt() {
+				reclaimPoolSizes[id] += float64(size) / float64(nonBindingReclaimNumas.Size())
+			}
+		} else {
+			reclaimPoolSizes[numaID] += float64(size)
+		}
+	}
+
+	numas := make([]int, 0, len(reclaimPoolSizes))
+	for numaID, size := range reclaimPoolSizes {
+		if size > 0 {
+			numas = append(numas, numaID)
+		}
+	}
+	sort.Slice(numas, func(i, j int) bool {
+		if reclaimPoolSizes[numas[i]] != reclaimPoolSizes[numas[j]] {
+			return reclaimPoolSizes[numas[i]] > reclaimPoolSizes[numas[j]]
+		}
+		return numas[i] < numas[j]
+	})
+
+	return numas
+}
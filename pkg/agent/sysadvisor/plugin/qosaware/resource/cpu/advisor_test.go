@@ -34,10 +34,13 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	k8stypes "k8s.io/apimachinery/pkg/types"
 	"k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
+	"k8s.io/utils/clock"
+	testingclock "k8s.io/utils/clock/testing"
 
 	"github.com/kubewharf/katalyst-api/pkg/consts"
 	katalyst_base "github.com/kubewharf/katalyst-core/cmd/base"
 	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
@@ -95,7 +98,7 @@ func newTestCPUResourceAdvisor(t *testing.T, pods []*v1.Pod, conf *config.Config
 	err = metaServer.SetServiceProfilingManager(spd.NewDummyServiceProfilingManager(profiles))
 	require.NoError(t, err)
 
-	cra := NewCPUResourceAdvisor(conf, struct{}{}, metaCache, metaServer, metrics.DummyMetrics{})
+	cra := NewCPUResourceAdvisor(conf, struct{}{}, metaCache, metaServer, metrics.DummyMetrics{}, nil)
 	require.NotNil(t, cra)
 
 	return cra, metaCache
@@ -973,6 +976,8 @@ func TestAdvisorUpdate(t *testing.T) {
 			conf.IsolatedMaxResourceRatio = 0.3
 			conf.IsolationLockInThreshold = 1
 			conf.IsolationLockOutPeriodSecs = 30
+			// every update is expected to be responded, even if the result is unchanged after pre-update
+			conf.ProvisionForcePushInterval = 0
 
 			advisor, metaCache := newTestCPUResourceAdvisor(t, tt.pods, conf, mf, tt.podProfiles)
 			advisor.startTime = time.Now().Add(-types.StartUpPeriod)
@@ -1112,3 +1117,454 @@ func TestGetIsolatedContainerRegions(t *testing.T) {
 	assert.ElementsMatch(t, []string{}, f(c3_1))
 	assert.ElementsMatch(t, []string{}, f(c3_2))
 }
+
+func TestListRegions(t *testing.T) {
+	t.Parallel()
+
+	conf, _ := options.NewOptions().Config()
+
+	share := region.NewQoSRegionBase("share-r", state.PoolNameShare, types.QoSRegionTypeShare,
+		conf, struct{}{}, nil, nil, nil)
+	share.SetBindingNumas(machine.NewCPUSet(1))
+	isolation := region.NewQoSRegionBase("isolation-r", "isolation-pool", types.QoSRegionTypeIsolation,
+		conf, struct{}{}, nil, nil, nil)
+	isolation.SetBindingNumas(machine.NewCPUSet(1))
+	dedicated := region.NewQoSRegionBase("dedicated-r", state.PoolNameDedicated, types.QoSRegionTypeDedicatedNumaExclusive,
+		conf, struct{}{}, nil, nil, nil)
+	dedicated.SetBindingNumas(machine.NewCPUSet(0))
+
+	advisor := &cpuResourceAdvisor{
+		regionMap: map[string]region.QoSRegion{
+			share.Name():     share,
+			isolation.Name(): isolation,
+			dedicated.Name(): dedicated,
+		},
+	}
+
+	regions := advisor.ListRegions()
+	assert.Equal(t, []RegionInfo{
+		{Name: "dedicated-r", Type: types.QoSRegionTypeDedicatedNumaExclusive, OwnerPoolName: state.PoolNameDedicated, BindingNumas: machine.NewCPUSet(0)},
+		{Name: "isolation-r", Type: types.QoSRegionTypeIsolation, OwnerPoolName: "isolation-pool", BindingNumas: machine.NewCPUSet(1)},
+		{Name: "share-r", Type: types.QoSRegionTypeShare, OwnerPoolName: state.PoolNameShare, BindingNumas: machine.NewCPUSet(1)},
+	}, regions)
+
+	// modifying the snapshot should not affect regions tracked by advisor
+	regions[0].BindingNumas = machine.NewCPUSet(0, 1)
+	delete(advisor.regionMap, share.Name())
+	assert.Equal(t, machine.NewCPUSet(0), dedicated.GetBindingNumas())
+	assert.Len(t, regions, 3)
+	assert.Len(t, advisor.ListRegions(), 2)
+}
+
+func TestUpdateAdvisorEssentialsIsolationBindingNumas(t *testing.T) {
+	t.Parallel()
+
+	ckDir, err := ioutil.TempDir("", "checkpoint-TestUpdateAdvisorEssentialsIsolationBindingNumas")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(ckDir) }()
+
+	sfDir, err := ioutil.TempDir("", "statefile")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(sfDir) }()
+
+	conf := generateTestConfiguration(t, ckDir, sfDir)
+	mf := metric.NewFakeMetricsFetcher(metrics.DummyMetrics{}).(*metric.FakeMetricsFetcher)
+	advisor, metaCache := newTestCPUResourceAdvisor(t, nil, conf, mf, nil)
+
+	_ = metaCache.SetPoolInfo(state.PoolNameShare, &types.PoolInfo{
+		PoolName: state.PoolNameShare,
+		TopologyAwareAssignments: map[int]machine.CPUSet{
+			0: machine.MustParse("1"),
+			1: machine.MustParse("25"),
+		},
+	})
+	numaBinding := map[string]string{
+		consts.PodAnnotationMemoryEnhancementNumaBinding: consts.PodAnnotationMemoryEnhancementNumaBindingEnable,
+	}
+
+	share := makeContainerInfo("uid1", "default", "pod1", "c1", consts.PodAnnotationQoSLevelSharedCores, state.PoolNameShare, nil,
+		map[int]machine.CPUSet{0: machine.MustParse("1"), 1: machine.MustParse("25")}, 4)
+	// isolated pod pinned to numa 1
+	pinned := makeContainerInfo("uid2", "default", "pod2", "c2", consts.PodAnnotationQoSLevelSharedCores, state.PoolNameShare, numaBinding,
+		map[int]machine.CPUSet{1: machine.MustParse("26")}, 2)
+	pinned.Isolated = true
+	// isolated pod annotated with numa binding but not assigned to one numa yet
+	unpinned := makeContainerInfo("uid3", "default", "pod3", "c3", consts.PodAnnotationQoSLevelSharedCores, state.PoolNameShare, numaBinding,
+		map[int]machine.CPUSet{0: machine.MustParse("2"), 1: machine.MustParse("27")}, 2)
+	unpinned.Isolated = true
+	for _, ci := range []*types.ContainerInfo{share, pinned, unpinned} {
+		require.NoError(t, metaCache.SetContainerInfo(ci.PodUID, ci.ContainerName, ci))
+	}
+
+	require.NoError(t, advisor.assignContainersToRegions())
+	advisor.updateAdvisorEssentials()
+
+	bindingNumas := make(map[string]string)
+	for _, r := range advisor.regionMap {
+		for podUID := range r.GetPods() {
+			bindingNumas[podUID] = r.GetBindingNumas().String()
+		}
+	}
+	assert.Equal(t, map[string]string{"uid1": "0", "uid2": "1", "uid3": ""}, bindingNumas)
+	assert.Equal(t, "0", advisor.nonBindingNumas.String())
+	assert.Equal(t, map[int]int{0: 1, 1: 1}, advisor.numRegionsPerNuma)
+
+	// isolated pod unpinned from its numa makes the region non-binding again
+	pinned.TopologyAwareAssignments = map[int]machine.CPUSet{0: machine.MustParse("3"), 1: machine.MustParse("26")}
+	require.NoError(t, metaCache.SetContainerInfo(pinned.PodUID, pinned.ContainerName, pinned))
+	require.NoError(t, advisor.assignContainersToRegions())
+	advisor.updateAdvisorEssentials()
+	assert.Equal(t, "0-1", advisor.nonBindingNumas.String())
+}
+
+type fakeHeadroomAssembler struct {
+	headroom resource.Quantity
+}
+
+func (a *fakeHeadroomAssembler) GetHeadroom() (resource.Quantity, error) {
+	return a.headroom, nil
+}
+
+func TestGetDonatableHeadroom(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                  string
+		headroom              resource.Quantity
+		reservedForReclaim    int
+		criticalReservation   int
+		wantHeadroom          resource.Quantity
+		wantDonatableHeadroom resource.Quantity
+	}{
+		{
+			name:                  "headroom above reserved floor",
+			headroom:              resource.MustParse("10"),
+			reservedForReclaim:    4,
+			wantHeadroom:          resource.MustParse("10"),
+			wantDonatableHeadroom: resource.MustParse("6"),
+		},
+		{
+			name:                  "headroom below reserved floor",
+			headroom:              resource.MustParse("3"),
+			reservedForReclaim:    4,
+			wantHeadroom:          resource.MustParse("3"),
+			wantDonatableHeadroom: resource.MustParse("0"),
+		},
+		{
+			name:                  "critical reservation excluded",
+			headroom:              resource.MustParse("10"),
+			reservedForReclaim:    4,
+			criticalReservation:   3,
+			wantHeadroom:          resource.MustParse("10"),
+			wantDonatableHeadroom: resource.MustParse("3"),
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf, err := options.NewOptions().Config()
+			require.NoError(t, err)
+			advisor := &cpuResourceAdvisor{
+				conf:              conf,
+				advisorUpdated:    true,
+				headroomAssembler: &fakeHeadroomAssembler{headroom: tt.headroom},
+				provisionAssembler: &fakeProvisionAssembler{
+					appliedReservedForReclaim: tt.reservedForReclaim,
+					criticalReservation:       tt.criticalReservation,
+				},
+				circuitBreaker: newProvisionCircuitBreaker(0, 0, clock.RealClock{}),
+			}
+
+			headroom, err := advisor.GetHeadroom()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantHeadroom.MilliValue(), headroom.MilliValue())
+
+			donatableHeadroom, err := advisor.GetDonatableHeadroom()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDonatableHeadroom.MilliValue(), donatableHeadroom.MilliValue())
+		})
+	}
+}
+
+func TestGetHeadroomAttribution(t *testing.T) {
+	t.Parallel()
+
+	advisor := &cpuResourceAdvisor{}
+	assert.Empty(t, advisor.GetHeadroomAttribution())
+
+	advisor.provisionAssembler = &fakeProvisionAssembler{attribution: map[string]int{"uid1": 4, "uid2": 0}}
+	assert.Equal(t, map[string]resource.Quantity{
+		"uid1": *resource.NewQuantity(4, resource.DecimalSI),
+		"uid2": *resource.NewQuantity(0, resource.DecimalSI),
+	}, advisor.GetHeadroomAttribution())
+}
+
+func TestPreferredReclaimNumas(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                   string
+		reclaimPoolEntries     map[int]int
+		nonBindingReclaimNumas machine.CPUSet
+		want                   []int
+	}{
+		{
+			name:                   "binding and distributed non-binding reclaim",
+			reclaimPoolEntries:     map[int]int{cpuadvisor.FakedNUMAID: 20, 0: 6, 1: 12},
+			nonBindingReclaimNumas: machine.NewCPUSet(2, 3),
+			want:                   []int{1, 2, 3, 0},
+		},
+		{
+			name:                   "ties broken by numa id",
+			reclaimPoolEntries:     map[int]int{cpuadvisor.FakedNUMAID: 16, 3: 8},
+			nonBindingReclaimNumas: machine.NewCPUSet(0, 1),
+			want:                   []int{0, 1, 3},
+		},
+		{
+			name:                   "numas without reclaim are excluded",
+			reclaimPoolEntries:     map[int]int{cpuadvisor.FakedNUMAID: 4, 0: 0},
+			nonBindingReclaimNumas: machine.NewCPUSet(),
+			want:                   []int{},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result := types.InternalCPUCalculationResult{
+				PoolEntries: map[string]map[int]int{
+					state.PoolNameReclaim: tt.reclaimPoolEntries,
+				},
+			}
+			advisor := &cpuResourceAdvisor{
+				preferredReclaimNumas: getPreferredReclaimNumas(result, state.PoolNameReclaim, tt.nonBindingReclaimNumas),
+			}
+			assert.Equal(t, tt.want, advisor.PreferredReclaimNumas())
+		})
+	}
+}
+
+// provisionCountingRegion counts calls to get provision of the region
+type provisionCountingRegion struct {
+	region.QoSRegion
+	calls int
+}
+
+func (r *provisionCountingRegion) GetProvision() (types.ControlKnob, error) {
+	r.calls++
+	return r.QoSRegion.GetProvision()
+}
+
+func TestRefreshRegion(t *testing.T) {
+	t.Parallel()
+
+	ckDir, err := ioutil.TempDir("", "checkpoint-TestRefreshRegion")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(ckDir) }()
+
+	sfDir, err := ioutil.TempDir("", "statefile")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(sfDir) }()
+
+	conf := generateTestConfiguration(t, ckDir, sfDir)
+	mf := metric.NewFakeMetricsFetcher(metrics.DummyMetrics{}).(*metric.FakeMetricsFetcher)
+	pods := []*v1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod1",
+				Namespace: "default",
+				UID:       "uid1",
+			},
+		},
+	}
+
+	advisor, metaCache := newTestCPUResourceAdvisor(t, pods, conf, mf, nil)
+	advisor.startTime = time.Now().Add(-types.StartUpPeriod)
+	advisor.conf.GetDynamicConfiguration().EnableReclaim = true
+
+	_ = metaCache.SetPoolInfo(state.PoolNameReserve, &types.PoolInfo{
+		PoolName: state.PoolNameReserve,
+		TopologyAwareAssignments: map[int]machine.CPUSet{
+			0: machine.MustParse("0"),
+			1: machine.MustParse("24"),
+		},
+	})
+	_ = metaCache.SetPoolInfo(state.PoolNameShare, &types.PoolInfo{
+		PoolName: state.PoolNameShare,
+		TopologyAwareAssignments: map[int]machine.CPUSet{
+			0: machine.MustParse("1"),
+			1: machine.MustParse("25"),
+		},
+	})
+	_ = metaCache.SetContainerInfo("uid1", "c1", makeContainerInfo("uid1", "default", "pod1", "c1",
+		consts.PodAnnotationQoSLevelSharedCores, state.PoolNameShare, nil,
+		map[int]machine.CPUSet{
+			0: machine.MustParse("1"),
+			1: machine.MustParse("25"),
+		}, 4))
+
+	advisor.update()
+	result := <-advisor.sendCh
+	assert.Equal(t, map[int]int{-1: 8}, result.PoolEntries[state.PoolNameShare])
+	assert.Equal(t, types.AssemblyReasonPeriodic, result.Reason)
+
+	regions := advisor.ListRegions()
+	require.Len(t, regions, 1)
+	regionName := regions[0].Name
+
+	// control knob of the region is adjusted out of band
+	r := newSimulatedRegion(advisor.regionMap[regionName])
+	r.controlKnob = types.ControlKnob{
+		types.ControlKnobNonReclaimedCPUSize: {Value: 20},
+	}
+	counted := &provisionCountingRegion{QoSRegion: r}
+	advisor.regionMap[regionName] = counted
+
+	require.NoError(t, advisor.RefreshRegion(regionName))
+	result = <-advisor.sendCh
+	assert.Equal(t, map[int]int{-1: 20}, result.PoolEntries[state.PoolNameShare])
+	assert.Equal(t, map[int]int{-1: 74}, result.PoolEntries[state.PoolNameReclaim])
+	assert.Equal(t, types.AssemblyReasonRegionRefresh, result.Reason)
+	assert.Equal(t, 1, counted.calls)
+
+	assert.Error(t, advisor.RefreshRegion("not-exist"))
+}
+
+// updateCountingEmitter counts updates of cpu advisor by the update duration emitted on each of them
+type updateCountingEmitter struct {
+	metrics.DummyMetrics
+
+	mutex   sync.Mutex
+	updates int
+}
+
+func (e *updateCountingEmitter) StoreFloat64(key string, _ float64, _ metrics.MetricTypeName, _ ...metrics.MetricTag) error {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	if key == metricCPUAdvisorUpdateDuration {
+		e.updates++
+	}
+	return nil
+}
+
+func (e *updateCountingEmitter) getUpdates() int {
+	e.mutex.Lock()
+	defer e.mutex.Unlock()
+
+	return e.updates
+}
+
+func TestUpdateDue(t *testing.T) {
+	t.Parallel()
+
+	conf, err := options.NewOptions().Config()
+	require.NoError(t, err)
+	conf.QoSAwarePluginConfiguration.SyncPeriod = 5 * time.Second
+	conf.ResourceUpdateIntervals = map[string]time.Duration{
+		string(types.QoSResourceCPU):    10 * time.Second,
+		string(types.QoSResourceMemory): 30 * time.Second,
+	}
+
+	now := time.Now()
+	fakeClock := testingclock.NewFakePassiveClock(now)
+	emitter := &updateCountingEmitter{}
+	// advisor is kept starting up, so that each update returns right after being counted
+	cra := &cpuResourceAdvisor{
+		conf:      conf,
+		recvCh:    make(chan types.TriggerInfo),
+		period:    conf.QoSAwarePluginConfiguration.SyncPeriod,
+		startTime: now.Add(time.Hour),
+		emitter:   emitter,
+		clock:     fakeClock,
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go cra.Run(ctx)
+
+	// triggerFor triggers advisor every sync period for the duration, and each trigger is followed by
+	// an outdated one, which is received only after the former is handled
+	elapsed := time.Duration(0)
+	triggerFor := func(d time.Duration) {
+		for end := elapsed + d; elapsed < end; elapsed += conf.QoSAwarePluginConfiguration.SyncPeriod {
+			fakeClock.SetTime(now.Add(elapsed))
+			cra.recvCh <- types.TriggerInfo{TimeStamp: fakeClock.Now()}
+			cra.recvCh <- types.TriggerInfo{}
+		}
+	}
+
+	// triggered every sync period in a minute, cpu advisor updates every 10s, more frequently than
+	// memory advisor updating every 30s
+	triggerFor(time.Minute)
+	assert.Equal(t, 6, emitter.getUpdates())
+	assert.Greater(t, emitter.getUpdates(), int(time.Minute/conf.ResourceUpdateIntervals[string(types.QoSResourceMemory)]))
+
+	// update interval changed to 20s takes effect since the last update at 50s, i.e. updates at 70s, 90s and 110s
+	conf.ResourceUpdateIntervals[string(types.QoSResourceCPU)] = 20 * time.Second
+	triggerFor(time.Minute)
+	assert.Equal(t, 9, emitter.getUpdates())
+
+	// cpu advisor without update interval updates on every trigger
+	delete(conf.ResourceUpdateIntervals, string(types.QoSResourceCPU))
+	triggerFor(3 * conf.QoSAwarePluginConfiguration.SyncPeriod)
+	assert.Equal(t, 12, emitter.getUpdates())
+}
+
+func TestNotifyProvisionChangeDetection(t *testing.T) {
+	t.Parallel()
+
+	conf, err := options.NewOptions().Config()
+	require.NoError(t, err)
+	conf.ProvisionForcePushInterval = time.Minute
+
+	now := time.Now()
+	fakeClock := testingclock.NewFakePassiveClock(now)
+	assembler := &fakeProvisionAssembler{result: types.InternalCPUCalculationResult{
+		PoolEntries: map[string]map[int]int{
+			state.PoolNameShare:   {-1: 8},
+			state.PoolNameReclaim: {-1: 4},
+		},
+		TimeStamp: now,
+	}}
+	cra := &cpuResourceAdvisor{
+		conf:               conf,
+		sendCh:             make(chan types.InternalCPUCalculationResult, 4),
+		provisionAssembler: assembler,
+		circuitBreaker:     newProvisionCircuitBreaker(0, time.Minute, fakeClock),
+		emitter:            metrics.DummyMetrics{},
+		clock:              fakeClock,
+	}
+	assembleAndNotify := func() {
+		result, boundUpper, err := cra.assembleProvision()
+		require.NoError(t, err)
+		cra.notifyProvision(result, boundUpper)
+	}
+
+	// identical assemblies are sent only once, regardless of timestamp
+	assembleAndNotify()
+	fakeClock.SetTime(now.Add(10 * time.Second))
+	assembler.result.TimeStamp = fakeClock.Now()
+	assembleAndNotify()
+	assert.Equal(t, 1, len(cra.sendCh))
+
+	// changed pool entries are sent immediately
+	assembler.result.PoolEntries[state.PoolNameReclaim][-1] = 6
+	assembleAndNotify()
+	assert.Equal(t, 2, len(cra.sendCh))
+
+	// unchanged result is sent as heartbeat after force push interval
+	fakeClock.SetTime(now.Add(2 * time.Minute))
+	assembleAndNotify()
+	assert.Equal(t, 3, len(cra.sendCh))
+
+	// zero force push interval pushes every result
+	conf.ProvisionForcePushInterval = 0
+	assembleAndNotify()
+	assert.Equal(t, 4, len(cra.sendCh))
+}
@@ -21,7 +21,6 @@ import (
 
 	"k8s.io/apimachinery/pkg/api/resource"
 
-	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
 	"github.com/kubewharf/katalyst-core/pkg/config"
@@ -33,21 +32,26 @@ import (
 )
 
 type HeadroomAssemblerCommon struct {
-	conf *config.Configuration
+	conf            *config.Configuration
+	nonBindingNumas *machine.CPUSet
 
 	metaReader metacache.MetaReader
 	metaServer *metaserver.MetaServer
 	emitter    metrics.MetricEmitter
+
+	spikeBuffer *spikeBuffer
 }
 
 func NewHeadroomAssemblerCommon(conf *config.Configuration, _ interface{}, _ *map[string]region.QoSRegion,
-	_ *map[int]int, _ *map[int]int, _ *machine.CPUSet, metaReader metacache.MetaReader,
+	_ *map[int]int, _ *map[int]int, nonBindingNumas *machine.CPUSet, metaReader metacache.MetaReader,
 	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter) HeadroomAssembler {
 	return &HeadroomAssemblerCommon{
-		conf:       conf,
-		metaReader: metaReader,
-		metaServer: metaServer,
-		emitter:    emitter,
+		conf:            conf,
+		nonBindingNumas: nonBindingNumas,
+		metaReader:      metaReader,
+		metaServer:      metaServer,
+		emitter:         emitter,
+		spikeBuffer:     newSpikeBuffer(conf.SpikeBufferWindowSize, conf.SpikeBufferSensitivity),
 	}
 }
 
@@ -59,17 +63,23 @@ func (ha *HeadroomAssemblerCommon) GetHeadroom() (resource.Quantity, error) {
 		return *resource.NewQuantity(0, resource.DecimalSI), nil
 	}
 
-	reclaimedMetrics, err := ha.getPoolMetrics(state.PoolNameReclaim)
+	reclaimedMetrics, err := ha.getPoolMetrics(ha.conf.ReclaimPoolName)
 	if err != nil {
 		return resource.Quantity{}, err
 	}
 
 	// if util based cpu headroom disable, just return total reclaim pool size as headroom
 	if !dynamicConfig.CPUUtilBasedConfiguration.Enable {
-		return *resource.NewQuantity(int64(reclaimedMetrics.poolSize), resource.DecimalSI), nil
+		headroom := subtractSpikeBuffer(ha.spikeBuffer, ha.metaServer, ha.emitter, float64(reclaimedMetrics.poolSize))
+		return *resource.NewQuantity(int64(headroom), resource.DecimalSI), nil
 	}
 
-	return ha.getUtilBasedHeadroom(dynamicConfig, reclaimedMetrics)
+	utilBasedHeadroom, err := ha.getUtilBasedHeadroom(dynamicConfig, reclaimedMetrics)
+	if err != nil {
+		return resource.Quantity{}, err
+	}
+	headroom := subtractSpikeBuffer(ha.spikeBuffer, ha.metaServer, ha.emitter, float64(utilBasedHeadroom.Value()))
+	return *resource.NewQuantity(int64(headroom), resource.DecimalSI), nil
 }
 
 type poolMetrics struct {
@@ -85,7 +95,16 @@ func (ha *HeadroomAssemblerCommon) getPoolMetrics(poolName string) (*poolMetrics
 		return nil, fmt.Errorf("failed get reclaim pool info")
 	}
 
-	cpuSet := reclaimedInfo.TopologyAwareAssignments.MergeCPUSet()
+	assignments := reclaimedInfo.TopologyAwareAssignments
+	if poolName == ha.conf.ReclaimPoolName && ha.conf.DisableNonBindingReclaim && ha.nonBindingNumas != nil {
+		// reclaim pool on non-binding numas is not offered any more, and only binding numas are counted
+		assignments = assignments.Clone()
+		for _, numaID := range ha.nonBindingNumas.ToSliceInt() {
+			delete(assignments, numaID)
+		}
+	}
+
+	cpuSet := assignments.MergeCPUSet()
 	m := ha.metaServer.AggregateCoreMetric(cpuSet, pkgconsts.MetricCPUUsageRatio, metric.AggregatorAvg)
 	return &poolMetrics{
 		coreAvgUtil: m.Value,
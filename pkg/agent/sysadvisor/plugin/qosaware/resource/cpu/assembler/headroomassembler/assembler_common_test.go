@@ -88,6 +88,7 @@ func TestHeadroomAssemblerCommon_GetHeadroom(t *testing.T) {
 	t.Parallel()
 
 	now := time.Now()
+	nonBindingNumas := machine.NewCPUSet(0)
 
 	type fields struct {
 		entries                        types.RegionEntries
@@ -96,6 +97,8 @@ func TestHeadroomAssemblerCommon_GetHeadroom(t *testing.T) {
 		reclaimedResourceConfiguration *reclaimedresource.ReclaimedResourceConfiguration
 		setFakeMetric                  func(store *metric.FakeMetricsFetcher)
 		setMetaCache                   func(cache *metacache.MetaCacheImp)
+		disableNonBindingReclaim       bool
+		nonBindingNumas                *machine.CPUSet
 	}
 	tests := []struct {
 		name    string
@@ -193,6 +196,47 @@ func TestHeadroomAssemblerCommon_GetHeadroom(t *testing.T) {
 			},
 			want: *resource.NewQuantity(10, resource.DecimalSI),
 		},
+		{
+			name: "disable non binding reclaim",
+			fields: fields{
+				entries: map[string]*types.RegionInfo{
+					"share-0": {
+						RegionType: types.QoSRegionTypeShare,
+					},
+				},
+				cnr: &v1alpha1.CustomNodeResource{
+					Status: v1alpha1.CustomNodeResourceStatus{
+						Resources: v1alpha1.Resources{
+							Allocatable: &v1.ResourceList{
+								consts.ReclaimedResourceMilliCPU: resource.MustParse("14000"),
+							},
+						},
+					},
+				},
+				reclaimedResourceConfiguration: &reclaimedresource.ReclaimedResourceConfiguration{
+					EnableReclaim: true,
+					CPUHeadroomConfiguration: &cpuheadroom.CPUHeadroomConfiguration{
+						CPUUtilBasedConfiguration: &cpuheadroom.CPUUtilBasedConfiguration{
+							Enable: false,
+						},
+					},
+				},
+				setFakeMetric: func(store *metric.FakeMetricsFetcher) {},
+				setMetaCache: func(cache *metacache.MetaCacheImp) {
+					err := cache.SetPoolInfo(state.PoolNameReclaim, &types.PoolInfo{
+						PoolName: state.PoolNameReclaim,
+						TopologyAwareAssignments: map[int]machine.CPUSet{
+							0: machine.MustParse("0-9"),
+							1: machine.MustParse("48-51"),
+						},
+					})
+					require.NoError(t, err)
+				},
+				disableNonBindingReclaim: true,
+				nonBindingNumas:          &nonBindingNumas,
+			},
+			want: *resource.NewQuantity(4, resource.DecimalSI),
+		},
 		{
 			name: "gap by oversold ratio",
 			fields: fields{
@@ -352,7 +396,8 @@ func TestHeadroomAssemblerCommon_GetHeadroom(t *testing.T) {
 			tt.fields.setMetaCache(metaCache)
 
 			metaServer := generateTestMetaServer(t, tt.fields.cnr, tt.fields.podList, metricsFetcher)
-			ha := NewHeadroomAssemblerCommon(conf, nil, nil, nil, nil, nil, metaCache, metaServer, metrics.DummyMetrics{})
+			conf.DisableNonBindingReclaim = tt.fields.disableNonBindingReclaim
+			ha := NewHeadroomAssemblerCommon(conf, nil, nil, nil, nil, tt.fields.nonBindingNumas, metaCache, metaServer, metrics.DummyMetrics{})
 
 			store := metricsFetcher.(*metric.FakeMetricsFetcher)
 			tt.fields.setFakeMetric(store)
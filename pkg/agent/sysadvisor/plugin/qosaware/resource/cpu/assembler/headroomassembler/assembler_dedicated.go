@@ -23,7 +23,6 @@ import (
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/klog/v2"
 
-	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
@@ -43,6 +42,8 @@ type HeadroomAssemblerDedicated struct {
 	metaReader metacache.MetaReader
 	metaServer *metaserver.MetaServer
 	emitter    metrics.MetricEmitter
+
+	spikeBuffer *spikeBuffer
 }
 
 func NewHeadroomAssemblerDedicated(conf *config.Configuration, _ interface{}, regionMap *map[string]region.QoSRegion,
@@ -58,6 +59,8 @@ func NewHeadroomAssemblerDedicated(conf *config.Configuration, _ interface{}, re
 		metaReader: metaReader,
 		metaServer: metaServer,
 		emitter:    emitter,
+
+		spikeBuffer: newSpikeBuffer(conf.SpikeBufferWindowSize, conf.SpikeBufferSensitivity),
 	}
 }
 
@@ -89,8 +92,15 @@ func (ha *HeadroomAssemblerDedicated) GetHeadroom() (resource.Quantity, error) {
 		emptyNUMAs = emptyNUMAs.Difference(r.GetBindingNumas())
 	}
 
+	// non binding numas, including empty ones, offer nothing if non binding reclaim is disabled
+	if ha.conf.DisableNonBindingReclaim {
+		klog.Infof("[qosaware-cpu] total headroom assembled %.2f, non binding reclaim disabled", headroomTotal)
+		headroomTotal = subtractSpikeBuffer(ha.spikeBuffer, ha.metaServer, ha.emitter, headroomTotal)
+		return *resource.NewQuantity(int64(headroomTotal), resource.DecimalSI), nil
+	}
+
 	// add non binding reclaim pool size
-	reclaimPoolInfo, ok := ha.metaReader.GetPoolInfo(state.PoolNameReclaim)
+	reclaimPoolInfo, ok := ha.metaReader.GetPoolInfo(ha.conf.ReclaimPoolName)
 	if ok && reclaimPoolInfo != nil {
 		reclaimPoolNUMAs := machine.GetCPUAssignmentNUMAs(reclaimPoolInfo.TopologyAwareAssignments)
 		for _, numaID := range reclaimPoolNUMAs.Difference(exclusiveNUMAs).Difference(emptyNUMAs).ToSliceInt() {
@@ -113,6 +123,7 @@ func (ha *HeadroomAssemblerDedicated) GetHeadroom() (resource.Quantity, error) {
 	}
 
 	klog.Infof("[qosaware-cpu] total headroom assembled %.2f", headroomTotal)
+	headroomTotal = subtractSpikeBuffer(ha.spikeBuffer, ha.metaServer, ha.emitter, headroomTotal)
 
 	return *resource.NewQuantity(int64(headroomTotal), resource.DecimalSI), nil
 }
@@ -0,0 +1,106 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package headroomassembler
+
+import (
+	"math"
+
+	"k8s.io/klog/v2"
+
+	pkgconsts "github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	"github.com/kubewharf/katalyst-core/pkg/util/metric"
+)
+
+const (
+	metricCPUHeadroomSpikeBuffer = "cpu_headroom_spike_buffer"
+)
+
+// spikeBuffer keeps a rolling window of node cpu utilization samples, and estimates the buffer
+// reserved for system spikes, which scales with standard deviation of the samples and approaches
+// zero if the utilization is steady
+type spikeBuffer struct {
+	windowSize  int
+	sensitivity float64
+
+	index   int
+	samples []float64
+}
+
+func newSpikeBuffer(windowSize int, sensitivity float64) *spikeBuffer {
+	return &spikeBuffer{
+		windowSize:  windowSize,
+		sensitivity: sensitivity,
+		samples:     make([]float64, 0, general.Max(windowSize, 0)),
+	}
+}
+
+// addSample records a utilization sample, and overwrites the oldest one if the window is full
+func (b *spikeBuffer) addSample(utilization float64) {
+	if b.windowSize <= 0 {
+		return
+	}
+
+	if len(b.samples) < b.windowSize {
+		b.samples = append(b.samples, utilization)
+		return
+	}
+	b.samples[b.index] = utilization
+	b.index = (b.index + 1) % b.windowSize
+}
+
+// getBuffer returns cpu cores reserved for spikes out of the given capacity, and at least
+// two samples are needed to estimate volatility
+func (b *spikeBuffer) getBuffer(capacity float64) float64 {
+	if len(b.samples) < 2 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, sample := range b.samples {
+		mean += sample
+	}
+	mean /= float64(len(b.samples))
+
+	variance := 0.0
+	for _, sample := range b.samples {
+		variance += (sample - mean) * (sample - mean)
+	}
+	variance /= float64(len(b.samples))
+
+	return b.sensitivity * math.Sqrt(variance) * capacity
+}
+
+// subtractSpikeBuffer samples node cpu utilization from metaserver, and returns the headroom
+// after subtracting the spike buffer, which never drops below zero
+func subtractSpikeBuffer(buffer *spikeBuffer, metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter, headroom float64) float64 {
+	if buffer == nil || buffer.windowSize <= 0 {
+		return headroom
+	}
+
+	cpus := metaServer.CPUDetails.CPUs()
+	utilization := metaServer.AggregateCoreMetric(cpus, pkgconsts.MetricCPUUsageRatio, metric.AggregatorAvg)
+	buffer.addSample(utilization.Value)
+
+	spike := buffer.getBuffer(float64(cpus.Size()))
+	klog.InfoS("[qosaware-cpu] cpu headroom spike buffer", "headroom", headroom, "buffer", spike, "samples", len(buffer.samples))
+	_ = emitter.StoreFloat64(metricCPUHeadroomSpikeBuffer, spike, metrics.MetricTypeNameRaw)
+
+	return math.Max(headroom-spike, 0)
+}
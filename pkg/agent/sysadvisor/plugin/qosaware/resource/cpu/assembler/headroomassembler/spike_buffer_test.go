@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package headroomassembler
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/adminqos/reclaimedresource"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/adminqos/reclaimedresource/cpuheadroom"
+	pkgconsts "github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/metric"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	metricspool "github.com/kubewharf/katalyst-core/pkg/metrics/metrics-pool"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+	utilmetric "github.com/kubewharf/katalyst-core/pkg/util/metric"
+)
+
+func TestHeadroomAssemblerCommon_SpikeBuffer(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		utilizations []float64
+		want         int64
+	}{
+		{
+			name:         "steady load",
+			utilizations: []float64{0.3, 0.3, 0.3, 0.3, 0.3},
+			want:         86,
+		},
+		{
+			name:         "slightly fluctuating load",
+			utilizations: []float64{0.3, 0.31, 0.3, 0.31, 0.3},
+			want:         85,
+		},
+		{
+			name:         "volatile load",
+			utilizations: []float64{0.1, 0.7, 0.1, 0.7, 0.1},
+			want:         29,
+		},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ckDir, err := ioutil.TempDir("", "checkpoint-TestHeadroomAssemblerCommon_SpikeBuffer")
+			require.NoError(t, err)
+			defer os.RemoveAll(ckDir)
+
+			sfDir, err := ioutil.TempDir("", "statefile")
+			require.NoError(t, err)
+			defer os.RemoveAll(sfDir)
+
+			conf := generateTestConfiguration(t, ckDir, sfDir)
+			conf.GetDynamicConfiguration().ReclaimedResourceConfiguration = &reclaimedresource.ReclaimedResourceConfiguration{
+				EnableReclaim: true,
+				CPUHeadroomConfiguration: &cpuheadroom.CPUHeadroomConfiguration{
+					CPUUtilBasedConfiguration: &cpuheadroom.CPUUtilBasedConfiguration{},
+				},
+			}
+			conf.SpikeBufferWindowSize = 5
+			conf.SpikeBufferSensitivity = 2
+
+			metricsFetcher := metric.NewFakeMetricsFetcher(metrics.DummyMetrics{})
+			metaCache, err := metacache.NewMetaCacheImp(conf, metricspool.DummyMetricsEmitterPool{}, metricsFetcher)
+			require.NoError(t, err)
+			require.NoError(t, metaCache.SetPoolInfo(state.PoolNameReclaim, &types.PoolInfo{
+				PoolName: state.PoolNameReclaim,
+				TopologyAwareAssignments: map[int]machine.CPUSet{
+					0: machine.MustParse("0-85"),
+				},
+			}))
+
+			metaServer := generateTestMetaServer(t, nil, nil, metricsFetcher)
+			ha := NewHeadroomAssemblerCommon(conf, nil, nil, nil, nil, nil, metaCache, metaServer, metrics.DummyMetrics{})
+
+			store := metricsFetcher.(*metric.FakeMetricsFetcher)
+			var got int64
+			for _, utilization := range tt.utilizations {
+				now := time.Now()
+				for i := 0; i < 96; i++ {
+					store.SetCPUMetric(i, pkgconsts.MetricCPUUsageRatio, utilmetric.MetricData{Value: utilization, Time: &now})
+				}
+				headroom, err := ha.GetHeadroom()
+				require.NoError(t, err)
+				got = headroom.Value()
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSpikeBuffer(t *testing.T) {
+	t.Parallel()
+
+	b := newSpikeBuffer(3, 1)
+	b.addSample(0.5)
+	assert.Equal(t, 0.0, b.getBuffer(10), "volatility needs at least two samples")
+
+	b.addSample(0.5)
+	assert.Equal(t, 0.0, b.getBuffer(10))
+
+	b.addSample(0.8)
+	assert.InDelta(t, 1.414, b.getBuffer(10), 0.001)
+
+	// the oldest samples are rolled out of the window
+	b.addSample(0.8)
+	b.addSample(0.8)
+	assert.InDelta(t, 0.0, b.getBuffer(10), 1e-9)
+
+	disabled := newSpikeBuffer(0, 1)
+	disabled.addSample(0.1)
+	disabled.addSample(0.9)
+	assert.Equal(t, 0.0, disabled.getBuffer(10))
+}
@@ -17,7 +17,9 @@ limitations under the License.
 package provisionassembler
 
 import (
+	"context"
 	"sync"
+	"time"
 
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
@@ -33,10 +35,37 @@ import (
 // and NOT supposed to be used by other components.
 type ProvisionAssembler interface {
 	AssembleProvision() (types.InternalCPUCalculationResult, bool, error)
+	// AssembleProvisionWithContext works like AssembleProvision, but gives up assembling once ctx is done
+	AssembleProvisionWithContext(ctx context.Context) (types.InternalCPUCalculationResult, bool, error)
+	// AssembleProvisionPartial recomputes provision of the changed regions only, and reuses
+	// provision of other regions cached by previous assembling; the result is built with the
+	// same regulation as AssembleProvision, so entries derived from changed regions match it
+	AssembleProvisionPartial(changedRegions []string) (types.InternalCPUCalculationResult, bool, error)
+	// Reset clears internal states kept by assembler across consecutive assembling
+	Reset()
+	// ReclaimBreakdown itemizes factors trimming reclaim pool of the last successful assembling
+	ReclaimBreakdown() ReclaimBreakdown
+	// SocketReclaimView rolls up reclaim pool entries of the last successful assembling into sockets
+	SocketReclaimView() map[int]int
+	// HeadroomAttribution returns slack donated to reclaim by each dedicated pod in the last successful assembling
+	HeadroomAttribution() map[string]int
+	// AppliedReservedForReclaim returns reserved for reclaim applied to reclaim pool of the last successful assembling
+	AppliedReservedForReclaim() int
+	// ReclaimCriticalReservation returns the reclaim critical reservation carved in the last assembling in total
+	ReclaimCriticalReservation() int
+	// DrainNumaReclaim drains reclaim of the numa toward zero gradually over the duration across assembling,
+	// and UndrainNumaReclaim stops draining it
+	DrainNumaReclaim(numaID int, over time.Duration) error
+	UndrainNumaReclaim(numaID int)
+
+	// SetRegionReservedForReclaim overrides reserved for reclaim on the binding numa of the dedicated region,
+	// taking precedence over node-level reserved for reclaim, and ClearRegionReservedForReclaim clears it
+	SetRegionReservedForReclaim(regionName string, reserved int) error
+	ClearRegionReservedForReclaim(regionName string)
 }
 
 type InitFunc func(conf *config.Configuration, extraConf interface{}, regionMap *map[string]region.QoSRegion,
-	reservedForReclaim *map[int]int, numaAvailable *map[int]int, nonBindingNumas *machine.CPUSet,
+	reservedForReclaim *map[int]int, availability AvailabilityProvider, nonBindingNumas *machine.CPUSet,
 	reader metacache.MetaReader, metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter) ProvisionAssembler
 
 var initializers sync.Map
@@ -19,9 +19,14 @@ package provisionassembler
 import (
 	"context"
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"sync"
 	"time"
 
-	"k8s.io/klog/v2"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/utils/clock"
 
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
@@ -36,111 +41,442 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/util/machine"
 )
 
+const (
+	metricCPUProvisionReservePoolConfigured     = "cpu_provision_reserve_pool_configured"
+	metricCPUProvisionReservePoolClamped        = "cpu_provision_reserve_pool_clamped"
+	metricCPUProvisionDedicatedReclaimExhausted = "cpu_provision_dedicated_reclaim_exhausted"
+	metricCPUProvisionCapacityOvercommit        = "cpu_provision_capacity_overcommit"
+	metricCPUProvisionPoolSizeDrift             = "cpu_provision_pool_size_drift"
+	metricCPUProvisionReclaimCeiling            = "cpu_provision_reclaim_ceiling"
+	metricCPUProvisionDedicatedRegionEmptyPod   = "cpu_provision_dedicated_region_empty_pod"
+	metricCPUProvisionAssemblyDuration          = "cpu_provision_assembly_duration"
+	metricCPUProvisionAssemblyTimestamp         = "cpu_provision_assembly_timestamp"
+	metricCPUProvisionImplausibleControlKnob    = "cpu_provision_implausible_control_knob"
+	metricCPUProvisionNonFiniteControlKnob      = "cpu_provision_non_finite_control_knob"
+	metricCPUProvisionReservedForReclaimDrift   = "cpu_provision_reserved_for_reclaim_drift"
+	metricCPUProvisionRegionStaleNuma           = "cpu_provision_region_stale_numa"
+)
+
 type ProvisionAssemblerCommon struct {
+	// mutex serializes assembling and resetting, since they share pointers with advisor and
+	// cached states across cycles; post processors and logger are invoked with mutex held,
+	// so they must not call back into the assembler
+	mutex sync.Mutex
+
 	conf               *config.Configuration
+	assemblerConf      *AssemblerConfig // resolved from conf once per assembly
 	regionMap          *map[string]region.QoSRegion
 	reservedForReclaim *map[int]int
-	numaAvailable      *map[int]int
-	nonBindingNumas    *machine.CPUSet
+	// resolvedReservedForReclaim is reserved for reclaim of each numa resolved for this assembling
+	resolvedReservedForReclaim map[int]int // map[numaID]reservedForReclaim
+	nonBindingNumas            *machine.CPUSet
+
+	// availability is the provided availability wrapped with adjustments of this assembling
+	availability AvailabilityProvider
+	adjustments  *availabilityAdjustments
 
 	metaReader metacache.MetaReader
 	metaServer *metaserver.MetaServer
 	emitter    metrics.MetricEmitter
+	logger     Logger
+
+	// clock is an interface that provides time related functionality in a way that makes it
+	// easy to test the code.
+	clock clock.PassiveClock
+
+	// postProcessors are invoked in order to tweak the raw provision result
+	postProcessors []PostProcessor
+
+	// per feature state kept across assembling
+	reservePoolState *reservePoolState
+	reclaimPoolState *reclaimPoolState
+	sharePoolState   *sharePoolState
+	regionState      *regionState
+
+	// isolationContended records whether isolation regions on non-binding numas are sized by lower sizes
+	isolationContended bool
+
+	// lastPoolLayoutSeries records series of pool layout metric emitted for the last assembling
+	lastPoolLayoutSeries map[poolLayoutSeries]struct{}
+
+	// reclaimBreakdown itemizes factors trimming reclaim pool of the last successful assembling
+	reclaimBreakdown ReclaimBreakdown
+
+	// socketReclaimView is reclaim pool size of each socket in the last successful assembling
+	socketReclaimView map[int]int // map[socketID]reclaimPoolSize
+
+	// headroomAttribution is slack donated to reclaim by each dedicated pod in the last successful assembling
+	headroomAttribution map[string]int // map[podUID]slack
+
+	// numaReclaimDrains records numas whose reclaim is being drained, kept across resetting
+	numaReclaimDrains map[int]*numaReclaimDrain // map[numaID]drain
 }
 
 func NewProvisionAssemblerCommon(conf *config.Configuration, _ interface{}, regionMap *map[string]region.QoSRegion,
-	reservedForReclaim *map[int]int, numaAvailable *map[int]int, nonBindingNumas *machine.CPUSet,
+	reservedForReclaim *map[int]int, availability AvailabilityProvider, nonBindingNumas *machine.CPUSet,
 	metaReader metacache.MetaReader, metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter) ProvisionAssembler {
-	return &ProvisionAssemblerCommon{
+	// metric emission is a no-op if assembler is embedded without emitter, e.g. in tests and tools
+	if emitter == nil {
+		emitter = metrics.DummyMetrics{}
+	}
+
+	pa := &ProvisionAssemblerCommon{
 		conf:               conf,
+		assemblerConf:      &AssemblerConfig{},
 		regionMap:          regionMap,
 		reservedForReclaim: reservedForReclaim,
-		numaAvailable:      numaAvailable,
 		nonBindingNumas:    nonBindingNumas,
 
 		metaReader: metaReader,
 		metaServer: metaServer,
 		emitter:    emitter,
+		logger:     NewKlogLogger(),
+
+		clock: clock.RealClock{},
+
+		adjustments: newAvailabilityAdjustments(),
+
+		reservePoolState: newReservePoolState(),
+		reclaimPoolState: newReclaimPoolState(),
+		sharePoolState:   newSharePoolState(),
+		regionState:      newRegionState(),
+
+		numaReclaimDrains:    make(map[int]*numaReclaimDrain),
+		lastPoolLayoutSeries: make(map[poolLayoutSeries]struct{}),
+		reclaimBreakdown:     newReclaimBreakdown(0, state.PoolNameReclaim),
+
+		postProcessors: []PostProcessor{NewPostProcessorNoop()},
+	}
+	pa.availability = newAdjustedAvailability(availability, pa.adjustments)
+	if err := pa.refreshAssemblerConfig(); err != nil {
+		pa.logger.Errorf("[qosaware-cpu] %v", err)
 	}
+	pa.resolveReservedForReclaim()
+
+	return pa
+}
+
+// SetLogger overwrites the logger recording assembling decisions
+func (pa *ProvisionAssemblerCommon) SetLogger(logger Logger) {
+	pa.mutex.Lock()
+	defer pa.mutex.Unlock()
+
+	pa.logger = logger
+}
+
+// SetPostProcessors overwrites the chain of post processors invoked after raw result is built
+func (pa *ProvisionAssemblerCommon) SetPostProcessors(processors ...PostProcessor) {
+	pa.mutex.Lock()
+	defer pa.mutex.Unlock()
+
+	pa.postProcessors = processors
 }
 
 func (pa *ProvisionAssemblerCommon) AssembleProvision() (types.InternalCPUCalculationResult, bool, error) {
-	nodeEnableReclaim := pa.conf.GetDynamicConfiguration().EnableReclaim
+	return pa.AssembleProvisionWithContext(context.Background())
+}
+
+func (pa *ProvisionAssemblerCommon) AssembleProvisionWithContext(ctx context.Context) (types.InternalCPUCalculationResult, bool, error) {
+	pa.mutex.Lock()
+	defer pa.mutex.Unlock()
+
+	startTime := pa.clock.Now()
+	calculationResult, boundUpper, err := pa.assembleProvision(ctx, nil)
+	pa.emitAssemblyMetrics(startTime, err)
+
+	return calculationResult, boundUpper, err
+}
+
+func (pa *ProvisionAssemblerCommon) AssembleProvisionPartial(changedRegions []string) (types.InternalCPUCalculationResult, bool, error) {
+	pa.mutex.Lock()
+	defer pa.mutex.Unlock()
+
+	for _, regionName := range changedRegions {
+		if _, ok := (*pa.regionMap)[regionName]; !ok {
+			return types.InternalCPUCalculationResult{}, false, fmt.Errorf("changed region %v not found", regionName)
+		}
+	}
+
+	startTime := pa.clock.Now()
+	calculationResult, boundUpper, err := pa.assembleProvision(context.Background(), sets.NewString(changedRegions...))
+	pa.emitAssemblyMetrics(startTime, err)
+
+	return calculationResult, boundUpper, err
+}
+
+// emitAssemblyMetrics emits duration (in milliseconds) and completion timestamp of assembling
+func (pa *ProvisionAssemblerCommon) emitAssemblyMetrics(startTime time.Time, err error) {
+	status := "success"
+	if err != nil {
+		status = "failure"
+	}
+	tags := []metrics.MetricTag{
+		{Key: "node", Val: pa.assemblerConf.NodeName},
+		{Key: "status", Val: status},
+	}
+
+	_ = pa.emitter.StoreInt64(metricCPUProvisionAssemblyDuration, pa.clock.Since(startTime).Milliseconds(), metrics.MetricTypeNameRaw, tags...)
+	_ = pa.emitter.StoreInt64(metricCPUProvisionAssemblyTimestamp, pa.clock.Now().Unix(), metrics.MetricTypeNameRaw, tags...)
+}
+
+// assembleProvision builds provision result from all regions; if changedRegions is not nil,
+// only provision of the changed regions (and those not cached yet) is refreshed. assembling
+// is given up once ctx is done, and ctx is passed on to queries against metaserver.
+func (pa *ProvisionAssemblerCommon) assembleProvision(ctx context.Context, changedRegions sets.String) (types.InternalCPUCalculationResult, bool, error) {
+	if err := pa.refreshAssemblerConfig(); err != nil {
+		return types.InternalCPUCalculationResult{}, false, err
+	}
+	nodeEnableReclaim := pa.getNodeEnableReclaim()
 
 	calculationResult := types.InternalCPUCalculationResult{
-		PoolEntries: make(map[string]map[int]int),
-		TimeStamp:   time.Now(),
+		PoolEntries:         make(map[string]map[int]int),
+		TimeStamp:           pa.clock.Now(),
+		RegionContributions: make(map[string]types.RegionContribution),
+		NumaClassifications: pa.classifyNumas(),
 	}
 
 	// fill in reserve pool entry
-	reservePoolSize, _ := pa.metaReader.GetPoolSize(state.PoolNameReserve)
+	reservePoolSize := pa.limitReservePoolGrowth()
+	reservePoolSize = pa.regulateReservePoolSize(reservePoolSize)
 	calculationResult.SetPoolEntry(state.PoolNameReserve, cpuadvisor.FakedNUMAID, reservePoolSize)
+	breakdown := newReclaimBreakdown(reservePoolSize, pa.assemblerConf.ReclaimPoolName)
+
+	pa.resolveReservedForReclaim()
+	pa.checkReservedForReclaimCoverage()
+	pa.carveReclaimCriticalReservation()
+	pa.carveReclaimReservation()
 
 	shares := 0
 	isolationUppers := 0
 
+	// summed requirements of share regions by owner pool, map[poolName]requirement
+	sharePoolRequirements := make(map[string]*sharePoolRequirement)
 	sharePoolSizes := make(map[string]int)
+	// sizes of share pools pinned to non-binding numas, map[numaID]map[poolName]size
+	pinnedSharePoolSizes := make(map[int]map[string]int)
 	isolationUpperSizes := make(map[string]int)
 	isolationLowerSizes := make(map[string]int)
 
+	// sizes of isolation regions with numa binding, map[numaID]map[regionName]size
+	bindingIsolationUpperSizes := make(map[int]map[string]int)
+	bindingIsolationLowerSizes := make(map[int]map[string]int)
+
+	// requested sizes of share and isolation regions, granted sizes are filled after regulation
+	regionRequests := make(map[string]types.RegionContribution)
+
+	// reserved for reclaim unsatisfiable on saturated dedicated numas
+	reservedForReclaimDeficit := 0
+
+	// slack donated to reclaim by each dedicated pod, map[podUID]slack
+	headroomAttribution := make(map[string]int)
+
+	// regions skipped in partial assembling
+	failures := newRegionFailures()
+
+	pa.regionState.updateFirstSeen(*pa.regionMap, pa.clock.Now())
+	pa.regionState.gc(*pa.regionMap)
+
+	nodeNumas := pa.metaServer.CPUDetails.NUMANodes()
 	for _, r := range *pa.regionMap {
-		controlKnob, err := r.GetProvision()
+		if err := ctx.Err(); err != nil {
+			return types.InternalCPUCalculationResult{}, false, fmt.Errorf("assembling cancelled: %v", err)
+		}
+
+		// stale region referencing numas absent from the node would be sized as if the numas were empty
+		if staleNumas := r.GetBindingNumas().Difference(nodeNumas); !staleNumas.IsEmpty() {
+			pa.logger.Warningf("[qosaware-cpu] skip region %v: binding numas %v are absent from node numas %v",
+				r.Name(), staleNumas.String(), nodeNumas.String())
+			_ = pa.emitter.StoreInt64(metricCPUProvisionRegionStaleNuma, int64(staleNumas.Size()), metrics.MetricTypeNameRaw,
+				metrics.MetricTag{Key: "region_name", Val: r.Name()})
+			continue
+		}
+
+		controlKnob, err := pa.resolveRegionProvision(r, changedRegions)
 		if err != nil {
-			return types.InternalCPUCalculationResult{}, false, err
+			if !pa.recordRegionFailure(&calculationResult, failures, r, err) {
+				return types.InternalCPUCalculationResult{}, false, err
+			}
+			continue
 		}
 
 		switch r.Type() {
 		case types.QoSRegionTypeShare:
-			// save raw share pool sizes
-			sharePoolSizes[r.OwnerPoolName()] = int(controlKnob[types.ControlKnobNonReclaimedCPUSize].Value)
+			size, err := pa.getRegionControlKnobValue(r, controlKnob, types.ControlKnobNonReclaimedCPUSize)
+			if err != nil {
+				if !pa.recordRegionFailure(&calculationResult, failures, r, err) {
+					return types.InternalCPUCalculationResult{}, false, err
+				}
+				continue
+			}
 
-			shares += sharePoolSizes[r.OwnerPoolName()]
+			pa.addSharePoolRequirement(sharePoolRequirements, r, size)
 
 		case types.QoSRegionTypeIsolation:
+			upper, err := pa.getRegionControlKnobValue(r, controlKnob, types.ControlKnobNonReclaimedCPUSizeUpper)
+			if err != nil {
+				if !pa.recordRegionFailure(&calculationResult, failures, r, err) {
+					return types.InternalCPUCalculationResult{}, false, err
+				}
+				continue
+			}
+			lower, err := pa.getRegionControlKnobValue(r, controlKnob, types.ControlKnobNonReclaimedCPUSizeLower)
+			if err != nil {
+				if !pa.recordRegionFailure(&calculationResult, failures, r, err) {
+					return types.InternalCPUCalculationResult{}, false, err
+				}
+				continue
+			}
+
+			// isolated region with numa binding is carved out of its binding numa
+			if bindingNumas := r.GetBindingNumas(); !bindingNumas.IsEmpty() {
+				if bindingNumas.Size() != 1 {
+					err := fmt.Errorf("isolation region %v binds to more than one numa: %v", r.Name(), bindingNumas)
+					if !pa.recordRegionFailure(&calculationResult, failures, r, err) {
+						return types.InternalCPUCalculationResult{}, false, err
+					}
+					continue
+				}
+				regionRequests[r.Name()] = types.RegionContribution{PoolName: r.Name(), RequestedSize: upper}
+				regionNuma := bindingNumas.ToSliceInt()[0]
+				if bindingIsolationUpperSizes[regionNuma] == nil {
+					bindingIsolationUpperSizes[regionNuma] = make(map[string]int)
+					bindingIsolationLowerSizes[regionNuma] = make(map[string]int)
+				}
+				bindingIsolationUpperSizes[regionNuma][r.Name()] = upper
+				bindingIsolationLowerSizes[regionNuma][r.Name()] = lower
+				continue
+			}
+
 			// save limits and requests for isolated region
-			isolationUpperSizes[r.Name()] = int(controlKnob[types.ControlKnobNonReclaimedCPUSizeUpper].Value)
-			isolationLowerSizes[r.Name()] = int(controlKnob[types.ControlKnobNonReclaimedCPUSizeLower].Value)
+			regionRequests[r.Name()] = types.RegionContribution{PoolName: r.Name(), RequestedSize: upper}
+			isolationUpperSizes[r.Name()] = upper
+			isolationLowerSizes[r.Name()] = lower
 
 			isolationUppers += isolationUpperSizes[r.Name()]
 
 		case types.QoSRegionTypeDedicatedNumaExclusive:
 			regionNuma := r.GetBindingNumas().ToSliceInt()[0] // always one binding numa for this type of region
-			reservedForReclaim := pa.getNumasReservedForReclaim(r.GetBindingNumas())
+			reservedForReclaim := pa.getRegionReservedForReclaim(r)
 
 			podSet := r.GetPods()
 			if podSet.Pods() != 1 {
-				return types.InternalCPUCalculationResult{}, false, fmt.Errorf("more than one pod are assgined to numa exclusive region: %v", podSet)
+				err := fmt.Errorf("more than one pod are assgined to numa exclusive region: %v", podSet)
+				if !pa.recordRegionFailure(&calculationResult, failures, r, err) {
+					return types.InternalCPUCalculationResult{}, false, err
+				}
+				continue
+			}
+			podUID, _, ok := podSet.PopAny()
+			if !ok || podUID == "" {
+				// pod set may be mutated concurrently after counting, skip the region instead of querying with empty uid
+				pa.logger.Warningf("[qosaware-cpu] skip region %v: no pod popped from pod set %v", r.Name(), podSet)
+				_ = pa.emitter.StoreInt64(metricCPUProvisionDedicatedRegionEmptyPod, 1, metrics.MetricTypeNameRaw,
+					metrics.MetricTag{Key: "region_name", Val: r.Name()})
+				continue
 			}
-			podUID, _, _ := podSet.PopAny()
 
-			enableReclaim, err := helper.PodEnableReclaim(context.Background(), pa.metaServer, podUID, nodeEnableReclaim)
+			enableReclaim, err := helper.PodEnableReclaim(ctx, pa.metaServer, podUID, nodeEnableReclaim)
 			if err != nil {
-				return types.InternalCPUCalculationResult{}, false, err
+				if !pa.recordRegionFailure(&calculationResult, failures, r, err) {
+					return types.InternalCPUCalculationResult{}, false, err
+				}
+				continue
 			}
 
-			// fill in reclaim pool entry for dedicated numa exclusive regions
+			// fill in reclaim pool entry for dedicated numa exclusive regions,
+			// and the entry should exist explicitly even if it's empty
+			available := getNumasAvailableResource(pa.availability, r.GetBindingNumas())
 			if !enableReclaim {
-				if reservedForReclaim > 0 {
-					calculationResult.SetPoolEntry(state.PoolNameReclaim, regionNuma, reservedForReclaim)
-				}
+				headroomAttribution[podUID] = 0
+				calculationResult.SetPoolEntryExplicitly(pa.assemblerConf.ReclaimPoolName, regionNuma, reservedForReclaim)
+				breakdown.setEntry(regionNuma, ReclaimBreakdownEntry{Available: available, NonReclaimed: available, ReservedForReclaim: reservedForReclaim})
 			} else {
-				available := getNumasAvailableResource(*pa.numaAvailable, r.GetBindingNumas())
-				nonReclaimRequirement := int(controlKnob[types.ControlKnobNonReclaimedCPUSize].Value)
+				nonReclaimRequirement, err := pa.getRegionControlKnobValue(r, controlKnob, types.ControlKnobNonReclaimedCPUSize)
+				if err != nil {
+					if !pa.recordRegionFailure(&calculationResult, failures, r, err) {
+						return types.InternalCPUCalculationResult{}, false, err
+					}
+					continue
+				}
+
+				breakdown.setEntry(regionNuma, ReclaimBreakdownEntry{Available: available, NonReclaimed: nonReclaimRequirement, ReservedForReclaim: reservedForReclaim})
+				headroomAttribution[podUID] = general.Max(available-nonReclaimRequirement, 0)
 				reclaimed := available - nonReclaimRequirement + reservedForReclaim
+				if reclaimed < reservedForReclaim {
+					reservedForReclaimDeficit += reservedForReclaim - general.Max(reclaimed, 0)
+				}
+				if reclaimed <= 0 {
+					// dedicated pod has grown to consume the whole numa, and nothing is left for reclaim
+					pa.logger.Warningf("[qosaware-cpu] region %v has no reclaim resource on numa %v: available %v, requirement %v, reserved %v",
+						r.Name(), regionNuma, available, nonReclaimRequirement, reservedForReclaim)
+					_ = pa.emitter.StoreInt64(metricCPUProvisionDedicatedReclaimExhausted, int64(reclaimed), metrics.MetricTypeNameRaw,
+						metrics.MetricTag{Key: "region_name", Val: r.Name()},
+						metrics.MetricTag{Key: "numa_id", Val: strconv.Itoa(regionNuma)})
+					reclaimed = 0
+					breakdown.adjust(regionNuma, reclaimAdjustmentExhausted, reclaimed)
+				}
 
-				calculationResult.SetPoolEntry(state.PoolNameReclaim, regionNuma, reclaimed)
+				calculationResult.SetPoolEntryExplicitly(pa.assemblerConf.ReclaimPoolName, regionNuma, reclaimed)
 			}
 		}
 	}
 
-	shareAndIsolatedPoolAvailable := getNumasAvailableResource(*pa.numaAvailable, *pa.nonBindingNumas)
+	for poolName, requirement := range sharePoolRequirements {
+		size := pa.deferSharePoolGrowth(poolName, requirement.size)
+		size = pa.limitSharePoolRate(poolName, size)
+		for _, regionName := range requirement.regionNames {
+			regionRequests[regionName] = types.RegionContribution{PoolName: poolName, RequestedSize: size}
+		}
+		size = pa.forecastSharePoolSize(poolName, size)
+
+		// share pool pinned to a non-binding numa is carved out of the numa
+		if numaID, ok := pa.getSharePoolNUMAAffinity(poolName); ok {
+			if pinnedSharePoolSizes[numaID] == nil {
+				pinnedSharePoolSizes[numaID] = make(map[string]int)
+			}
+			pinnedSharePoolSizes[numaID][poolName] = size
+			continue
+		}
+
+		// save raw share pool sizes
+		sharePoolSizes[poolName] = size
+		shares += size
+	}
+
+	pa.sharePoolState.gc(func(poolName string) bool {
+		_, ok := sharePoolSizes[poolName]
+		return ok || isPinnedSharePool(pinnedSharePoolSizes, poolName)
+	})
+
+	pa.assembleBindingIsolation(&calculationResult, breakdown, bindingIsolationUpperSizes, bindingIsolationLowerSizes, nodeEnableReclaim)
+	pa.reserveFailedRegionNumas(&calculationResult, breakdown, failures.bindingNumas)
+
+	// capacity of failed regions on non-binding numas is kept by share and isolation pools instead of reclaim pool
+	nonBindingNodeEnableReclaim := nodeEnableReclaim && !failures.nonBinding
+
+	// share and isolation pools are expanded to keep all slack if it's never donated to reclaim pool
+	nonBindingEnableReclaim := nonBindingNodeEnableReclaim && !pa.assemblerConf.DisableNonBindingReclaim
+	pinnedNumas := pa.assemblePinnedSharePools(&calculationResult, breakdown, pinnedSharePoolSizes, nonBindingEnableReclaim)
+	nonBindingNumas := pa.nonBindingNumas.Difference(pinnedNumas)
+
+	// excluded numas still host share and isolation pools, but never donate to reclaim
+	excludedReclaimNumas := machine.NewCPUSet(pa.assemblerConf.ExcludedReclaimNumas...)
+	nonBindingReclaimNumas := nonBindingNumas.Difference(excludedReclaimNumas)
+
+	// reserved for reclaim handed off to non-binding numas is carved out of share and isolation pools
+	handedOffReservedForReclaim := pa.handOffReservedForReclaim(reservedForReclaimDeficit, nonBindingReclaimNumas)
+	shareAndIsolatedPoolAvailable := getNumasAvailableResource(pa.availability, nonBindingNumas) - handedOffReservedForReclaim
 	shareAndIsolatePoolSizes := general.MergeMapInt(sharePoolSizes, isolationUpperSizes)
-	if shares+isolationUppers > shareAndIsolatedPoolAvailable {
-		shareAndIsolatePoolSizes = general.MergeMapInt(sharePoolSizes, isolationLowerSizes)
+	if pa.updateIsolationContention(shares+isolationUppers, shareAndIsolatedPoolAvailable) {
+		shareAndIsolatePoolSizes = general.MergeMapInt(sharePoolSizes, pa.getContendedIsolationSizes(isolationUpperSizes, isolationLowerSizes))
 	}
-	boundUpper := regulatePoolSizes(shareAndIsolatePoolSizes, shareAndIsolatedPoolAvailable, nodeEnableReclaim)
+	applyPoolMinSizes(shareAndIsolatePoolSizes, pa.assemblerConf.SharePoolMinSizes, shareAndIsolatedPoolAvailable)
+	pa.detectNegativeSlack(&calculationResult, shareAndIsolatedPoolAvailable, general.SumUpMapValues(shareAndIsolatePoolSizes))
+	shareAndIsolatePoolSizes, boundUpper := regulatePoolSizesByGroups(shareAndIsolatePoolSizes, shareAndIsolatedPoolAvailable, nonBindingEnableReclaim,
+		pa.getRegulationPriorities(isolationUpperSizes), pa.getRegulationUtilizations(shareAndIsolatePoolSizes, shareAndIsolatedPoolAvailable),
+		pa.assemblerConf.RegionGroups)
 
-	klog.InfoS("pool sizes", "share size", sharePoolSizes,
+	pa.logger.InfoS("[qosaware-cpu] pool sizes", "share size", sharePoolSizes,
 		"isolate upper-size", isolationUpperSizes, "isolate lower-size", isolationLowerSizes,
 		"shareAndIsolatePoolSizes", shareAndIsolatePoolSizes,
 		"shareAndIsolatedPoolAvailable", shareAndIsolatedPoolAvailable)
@@ -151,24 +487,440 @@ func (pa *ProvisionAssemblerCommon) AssembleProvision() (types.InternalCPUCalcul
 	}
 
 	var reclaimPoolSizeOfNonBindingNumas int
+	nonBindingBreakdown := ReclaimBreakdownEntry{
+		Available:          shareAndIsolatedPoolAvailable,
+		ReservedForReclaim: pa.getNumasReservedForReclaim(nonBindingReclaimNumas),
+		HandedOff:          handedOffReservedForReclaim,
+	}
+	for poolName, poolSize := range shareAndIsolatePoolSizes {
+		if _, ok := sharePoolSizes[poolName]; ok {
+			nonBindingBreakdown.SharePools += poolSize
+		} else {
+			nonBindingBreakdown.IsolationPools += poolSize
+		}
+	}
+	breakdown.setEntry(cpuadvisor.FakedNUMAID, nonBindingBreakdown)
 
 	// fill in reclaim pool entries of non binding numas
-	if nodeEnableReclaim {
-		// generate based on share pool requirement on non binding numas
-		reclaimPoolSizeOfNonBindingNumas = shareAndIsolatedPoolAvailable - general.SumUpMapValues(shareAndIsolatePoolSizes) + pa.getNumasReservedForReclaim(*pa.nonBindingNumas)
+	if nonBindingNodeEnableReclaim {
+		// generate based on share pool requirement on non binding numas, and slack can't exceed non-excluded numas;
+		// negative slack is clamped to zero, since it's detected and flagged before regulation already
+		slack := general.Min(shareAndIsolatedPoolAvailable-general.SumUpMapValues(shareAndIsolatePoolSizes),
+			getNumasAvailableResource(pa.availability, nonBindingReclaimNumas)-handedOffReservedForReclaim)
+		slack = general.Max(slack, 0)
+		reclaimPoolSizeOfNonBindingNumas = slack + pa.getNumasReservedForReclaim(nonBindingReclaimNumas)
 	} else {
 		// generate by reserved value on non binding numas
-		reclaimPoolSizeOfNonBindingNumas = pa.getNumasReservedForReclaim(*pa.nonBindingNumas)
+		reclaimPoolSizeOfNonBindingNumas = pa.getNumasReservedForReclaim(nonBindingReclaimNumas)
+	}
+	reclaimPoolSizeOfNonBindingNumas += handedOffReservedForReclaim
+	if nonBindingNodeEnableReclaim {
+		breakdown.adjust(cpuadvisor.FakedNUMAID, reclaimAdjustmentExcludedNumas, reclaimPoolSizeOfNonBindingNumas)
+	} else if failures.nonBinding {
+		breakdown.adjust(cpuadvisor.FakedNUMAID, reclaimAdjustmentFailedRegion, reclaimPoolSizeOfNonBindingNumas)
+	} else {
+		breakdown.adjust(cpuadvisor.FakedNUMAID, reclaimAdjustmentReclaimDisabled, reclaimPoolSizeOfNonBindingNumas)
+	}
+	if !pa.assemblerConf.DisableNonBindingReclaim {
+		calculationResult.SetPoolEntryExplicitly(pa.assemblerConf.ReclaimPoolName, cpuadvisor.FakedNUMAID, reclaimPoolSizeOfNonBindingNumas)
+	}
+
+	// remove reclaim pool entries of excluded binding numas
+	for _, numaID := range excludedReclaimNumas.ToSliceInt() {
+		calculationResult.DeletePoolEntry(pa.assemblerConf.ReclaimPoolName, numaID)
+	}
+	breakdown.reconcile(reclaimAdjustmentExcludedNumas, calculationResult)
+	pa.applyReclaimReservation(&calculationResult)
+	breakdown.reconcile(reclaimAdjustmentReclaimReservation, calculationResult)
+
+	pa.applyReclaimPressureFeedback(&calculationResult, shareAndIsolatePoolSizes, nodeEnableReclaim)
+	breakdown.reconcile(reclaimAdjustmentPressureFeedback, calculationResult)
+	pa.fillRegionContributions(&calculationResult, regionRequests)
+	if pa.assemblerConf.EnablePoolSizeDriftCheck {
+		pa.checkPoolSizeDrift(shareAndIsolatePoolSizes)
 	}
-	calculationResult.SetPoolEntry(state.PoolNameReclaim, cpuadvisor.FakedNUMAID, reclaimPoolSizeOfNonBindingNumas)
+	pa.capReclaimPoolByCeiling(&calculationResult)
+	breakdown.reconcile(reclaimAdjustmentCeiling, calculationResult)
+	pa.rebalanceReclaimPoolForFairness(&calculationResult)
+	breakdown.reconcile(reclaimAdjustmentFairness, calculationResult)
+	pa.drainNumaReclaim(&calculationResult)
+	breakdown.reconcile(reclaimAdjustmentNumaDrain, calculationResult)
+	pa.limitReclaimPoolRampUp(&calculationResult)
+	breakdown.reconcile(reclaimAdjustmentRampUp, calculationResult)
+	pa.smoothReclaimPool(&calculationResult)
+	breakdown.reconcile(reclaimAdjustmentSmoothing, calculationResult)
+	pa.applyReclaimCriticalReservation(&calculationResult)
+	breakdown.reconcile(reclaimAdjustmentCriticalReservation, calculationResult)
+
+	for _, processor := range pa.postProcessors {
+		var err error
+		calculationResult, err = processor.Process(calculationResult)
+		if err != nil {
+			return types.InternalCPUCalculationResult{}, false, fmt.Errorf("post processor %v failed: %v", processor.Name(), err)
+		}
+	}
+	breakdown.reconcile(reclaimAdjustmentPostProcessors, calculationResult)
+
+	if err := pa.checkCapacity(calculationResult); err != nil {
+		return types.InternalCPUCalculationResult{}, false, err
+	}
+	pa.emitPoolLayout(calculationResult)
+	pa.reclaimBreakdown = breakdown
+	pa.socketReclaimView = pa.rollUpReclaimBySocket(calculationResult)
+	pa.headroomAttribution = headroomAttribution
+	pa.logger.InfoS("[qosaware-cpu] reclaim breakdown", "aggregate", breakdown.Aggregate().String())
 
 	return calculationResult, boundUpper, nil
 }
 
+// assembleBindingIsolation fills in pool entries of isolation regions with numa binding, and the reclaim
+// pool entries of their binding numas; upper sizes are used only if all of them fit into the numa.
+func (pa *ProvisionAssemblerCommon) assembleBindingIsolation(calculationResult *types.InternalCPUCalculationResult,
+	breakdown ReclaimBreakdown, upperSizes, lowerSizes map[int]map[string]int, nodeEnableReclaim bool) {
+	for numaID, uppers := range upperSizes {
+		available, _ := pa.availability.GetNumaAvailable(numaID)
+		reservedForReclaim := pa.getNumasReservedForReclaim(machine.NewCPUSet(numaID))
+
+		isolationPoolSizes := general.MergeMapInt(uppers, nil)
+		if general.SumUpMapValues(uppers) > available {
+			isolationPoolSizes = general.MergeMapInt(lowerSizes[numaID], nil)
+		}
+		// only shrink pools if exceeding available
+		isolationPoolSizes, _ = RegulatePoolSizes(isolationPoolSizes, available, true)
+
+		pa.logger.InfoS("[qosaware-cpu] binding isolation pool sizes", "numaID", numaID, "isolate upper-size", uppers,
+			"isolate lower-size", lowerSizes[numaID], "isolationPoolSizes", isolationPoolSizes, "available", available)
+
+		for poolName, poolSize := range isolationPoolSizes {
+			calculationResult.SetPoolEntry(poolName, numaID, poolSize)
+		}
+
+		breakdown.setEntry(numaID, ReclaimBreakdownEntry{Available: available, IsolationPools: general.SumUpMapValues(isolationPoolSizes),
+			ReservedForReclaim: reservedForReclaim})
+		reclaimed := reservedForReclaim
+		if nodeEnableReclaim {
+			reclaimed = available - general.SumUpMapValues(isolationPoolSizes) + reservedForReclaim
+		} else {
+			breakdown.adjust(numaID, reclaimAdjustmentReclaimDisabled, reclaimed)
+		}
+		calculationResult.SetPoolEntryExplicitly(pa.assemblerConf.ReclaimPoolName, numaID, reclaimed)
+	}
+}
+
+// fillRegionContributions fills in granted and trimmed sizes of regions according to
+// the pool entries of their pools, which must be called after pool entries are regulated
+func (pa *ProvisionAssemblerCommon) fillRegionContributions(calculationResult *types.InternalCPUCalculationResult,
+	regionRequests map[string]types.RegionContribution) {
+	for regionName, contribution := range regionRequests {
+		contribution.GrantedSize = 0
+		for _, poolSize := range calculationResult.PoolEntries[contribution.PoolName] {
+			contribution.GrantedSize += poolSize
+		}
+		contribution.TrimmedSize = contribution.RequestedSize - contribution.GrantedSize
+		calculationResult.RegionContributions[regionName] = contribution
+	}
+}
+
+func (pa *ProvisionAssemblerCommon) Reset() {
+	pa.mutex.Lock()
+	defer pa.mutex.Unlock()
+
+	pa.reservePoolState.reset()
+	pa.reclaimPoolState.reset()
+	pa.sharePoolState.reset()
+	pa.regionState.reset()
+	pa.lastPoolLayoutSeries = make(map[poolLayoutSeries]struct{})
+	pa.isolationContended = false
+}
+
+// resolveRegionProvision returns the cached provision for regions unchanged in partial assembling,
+// and refreshes the cache with the latest provision for others
+func (pa *ProvisionAssemblerCommon) resolveRegionProvision(r region.QoSRegion, changedRegions sets.String) (types.ControlKnob, error) {
+	if changedRegions != nil && !changedRegions.Has(r.Name()) {
+		if controlKnob, ok := pa.regionState.provisions[r.Name()]; ok {
+			return controlKnob, nil
+		}
+	}
+
+	controlKnob, err := pa.getRegionProvision(r)
+	if err != nil {
+		return nil, err
+	}
+	pa.regionState.provisions[r.Name()] = controlKnob.Clone()
+	pa.checkRegionProvisionStaleness(r, controlKnob)
+	return controlKnob, nil
+}
+
+// deferSharePoolGrowth returns the size to provision for the share pool; growth is deferred until
+// the elevated requirement persists for SharePoolGrowthCooldownCycles, while shrinking is immediate
+func (pa *ProvisionAssemblerCommon) deferSharePoolGrowth(poolName string, requirement int) int {
+	cooldownCycles := pa.assemblerConf.SharePoolGrowthCooldownCycles
+	if cooldownCycles <= 0 {
+		return requirement
+	}
+
+	growth, ok := pa.sharePoolState.growths[poolName]
+	if !ok || requirement <= growth.honoredSize {
+		pa.sharePoolState.growths[poolName] = &sharePoolGrowth{honoredSize: requirement}
+		return requirement
+	}
+
+	if growth.elevatedCycles == 0 {
+		growth.elevatedSince = pa.clock.Now()
+	}
+	growth.elevatedCycles++
+	if growth.elevatedCycles > cooldownCycles {
+		pa.logger.InfoS("[qosaware-cpu] share pool grows after cooldown", "pool", poolName, "from", growth.honoredSize,
+			"to", requirement, "elevatedSince", growth.elevatedSince)
+		pa.sharePoolState.growths[poolName] = &sharePoolGrowth{honoredSize: requirement}
+		return requirement
+	}
+
+	pa.logger.InfoS("[qosaware-cpu] share pool growth deferred in cooldown", "pool", poolName, "size", growth.honoredSize,
+		"requirement", requirement, "elevatedCycles", growth.elevatedCycles)
+	return growth.honoredSize
+}
+
+// getRegionProvision returns provision of the region; share and isolation regions still in warm-up
+// window use a conservative default size instead, since their provision may be built on too few samples.
+// dedicated regions are not affected, as a default size may leave more resource to reclaim than expected.
+func (pa *ProvisionAssemblerCommon) getRegionProvision(r region.QoSRegion) (types.ControlKnob, error) {
+	if pa.assemblerConf.RegionWarmUpWindow > 0 &&
+		(r.Type() == types.QoSRegionTypeShare || r.Type() == types.QoSRegionTypeIsolation) &&
+		pa.clock.Since(pa.regionState.firstSeen[r.Name()]) < pa.assemblerConf.RegionWarmUpWindow {
+		pa.logger.InfoS("[qosaware-cpu] region in warm-up window uses default size", "region", r.Name(),
+			"firstSeen", pa.regionState.firstSeen[r.Name()], "size", pa.assemblerConf.RegionWarmUpSize)
+
+		size := float64(pa.assemblerConf.RegionWarmUpSize)
+		return types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize:      {Value: size, Action: types.ControlKnobActionNone},
+			types.ControlKnobNonReclaimedCPUSizeUpper: {Value: size, Action: types.ControlKnobActionNone},
+			types.ControlKnobNonReclaimedCPUSizeLower: {Value: size, Action: types.ControlKnobActionNone},
+		}, nil
+	}
+	return r.GetProvision()
+}
+
+// getRegionControlKnobValue returns control knob value of the region in cores, and rejects non-finite values
+// produced by numeric bugs of the region, as well as implausible values to avoid sizing pools 1000x off with
+// knobs supplied in wrong units; the region is rejected as a whole since int() of NaN or Inf is garbage
+func (pa *ProvisionAssemblerCommon) getRegionControlKnobValue(r region.QoSRegion, controlKnob types.ControlKnob,
+	name types.ControlKnobName) (int, error) {
+	value, err := getControlKnobValue(r.Name(), controlKnob, name)
+	if err != nil {
+		return 0, err
+	}
+
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		pa.logger.Errorf("[qosaware-cpu] region %v control knob %v value %v is not finite", r.Name(), name, value)
+		_ = pa.emitter.StoreInt64(metricCPUProvisionNonFiniteControlKnob, 1, metrics.MetricTypeNameRaw,
+			metrics.MetricTag{Key: "region_name", Val: r.Name()},
+			metrics.MetricTag{Key: "control_knob", Val: string(name)})
+		return 0, fmt.Errorf("region %v control knob %v value %v is not finite", r.Name(), name, value)
+	}
+	if !isPlausibleControlKnobValue(value, pa.metaServer.NumCPUs) {
+		pa.logger.Errorf("[qosaware-cpu] region %v control knob %v value %v is implausible for node with %v cpus",
+			r.Name(), name, value, pa.metaServer.NumCPUs)
+		_ = pa.emitter.StoreInt64(metricCPUProvisionImplausibleControlKnob, 1, metrics.MetricTypeNameRaw,
+			metrics.MetricTag{Key: "region_name", Val: r.Name()},
+			metrics.MetricTag{Key: "control_knob", Val: string(name)})
+		return 0, fmt.Errorf("region %v control knob %v value %v out of range [0, %v] in cores",
+			r.Name(), name, value, pa.metaServer.NumCPUs)
+	}
+	return int(value), nil
+}
+
+// checkPoolSizeDrift compares the computed share and isolation pool sizes against those recorded
+// in metacache, and emits drift metrics for pools differing beyond threshold to catch desync bugs
+// between advisor and qrm; pools not recorded yet are skipped since they are newly created
+func (pa *ProvisionAssemblerCommon) checkPoolSizeDrift(poolSizes map[string]int) {
+	for poolName, poolSize := range poolSizes {
+		recordedSize, ok := pa.metaReader.GetPoolSize(poolName)
+		if !ok {
+			continue
+		}
+
+		drift := poolSize - recordedSize
+		if drift > pa.assemblerConf.PoolSizeDriftThreshold || -drift > pa.assemblerConf.PoolSizeDriftThreshold {
+			pa.logger.Warningf("[qosaware-cpu] pool %v size drifts from metacache: computed %v, recorded %v",
+				poolName, poolSize, recordedSize)
+			_ = pa.emitter.StoreInt64(metricCPUProvisionPoolSizeDrift, int64(drift), metrics.MetricTypeNameRaw,
+				metrics.MetricTag{Key: "pool_name", Val: poolName})
+		}
+	}
+}
+
+// capReclaimPoolByCeiling scales down all reclaim pool entries proportionally
+// if their sum exceeds the node-level reclaim ceiling
+func (pa *ProvisionAssemblerCommon) capReclaimPoolByCeiling(calculationResult *types.InternalCPUCalculationResult) {
+	ceiling := pa.assemblerConf.ReclaimCeiling
+	if ceiling <= 0 {
+		return
+	}
+
+	reclaimPoolSizes := calculationResult.PoolEntries[pa.assemblerConf.ReclaimPoolName]
+	total := 0
+	for _, size := range reclaimPoolSizes {
+		total += size
+	}
+
+	bound := total > ceiling
+	if bound {
+		pa.logger.Infof("[qosaware-cpu] cap reclaim pool by ceiling: total %v, ceiling %v", total, ceiling)
+		for numaID, size := range reclaimPoolSizes {
+			reclaimPoolSizes[numaID] = size * ceiling / total
+		}
+	}
+
+	_ = pa.emitter.StoreInt64(metricCPUProvisionReclaimCeiling, int64(ceiling), metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "bound", Val: strconv.FormatBool(bound)})
+}
+
+// limitReclaimPoolRampUp limits the growth of each reclaim pool entry compared with the last
+// assembling, while shrinking takes effect immediately; entries without history are not limited,
+// and entries are allowed to grow by at least one core, so that those shrunk to zero can regrow
+func (pa *ProvisionAssemblerCommon) limitReclaimPoolRampUp(calculationResult *types.InternalCPUCalculationResult) {
+	reclaimPoolSizes := calculationResult.PoolEntries[pa.assemblerConf.ReclaimPoolName]
+	step, ratio := pa.assemblerConf.ReclaimRampUpStep, pa.assemblerConf.ReclaimRampUpRatio
+
+	if step > 0 || ratio > 0 {
+		for numaID, size := range reclaimPoolSizes {
+			lastSize, ok := pa.reclaimPoolState.lastSizes[numaID]
+			if !ok {
+				continue
+			}
+
+			maxGrowth := general.Max(general.Max(step, int(math.Ceil(float64(lastSize)*ratio))), 1)
+			if size > lastSize+maxGrowth {
+				pa.logger.Infof("[qosaware-cpu] limit reclaim pool ramping up on numa %v: last %v, target %v, max growth %v",
+					numaID, lastSize, size, maxGrowth)
+				reclaimPoolSizes[numaID] = lastSize + maxGrowth
+			}
+		}
+	}
+
+	// entries not existing in this round are regarded as empty
+	lastReclaimPoolSizes := make(map[int]int)
+	for numaID := range pa.reclaimPoolState.lastSizes {
+		lastReclaimPoolSizes[numaID] = 0
+	}
+	for numaID, size := range reclaimPoolSizes {
+		lastReclaimPoolSizes[numaID] = size
+	}
+	pa.reclaimPoolState.lastSizes = lastReclaimPoolSizes
+}
+
+// checkCapacity makes sure the sum of all pool entries doesn't exceed node capacity.
+// pool entries with faked numa id are distributed among non-binding numas by cpu plugin,
+// so they are accounted together with the entries of binding numas at node level.
+func (pa *ProvisionAssemblerCommon) checkCapacity(calculationResult types.InternalCPUCalculationResult) error {
+	total := 0
+	for _, poolEntry := range calculationResult.PoolEntries {
+		for _, size := range poolEntry {
+			total += size
+		}
+	}
+
+	capacity := pa.metaServer.NumCPUs
+	if total <= capacity {
+		return nil
+	}
+
+	pa.logger.Errorf("[qosaware-cpu] sum of pool entries %v exceeds node capacity %v: %+v", total, capacity, calculationResult.PoolEntries)
+	_ = pa.emitter.StoreInt64(metricCPUProvisionCapacityOvercommit, int64(total-capacity), metrics.MetricTypeNameRaw)
+
+	if pa.assemblerConf.ErrorOnCapacityOvercommit {
+		return fmt.Errorf("sum of pool entries %v exceeds node capacity %v", total, capacity)
+	}
+	return nil
+}
+
+// regulateReservePoolSize caps reserve pool size to make sure that there always exists
+// a minimum working set of cpus left on node for the other pools; the clamped size is taken
+// off numas with the largest reserve pool first, and recorded to be given back to numa available resource
+func (pa *ProvisionAssemblerCommon) regulateReservePoolSize(reservePoolSize int) int {
+	pa.adjustments.reservePoolClamped = make(map[int]int)
+
+	maxReservePoolSize := general.Max(pa.metaServer.NumCPUs-types.MinShareCPURequirement, 0)
+	if reservePoolSize <= maxReservePoolSize {
+		return reservePoolSize
+	}
+
+	pa.logger.Warningf("[qosaware-cpu] reserve pool size %v exceeds max %v, clamp it", reservePoolSize, maxReservePoolSize)
+	_ = pa.emitter.StoreInt64(metricCPUProvisionReservePoolConfigured, int64(reservePoolSize), metrics.MetricTypeNameRaw)
+	_ = pa.emitter.StoreInt64(metricCPUProvisionReservePoolClamped, int64(maxReservePoolSize), metrics.MetricTypeNameRaw)
+
+	numaSizes := pa.getEffectiveReservePoolSizes()
+	numaIDs := make([]int, 0, len(numaSizes))
+	for numaID := range numaSizes {
+		numaIDs = append(numaIDs, numaID)
+	}
+	sort.Ints(numaIDs)
+	for clamped := reservePoolSize - maxReservePoolSize; clamped > 0; clamped-- {
+		largest := -1
+		for _, numaID := range numaIDs {
+			if numaSizes[numaID] > 0 && (largest < 0 || numaSizes[numaID] > numaSizes[largest]) {
+				largest = numaID
+			}
+		}
+		if largest < 0 {
+			break
+		}
+		numaSizes[largest]--
+		pa.adjustments.reservePoolClamped[largest]++
+	}
+
+	return maxReservePoolSize
+}
+
+// getEffectiveReservePoolSizes returns reserve pool size on each numa before clamping, which is the limited
+// one if recorded, or the one in metacache otherwise
+func (pa *ProvisionAssemblerCommon) getEffectiveReservePoolSizes() map[int]int {
+	sizes := make(map[int]int)
+	if len(pa.reservePoolState.lastSizes) > 0 {
+		for numaID, size := range pa.reservePoolState.lastSizes {
+			sizes[numaID] = size
+		}
+		return sizes
+	}
+
+	reservePoolInfo, ok := pa.metaReader.GetPoolInfo(state.PoolNameReserve)
+	if !ok || reservePoolInfo == nil {
+		return sizes
+	}
+	for numaID, cpuset := range reservePoolInfo.TopologyAwareAssignments {
+		sizes[numaID] = cpuset.Size()
+	}
+	return sizes
+}
+
+// checkReservedForReclaimCoverage cross-checks numas of reserved for reclaim against numas of node, and emits
+// metrics counting orphaned entries (numas not existing) and missing entries (numas regarded as zero reserved)
+func (pa *ProvisionAssemblerCommon) checkReservedForReclaimCoverage() {
+	nodeNumas := pa.metaServer.CPUDetails.NUMANodes()
+
+	reservedNumas := machine.NewCPUSet()
+	for numaID := range pa.resolvedReservedForReclaim {
+		reservedNumas.Add(numaID)
+	}
+
+	orphaned := reservedNumas.Difference(nodeNumas)
+	missing := nodeNumas.Difference(reservedNumas)
+	if orphaned.Size() > 0 || missing.Size() > 0 {
+		pa.logger.Warningf("[qosaware-cpu] reserved for reclaim drifts from node numas %v: orphaned %v, missing %v",
+			nodeNumas.String(), orphaned.String(), missing.String())
+	}
+
+	_ = pa.emitter.StoreInt64(metricCPUProvisionReservedForReclaimDrift, int64(orphaned.Size()), metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "kind", Val: "orphaned"})
+	_ = pa.emitter.StoreInt64(metricCPUProvisionReservedForReclaimDrift, int64(missing.Size()), metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "kind", Val: "missing"})
+}
+
 func (pa *ProvisionAssemblerCommon) getNumasReservedForReclaim(numas machine.CPUSet) int {
 	res := 0
 	for _, id := range numas.ToSliceInt() {
-		if v, ok := (*pa.reservedForReclaim)[id]; ok {
+		if v, ok := pa.resolvedReservedForReclaim[id]; ok {
 			res += v
 		}
 	}
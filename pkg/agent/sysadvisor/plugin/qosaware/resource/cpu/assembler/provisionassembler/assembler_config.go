@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu"
+)
+
+// AssemblerConfig consolidates settings of provision assembling, which is resolved once per assembly from
+// static and dynamic configurations, so that an assembly never observes configurations changing halfway
+type AssemblerConfig struct {
+	// EnableReclaim is whether reclaim is effectively enabled on node, i.e. it's enabled in dynamic
+	// configuration and the resolving time falls into none of the reclaim suppression windows
+	EnableReclaim bool
+	// ReclaimSuppressed is whether reclaim enabled in dynamic configuration is suppressed by time windows
+	ReclaimSuppressed bool
+
+	NodeName                      string
+	ReclaimRelativeRootCgroupPath string
+	// ReclaimPoolName is the name of reclaim pool entries written into provision results
+	ReclaimPoolName string
+
+	cpu.CPUProvisionAssemblerConfiguration
+}
+
+// ResolveAssemblerConfig resolves assembler settings from configurations at the given time, and returns
+// error if any of them is invalid
+func ResolveAssemblerConfig(conf *config.Configuration, now time.Time) (*AssemblerConfig, error) {
+	dynamicConf := conf.GetDynamicConfiguration()
+	reclaimSuppressed := dynamicConf.EnableReclaim && dynamicConf.ReclaimSuppressed(now)
+
+	assemblerConf := &AssemblerConfig{
+		EnableReclaim:                 dynamicConf.EnableReclaim && !reclaimSuppressed,
+		ReclaimSuppressed:             reclaimSuppressed,
+		NodeName:                      conf.NodeName,
+		ReclaimRelativeRootCgroupPath: conf.ReclaimRelativeRootCgroupPath,
+		ReclaimPoolName:               conf.ReclaimPoolName,
+	}
+	if assemblerConf.ReclaimPoolName == "" {
+		assemblerConf.ReclaimPoolName = state.PoolNameReclaim
+	}
+	if conf.CPUProvisionAssemblerConfiguration != nil {
+		assemblerConf.CPUProvisionAssemblerConfiguration = *conf.CPUProvisionAssemblerConfiguration
+	}
+
+	if err := assemblerConf.validate(); err != nil {
+		return nil, err
+	}
+	return assemblerConf, nil
+}
+
+// refreshAssemblerConfig resolves assembler settings for the coming assembly, and keeps the last resolved
+// ones if failed
+func (pa *ProvisionAssemblerCommon) refreshAssemblerConfig() error {
+	assemblerConf, err := ResolveAssemblerConfig(pa.conf, pa.clock.Now())
+	if err != nil {
+		return fmt.Errorf("resolve assembler config failed: %v", err)
+	}
+	pa.assemblerConf = assemblerConf
+	return nil
+}
+
+func (c *AssemblerConfig) validate() error {
+	if c.ReclaimRampUpStep < 0 || c.ReclaimRampUpRatio < 0 {
+		return fmt.Errorf("reclaim ramp up step %v and ratio %v must not be negative", c.ReclaimRampUpStep, c.ReclaimRampUpRatio)
+	}
+	if c.ReclaimCeiling < 0 {
+		return fmt.Errorf("reclaim ceiling %v must not be negative", c.ReclaimCeiling)
+	}
+	if c.ReservePoolGrowthStep < 0 || c.PoolSizeDriftThreshold < 0 {
+		return fmt.Errorf("reserve pool growth step %v and pool size drift threshold %v must not be negative",
+			c.ReservePoolGrowthStep, c.PoolSizeDriftThreshold)
+	}
+	for _, numaID := range c.ExcludedReclaimNumas {
+		if numaID < 0 {
+			return fmt.Errorf("excluded reclaim numa %v must not be negative", numaID)
+		}
+	}
+	if c.ReservedForReclaimPercentage < 0 || c.ReservedForReclaimPercentage > 100 {
+		return fmt.Errorf("reserved for reclaim percentage %v must be within [0, 100]", c.ReservedForReclaimPercentage)
+	}
+	if c.ReclaimReservationSize < 0 {
+		return fmt.Errorf("reclaim reservation size %v must not be negative", c.ReclaimReservationSize)
+	}
+	if c.ReclaimCriticalReservationSize < 0 {
+		return fmt.Errorf("reclaim critical reservation size %v must not be negative", c.ReclaimCriticalReservationSize)
+	}
+	if c.IsolationContentionMargin < 0 {
+		return fmt.Errorf("isolation contention margin %v must not be negative", c.IsolationContentionMargin)
+	}
+	if c.PodScaledReservePoolBase < 0 || c.PodScaledReservePoolPerPod < 0 || c.PodScaledReservePoolMax < 0 {
+		return fmt.Errorf("base %v, per-pod increment %v and max %v of pod scaled reserve pool must not be negative",
+			c.PodScaledReservePoolBase, c.PodScaledReservePoolPerPod, c.PodScaledReservePoolMax)
+	}
+	if c.BootReservePoolSize < 0 || c.BootPhaseMinPods < 0 || c.BootPhaseMinUptime < 0 {
+		return fmt.Errorf("boot reserve pool size %v, min pods %v and min uptime %v of boot phase must not be negative",
+			c.BootReservePoolSize, c.BootPhaseMinPods, c.BootPhaseMinUptime)
+	}
+	if c.ReclaimFairnessMaxSkew < 0 {
+		return fmt.Errorf("reclaim fairness max skew %v must not be negative", c.ReclaimFairnessMaxSkew)
+	}
+	if c.ReclaimSmoothingTimeConstant < 0 {
+		return fmt.Errorf("reclaim smoothing time constant %v must not be negative", c.ReclaimSmoothingTimeConstant)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/adminqos/reclaimedresource"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestResolveAssemblerConfig(t *testing.T) {
+	t.Parallel()
+
+	// 2024-01-03 is a Wednesday
+	wednesday := time.Date(2024, 1, 3, 0, 0, 0, 0, time.Local)
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+	conf.ReclaimCeiling = 16
+	conf.SharePoolMinSizes = map[string]int{state.PoolNameShare: 2}
+
+	assemblerConf, err := ResolveAssemblerConfig(conf, wednesday.Add(20*time.Hour))
+	require.NoError(t, err)
+	assert.True(t, assemblerConf.EnableReclaim)
+	assert.False(t, assemblerConf.ReclaimSuppressed)
+	assert.Equal(t, 16, assemblerConf.ReclaimCeiling)
+	assert.Equal(t, map[string]int{state.PoolNameShare: 2}, assemblerConf.SharePoolMinSizes)
+
+	// reclaim is suppressed within time windows
+	window, err := reclaimedresource.ParseReclaimSuppressionWindow("mon-fri@09:00-18:00")
+	require.NoError(t, err)
+	conf.GetDynamicConfiguration().ReclaimSuppressionWindows = append(conf.GetDynamicConfiguration().ReclaimSuppressionWindows, window)
+	assemblerConf, err = ResolveAssemblerConfig(conf, wednesday.Add(10*time.Hour))
+	require.NoError(t, err)
+	assert.False(t, assemblerConf.EnableReclaim)
+	assert.True(t, assemblerConf.ReclaimSuppressed)
+
+	// invalid settings are rejected
+	conf.ReclaimCeiling = -1
+	_, err = ResolveAssemblerConfig(conf, wednesday)
+	assert.Error(t, err)
+}
+
+func TestAssemblerConfigReflectsDynamicConfig(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("8"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 16, 2, nil)
+
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(0, 1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 4},
+		},
+	}
+	regionMap := map[string]region.QoSRegion{share.Name(): share}
+	reservedForReclaim := map[int]int{0: 1, 1: 1}
+	numaAvailable := map[int]int{0: 6, 1: 6}
+	nonBindingNumas := machine.NewCPUSet(0, 1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, newFakeMetricEmitter()).(*ProvisionAssemblerCommon)
+
+	_, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+	assert.True(t, pa.assemblerConf.EnableReclaim)
+
+	// dynamic config changes between cycles are resolved in the next assembly
+	conf.GetDynamicConfiguration().EnableReclaim = false
+	assert.True(t, pa.assemblerConf.EnableReclaim)
+	_, _, err = pa.AssembleProvision()
+	require.NoError(t, err)
+	assert.False(t, pa.assemblerConf.EnableReclaim)
+
+	// assembly fails on invalid settings
+	conf.ReclaimRampUpStep = -1
+	_, _, err = pa.AssembleProvision()
+	assert.Error(t, err)
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"time"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+// availabilityAdjustments are per numa adjustments to the available resource provided by caller, which are
+// rebuilt in each assembling before pools are sized; all of them are map[numaID]size
+type availabilityAdjustments struct {
+	// reservedForReclaimDelta is how much the provided reserved for reclaim exceeds the resolved one
+	reservedForReclaimDelta map[int]int
+	// reservePoolHeldBack is growth of reserve pool held back by the growth step
+	reservePoolHeldBack map[int]int
+	// reserveComposedDelta is how much reserve pool in metacache exceeds the composed (or pod scaled) one
+	reserveComposedDelta map[int]int
+	// reservePoolClamped is reserve pool clamped to leave a minimum working set on node
+	reservePoolClamped map[int]int
+	// reclaimCriticalReservation and reclaimReservation are carved out of numas for reclaim
+	reclaimCriticalReservation map[int]int
+	reclaimReservation         map[int]int
+}
+
+func newAvailabilityAdjustments() *availabilityAdjustments {
+	return &availabilityAdjustments{
+		reservedForReclaimDelta:    make(map[int]int),
+		reservePoolHeldBack:        make(map[int]int),
+		reserveComposedDelta:       make(map[int]int),
+		reservePoolClamped:         make(map[int]int),
+		reclaimCriticalReservation: make(map[int]int),
+		reclaimReservation:         make(map[int]int),
+	}
+}
+
+// newAdjustedAvailability wraps the provided availability with the adjustments, innermost first:
+//  1. the delta of reserved for reclaim is given back, since provided availability excludes the provided one
+//  2. reserve pool held back, composed delta and clamped are given back, since provided availability excludes
+//     the whole reserve pool in metacache
+//  3. reclaim critical reservation is taken off, which is carved out of the availability adjusted by 1 and 2
+//  4. reclaim reservation is taken off, which is carved out of the availability adjusted by 1 to 3
+//
+// adjustments are read on each query, so the chain always reflects those of the current assembling.
+func newAdjustedAvailability(availability AvailabilityProvider, adjustments *availabilityAdjustments) AvailabilityProvider {
+	return &reclaimReservedAvailability{
+		AvailabilityProvider: &reclaimReservedAvailability{
+			AvailabilityProvider: &reserveAdjustedAvailability{
+				AvailabilityProvider: &reservedForReclaimAdjustedAvailability{
+					AvailabilityProvider: availability,
+					delta:                &adjustments.reservedForReclaimDelta,
+				},
+				heldBack:      &adjustments.reservePoolHeldBack,
+				composedDelta: &adjustments.reserveComposedDelta,
+				clamped:       &adjustments.reservePoolClamped,
+			},
+			reserved: &adjustments.reclaimCriticalReservation,
+		},
+		reserved: &adjustments.reclaimReservation,
+	}
+}
+
+// reservePoolState is reserve pool state kept across assembling
+type reservePoolState struct {
+	// lastSizes records effective reserve pool size on each numa of the last assembling
+	lastSizes map[int]int // map[numaID]reservePoolSize
+
+	// bootPhaseStartedAt is when boot phase is first checked, and bootPhaseEnded latches once boot phase ends;
+	// both are kept across resetting since node boots only once
+	bootPhaseStartedAt time.Time
+	bootPhaseEnded     bool
+}
+
+func newReservePoolState() *reservePoolState {
+	return &reservePoolState{lastSizes: make(map[int]int)}
+}
+
+func (s *reservePoolState) reset() {
+	s.lastSizes = make(map[int]int)
+}
+
+// reclaimPoolState is reclaim pool state kept across assembling to shape its changes
+type reclaimPoolState struct {
+	// lastSizes records reclaim pool sizes of the last assembling to limit ramping up
+	lastSizes map[int]int // map[numaID]reclaimPoolSize
+	// smoothedSizes records low-pass filtered reclaim pool sizes and smoothedAt is when they are filtered last time
+	smoothedSizes map[int]float64 // map[numaID]filteredSize
+	smoothedAt    time.Time
+
+	// consecutive cycles reclaim pressure stays high or low
+	highPressureCycles int
+	lowPressureCycles  int
+}
+
+func newReclaimPoolState() *reclaimPoolState {
+	return &reclaimPoolState{
+		lastSizes:     make(map[int]int),
+		smoothedSizes: make(map[int]float64),
+	}
+}
+
+func (s *reclaimPoolState) reset() {
+	*s = *newReclaimPoolState()
+}
+
+// sharePoolState is share pool state kept across assembling, all of which are keyed by pool name
+type sharePoolState struct {
+	// growths tracks honored requirement and elevation of each share pool to defer growth
+	growths map[string]*sharePoolGrowth
+	// history records recent requirements of each share pool to forecast
+	history map[string][]int
+	// lastSizes records rate limited size of each share pool in the last assembling
+	lastSizes map[string]int
+}
+
+// sharePoolGrowth records the last honored requirement of a share pool, and when and how long
+// the requirement has been elevated above it
+type sharePoolGrowth struct {
+	honoredSize    int
+	elevatedSince  time.Time
+	elevatedCycles int
+}
+
+func newSharePoolState() *sharePoolState {
+	return &sharePoolState{
+		growths:   make(map[string]*sharePoolGrowth),
+		history:   make(map[string][]int),
+		lastSizes: make(map[string]int),
+	}
+}
+
+func (s *sharePoolState) reset() {
+	*s = *newSharePoolState()
+}
+
+// gc cleans up state of share pools already gone
+func (s *sharePoolState) gc(exists func(poolName string) bool) {
+	for poolName := range s.growths {
+		if !exists(poolName) {
+			delete(s.growths, poolName)
+		}
+	}
+	for poolName := range s.history {
+		if !exists(poolName) {
+			delete(s.history, poolName)
+		}
+	}
+	for poolName := range s.lastSizes {
+		if !exists(poolName) {
+			delete(s.lastSizes, poolName)
+		}
+	}
+}
+
+// regionState is region state kept across assembling, all of which are keyed by region name
+type regionState struct {
+	// firstSeen records the time each region is first seen by assembler to decide warm-up
+	firstSeen map[string]time.Time
+	// provisions caches provision of each region used by the last assembling,
+	// to be reused for unchanged regions in partial assembling
+	provisions map[string]types.ControlKnob
+	// provisionChanges records the last provision of each region and when it is changed to detect staleness
+	provisionChanges map[string]*regionProvisionChange
+	// reservedForReclaim records reserved for reclaim overrides of dedicated regions, kept across resetting
+	reservedForReclaim map[string]int
+}
+
+func newRegionState() *regionState {
+	return &regionState{
+		firstSeen:          make(map[string]time.Time),
+		provisions:         make(map[string]types.ControlKnob),
+		provisionChanges:   make(map[string]*regionProvisionChange),
+		reservedForReclaim: make(map[string]int),
+	}
+}
+
+func (s *regionState) reset() {
+	s.firstSeen = make(map[string]time.Time)
+	s.provisions = make(map[string]types.ControlKnob)
+	s.provisionChanges = make(map[string]*regionProvisionChange)
+}
+
+// updateFirstSeen records first seen time for new regions
+func (s *regionState) updateFirstSeen(regionMap map[string]region.QoSRegion, now time.Time) {
+	for regionName := range regionMap {
+		if _, ok := s.firstSeen[regionName]; !ok {
+			s.firstSeen[regionName] = now
+		}
+	}
+}
+
+// gc cleans up state of regions already gone
+func (s *regionState) gc(regionMap map[string]region.QoSRegion) {
+	for regionName := range s.firstSeen {
+		if _, ok := regionMap[regionName]; !ok {
+			delete(s.firstSeen, regionName)
+		}
+	}
+	for regionName := range s.provisions {
+		if _, ok := regionMap[regionName]; !ok {
+			delete(s.provisions, regionName)
+		}
+	}
+	for regionName := range s.provisionChanges {
+		if _, ok := regionMap[regionName]; !ok {
+			delete(s.provisionChanges, regionName)
+		}
+	}
+	for regionName := range s.reservedForReclaim {
+		if _, ok := regionMap[regionName]; !ok {
+			delete(s.reservedForReclaim, regionName)
+		}
+	}
+}
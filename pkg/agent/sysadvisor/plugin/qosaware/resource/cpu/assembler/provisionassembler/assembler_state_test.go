@@ -0,0 +1,143 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+func TestAdjustedAvailability(t *testing.T) {
+	t.Parallel()
+
+	numaAvailable := map[int]int{0: 10, 1: 10}
+	adjustments := newAvailabilityAdjustments()
+	availability := newAdjustedAvailability(NewMapAvailabilityProvider(&numaAvailable), adjustments)
+
+	available, ok := availability.GetNumaAvailable(0)
+	assert.True(t, ok)
+	assert.Equal(t, 10, available)
+
+	// adjustments are rebuilt in each assembling, and the chain always reads the current ones
+	adjustments.reservedForReclaimDelta = map[int]int{0: 1}
+	adjustments.reservePoolHeldBack = map[int]int{0: 2}
+	adjustments.reserveComposedDelta = map[int]int{0: -1}
+	adjustments.reservePoolClamped = map[int]int{0: 3, 1: 1}
+	available, ok = availability.GetNumaAvailable(0)
+	assert.True(t, ok)
+	assert.Equal(t, 15, available)
+
+	// reclaim critical reservation is carved out of the availability adjusted by reserved for reclaim and
+	// reserve pool, and reclaim reservation is carved out of what is left by the critical reservation
+	adjustments.reclaimCriticalReservation = map[int]int{0: 4}
+	available, _ = availability.GetNumaAvailable(0)
+	assert.Equal(t, 11, available)
+	adjustments.reclaimReservation = map[int]int{0: 11}
+	available, _ = availability.GetNumaAvailable(0)
+	assert.Equal(t, 0, available)
+
+	available, ok = availability.GetNumaAvailable(1)
+	assert.True(t, ok)
+	assert.Equal(t, 11, available)
+
+	// unknown numas are not adjusted
+	_, ok = availability.GetNumaAvailable(2)
+	assert.False(t, ok)
+}
+
+func TestAssemblerStateReset(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	reservePool := newReservePoolState()
+	reservePool.lastSizes[0] = 4
+	reservePool.bootPhaseStartedAt = now
+	reservePool.bootPhaseEnded = true
+	reservePool.reset()
+	assert.Empty(t, reservePool.lastSizes)
+	// node boots only once
+	assert.Equal(t, now, reservePool.bootPhaseStartedAt)
+	assert.True(t, reservePool.bootPhaseEnded)
+
+	reclaimPool := newReclaimPoolState()
+	reclaimPool.lastSizes[0] = 4
+	reclaimPool.smoothedSizes[0] = 4
+	reclaimPool.smoothedAt = now
+	reclaimPool.highPressureCycles = 2
+	reclaimPool.reset()
+	assert.Equal(t, newReclaimPoolState(), reclaimPool)
+
+	sharePool := newSharePoolState()
+	sharePool.growths["share"] = &sharePoolGrowth{honoredSize: 4}
+	sharePool.history["share"] = []int{4}
+	sharePool.lastSizes["share"] = 4
+	sharePool.reset()
+	assert.Equal(t, newSharePoolState(), sharePool)
+
+	regions := newRegionState()
+	regions.firstSeen["share-r"] = now
+	regions.provisions["share-r"] = types.ControlKnob{}
+	regions.provisionChanges["share-r"] = &regionProvisionChange{}
+	regions.reservedForReclaim["dedicated-r"] = 2
+	regions.reset()
+	assert.Empty(t, regions.firstSeen)
+	assert.Empty(t, regions.provisions)
+	assert.Empty(t, regions.provisionChanges)
+	// overrides of dedicated regions are kept
+	assert.Equal(t, map[string]int{"dedicated-r": 2}, regions.reservedForReclaim)
+}
+
+func TestAssemblerStateGC(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+
+	sharePool := newSharePoolState()
+	for _, poolName := range []string{"share", "share-gone"} {
+		sharePool.growths[poolName] = &sharePoolGrowth{honoredSize: 4}
+		sharePool.history[poolName] = []int{4}
+		sharePool.lastSizes[poolName] = 4
+	}
+	sharePool.gc(func(poolName string) bool { return poolName == "share" })
+	assert.Equal(t, map[string]*sharePoolGrowth{"share": {honoredSize: 4}}, sharePool.growths)
+	assert.Equal(t, map[string][]int{"share": {4}}, sharePool.history)
+	assert.Equal(t, map[string]int{"share": 4}, sharePool.lastSizes)
+
+	regions := newRegionState()
+	for _, regionName := range []string{"share-r", "share-r-gone"} {
+		regions.firstSeen[regionName] = now.Add(-time.Minute)
+		regions.provisions[regionName] = types.ControlKnob{}
+		regions.provisionChanges[regionName] = &regionProvisionChange{}
+		regions.reservedForReclaim[regionName] = 2
+	}
+	regionMap := map[string]region.QoSRegion{
+		"share-r":     &fakeRegion{name: "share-r"},
+		"share-r-new": &fakeRegion{name: "share-r-new"},
+	}
+	regions.updateFirstSeen(regionMap, now)
+	regions.gc(regionMap)
+	assert.Equal(t, map[string]time.Time{"share-r": now.Add(-time.Minute), "share-r-new": now}, regions.firstSeen)
+	assert.Equal(t, map[string]types.ControlKnob{"share-r": {}}, regions.provisions)
+	assert.Equal(t, map[string]*regionProvisionChange{"share-r": {}}, regions.provisionChanges)
+	assert.Equal(t, map[string]int{"share-r": 2}, regions.reservedForReclaim)
+}
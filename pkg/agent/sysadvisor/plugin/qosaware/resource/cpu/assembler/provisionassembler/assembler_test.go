@@ -17,11 +17,243 @@ limitations under the License.
 package provisionassembler
 
 import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	testingclock "k8s.io/utils/clock/testing"
+
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/metric"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/pod"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/spd"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	metricspool "github.com/kubewharf/katalyst-core/pkg/metrics/metrics-pool"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
 )
 
+// fakeMetricEmitter records int64 metrics by key for assertion
+type fakeMetricEmitter struct {
+	metrics.DummyMetrics
+
+	mutex  sync.Mutex
+	stored map[string][]fakeMetric
+}
+
+type fakeMetric struct {
+	val  int64
+	tags map[string]string
+}
+
+func newFakeMetricEmitter() *fakeMetricEmitter {
+	return &fakeMetricEmitter{stored: make(map[string][]fakeMetric)}
+}
+
+func (f *fakeMetricEmitter) StoreInt64(key string, val int64, _ metrics.MetricTypeName, tags ...metrics.MetricTag) error {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	m := fakeMetric{val: val, tags: make(map[string]string)}
+	for _, tag := range tags {
+		m.tags[tag.Key] = tag.Val
+	}
+	f.stored[key] = append(f.stored[key], m)
+	return nil
+}
+
+func (f *fakeMetricEmitter) get(key string) []int64 {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var res []int64
+	for _, m := range f.stored[key] {
+		res = append(res, m.val)
+	}
+	return res
+}
+
+func (f *fakeMetricEmitter) getTags(key string) []map[string]string {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	var res []map[string]string
+	for _, m := range f.stored[key] {
+		res = append(res, m.tags)
+	}
+	return res
+}
+
+// fakeRegion implements the methods of region.QoSRegion used by provision assembler
+type fakeRegion struct {
+	region.QoSRegion
+
+	name          string
+	regionType    types.QoSRegionType
+	ownerPoolName string
+	bindingNumas  machine.CPUSet
+	pods          types.PodSet
+	controlKnob   types.ControlKnob
+	provisionErr  error
+}
+
+func (r *fakeRegion) Name() string                             { return r.name }
+func (r *fakeRegion) Type() types.QoSRegionType                { return r.regionType }
+func (r *fakeRegion) OwnerPoolName() string                    { return r.ownerPoolName }
+func (r *fakeRegion) GetBindingNumas() machine.CPUSet          { return r.bindingNumas.Clone() }
+func (r *fakeRegion) GetPods() types.PodSet                    { return r.pods.Clone() }
+func (r *fakeRegion) GetProvision() (types.ControlKnob, error) { return r.controlKnob, r.provisionErr }
+
+func newFakeDedicatedRegion(name string, numaID int, podUID string, requirement float64) *fakeRegion {
+	pods := make(types.PodSet)
+	pods.Insert(podUID, "c")
+	return &fakeRegion{
+		name:          name,
+		regionType:    types.QoSRegionTypeDedicatedNumaExclusive,
+		ownerPoolName: state.PoolNameDedicated,
+		bindingNumas:  machine.NewCPUSet(numaID),
+		pods:          pods,
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: requirement, Action: types.ControlKnobActionNone},
+		},
+	}
+}
+
+func generateTestConfiguration(t *testing.T) *config.Configuration {
+	conf, err := options.NewOptions().Config()
+	require.NoError(t, err)
+	require.NotNil(t, conf)
+
+	ckDir, err := ioutil.TempDir("", "checkpoint-provision-assembler")
+	require.NoError(t, err)
+	sfDir, err := ioutil.TempDir("", "statefile-provision-assembler")
+	require.NoError(t, err)
+	t.Cleanup(func() {
+		_ = os.RemoveAll(ckDir)
+		_ = os.RemoveAll(sfDir)
+	})
+
+	conf.GenericSysAdvisorConfiguration.StateFileDirectory = sfDir
+	conf.MetaServerConfiguration.CheckpointManagerDir = ckDir
+
+	return conf
+}
+
+func generateTestMetaServer(t *testing.T, cpuNum, numaNum int, pods []*v1.Pod) *metaserver.MetaServer {
+	cpuTopology, err := machine.GenerateDummyCPUTopology(cpuNum, 1, numaNum)
+	require.NoError(t, err)
+
+	metaServer := &metaserver.MetaServer{
+		MetaAgent: &agent.MetaAgent{
+			KatalystMachineInfo: &machine.KatalystMachineInfo{
+				CPUTopology: cpuTopology,
+			},
+			PodFetcher: &pod.PodFetcherStub{PodList: pods},
+		},
+	}
+	require.NoError(t, metaServer.SetServiceProfilingManager(spd.NewDummyServiceProfilingManager(nil)))
+	return metaServer
+}
+
+func makeTestPod(podUID string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "pod-" + podUID,
+			Namespace: "default",
+			UID:       k8stypes.UID(podUID),
+		},
+	}
+}
+
+func generateTestMetaCache(t *testing.T, conf *config.Configuration, pools map[string]*types.PoolInfo) *metacache.MetaCacheImp {
+	metaCache, err := metacache.NewMetaCacheImp(conf, metricspool.DummyMetricsEmitterPool{}, metric.NewFakeMetricsFetcher(metrics.DummyMetrics{}))
+	require.NoError(t, err)
+
+	for poolName, poolInfo := range pools {
+		require.NoError(t, metaCache.SetPoolInfo(poolName, poolInfo))
+	}
+	return metaCache
+}
+
+func TestAssembleProvisionReservePoolClamped(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+
+	// reserve pool configured with 7 cpus on a node with only 8 cpus
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0-3"),
+				1: machine.MustParse("4-6"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 8, 2, nil)
+	emitter := newFakeMetricEmitter()
+
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(0, 1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 2},
+		},
+	}
+	regionMap := map[string]region.QoSRegion{share.Name(): share}
+	reservedForReclaim := map[int]int{}
+	// available resource excludes the whole configured reserve pool
+	numaAvailable := map[int]int{0: 0, 1: 1}
+	nonBindingNumas := machine.NewCPUSet(0, 1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, emitter).(*ProvisionAssemblerCommon)
+	result, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+
+	reservePoolSize, ok := result.GetPoolEntry(state.PoolNameReserve, cpuadvisor.FakedNUMAID)
+	assert.True(t, ok)
+	assert.Equal(t, 8-types.MinShareCPURequirement, reservePoolSize)
+	assert.Equal(t, []int64{7}, emitter.get(metricCPUProvisionReservePoolConfigured))
+	assert.Equal(t, []int64{4}, emitter.get(metricCPUProvisionReservePoolClamped))
+
+	// clamped reserve pool is taken off the larger numa first and given back to numa available resource
+	assert.Equal(t, map[int]int{0: 2, 1: 1}, pa.adjustments.reservePoolClamped)
+	available, _ := pa.availability.GetNumaAvailable(0)
+	assert.Equal(t, 2, available)
+	available, _ = pa.availability.GetNumaAvailable(1)
+	assert.Equal(t, 2, available)
+
+	// the minimum working set left is shared by share and reclaim pools, and all pools fill up the node
+	sharePoolSize, ok := result.GetPoolEntry(state.PoolNameShare, cpuadvisor.FakedNUMAID)
+	assert.True(t, ok)
+	assert.Equal(t, 2, sharePoolSize)
+	reclaimPoolSize, ok := result.GetPoolEntry(state.PoolNameReclaim, cpuadvisor.FakedNUMAID)
+	assert.True(t, ok)
+	assert.Equal(t, 2, reclaimPoolSize)
+	assert.Equal(t, 8, reservePoolSize+sharePoolSize+reclaimPoolSize)
+}
+
 func TestRegulatePoolSizes(t *testing.T) {
 	t.Parallel()
 
@@ -30,6 +262,7 @@ func TestRegulatePoolSizes(t *testing.T) {
 		available         int
 		enableReclaim     bool
 		poolSizes         map[string]int
+		poolMinSizes      map[string]int
 		expectedPoolSizes map[string]int
 	}{
 		{
@@ -81,12 +314,1551 @@ func TestRegulatePoolSizes(t *testing.T) {
 			poolSizes:         map[string]int{"share": 1, "batch": 2, "flink": 3},
 			expectedPoolSizes: map[string]int{"share": 2, "batch": 2, "flink": 2},
 		},
+		{
+			name:              "single pool with min size",
+			available:         12,
+			enableReclaim:     true,
+			poolSizes:         map[string]int{"share": 1, "batch": 2, "flink": 3},
+			poolMinSizes:      map[string]int{"share": 4, "offline": 4},
+			expectedPoolSizes: map[string]int{"share": 4, "batch": 2, "flink": 3},
+		},
+		{
+			name:              "pool min sizes exceed available",
+			available:         8,
+			enableReclaim:     true,
+			poolSizes:         map[string]int{"share": 1, "batch": 2},
+			poolMinSizes:      map[string]int{"share": 6, "batch": 10},
+			expectedPoolSizes: map[string]int{"share": 3, "batch": 5},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			applyPoolMinSizes(tt.poolSizes, tt.poolMinSizes, tt.available)
+			poolSizes, _ := RegulatePoolSizes(tt.poolSizes, tt.available, tt.enableReclaim)
+			assert.Equal(t, tt.expectedPoolSizes, poolSizes)
+		})
+	}
+}
+
+func TestAssembleProvisionTimeStamp(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("4"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 8, 2, nil)
+
+	regionMap := map[string]region.QoSRegion{}
+	reservedForReclaim := map[int]int{}
+	numaAvailable := map[int]int{0: 3, 1: 3}
+	nonBindingNumas := machine.NewCPUSet(0, 1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, metrics.DummyMetrics{}).(*ProvisionAssemblerCommon)
+
+	now := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+	fakeClock := testingclock.NewFakePassiveClock(now)
+	pa.clock = fakeClock
+
+	result, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+	assert.Equal(t, now, result.TimeStamp)
+
+	fakeClock.SetTime(now.Add(time.Minute))
+	result, _, err = pa.AssembleProvision()
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(time.Minute), result.TimeStamp)
+}
+
+func TestAssembleProvisionDedicatedReclaimExhausted(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("8"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 16, 2, []*v1.Pod{makeTestPod("uid1")})
+	emitter := newFakeMetricEmitter()
+
+	// dedicated pod requires more than available on numa 0
+	r := newFakeDedicatedRegion("dedicated-r", 0, "uid1", 10)
+	regionMap := map[string]region.QoSRegion{r.Name(): r}
+	reservedForReclaim := map[int]int{0: 1, 1: 1}
+	numaAvailable := map[int]int{0: 6, 1: 6}
+	nonBindingNumas := machine.NewCPUSet(1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, emitter)
+	result, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+
+	reclaimPoolSize, ok := result.GetPoolEntry(state.PoolNameReclaim, 0)
+	assert.True(t, ok)
+	assert.Equal(t, 0, reclaimPoolSize)
+	assert.Equal(t, []int64{-3}, emitter.get(metricCPUProvisionDedicatedReclaimExhausted))
+	assert.Equal(t, []map[string]string{{"region_name": "dedicated-r", "numa_id": "0"}},
+		emitter.getTags(metricCPUProvisionDedicatedReclaimExhausted))
+}
+
+func TestAssembleProvisionDedicatedReclaimEntry(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                string
+		nodeEnableReclaim   bool
+		requirement         float64
+		reservedForReclaim  map[int]int
+		wantReclaimPoolSize int
+	}{
+		{
+			name:                "saturated numa with reclaim enabled",
+			nodeEnableReclaim:   true,
+			requirement:         6,
+			reservedForReclaim:  map[int]int{0: 0, 1: 0},
+			wantReclaimPoolSize: 0,
+		},
+		{
+			name:                "no reserved for reclaim with reclaim disabled",
+			nodeEnableReclaim:   false,
+			requirement:         2,
+			reservedForReclaim:  map[int]int{0: 0, 1: 0},
+			wantReclaimPoolSize: 0,
+		},
+		{
+			name:                "reserved for reclaim with reclaim disabled",
+			nodeEnableReclaim:   false,
+			requirement:         2,
+			reservedForReclaim:  map[int]int{0: 2, 1: 2},
+			wantReclaimPoolSize: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = tt.nodeEnableReclaim
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+				state.PoolNameReserve: {
+					PoolName: state.PoolNameReserve,
+					TopologyAwareAssignments: map[int]machine.CPUSet{
+						0: machine.MustParse("0"),
+						1: machine.MustParse("8"),
+					},
+				},
+			})
+			metaServer := generateTestMetaServer(t, 16, 2, []*v1.Pod{makeTestPod("uid1")})
+
+			r := newFakeDedicatedRegion("dedicated-r", 0, "uid1", tt.requirement)
+			regionMap := map[string]region.QoSRegion{r.Name(): r}
+			numaAvailable := map[int]int{0: 6, 1: 6}
+			nonBindingNumas := machine.NewCPUSet(1)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &tt.reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, metrics.DummyMetrics{})
+			result, _, err := pa.AssembleProvision()
+			require.NoError(t, err)
+
+			reclaimPoolSize, ok := result.GetPoolEntry(state.PoolNameReclaim, 0)
+			assert.True(t, ok)
+			assert.Equal(t, tt.wantReclaimPoolSize, reclaimPoolSize)
+		})
+	}
+}
+
+func TestAssembleProvisionReclaimRampUp(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+	conf.ReclaimRampUpStep = 4
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("20"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 40, 2, []*v1.Pod{makeTestPod("uid1")})
+
+	r := newFakeDedicatedRegion("dedicated-r", 0, "uid1", 16)
+	regionMap := map[string]region.QoSRegion{r.Name(): r}
+	reservedForReclaim := map[int]int{0: 0, 1: 0}
+	numaAvailable := map[int]int{0: 16, 1: 16}
+	nonBindingNumas := machine.NewCPUSet(1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, metrics.DummyMetrics{})
+
+	assembleReclaimPoolSize := func(requirement float64) int {
+		r.controlKnob[types.ControlKnobNonReclaimedCPUSize] = types.ControlKnobValue{Value: requirement}
+		result, _, err := pa.AssembleProvision()
+		require.NoError(t, err)
+		size, ok := result.GetPoolEntry(state.PoolNameReclaim, 0)
+		require.True(t, ok)
+		return size
+	}
+
+	assert.Equal(t, 0, assembleReclaimPoolSize(16))
+	// ramping up from 0 to 16 is limited by step
+	assert.Equal(t, 4, assembleReclaimPoolSize(0))
+	assert.Equal(t, 8, assembleReclaimPoolSize(0))
+	// shrinking is not limited
+	assert.Equal(t, 2, assembleReclaimPoolSize(14))
+
+	// history is cleared after reset
+	pa.Reset()
+	assert.Equal(t, 16, assembleReclaimPoolSize(0))
+}
+
+func TestAssembleProvisionReclaimRampUpRatioFromZero(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+	conf.ReclaimRampUpRatio = 1
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{})
+	metaServer := generateTestMetaServer(t, 40, 2, []*v1.Pod{makeTestPod("uid1")})
+
+	r := newFakeDedicatedRegion("dedicated-r", 0, "uid1", 16)
+	regionMap := map[string]region.QoSRegion{r.Name(): r}
+	reservedForReclaim := map[int]int{0: 0, 1: 0}
+	numaAvailable := map[int]int{0: 16, 1: 16}
+	nonBindingNumas := machine.NewCPUSet(1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, metrics.DummyMetrics{})
+
+	assembleReclaimPoolSize := func(requirement float64) int {
+		r.controlKnob[types.ControlKnobNonReclaimedCPUSize] = types.ControlKnobValue{Value: requirement}
+		result, _, err := pa.AssembleProvision()
+		require.NoError(t, err)
+		size, ok := result.GetPoolEntry(state.PoolNameReclaim, 0)
+		require.True(t, ok)
+		return size
+	}
+
+	// the entry exhausted to zero still regrows by ratio, from at least one core
+	assert.Equal(t, 0, assembleReclaimPoolSize(16))
+	assert.Equal(t, 1, assembleReclaimPoolSize(0))
+	assert.Equal(t, 2, assembleReclaimPoolSize(0))
+	assert.Equal(t, 4, assembleReclaimPoolSize(0))
+}
+
+func TestAssembleProvisionCapacityOvercommit(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		onError bool
+		wantErr bool
+	}{
+		{
+			name:    "only emit metrics",
+			onError: false,
+			wantErr: false,
+		},
+		{
+			name:    "return error",
+			onError: true,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+			conf.ErrorOnCapacityOvercommit = tt.onError
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+				state.PoolNameReserve: {
+					PoolName: state.PoolNameReserve,
+					TopologyAwareAssignments: map[int]machine.CPUSet{
+						0: machine.MustParse("0"),
+						1: machine.MustParse("4"),
+					},
+				},
+			})
+			metaServer := generateTestMetaServer(t, 8, 2, nil)
+			emitter := newFakeMetricEmitter()
+
+			// available resource is deliberately larger than node capacity
+			regionMap := map[string]region.QoSRegion{}
+			reservedForReclaim := map[int]int{}
+			numaAvailable := map[int]int{0: 8, 1: 8}
+			nonBindingNumas := machine.NewCPUSet(0, 1)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, emitter)
+			_, _, err := pa.AssembleProvision()
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, []int64{10}, emitter.get(metricCPUProvisionCapacityOvercommit))
+		})
+	}
+}
+
+func TestAssembleProvisionBindingIsolation(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("12"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 24, 2, nil)
+
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 6},
+		},
+	}
+	isolationBinding := &fakeRegion{
+		name:          "isolation-binding",
+		regionType:    types.QoSRegionTypeIsolation,
+		ownerPoolName: "isolation-binding",
+		bindingNumas:  machine.NewCPUSet(0),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSizeUpper: {Value: 12},
+			types.ControlKnobNonReclaimedCPUSizeLower: {Value: 4},
+		},
+	}
+	isolationNonBinding := &fakeRegion{
+		name:          "isolation-non-binding",
+		regionType:    types.QoSRegionTypeIsolation,
+		ownerPoolName: "isolation-non-binding",
+		bindingNumas:  machine.NewCPUSet(),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSizeUpper: {Value: 2},
+			types.ControlKnobNonReclaimedCPUSizeLower: {Value: 1},
+		},
+	}
+
+	regionMap := map[string]region.QoSRegion{
+		share.Name():               share,
+		isolationBinding.Name():    isolationBinding,
+		isolationNonBinding.Name(): isolationNonBinding,
+	}
+	reservedForReclaim := map[int]int{0: 1, 1: 1}
+	numaAvailable := map[int]int{0: 10, 1: 10}
+	nonBindingNumas := machine.NewCPUSet(1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, metrics.DummyMetrics{})
+	result, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]map[int]int{
+		state.PoolNameReserve: {cpuadvisor.FakedNUMAID: 2},
+		// shared pool budget is only based on non-binding numas
+		state.PoolNameShare:     {cpuadvisor.FakedNUMAID: 6},
+		"isolation-non-binding": {cpuadvisor.FakedNUMAID: 2},
+		// upper size of binding isolation exceeds numa available, so lower size is used
+		"isolation-binding": {0: 4},
+		state.PoolNameReclaim: {
+			0:                      10 - 4 + 1,
+			cpuadvisor.FakedNUMAID: 10 - 6 - 2 + 1,
+		},
+	}, result.PoolEntries)
+}
+
+// fakeAvailabilityProvider serves numa available as a live source updated out of assembler
+type fakeAvailabilityProvider struct {
+	mutex         sync.Mutex
+	numaAvailable map[int]int
+}
+
+func (p *fakeAvailabilityProvider) GetNumaAvailable(numaID int) (int, bool) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	available, ok := p.numaAvailable[numaID]
+	return available, ok
+}
+
+func (p *fakeAvailabilityProvider) set(numaAvailable map[int]int) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.numaAvailable = numaAvailable
+}
+
+func TestAssembleProvisionAvailabilityProvider(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("8"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 16, 2, nil)
+
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(0, 1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 4},
+		},
+	}
+	regionMap := map[string]region.QoSRegion{share.Name(): share}
+	reservedForReclaim := map[int]int{0: 1, 1: 1}
+	nonBindingNumas := machine.NewCPUSet(0, 1)
+	availability := &fakeAvailabilityProvider{numaAvailable: map[int]int{0: 6, 1: 6}}
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, availability, &nonBindingNumas,
+		metaCache, metaServer, metrics.DummyMetrics{})
+
+	result, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+	reclaimPoolSize, ok := result.GetPoolEntry(state.PoolNameReclaim, cpuadvisor.FakedNUMAID)
+	assert.True(t, ok)
+	assert.Equal(t, 6+6-4+2, reclaimPoolSize)
+
+	// availability changes are picked up without the caller touching assembler
+	availability.set(map[int]int{0: 3, 1: 3})
+	result, _, err = pa.AssembleProvision()
+	require.NoError(t, err)
+	reclaimPoolSize, ok = result.GetPoolEntry(state.PoolNameReclaim, cpuadvisor.FakedNUMAID)
+	assert.True(t, ok)
+	assert.Equal(t, 3+3-4+2, reclaimPoolSize)
+}
+
+func TestAssembleProvisionRegionWarmUp(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+	conf.RegionWarmUpWindow = time.Minute
+	conf.RegionWarmUpSize = 8
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("8"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 16, 2, nil)
+
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(0, 1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 2},
+		},
+	}
+	regionMap := map[string]region.QoSRegion{share.Name(): share}
+	reservedForReclaim := map[int]int{0: 1, 1: 1}
+	numaAvailable := map[int]int{0: 6, 1: 6}
+	nonBindingNumas := machine.NewCPUSet(0, 1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, metrics.DummyMetrics{}).(*ProvisionAssemblerCommon)
+	now := time.Now()
+	fakeClock := testingclock.NewFakePassiveClock(now)
+	pa.clock = fakeClock
+
+	// brand-new region uses the warm-up size
+	result, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+	sharePoolSize, ok := result.GetPoolEntry(state.PoolNameShare, cpuadvisor.FakedNUMAID)
+	assert.True(t, ok)
+	assert.Equal(t, 8, sharePoolSize)
+
+	// aged region uses its real provision
+	fakeClock.SetTime(now.Add(time.Minute))
+	result, _, err = pa.AssembleProvision()
+	require.NoError(t, err)
+	sharePoolSize, ok = result.GetPoolEntry(state.PoolNameShare, cpuadvisor.FakedNUMAID)
+	assert.True(t, ok)
+	assert.Equal(t, 2, sharePoolSize)
+}
+
+func TestAssembleProvisionRegionContributions(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("8"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 16, 2, nil)
+
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(0, 1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 8},
+		},
+	}
+	batch := &fakeRegion{
+		name:          "batch-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: "batch",
+		bindingNumas:  machine.NewCPUSet(0, 1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 4},
+		},
+	}
+	isolation := &fakeRegion{
+		name:          "isolation-r",
+		regionType:    types.QoSRegionTypeIsolation,
+		ownerPoolName: "isolation-r",
+		bindingNumas:  machine.NewCPUSet(),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSizeUpper: {Value: 6},
+			types.ControlKnobNonReclaimedCPUSizeLower: {Value: 4},
+		},
+	}
+	regionMap := map[string]region.QoSRegion{share.Name(): share, batch.Name(): batch, isolation.Name(): isolation}
+	reservedForReclaim := map[int]int{0: 1, 1: 1}
+	numaAvailable := map[int]int{0: 6, 1: 6}
+	nonBindingNumas := machine.NewCPUSet(0, 1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, metrics.DummyMetrics{})
+	result, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+
+	require.Len(t, result.RegionContributions, 3)
+	granted := 0
+	for regionName, contribution := range result.RegionContributions {
+		granted += contribution.GrantedSize
+		assert.Equal(t, contribution.RequestedSize-contribution.GrantedSize, contribution.TrimmedSize, regionName)
+		assert.True(t, contribution.TrimmedSize > 0, regionName)
+
+		poolSize, ok := result.GetPoolEntry(contribution.PoolName, cpuadvisor.FakedNUMAID)
+		assert.True(t, ok)
+		assert.Equal(t, poolSize, contribution.GrantedSize, regionName)
+	}
+	// all available resource is granted under contention
+	assert.Equal(t, 12, granted)
+
+	assert.Equal(t, 8, result.RegionContributions[share.Name()].RequestedSize)
+	assert.Equal(t, 4, result.RegionContributions[batch.Name()].RequestedSize)
+	// upper size is regarded as the requested size of isolation region
+	assert.Equal(t, 6, result.RegionContributions[isolation.Name()].RequestedSize)
+}
+
+func TestAssembleProvisionPoolSizeDrift(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+	conf.EnablePoolSizeDriftCheck = true
+	conf.PoolSizeDriftThreshold = 1
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("8"),
+			},
+		},
+		// share pool recorded in metacache drifts from the computed size intentionally
+		state.PoolNameShare: {
+			PoolName: state.PoolNameShare,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("1-2"),
+			},
+		},
+		// batch pool recorded in metacache is within threshold
+		"batch": {
+			PoolName: "batch",
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				1: machine.MustParse("9-11"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 16, 2, nil)
+	emitter := newFakeMetricEmitter()
+
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(0, 1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 6},
+		},
+	}
+	batch := &fakeRegion{
+		name:          "batch-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: "batch",
+		bindingNumas:  machine.NewCPUSet(0, 1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 4},
+		},
+	}
+	regionMap := map[string]region.QoSRegion{share.Name(): share, batch.Name(): batch}
+	reservedForReclaim := map[int]int{0: 1, 1: 1}
+	numaAvailable := map[int]int{0: 6, 1: 6}
+	nonBindingNumas := machine.NewCPUSet(0, 1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, emitter)
+	_, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+
+	assert.Equal(t, []int64{4}, emitter.get(metricCPUProvisionPoolSizeDrift))
+	assert.Equal(t, []map[string]string{{"pool_name": state.PoolNameShare}}, emitter.getTags(metricCPUProvisionPoolSizeDrift))
+}
+
+func TestAssembleProvisionReclaimCeiling(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+	conf.ReclaimCeiling = 6
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("8"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 16, 2, []*v1.Pod{makeTestPod("uid1")})
+	emitter := newFakeMetricEmitter()
+
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 2},
+		},
+	}
+	dedicated := newFakeDedicatedRegion("dedicated-r", 0, "uid1", 4)
+	regionMap := map[string]region.QoSRegion{share.Name(): share, dedicated.Name(): dedicated}
+	reservedForReclaim := map[int]int{0: 1, 1: 1}
+	numaAvailable := map[int]int{0: 6, 1: 6}
+	nonBindingNumas := machine.NewCPUSet(1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, emitter)
+	result, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+
+	// natural reclaim entries are 3 on numa 0 and 5 on non-binding numas, and they are trimmed proportionally
+	assert.Equal(t, map[int]int{0: 2, cpuadvisor.FakedNUMAID: 3}, result.PoolEntries[state.PoolNameReclaim])
+	assert.Equal(t, []int64{6}, emitter.get(metricCPUProvisionReclaimCeiling))
+	assert.Equal(t, []map[string]string{{"bound": "true"}}, emitter.getTags(metricCPUProvisionReclaimCeiling))
+}
+
+func TestAssembleProvisionDedicatedRegionEmptyPod(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("8"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 16, 2, nil)
+	emitter := newFakeMetricEmitter()
+
+	// pod popped from the region is empty, as if the pod set is mutated concurrently
+	r := newFakeDedicatedRegion("dedicated-r", 0, "", 4)
+	regionMap := map[string]region.QoSRegion{r.Name(): r}
+	reservedForReclaim := map[int]int{0: 1, 1: 1}
+	numaAvailable := map[int]int{0: 6, 1: 6}
+	nonBindingNumas := machine.NewCPUSet(1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, emitter)
+	result, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+
+	_, ok := result.GetPoolEntry(state.PoolNameReclaim, 0)
+	assert.False(t, ok)
+	_, ok = result.GetPoolEntry(state.PoolNameReclaim, cpuadvisor.FakedNUMAID)
+	assert.True(t, ok)
+	assert.Equal(t, []int64{1}, emitter.get(metricCPUProvisionDedicatedRegionEmptyPod))
+	assert.Equal(t, []map[string]string{{"region_name": "dedicated-r"}}, emitter.getTags(metricCPUProvisionDedicatedRegionEmptyPod))
+}
+
+func TestAssembleProvisionRegionStaleNuma(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("8"),
+			},
+		},
+	})
+	pod := makeTestPod("uid1")
+	metaServer := generateTestMetaServer(t, 16, 2, []*v1.Pod{pod})
+	emitter := newFakeMetricEmitter()
+
+	// region binds to numa 2, which has been removed from the node
+	r := newFakeDedicatedRegion("dedicated-r", 2, "uid1", 4)
+	regionMap := map[string]region.QoSRegion{r.Name(): r}
+	reservedForReclaim := map[int]int{0: 1, 1: 1, 2: 1}
+	numaAvailable := map[int]int{0: 6, 1: 6}
+	nonBindingNumas := machine.NewCPUSet(0, 1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, emitter)
+	result, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+
+	_, ok := result.GetPoolEntry(state.PoolNameReclaim, 2)
+	assert.False(t, ok)
+	reclaimSize, ok := result.GetPoolEntry(state.PoolNameReclaim, cpuadvisor.FakedNUMAID)
+	assert.True(t, ok)
+	assert.Equal(t, 14, reclaimSize)
+	assert.Equal(t, []int64{1}, emitter.get(metricCPUProvisionRegionStaleNuma))
+	assert.Equal(t, []map[string]string{{"region_name": "dedicated-r"}}, emitter.getTags(metricCPUProvisionRegionStaleNuma))
+}
+
+func TestAssembleProvisionAssemblyMetrics(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		provisionErr error
+		wantStatus   string
+	}{
+		{
+			name:       "assembling succeeds",
+			wantStatus: "success",
+		},
+		{
+			name:         "assembling fails",
+			provisionErr: fmt.Errorf("fake provision error"),
+			wantStatus:   "failure",
+		},
 	}
 
 	for _, tt := range tests {
+		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
-			regulatePoolSizes(tt.poolSizes, tt.available, tt.enableReclaim)
-			assert.Equal(t, tt.expectedPoolSizes, tt.poolSizes)
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.NodeName = "test-node"
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{})
+			metaServer := generateTestMetaServer(t, 16, 2, nil)
+			emitter := newFakeMetricEmitter()
+
+			share := &fakeRegion{
+				name:          "share-r",
+				regionType:    types.QoSRegionTypeShare,
+				ownerPoolName: state.PoolNameShare,
+				bindingNumas:  machine.NewCPUSet(0, 1),
+				controlKnob: types.ControlKnob{
+					types.ControlKnobNonReclaimedCPUSize: {Value: 4},
+				},
+				provisionErr: tt.provisionErr,
+			}
+			regionMap := map[string]region.QoSRegion{share.Name(): share}
+			reservedForReclaim := map[int]int{0: 1, 1: 1}
+			numaAvailable := map[int]int{0: 6, 1: 6}
+			nonBindingNumas := machine.NewCPUSet(0, 1)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, emitter).(*ProvisionAssemblerCommon)
+			now := time.Now()
+			pa.clock = testingclock.NewFakePassiveClock(now)
+
+			_, _, err := pa.AssembleProvision()
+			assert.Equal(t, tt.provisionErr != nil, err != nil)
+
+			wantTags := []map[string]string{{"node": "test-node", "status": tt.wantStatus}}
+			assert.Len(t, emitter.get(metricCPUProvisionAssemblyDuration), 1)
+			assert.Equal(t, wantTags, emitter.getTags(metricCPUProvisionAssemblyDuration))
+			assert.Equal(t, []int64{now.Unix()}, emitter.get(metricCPUProvisionAssemblyTimestamp))
+			assert.Equal(t, wantTags, emitter.getTags(metricCPUProvisionAssemblyTimestamp))
 		})
 	}
 }
+
+func TestAssembleProvisionExcludedReclaimNumas(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+	conf.ExcludedReclaimNumas = []int{1, 2}
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("8"),
+				2: machine.MustParse("16"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 24, 3, []*v1.Pod{makeTestPod("uid1")})
+
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(0, 1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 8},
+		},
+	}
+	dedicated := newFakeDedicatedRegion("dedicated-r", 2, "uid1", 2)
+	regionMap := map[string]region.QoSRegion{share.Name(): share, dedicated.Name(): dedicated}
+	reservedForReclaim := map[int]int{0: 1, 1: 1, 2: 1}
+	numaAvailable := map[int]int{0: 6, 1: 6, 2: 6}
+	nonBindingNumas := machine.NewCPUSet(0, 1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, metrics.DummyMetrics{})
+	result, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+
+	// excluded numa 1 still contributes to share pool availability
+	sharePoolSize, ok := result.GetPoolEntry(state.PoolNameShare, cpuadvisor.FakedNUMAID)
+	assert.True(t, ok)
+	assert.Equal(t, 8, sharePoolSize)
+
+	// but it contributes nothing to reclaim, and no entry is written for excluded binding numa 2
+	assert.Equal(t, map[int]int{cpuadvisor.FakedNUMAID: 6 + 6 - 8 + 1}, result.PoolEntries[state.PoolNameReclaim])
+}
+
+// evenPostProcessor rounds all pool sizes up to even numbers
+type evenPostProcessor struct {
+	err error
+}
+
+func (p *evenPostProcessor) Name() string {
+	return "even"
+}
+
+func (p *evenPostProcessor) Process(result types.InternalCPUCalculationResult) (types.InternalCPUCalculationResult, error) {
+	if p.err != nil {
+		return types.InternalCPUCalculationResult{}, p.err
+	}
+	for _, entries := range result.PoolEntries {
+		for numaID, size := range entries {
+			entries[numaID] = size + size%2
+		}
+	}
+	return result, nil
+}
+
+func TestAssembleProvisionPostProcessors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name            string
+		processors      []PostProcessor
+		wantPoolEntries map[string]map[int]int
+		wantErr         bool
+	}{
+		{
+			name:       "noop post processor by default",
+			processors: nil,
+			wantPoolEntries: map[string]map[int]int{
+				state.PoolNameReserve: {cpuadvisor.FakedNUMAID: 2},
+				state.PoolNameShare:   {cpuadvisor.FakedNUMAID: 5},
+				state.PoolNameReclaim: {cpuadvisor.FakedNUMAID: 9},
+			},
+		},
+		{
+			name:       "round pool sizes up to even numbers",
+			processors: []PostProcessor{NewPostProcessorNoop(), &evenPostProcessor{}},
+			wantPoolEntries: map[string]map[int]int{
+				state.PoolNameReserve: {cpuadvisor.FakedNUMAID: 2},
+				state.PoolNameShare:   {cpuadvisor.FakedNUMAID: 6},
+				state.PoolNameReclaim: {cpuadvisor.FakedNUMAID: 10},
+			},
+		},
+		{
+			name:       "post processor error aborts assembling",
+			processors: []PostProcessor{&evenPostProcessor{err: fmt.Errorf("fake error")}},
+			wantErr:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+				state.PoolNameReserve: {
+					PoolName: state.PoolNameReserve,
+					TopologyAwareAssignments: map[int]machine.CPUSet{
+						0: machine.MustParse("0"),
+						1: machine.MustParse("8"),
+					},
+				},
+			})
+			metaServer := generateTestMetaServer(t, 16, 2, nil)
+
+			share := &fakeRegion{
+				name:          "share-r",
+				regionType:    types.QoSRegionTypeShare,
+				ownerPoolName: state.PoolNameShare,
+				bindingNumas:  machine.NewCPUSet(0, 1),
+				controlKnob: types.ControlKnob{
+					types.ControlKnobNonReclaimedCPUSize: {Value: 5},
+				},
+			}
+			regionMap := map[string]region.QoSRegion{share.Name(): share}
+			reservedForReclaim := map[int]int{0: 1, 1: 1}
+			numaAvailable := map[int]int{0: 6, 1: 6}
+			nonBindingNumas := machine.NewCPUSet(0, 1)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, metrics.DummyMetrics{}).(*ProvisionAssemblerCommon)
+			if tt.processors != nil {
+				pa.SetPostProcessors(tt.processors...)
+			}
+
+			result, _, err := pa.AssembleProvision()
+			if tt.wantErr {
+				assert.ErrorContains(t, err, "post processor even failed")
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantPoolEntries, result.PoolEntries)
+		})
+	}
+}
+
+func TestAssembleProvisionMissingControlKnob(t *testing.T) {
+	t.Parallel()
+
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(1),
+		controlKnob:   types.ControlKnob{},
+	}
+	dedicated := newFakeDedicatedRegion("dedicated-r", 0, "uid1", 4)
+	dedicated.controlKnob = types.ControlKnob{}
+
+	tests := []struct {
+		name   string
+		region *fakeRegion
+	}{
+		{
+			name:   "share region misses non-reclaimed cpu size",
+			region: share,
+		},
+		{
+			name:   "dedicated region misses non-reclaimed cpu size",
+			region: dedicated,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{})
+			metaServer := generateTestMetaServer(t, 16, 2, []*v1.Pod{makeTestPod("uid1")})
+
+			regionMap := map[string]region.QoSRegion{tt.region.Name(): tt.region}
+			reservedForReclaim := map[int]int{0: 1, 1: 1}
+			numaAvailable := map[int]int{0: 6, 1: 6}
+			nonBindingNumas := machine.NewCPUSet(1)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, metrics.DummyMetrics{})
+			_, _, err := pa.AssembleProvision()
+			assert.ErrorContains(t, err, fmt.Sprintf("region %v misses control knob %v", tt.region.Name(), types.ControlKnobNonReclaimedCPUSize))
+		})
+	}
+}
+
+func TestAssembleProvisionImplausibleControlKnob(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{})
+	metaServer := generateTestMetaServer(t, 16, 2, []*v1.Pod{makeTestPod("uid1")})
+
+	// share pool size accidentally supplied in millicores
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 4000, Action: types.ControlKnobActionNone},
+		},
+	}
+	regionMap := map[string]region.QoSRegion{share.Name(): share}
+	reservedForReclaim := map[int]int{0: 1, 1: 1}
+	numaAvailable := map[int]int{0: 6, 1: 6}
+	nonBindingNumas := machine.NewCPUSet(1)
+
+	emitter := newFakeMetricEmitter()
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, emitter)
+	_, _, err := pa.AssembleProvision()
+	assert.ErrorContains(t, err, "out of range")
+	assert.Equal(t, []int64{1}, emitter.get(metricCPUProvisionImplausibleControlKnob))
+
+	// values in cores are accepted
+	share.controlKnob[types.ControlKnobNonReclaimedCPUSize] = types.ControlKnobValue{Value: 4, Action: types.ControlKnobActionNone}
+	result, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.PoolEntries[state.PoolNameShare][cpuadvisor.FakedNUMAID])
+}
+
+func TestAssembleProvisionNonFiniteControlKnob(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name  string
+		value float64
+	}{
+		{name: "nan", value: math.NaN()},
+		{name: "positive inf", value: math.Inf(1)},
+		{name: "negative inf", value: math.Inf(-1)},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{})
+			metaServer := generateTestMetaServer(t, 16, 2, nil)
+
+			share := &fakeRegion{
+				name:          "share-r",
+				regionType:    types.QoSRegionTypeShare,
+				ownerPoolName: state.PoolNameShare,
+				bindingNumas:  machine.NewCPUSet(1),
+				controlKnob: types.ControlKnob{
+					types.ControlKnobNonReclaimedCPUSize: {Value: tt.value, Action: types.ControlKnobActionNone},
+				},
+			}
+			regionMap := map[string]region.QoSRegion{share.Name(): share}
+			reservedForReclaim := map[int]int{0: 1, 1: 1}
+			numaAvailable := map[int]int{0: 6, 1: 6}
+			nonBindingNumas := machine.NewCPUSet(1)
+
+			emitter := newFakeMetricEmitter()
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, emitter)
+			_, _, err := pa.AssembleProvision()
+			assert.ErrorContains(t, err, "not finite")
+			assert.Equal(t, []int64{1}, emitter.get(metricCPUProvisionNonFiniteControlKnob))
+			assert.Equal(t, []map[string]string{{"region_name": "share-r", "control_knob": string(types.ControlKnobNonReclaimedCPUSize)}},
+				emitter.getTags(metricCPUProvisionNonFiniteControlKnob))
+			assert.Empty(t, emitter.get(metricCPUProvisionImplausibleControlKnob))
+		})
+	}
+}
+
+func TestAssembleProvisionPartial(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{})
+	metaServer := generateTestMetaServer(t, 16, 2, []*v1.Pod{makeTestPod("uid1")})
+
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 4},
+		},
+	}
+	dedicated := newFakeDedicatedRegion("dedicated-r", 0, "uid1", 4)
+	regionMap := map[string]region.QoSRegion{share.Name(): share, dedicated.Name(): dedicated}
+	reservedForReclaim := map[int]int{0: 1, 1: 1}
+	numaAvailable := map[int]int{0: 8, 1: 8}
+	nonBindingNumas := machine.NewCPUSet(1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, metrics.DummyMetrics{})
+	_, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+
+	// dedicated pod's usage shifts, and partial result matches the full one
+	dedicated.controlKnob[types.ControlKnobNonReclaimedCPUSize] = types.ControlKnobValue{Value: 2}
+	partial, _, err := pa.AssembleProvisionPartial([]string{dedicated.Name()})
+	require.NoError(t, err)
+	full, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+	assert.Equal(t, full.PoolEntries, partial.PoolEntries)
+	assert.Equal(t, 7, partial.PoolEntries[state.PoolNameReclaim][0])
+
+	// provision of unchanged regions is reused from cache
+	share.controlKnob[types.ControlKnobNonReclaimedCPUSize] = types.ControlKnobValue{Value: 6}
+	dedicated.controlKnob[types.ControlKnobNonReclaimedCPUSize] = types.ControlKnobValue{Value: 3}
+	partial, _, err = pa.AssembleProvisionPartial([]string{dedicated.Name()})
+	require.NoError(t, err)
+	full, _, err = pa.AssembleProvision()
+	require.NoError(t, err)
+	assert.Equal(t, full.PoolEntries[state.PoolNameReclaim][0], partial.PoolEntries[state.PoolNameReclaim][0])
+	assert.Equal(t, 4, partial.PoolEntries[state.PoolNameShare][cpuadvisor.FakedNUMAID])
+	assert.Equal(t, 6, full.PoolEntries[state.PoolNameShare][cpuadvisor.FakedNUMAID])
+
+	_, _, err = pa.AssembleProvisionPartial([]string{"non-existing"})
+	assert.Error(t, err)
+}
+
+func TestAssembleProvisionSharePoolGrowthCooldown(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+	conf.SharePoolGrowthCooldownCycles = 2
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{})
+	metaServer := generateTestMetaServer(t, 16, 2, nil)
+
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(0, 1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 4},
+		},
+	}
+	regionMap := map[string]region.QoSRegion{share.Name(): share}
+	reservedForReclaim := map[int]int{0: 1, 1: 1}
+	numaAvailable := map[int]int{0: 8, 1: 8}
+	nonBindingNumas := machine.NewCPUSet(0, 1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, metrics.DummyMetrics{})
+
+	// requirement of share region in each cycle, and the expected share pool size
+	cycles := []struct {
+		requirement int
+		want        int
+	}{
+		{requirement: 4, want: 4},
+		// one-cycle spike is ignored
+		{requirement: 10, want: 4},
+		{requirement: 4, want: 4},
+		// sustained rise is honored after cooldown
+		{requirement: 8, want: 4},
+		{requirement: 8, want: 4},
+		{requirement: 8, want: 8},
+		// shrink is immediate
+		{requirement: 2, want: 2},
+	}
+	for i, cycle := range cycles {
+		share.controlKnob[types.ControlKnobNonReclaimedCPUSize] = types.ControlKnobValue{Value: float64(cycle.requirement)}
+		result, _, err := pa.AssembleProvision()
+		require.NoError(t, err)
+		assert.Equal(t, cycle.want, result.PoolEntries[state.PoolNameShare][cpuadvisor.FakedNUMAID], "cycle %v", i)
+	}
+}
+
+func TestAssembleProvisionDisableNonBindingReclaim(t *testing.T) {
+	t.Parallel()
+
+	for _, disable := range []bool{false, true} {
+		conf := generateTestConfiguration(t)
+		conf.GetDynamicConfiguration().EnableReclaim = true
+		conf.DisableNonBindingReclaim = disable
+
+		metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+			state.PoolNameReserve: {
+				PoolName: state.PoolNameReserve,
+				TopologyAwareAssignments: map[int]machine.CPUSet{
+					0: machine.MustParse("0"),
+					1: machine.MustParse("8"),
+				},
+			},
+		})
+		metaServer := generateTestMetaServer(t, 16, 2, []*v1.Pod{makeTestPod("uid1")})
+
+		dedicated := newFakeDedicatedRegion("dedicated-r", 0, "uid1", 4)
+		share := &fakeRegion{
+			name:          "share-r",
+			regionType:    types.QoSRegionTypeShare,
+			ownerPoolName: state.PoolNameShare,
+			bindingNumas:  machine.NewCPUSet(1),
+			controlKnob: types.ControlKnob{
+				types.ControlKnobNonReclaimedCPUSize: {Value: 2},
+			},
+		}
+		regionMap := map[string]region.QoSRegion{dedicated.Name(): dedicated, share.Name(): share}
+		reservedForReclaim := map[int]int{0: 1, 1: 1}
+		numaAvailable := map[int]int{0: 6, 1: 6}
+		nonBindingNumas := machine.NewCPUSet(1)
+
+		pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+			metaCache, metaServer, metrics.DummyMetrics{})
+		result, _, err := pa.AssembleProvision()
+		require.NoError(t, err)
+
+		// reclaim pool entry of binding numa is always kept
+		reclaimPoolSize, ok := result.GetPoolEntry(state.PoolNameReclaim, 0)
+		assert.True(t, ok)
+		assert.Equal(t, 6-4+1, reclaimPoolSize)
+
+		nonBindingReclaimPoolSize, ok := result.GetPoolEntry(state.PoolNameReclaim, cpuadvisor.FakedNUMAID)
+		sharePoolSize, _ := result.GetPoolEntry(state.PoolNameShare, cpuadvisor.FakedNUMAID)
+		if disable {
+			// share pool keeps all slack of non-binding numas
+			assert.False(t, ok)
+			assert.Equal(t, 6, sharePoolSize)
+		} else {
+			assert.True(t, ok)
+			assert.Equal(t, 6-2+1, nonBindingReclaimPoolSize)
+			assert.Equal(t, 2, sharePoolSize)
+		}
+	}
+}
+
+func TestAssembleProvisionReservedForReclaimDrift(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("8"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 16, 2, nil)
+	emitter := newFakeMetricEmitter()
+
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(0, 1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 4},
+		},
+	}
+	regionMap := map[string]region.QoSRegion{share.Name(): share}
+	// numa 2 doesn't exist on node any more, and numa 1 is missing
+	reservedForReclaim := map[int]int{0: 1, 2: 1}
+	numaAvailable := map[int]int{0: 6, 1: 7}
+	nonBindingNumas := machine.NewCPUSet(0, 1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, emitter)
+	_, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+
+	assert.Equal(t, []int64{1, 1}, emitter.get(metricCPUProvisionReservedForReclaimDrift))
+	assert.Equal(t, []map[string]string{{"kind": "orphaned"}, {"kind": "missing"}},
+		emitter.getTags(metricCPUProvisionReservedForReclaimDrift))
+
+	// no drift once reserved for reclaim covers node numas exactly
+	reservedForReclaim = map[int]int{0: 1, 1: 1}
+	_, _, err = pa.AssembleProvision()
+	require.NoError(t, err)
+	assert.Equal(t, []int64{1, 1, 0, 0}, emitter.get(metricCPUProvisionReservedForReclaimDrift))
+}
+
+func TestAssembleProvisionNilEmitter(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+	// enable features emitting metrics as many as possible
+	conf.EnablePoolSizeDriftCheck = true
+	conf.ReclaimCeiling = 4
+	conf.ReservePoolGrowthStep = 1
+	conf.RegionProvisionStalenessThreshold = time.Nanosecond
+	conf.EnableReservedForReclaimHandOff = true
+	conf.EnableReclaimPressureFeedback = true
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("8"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 16, 2, []*v1.Pod{makeTestPod("uid1")})
+
+	dedicated := newFakeDedicatedRegion("dedicated-r", 0, "uid1", 10)
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 2},
+		},
+	}
+	regionMap := map[string]region.QoSRegion{dedicated.Name(): dedicated, share.Name(): share}
+	reservedForReclaim := map[int]int{0: 2, 1: 1, 2: 1}
+	numaAvailable := map[int]int{0: 6, 1: 6}
+	nonBindingNumas := machine.NewCPUSet(1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, nil)
+
+	assert.NotPanics(t, func() {
+		for i := 0; i < 2; i++ {
+			_, _, err := pa.AssembleProvision()
+			require.NoError(t, err)
+		}
+		_, _, err := pa.AssembleProvisionPartial([]string{share.Name()})
+		require.NoError(t, err)
+	})
+}
+
+func TestAssembleProvisionConcurrently(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("8"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 16, 2, []*v1.Pod{makeTestPod("uid1")})
+
+	dedicated := newFakeDedicatedRegion("dedicated-r", 0, "uid1", 6)
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 4},
+		},
+	}
+	regionMap := map[string]region.QoSRegion{dedicated.Name(): dedicated, share.Name(): share}
+	reservedForReclaim := map[int]int{0: 2, 1: 2}
+	numaAvailable := map[int]int{0: 7, 1: 7}
+	nonBindingNumas := machine.NewCPUSet(1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, newFakeMetricEmitter())
+
+	expected, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+
+	// concurrent full, partial assembling and resetting are serialized and produce the same result
+	const workers = 8
+	results := make([]types.InternalCPUCalculationResult, workers)
+	errs := make([]error, workers)
+	wg := sync.WaitGroup{}
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			switch i % 3 {
+			case 0:
+				results[i], _, errs[i] = pa.AssembleProvision()
+			case 1:
+				results[i], _, errs[i] = pa.AssembleProvisionPartial([]string{share.Name()})
+			default:
+				pa.Reset()
+				results[i], _, errs[i] = pa.AssembleProvision()
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	for i := 0; i < workers; i++ {
+		require.NoError(t, errs[i])
+		assert.Equal(t, expected.PoolEntries, results[i].PoolEntries, "worker %v", i)
+	}
+}
+
+func TestAssembleProvisionCustomReclaimPoolName(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+	conf.ReclaimPoolName = "reclaim-batch"
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("8"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 16, 2, []*v1.Pod{makeTestPod("uid1")})
+
+	dedicated := newFakeDedicatedRegion("dedicated-r", 0, "uid1", 4)
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 2},
+		},
+	}
+	regionMap := map[string]region.QoSRegion{dedicated.Name(): dedicated, share.Name(): share}
+	reservedForReclaim := map[int]int{0: 1, 1: 1}
+	numaAvailable := map[int]int{0: 6, 1: 6}
+	nonBindingNumas := machine.NewCPUSet(1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, metrics.DummyMetrics{})
+	result, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+
+	// reclaim entries of both binding and non-binding numas are written under the configured name
+	reclaimPoolSize, ok := result.GetPoolEntry("reclaim-batch", 0)
+	assert.True(t, ok)
+	assert.Equal(t, 6-4+1, reclaimPoolSize)
+
+	nonBindingReclaimPoolSize, ok := result.GetPoolEntry("reclaim-batch", cpuadvisor.FakedNUMAID)
+	assert.True(t, ok)
+	assert.Equal(t, 6-2+1, nonBindingReclaimPoolSize)
+
+	_, ok = result.PoolEntries[state.PoolNameReclaim]
+	assert.False(t, ok)
+}
+
+func TestAssembleProvisionWithCancelledContext(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{})
+	metaServer := generateTestMetaServer(t, 16, 2, nil)
+
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 4, Action: types.ControlKnobActionNone},
+		},
+	}
+	regionMap := map[string]region.QoSRegion{share.Name(): share}
+	reservedForReclaim := map[int]int{0: 1, 1: 1}
+	numaAvailable := map[int]int{0: 6, 1: 6}
+	nonBindingNumas := machine.NewCPUSet(1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, newFakeMetricEmitter())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, _, err := pa.AssembleProvisionWithContext(ctx)
+	assert.ErrorContains(t, err, "cancelled")
+
+	result, _, err := pa.AssembleProvisionWithContext(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.PoolEntries[state.PoolNameShare][-1])
+}
@@ -0,0 +1,45 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+// AvailabilityProvider provides available resource of each numa for assembling,
+// i.e. resource left after excluding reserve pool and reserved for reclaim.
+type AvailabilityProvider interface {
+	// GetNumaAvailable returns available resource of the given numa, and false if unknown
+	GetNumaAvailable(numaID int) (int, bool)
+}
+
+// mapAvailabilityProvider adapts a numa available map maintained by caller to AvailabilityProvider
+type mapAvailabilityProvider struct {
+	numaAvailable *map[int]int
+}
+
+var _ AvailabilityProvider = &mapAvailabilityProvider{}
+
+// NewMapAvailabilityProvider returns an AvailabilityProvider reading from the given map,
+// which is supposed to be kept up-to-date by the caller.
+func NewMapAvailabilityProvider(numaAvailable *map[int]int) AvailabilityProvider {
+	return &mapAvailabilityProvider{numaAvailable: numaAvailable}
+}
+
+func (p *mapAvailabilityProvider) GetNumaAvailable(numaID int) (int, bool) {
+	if p.numaAvailable == nil {
+		return 0, false
+	}
+	available, ok := (*p.numaAvailable)[numaID]
+	return available, ok
+}
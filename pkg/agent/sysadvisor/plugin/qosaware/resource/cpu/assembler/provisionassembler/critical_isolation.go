@@ -0,0 +1,68 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"math"
+
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+// criticalIsolationPriority is the regulation priority of critical isolation regions, higher than any share pool
+const criticalIsolationPriority = math.MaxInt32
+
+// getContendedIsolationSizes returns sizes of isolation regions under contention, i.e. lower sizes
+// for non-critical regions, while critical regions keep their upper sizes
+func (pa *ProvisionAssemblerCommon) getContendedIsolationSizes(upperSizes, lowerSizes map[string]int) map[string]int {
+	sizes := general.MergeMapInt(lowerSizes, nil)
+	for regionName, upper := range upperSizes {
+		if pa.assemblerConf.CriticalIsolationRegions.Has(regionName) {
+			sizes[regionName] = upper
+		}
+	}
+	return sizes
+}
+
+// getRegulationPriorities returns priorities of share and isolation pools for regulation, where critical
+// isolation regions are of the highest priority, so that shortfall is absorbed by the others first
+func (pa *ProvisionAssemblerCommon) getRegulationPriorities(isolationSizes map[string]int) map[string]int {
+	priorities := general.MergeMapInt(pa.assemblerConf.SharePoolPriorities, nil)
+	for regionName := range isolationSizes {
+		if pa.assemblerConf.CriticalIsolationRegions.Has(regionName) {
+			priorities[regionName] = criticalIsolationPriority
+		}
+	}
+	return priorities
+}
+
+// updateIsolationContention returns whether isolation regions on non-binding numas are under contention, i.e.
+// sized by lower sizes; with IsolationContentionMargin, the contention is entered only if the requirement exceeds
+// available by more than the margin, and left only if it drops below available by at least the margin
+func (pa *ProvisionAssemblerCommon) updateIsolationContention(requirement, available int) bool {
+	margin := pa.assemblerConf.IsolationContentionMargin
+	contended := requirement > available+margin
+	if pa.isolationContended {
+		contended = requirement > available-margin
+	}
+
+	if contended != pa.isolationContended {
+		pa.logger.InfoS("[qosaware-cpu] isolation contention changes", "contended", contended,
+			"requirement", requirement, "available", available, "margin", margin)
+	}
+	pa.isolationContended = contended
+	return contended
+}
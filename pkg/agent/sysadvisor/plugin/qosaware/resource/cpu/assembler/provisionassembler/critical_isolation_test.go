@@ -0,0 +1,222 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestAssembleProvisionCriticalIsolationRegions(t *testing.T) {
+	t.Parallel()
+
+	newIsolationRegion := func(name string) *fakeRegion {
+		return &fakeRegion{
+			name:          name,
+			regionType:    types.QoSRegionTypeIsolation,
+			ownerPoolName: name,
+			bindingNumas:  machine.NewCPUSet(),
+			controlKnob: types.ControlKnob{
+				types.ControlKnobNonReclaimedCPUSizeUpper: {Value: 6},
+				types.ControlKnobNonReclaimedCPUSizeLower: {Value: 2},
+			},
+		}
+	}
+
+	tests := []struct {
+		name             string
+		criticalRegions  []string
+		shareRequirement float64
+		wantPoolSizes    map[string]int
+		wantReclaimSize  int
+	}{
+		{
+			name:             "all isolation regions shrink to lower sizes under contention",
+			shareRequirement: 4,
+			wantPoolSizes:    map[string]int{state.PoolNameShare: 4, "isolation-critical": 2, "isolation-normal": 2},
+			wantReclaimSize:  6,
+		},
+		{
+			name:             "critical isolation region keeps upper size under contention",
+			criticalRegions:  []string{"isolation-critical"},
+			shareRequirement: 4,
+			wantPoolSizes:    map[string]int{state.PoolNameShare: 4, "isolation-critical": 6, "isolation-normal": 2},
+			wantReclaimSize:  2,
+		},
+		{
+			name:             "shortfall is absorbed by non-critical pools",
+			criticalRegions:  []string{"isolation-critical"},
+			shareRequirement: 7,
+			wantPoolSizes:    map[string]int{state.PoolNameShare: 5, "isolation-critical": 6, "isolation-normal": 1},
+			wantReclaimSize:  2,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+			conf.CriticalIsolationRegions = sets.NewString(tt.criticalRegions...)
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+				state.PoolNameReserve: {
+					PoolName: state.PoolNameReserve,
+					TopologyAwareAssignments: map[int]machine.CPUSet{
+						0: machine.MustParse("0"),
+						1: machine.MustParse("8"),
+					},
+				},
+			})
+			metaServer := generateTestMetaServer(t, 16, 2, nil)
+
+			share := &fakeRegion{
+				name:          "share-r",
+				regionType:    types.QoSRegionTypeShare,
+				ownerPoolName: state.PoolNameShare,
+				bindingNumas:  machine.NewCPUSet(0, 1),
+				controlKnob: types.ControlKnob{
+					types.ControlKnobNonReclaimedCPUSize: {Value: tt.shareRequirement},
+				},
+			}
+			critical := newIsolationRegion("isolation-critical")
+			normal := newIsolationRegion("isolation-normal")
+			regionMap := map[string]region.QoSRegion{share.Name(): share, critical.Name(): critical, normal.Name(): normal}
+			reservedForReclaim := map[int]int{0: 1, 1: 1}
+			numaAvailable := map[int]int{0: 6, 1: 6}
+			nonBindingNumas := machine.NewCPUSet(0, 1)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, metrics.DummyMetrics{})
+			result, _, err := pa.AssembleProvision()
+			require.NoError(t, err)
+
+			for poolName, wantSize := range tt.wantPoolSizes {
+				size, ok := result.GetPoolEntry(poolName, cpuadvisor.FakedNUMAID)
+				assert.True(t, ok)
+				assert.Equal(t, wantSize, size, poolName)
+			}
+			reclaimPoolSize, ok := result.GetPoolEntry(state.PoolNameReclaim, cpuadvisor.FakedNUMAID)
+			assert.True(t, ok)
+			assert.Equal(t, tt.wantReclaimSize, reclaimPoolSize)
+		})
+	}
+}
+
+func TestAssembleProvisionIsolationContentionHysteresis(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		margin            int
+		shareRequirements []float64
+		wantContended     []bool
+	}{
+		{
+			name:              "flapping without margin",
+			shareRequirements: []float64{5, 7, 5, 7},
+			wantContended:     []bool{false, true, false, true},
+		},
+		{
+			name:              "no contention within hysteresis band",
+			margin:            2,
+			shareRequirements: []float64{5, 7, 5, 7},
+			wantContended:     []bool{false, false, false, false},
+		},
+		{
+			name:              "contention kept within hysteresis band",
+			margin:            2,
+			shareRequirements: []float64{9, 5, 7, 5, 3, 5},
+			wantContended:     []bool{true, true, true, true, false, false},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+			conf.IsolationContentionMargin = tt.margin
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+				state.PoolNameReserve: {
+					PoolName: state.PoolNameReserve,
+					TopologyAwareAssignments: map[int]machine.CPUSet{
+						0: machine.MustParse("0"),
+						1: machine.MustParse("8"),
+					},
+				},
+			})
+			metaServer := generateTestMetaServer(t, 16, 2, nil)
+
+			share := &fakeRegion{
+				name:          "share-r",
+				regionType:    types.QoSRegionTypeShare,
+				ownerPoolName: state.PoolNameShare,
+				bindingNumas:  machine.NewCPUSet(0, 1),
+			}
+			isolation := &fakeRegion{
+				name:          "isolation-r",
+				regionType:    types.QoSRegionTypeIsolation,
+				ownerPoolName: "isolation-r",
+				bindingNumas:  machine.NewCPUSet(),
+				controlKnob: types.ControlKnob{
+					types.ControlKnobNonReclaimedCPUSizeUpper: {Value: 6},
+					types.ControlKnobNonReclaimedCPUSizeLower: {Value: 2},
+				},
+			}
+			regionMap := map[string]region.QoSRegion{share.Name(): share, isolation.Name(): isolation}
+			reservedForReclaim := map[int]int{0: 1, 1: 1}
+			numaAvailable := map[int]int{0: 6, 1: 6}
+			nonBindingNumas := machine.NewCPUSet(0, 1)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, metrics.DummyMetrics{})
+
+			// requirement of share and isolation pools is share requirement plus 6 against 12 available
+			for i, shareRequirement := range tt.shareRequirements {
+				share.controlKnob = types.ControlKnob{
+					types.ControlKnobNonReclaimedCPUSize: {Value: shareRequirement},
+				}
+				result, _, err := pa.AssembleProvision()
+				require.NoError(t, err)
+
+				isolationSize, ok := result.GetPoolEntry(isolation.Name(), cpuadvisor.FakedNUMAID)
+				assert.True(t, ok)
+				if tt.wantContended[i] {
+					assert.Equal(t, 2, isolationSize, "cycle %v", i)
+				} else {
+					assert.Greater(t, isolationSize, 2, "cycle %v", i)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+// HeadroomAttribution returns a copy of slack donated to reclaim by each dedicated pod in the last successful
+// assembling, keyed by pod uid; the slack of a pod is available minus non-reclaimed requirement of its numa,
+// and pods donating nothing, e.g. with reclaim disabled or consuming the whole numa, are mapped to zero
+func (pa *ProvisionAssemblerCommon) HeadroomAttribution() map[string]int {
+	pa.mutex.Lock()
+	defer pa.mutex.Unlock()
+
+	attribution := make(map[string]int, len(pa.headroomAttribution))
+	for podUID, slack := range pa.headroomAttribution {
+		attribution[podUID] = slack
+	}
+	return attribution
+}
@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestAssembleProvisionHeadroomAttribution(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("8"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 16, 2, []*v1.Pod{makeTestPod("uid1"), makeTestPod("uid2")})
+
+	dedicated0 := newFakeDedicatedRegion("dedicated-r0", 0, "uid1", 2)
+	dedicated1 := newFakeDedicatedRegion("dedicated-r1", 1, "uid2", 5)
+	regionMap := map[string]region.QoSRegion{dedicated0.Name(): dedicated0, dedicated1.Name(): dedicated1}
+	reservedForReclaim := map[int]int{0: 1, 1: 1}
+	numaAvailable := map[int]int{0: 6, 1: 6}
+	nonBindingNumas := machine.NewCPUSet()
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, metrics.DummyMetrics{})
+	assert.Empty(t, pa.HeadroomAttribution())
+
+	_, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"uid1": 6 - 2, "uid2": 6 - 5}, pa.HeadroomAttribution())
+
+	// pod consuming the whole numa donates nothing
+	dedicated1.controlKnob[types.ControlKnobNonReclaimedCPUSize] = types.ControlKnobValue{Value: 6, Action: types.ControlKnobActionNone}
+	_, _, err = pa.AssembleProvision()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"uid1": 6 - 2, "uid2": 0}, pa.HeadroomAttribution())
+
+	// attribution is kept from the last successful assembling
+	dedicated0.provisionErr = assert.AnError
+	_, _, err = pa.AssembleProvision()
+	require.Error(t, err)
+	assert.Equal(t, map[string]int{"uid1": 6 - 2, "uid2": 0}, pa.HeadroomAttribution())
+}
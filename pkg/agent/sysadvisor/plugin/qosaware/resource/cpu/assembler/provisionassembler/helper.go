@@ -19,23 +19,67 @@ package provisionassembler
 import (
 	"fmt"
 	"math"
+	"sort"
 
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
 	"github.com/kubewharf/katalyst-core/pkg/util/general"
 	"github.com/kubewharf/katalyst-core/pkg/util/machine"
 )
 
-func getNumasAvailableResource(numaAvailable map[int]int, numas machine.CPUSet) int {
+// getControlKnobValue returns value of the control knob, and error if it's missing
+// to avoid collapsing the pool by sizing with zero value silently
+func getControlKnobValue(regionName string, controlKnob types.ControlKnob, name types.ControlKnobName) (float64, error) {
+	value, ok := controlKnob[name]
+	if !ok {
+		return 0, fmt.Errorf("region %v misses control knob %v", regionName, name)
+	}
+	return value.Value, nil
+}
+
+// isPlausibleControlKnobValue returns true if the value expressed in cores fits into node capacity;
+// values out of the range are likely supplied in other units, e.g. millicores or percent
+func isPlausibleControlKnobValue(value float64, capacity int) bool {
+	return value >= 0 && value <= float64(capacity)
+}
+
+func getNumasAvailableResource(availability AvailabilityProvider, numas machine.CPUSet) int {
 	res := 0
 	for _, numaID := range numas.ToSliceInt() {
-		res += numaAvailable[numaID]
+		if available, ok := availability.GetNumaAvailable(numaID); ok {
+			res += available
+		}
 	}
 	return res
 }
 
-// regulatePoolSizes modifies pool size map to legal values, taking total available
-// resource and config such as enable reclaim into account. should be compatible with
-// any case and not return error. return true if reach resource upper bound.
-func regulatePoolSizes(poolSizes map[string]int, available int, enableReclaim bool) bool {
+// RegulatePoolSizes returns legal pool sizes regulated from the given ones, taking total available
+// resource and whether reclaim is enabled into account, and returns true if reaching resource upper
+// bound. it's compatible with any case, and has no side effect on the given sizes.
+//   - pools use up all available resource if reclaim is disabled;
+//   - pools are scaled down proportionally (rounding up first and then trimmed) if exceeding available;
+//   - otherwise pool sizes are kept as they are.
+func RegulatePoolSizes(sizes map[string]int, available int, enableReclaim bool) (map[string]int, bool) {
+	return RegulatePoolSizesByPriority(sizes, available, enableReclaim, nil)
+}
+
+// RegulatePoolSizesByPriority regulates pool sizes as RegulatePoolSizes does, except that pools of different
+// priorities exceeding available are not scaled down together: pools with higher priority are satisfied first,
+// and pools with lower priority absorb the shortfall (see shrinkPoolSizesByPriority). priorities are keyed
+// by pool name, and pools not in it are of priority 0.
+func RegulatePoolSizesByPriority(sizes map[string]int, available int, enableReclaim bool,
+	priorities map[string]int) (map[string]int, bool) {
+	return regulatePoolSizes(sizes, available, enableReclaim, priorities, nil)
+}
+
+// regulatePoolSizes regulates pool sizes as RegulatePoolSizesByPriority does, except that pools are shrunk weighted by
+// their utilizations instead of proportionally if utilizations are given (see shrinkPoolSizesByUtilization)
+func regulatePoolSizes(sizes map[string]int, available int, enableReclaim bool, priorities map[string]int,
+	utilizations map[string]float64) (map[string]int, bool) {
+	poolSizes := general.MergeMapInt(sizes, nil)
+	if len(poolSizes) == 0 {
+		return poolSizes, false
+	}
+
 	targetSum := general.SumUpMapValues(poolSizes)
 	boundUpper := false
 
@@ -51,14 +95,126 @@ func regulatePoolSizes(poolSizes map[string]int, available int, enableReclaim bo
 		targetSum = available
 	}
 
-	if err := normalizePoolSizes(poolSizes, targetSum); err != nil {
+	var err error
+	if targetSum < general.SumUpMapValues(poolSizes) && len(getPoolPriorityTiers(poolSizes, priorities)) > 1 {
+		err = shrinkPoolSizesByPriority(poolSizes, targetSum, priorities, utilizations)
+	} else {
+		err = shrinkPoolSizes(poolSizes, targetSum, utilizations)
+	}
+	if err != nil {
 		// all pools share available resource as fallback if normalization failed
 		for k := range poolSizes {
 			poolSizes[k] = available
 		}
 	}
 
-	return boundUpper
+	return poolSizes, boundUpper
+}
+
+// shrinkPoolSizesByPriority shrinks pool sizes to targetSum tier by tier in descending priority:
+// each tier is kept as it is if fitting into what is left, otherwise it's scaled down proportionally,
+// and at least one core is left for each pool in lower tiers if what is left allows. if it doesn't,
+// tiers are filled from the top down with one core for each pool, and pools left over are sized zero.
+func shrinkPoolSizesByPriority(poolSizes map[string]int, targetSum int, priorities map[string]int,
+	utilizations map[string]float64) error {
+	tiers := getPoolPriorityTiers(poolSizes, priorities)
+
+	lowerPools := len(poolSizes)
+	remaining := general.Max(targetSum, 0)
+	for _, tier := range tiers {
+		lowerPools -= len(tier)
+
+		tierSizes := make(map[string]int, len(tier))
+		for _, poolName := range tier {
+			tierSizes[poolName] = poolSizes[poolName]
+		}
+
+		// lower tiers never take cores needed by this tier to keep one core for each pool
+		budget := general.Max(remaining-lowerPools, general.Min(remaining, len(tier)))
+		if budget < len(tier) {
+			fillPoolSizesByOneCore(tierSizes, budget)
+		} else if general.SumUpMapValues(tierSizes) > budget {
+			if err := shrinkPoolSizes(tierSizes, budget, utilizations); err != nil {
+				return err
+			}
+		}
+
+		for poolName, size := range tierSizes {
+			poolSizes[poolName] = size
+		}
+		remaining -= general.SumUpMapValues(tierSizes)
+	}
+
+	return nil
+}
+
+// fillPoolSizesByOneCore sizes the largest pools with one core each until budget runs out, and others zero,
+// for budget insufficient to keep one core for each pool; ties are broken by pool name
+func fillPoolSizesByOneCore(poolSizes map[string]int, budget int) {
+	poolNames := make([]string, 0, len(poolSizes))
+	for poolName := range poolSizes {
+		poolNames = append(poolNames, poolName)
+	}
+	sort.Slice(poolNames, func(i, j int) bool {
+		if poolSizes[poolNames[i]] != poolSizes[poolNames[j]] {
+			return poolSizes[poolNames[i]] > poolSizes[poolNames[j]]
+		}
+		return poolNames[i] < poolNames[j]
+	})
+
+	for i, poolName := range poolNames {
+		if i < budget {
+			poolSizes[poolName] = 1
+		} else {
+			poolSizes[poolName] = 0
+		}
+	}
+}
+
+// getPoolPriorityTiers groups pool names by priority, in descending order of priority
+func getPoolPriorityTiers(poolSizes map[string]int, priorities map[string]int) [][]string {
+	poolsByPriority := make(map[int][]string)
+	for poolName := range poolSizes {
+		priority := priorities[poolName]
+		poolsByPriority[priority] = append(poolsByPriority[priority], poolName)
+	}
+
+	sortedPriorities := make([]int, 0, len(poolsByPriority))
+	for priority := range poolsByPriority {
+		sortedPriorities = append(sortedPriorities, priority)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(sortedPriorities)))
+
+	tiers := make([][]string, 0, len(sortedPriorities))
+	for _, priority := range sortedPriorities {
+		tiers = append(tiers, poolsByPriority[priority])
+	}
+	return tiers
+}
+
+// applyPoolMinSizes raises pool sizes to their min sizes; min sizes are only valid for
+// existing pools, and they will be scaled down proportionally if exceeding total available.
+func applyPoolMinSizes(poolSizes map[string]int, poolMinSizes map[string]int, available int) {
+	minSizes := make(map[string]int)
+	for poolName, minSize := range poolMinSizes {
+		if _, ok := poolSizes[poolName]; ok && minSize > 0 {
+			minSizes[poolName] = minSize
+		}
+	}
+	if len(minSizes) == 0 {
+		return
+	}
+
+	if general.SumUpMapValues(minSizes) > available {
+		if err := normalizePoolSizes(minSizes, available); err != nil {
+			// skip min sizes as fallback if normalization failed
+			return
+		}
+	}
+
+	for poolName, minSize := range minSizes {
+		poolSizes[poolName] = general.Max(poolSizes[poolName], minSize)
+	}
 }
 
 func normalizePoolSizes(poolSizes map[string]int, targetSum int) error {
@@ -0,0 +1,194 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegulatePoolSizesStandalone(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		sizes          map[string]int
+		available      int
+		enableReclaim  bool
+		priorities     map[string]int
+		wantSizes      map[string]int
+		wantBoundUpper bool
+	}{
+		{
+			name:           "exact fit with reclaim enabled",
+			sizes:          map[string]int{"share": 4, "batch": 4},
+			available:      8,
+			enableReclaim:  true,
+			wantSizes:      map[string]int{"share": 4, "batch": 4},
+			wantBoundUpper: true,
+		},
+		{
+			name:           "exact fit with reclaim disabled",
+			sizes:          map[string]int{"share": 4, "batch": 4},
+			available:      8,
+			enableReclaim:  false,
+			wantSizes:      map[string]int{"share": 4, "batch": 4},
+			wantBoundUpper: false,
+		},
+		{
+			name:           "over budget is scaled down proportionally",
+			sizes:          map[string]int{"share": 6, "batch": 3, "flink": 3},
+			available:      8,
+			enableReclaim:  true,
+			wantSizes:      map[string]int{"share": 4, "batch": 2, "flink": 2},
+			wantBoundUpper: true,
+		},
+		{
+			name:           "over budget is rounded up first and then trimmed",
+			sizes:          map[string]int{"share": 1, "batch": 2, "flink": 3},
+			available:      5,
+			enableReclaim:  true,
+			wantSizes:      map[string]int{"share": 1, "batch": 2, "flink": 2},
+			wantBoundUpper: true,
+		},
+		{
+			name:           "under budget with reclaim enabled keeps sizes",
+			sizes:          map[string]int{"share": 2, "batch": 1},
+			available:      12,
+			enableReclaim:  true,
+			wantSizes:      map[string]int{"share": 2, "batch": 1},
+			wantBoundUpper: false,
+		},
+		{
+			name:           "under budget with reclaim disabled uses up available",
+			sizes:          map[string]int{"share": 2, "batch": 1},
+			available:      12,
+			enableReclaim:  false,
+			wantSizes:      map[string]int{"share": 8, "batch": 4},
+			wantBoundUpper: false,
+		},
+		{
+			name:           "equal priorities are scaled down proportionally",
+			sizes:          map[string]int{"share": 6, "batch": 3, "flink": 3},
+			available:      8,
+			enableReclaim:  true,
+			priorities:     map[string]int{"share": 1, "batch": 1, "flink": 1},
+			wantSizes:      map[string]int{"share": 4, "batch": 2, "flink": 2},
+			wantBoundUpper: true,
+		},
+		{
+			name:           "lower priority tier absorbs the shortfall",
+			sizes:          map[string]int{"share": 6, "batch": 6},
+			available:      8,
+			enableReclaim:  true,
+			priorities:     map[string]int{"share": 1},
+			wantSizes:      map[string]int{"share": 6, "batch": 2},
+			wantBoundUpper: true,
+		},
+		{
+			name:           "higher priority tier is satisfied together before lower tier",
+			sizes:          map[string]int{"share": 4, "latency": 4, "batch": 6},
+			available:      10,
+			enableReclaim:  true,
+			priorities:     map[string]int{"share": 1, "latency": 1},
+			wantSizes:      map[string]int{"share": 4, "latency": 4, "batch": 2},
+			wantBoundUpper: true,
+		},
+		{
+			name:           "lower priority tier keeps at least one core",
+			sizes:          map[string]int{"share": 10, "batch": 4},
+			available:      8,
+			enableReclaim:  true,
+			priorities:     map[string]int{"share": 2, "batch": 1},
+			wantSizes:      map[string]int{"share": 7, "batch": 1},
+			wantBoundUpper: true,
+		},
+		{
+			name:           "available smaller than tiers is filled from the top down",
+			sizes:          map[string]int{"share": 5, "latency": 5, "batch": 5},
+			available:      2,
+			enableReclaim:  true,
+			priorities:     map[string]int{"share": 2, "latency": 1},
+			wantSizes:      map[string]int{"share": 1, "latency": 1, "batch": 0},
+			wantBoundUpper: true,
+		},
+		{
+			name:           "available smaller than pools of the top tier",
+			sizes:          map[string]int{"share": 5, "latency": 4, "batch": 5},
+			available:      1,
+			enableReclaim:  true,
+			priorities:     map[string]int{"share": 1, "latency": 1},
+			wantSizes:      map[string]int{"share": 1, "latency": 0, "batch": 0},
+			wantBoundUpper: true,
+		},
+		{
+			name:           "priorities under contention with reclaim disabled",
+			sizes:          map[string]int{"share": 6, "batch": 6},
+			available:      8,
+			enableReclaim:  false,
+			priorities:     map[string]int{"share": 1},
+			wantSizes:      map[string]int{"share": 6, "batch": 2},
+			wantBoundUpper: false,
+		},
+		{
+			name:           "priorities take no effect without contention",
+			sizes:          map[string]int{"share": 2, "batch": 1},
+			available:      12,
+			enableReclaim:  false,
+			priorities:     map[string]int{"share": 1},
+			wantSizes:      map[string]int{"share": 8, "batch": 4},
+			wantBoundUpper: false,
+		},
+		{
+			name:           "empty input",
+			sizes:          map[string]int{},
+			available:      12,
+			enableReclaim:  false,
+			wantSizes:      map[string]int{},
+			wantBoundUpper: false,
+		},
+		{
+			name:           "nil input",
+			sizes:          nil,
+			available:      12,
+			enableReclaim:  true,
+			wantSizes:      map[string]int{},
+			wantBoundUpper: false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			original := make(map[string]int)
+			for k, v := range tt.sizes {
+				original[k] = v
+			}
+
+			sizes, boundUpper := RegulatePoolSizesByPriority(tt.sizes, tt.available, tt.enableReclaim, tt.priorities)
+			assert.Equal(t, tt.wantSizes, sizes)
+			assert.Equal(t, tt.wantBoundUpper, boundUpper)
+			// input sizes are never modified
+			if tt.sizes != nil {
+				assert.Equal(t, original, tt.sizes)
+			}
+		})
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+// Logger records assembling decisions of provision assembler, so that callers can route them
+// to a separate sink or adjust verbosity independently; InfoS takes structured key-value pairs.
+type Logger interface {
+	InfoS(message string, keysAndValues ...interface{})
+	Infof(format string, args ...interface{})
+	Warningf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+var _ Logger = general.Logger{}
+
+// KlogLogger writes logs through klog, which is used by assembler by default
+type KlogLogger struct{}
+
+var _ Logger = KlogLogger{}
+
+func NewKlogLogger() Logger {
+	return KlogLogger{}
+}
+
+func (l KlogLogger) InfoS(message string, keysAndValues ...interface{}) {
+	klog.InfoSDepth(1, message, keysAndValues...)
+}
+
+func (l KlogLogger) Infof(format string, args ...interface{}) {
+	klog.InfofDepth(1, format, args...)
+}
+
+func (l KlogLogger) Warningf(format string, args ...interface{}) {
+	klog.WarningfDepth(1, format, args...)
+}
+
+func (l KlogLogger) Errorf(format string, args ...interface{}) {
+	klog.ErrorfDepth(1, format, args...)
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+type fakeLogEntry struct {
+	level         string
+	message       string
+	keysAndValues []interface{}
+}
+
+// fakeLogger captures logs written by assembler
+type fakeLogger struct {
+	mutex   sync.Mutex
+	entries []fakeLogEntry
+}
+
+var _ Logger = &fakeLogger{}
+
+func (l *fakeLogger) record(level, message string, keysAndValues ...interface{}) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.entries = append(l.entries, fakeLogEntry{level: level, message: message, keysAndValues: keysAndValues})
+}
+
+func (l *fakeLogger) InfoS(message string, keysAndValues ...interface{}) {
+	l.record("info", message, keysAndValues...)
+}
+
+func (l *fakeLogger) Infof(format string, args ...interface{}) {
+	l.record("info", fmt.Sprintf(format, args...))
+}
+
+func (l *fakeLogger) Warningf(format string, args ...interface{}) {
+	l.record("warning", fmt.Sprintf(format, args...))
+}
+
+func (l *fakeLogger) Errorf(format string, args ...interface{}) {
+	l.record("error", fmt.Sprintf(format, args...))
+}
+
+// find returns the first entry with the message
+func (l *fakeLogger) find(message string) (fakeLogEntry, bool) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	for _, entry := range l.entries {
+		if entry.message == message {
+			return entry, true
+		}
+	}
+	return fakeLogEntry{}, false
+}
+
+func TestAssembleProvisionLogger(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0-14"),
+				1: machine.MustParse("16-30"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 32, 2, nil)
+
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(0, 1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 4},
+		},
+	}
+	regionMap := map[string]region.QoSRegion{share.Name(): share}
+	reservedForReclaim := map[int]int{0: 0, 1: 0}
+	numaAvailable := map[int]int{0: 4, 1: 4}
+	nonBindingNumas := machine.NewCPUSet(0, 1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, metrics.DummyMetrics{}).(*ProvisionAssemblerCommon)
+	logger := &fakeLogger{}
+	pa.SetLogger(logger)
+
+	_, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+
+	// pool sizes are logged with structured fields
+	entry, ok := logger.find("[qosaware-cpu] pool sizes")
+	require.True(t, ok)
+	assert.Equal(t, "info", entry.level)
+	fields := make(map[string]interface{})
+	for i := 0; i+1 < len(entry.keysAndValues); i += 2 {
+		fields[entry.keysAndValues[i].(string)] = entry.keysAndValues[i+1]
+	}
+	assert.Equal(t, map[string]int{state.PoolNameShare: 4}, fields["share size"])
+	// the 2 cpus clamped from reserve pool are given back to numa available resource
+	assert.Equal(t, 10, fields["shareAndIsolatedPoolAvailable"])
+
+	// decisions are routed to the injected logger instead of klog
+	entry, ok = logger.find("[qosaware-cpu] reserve pool size 30 exceeds max 28, clamp it")
+	require.True(t, ok)
+	assert.Equal(t, "warning", entry.level)
+}
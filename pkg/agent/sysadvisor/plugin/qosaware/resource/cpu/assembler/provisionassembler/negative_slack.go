@@ -0,0 +1,40 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const (
+	metricCPUProvisionNegativeSlack = "cpu_provision_negative_slack"
+)
+
+// detectNegativeSlack flags the result as overcommitted if share and isolation pools on non-binding numas
+// require more than available before regulation, and emits the magnitude of the negative slack
+func (pa *ProvisionAssemblerCommon) detectNegativeSlack(calculationResult *types.InternalCPUCalculationResult, available, requirement int) {
+	slack := available - requirement
+	if slack >= 0 {
+		return
+	}
+
+	pa.logger.Warningf("[qosaware-cpu] node is overcommitted: share and isolation pools require %v, exceeding available %v by %v",
+		requirement, available, -slack)
+	_ = pa.emitter.StoreInt64(metricCPUProvisionNegativeSlack, int64(-slack), metrics.MetricTypeNameRaw)
+	calculationResult.Overcommitted = true
+}
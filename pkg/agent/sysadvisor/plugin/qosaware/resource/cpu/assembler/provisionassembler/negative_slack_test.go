@@ -0,0 +1,113 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestAssembleProvisionNegativeSlack(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		shareRequirement  float64
+		wantOvercommitted bool
+		wantNegativeSlack []int64
+		wantReclaimSize   int
+	}{
+		{
+			name:             "positive slack",
+			shareRequirement: 4,
+			wantReclaimSize:  6,
+		},
+		{
+			name:              "overcommitted",
+			shareRequirement:  10,
+			wantOvercommitted: true,
+			wantNegativeSlack: []int64{2},
+			wantReclaimSize:   2,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+				state.PoolNameReserve: {
+					PoolName: state.PoolNameReserve,
+					TopologyAwareAssignments: map[int]machine.CPUSet{
+						0: machine.MustParse("0"),
+						1: machine.MustParse("8"),
+					},
+				},
+			})
+			metaServer := generateTestMetaServer(t, 16, 2, nil)
+			emitter := newFakeMetricEmitter()
+
+			// isolation pool requires 4 at least, along with share pool against 12 available
+			share := &fakeRegion{
+				name:          "share-r",
+				regionType:    types.QoSRegionTypeShare,
+				ownerPoolName: state.PoolNameShare,
+				bindingNumas:  machine.NewCPUSet(0, 1),
+				controlKnob: types.ControlKnob{
+					types.ControlKnobNonReclaimedCPUSize: {Value: tt.shareRequirement},
+				},
+			}
+			isolation := &fakeRegion{
+				name:          "isolation-r",
+				regionType:    types.QoSRegionTypeIsolation,
+				ownerPoolName: "isolation-r",
+				bindingNumas:  machine.NewCPUSet(),
+				controlKnob: types.ControlKnob{
+					types.ControlKnobNonReclaimedCPUSizeUpper: {Value: 4},
+					types.ControlKnobNonReclaimedCPUSizeLower: {Value: 4},
+				},
+			}
+			regionMap := map[string]region.QoSRegion{share.Name(): share, isolation.Name(): isolation}
+			reservedForReclaim := map[int]int{0: 1, 1: 1}
+			numaAvailable := map[int]int{0: 6, 1: 6}
+			nonBindingNumas := machine.NewCPUSet(0, 1)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, emitter)
+			result, _, err := pa.AssembleProvision()
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantOvercommitted, result.Overcommitted)
+			assert.Equal(t, tt.wantNegativeSlack, emitter.get(metricCPUProvisionNegativeSlack))
+			reclaimSize, ok := result.GetPoolEntry(state.PoolNameReclaim, cpuadvisor.FakedNUMAID)
+			assert.True(t, ok)
+			assert.Equal(t, tt.wantReclaimSize, reclaimSize)
+		})
+	}
+}
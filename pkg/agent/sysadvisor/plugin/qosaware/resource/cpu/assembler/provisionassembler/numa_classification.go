@@ -0,0 +1,35 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+// classifyNumas classifies each numa of the node as binding or non-binding, by the non-binding numas
+// given to the assembler, for consumers not to re-derive it from pool entries
+func (pa *ProvisionAssemblerCommon) classifyNumas() map[int]types.NumaClassification {
+	classifications := make(map[int]types.NumaClassification)
+	for _, numaID := range pa.metaServer.CPUDetails.NUMANodes().ToSliceInt() {
+		if pa.nonBindingNumas.Contains(numaID) {
+			classifications[numaID] = types.NumaClassificationNonBinding
+		} else {
+			classifications[numaID] = types.NumaClassificationBinding
+		}
+	}
+	return classifications
+}
@@ -0,0 +1,82 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestAssembleProvisionNumaClassifications(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{})
+	metaServer := generateTestMetaServer(t, 16, 4, []*v1.Pod{makeTestPod("uid1"), makeTestPod("uid2")})
+
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(2, 3),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 2, Action: types.ControlKnobActionNone},
+		},
+	}
+	dedicated0 := newFakeDedicatedRegion("dedicated-r0", 0, "uid1", 2)
+	dedicated1 := newFakeDedicatedRegion("dedicated-r1", 1, "uid2", 2)
+	regionMap := map[string]region.QoSRegion{
+		share.Name():      share,
+		dedicated0.Name(): dedicated0,
+		dedicated1.Name(): dedicated1,
+	}
+	reservedForReclaim := map[int]int{0: 0, 1: 0, 2: 0, 3: 0}
+	numaAvailable := map[int]int{0: 4, 1: 4, 2: 4, 3: 4}
+	nonBindingNumas := machine.NewCPUSet(2, 3)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, metrics.DummyMetrics{})
+	result, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+
+	assert.Equal(t, map[int]types.NumaClassification{
+		0: types.NumaClassificationBinding,
+		1: types.NumaClassificationBinding,
+		2: types.NumaClassificationNonBinding,
+		3: types.NumaClassificationNonBinding,
+	}, result.NumaClassifications)
+
+	// reclaim pool entries of binding numas are keyed by numa ids, and those of non-binding numas by FakedNUMAID
+	for numaID, classification := range result.NumaClassifications {
+		_, ok := result.GetPoolEntry(state.PoolNameReclaim, numaID)
+		assert.Equal(t, classification == types.NumaClassificationBinding, ok, "numa %v", numaID)
+	}
+	_, ok := result.GetPoolEntry(state.PoolNameReclaim, cpuadvisor.FakedNUMAID)
+	assert.True(t, ok)
+}
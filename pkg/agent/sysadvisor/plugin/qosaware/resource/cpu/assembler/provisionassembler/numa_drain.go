@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const (
+	metricCPUProvisionNumaReclaimDrained = "cpu_provision_numa_reclaim_drained"
+)
+
+// numaReclaimDrain records when draining reclaim of a numa starts and how long it takes to drain to zero
+type numaReclaimDrain struct {
+	startedAt time.Time
+	over      time.Duration
+}
+
+// DrainNumaReclaim drains reclaim of the numa toward zero gradually across assembling cycles, e.g. for planned
+// maintenance of the numa, while reclaim of other numas is untouched; reclaim of the numa is capped to the
+// fraction of the duration left, so it reaches zero once the duration elapses, and non-positive duration
+// drains it at once. draining a numa being drained restarts the duration.
+func (pa *ProvisionAssemblerCommon) DrainNumaReclaim(numaID int, over time.Duration) error {
+	pa.mutex.Lock()
+	defer pa.mutex.Unlock()
+
+	if !pa.metaServer.CPUDetails.NUMANodes().Contains(numaID) {
+		return fmt.Errorf("numa %v is absent from node numas %v", numaID, pa.metaServer.CPUDetails.NUMANodes().String())
+	}
+	pa.numaReclaimDrains[numaID] = &numaReclaimDrain{startedAt: pa.clock.Now(), over: over}
+	pa.logger.Infof("[qosaware-cpu] start draining reclaim of numa %v over %v", numaID, over)
+	return nil
+}
+
+// UndrainNumaReclaim stops draining reclaim of the numa, and its reclaim regrows as ramp-up limit allows
+func (pa *ProvisionAssemblerCommon) UndrainNumaReclaim(numaID int) {
+	pa.mutex.Lock()
+	defer pa.mutex.Unlock()
+
+	if _, ok := pa.numaReclaimDrains[numaID]; ok {
+		delete(pa.numaReclaimDrains, numaID)
+		pa.logger.Infof("[qosaware-cpu] stop draining reclaim of numa %v", numaID)
+	}
+}
+
+// drainNumaReclaim caps reclaim pool entries of numas being drained; reclaim of a non-binding numa is regarded
+// as evenly distributed in the entry of non-binding numas, and only its share in the entry is drained
+func (pa *ProvisionAssemblerCommon) drainNumaReclaim(calculationResult *types.InternalCPUCalculationResult) {
+	now := pa.clock.Now()
+	for numaID, drain := range pa.numaReclaimDrains {
+		remaining := 0.0
+		if drain.over > 0 {
+			remaining = math.Max(1-float64(now.Sub(drain.startedAt))/float64(drain.over), 0)
+		}
+
+		entryNumaID, share := numaID, 0
+		if size, ok := calculationResult.GetPoolEntry(pa.assemblerConf.ReclaimPoolName, numaID); ok {
+			share = size
+		} else if pa.nonBindingNumas.Contains(numaID) {
+			size, ok := calculationResult.GetPoolEntry(pa.assemblerConf.ReclaimPoolName, cpuadvisor.FakedNUMAID)
+			if !ok {
+				continue
+			}
+			entryNumaID, share = cpuadvisor.FakedNUMAID, size/pa.nonBindingNumas.Size()
+		} else {
+			continue
+		}
+
+		drained := int(math.Ceil(float64(share) * (1 - remaining)))
+		size, _ := calculationResult.GetPoolEntry(pa.assemblerConf.ReclaimPoolName, entryNumaID)
+		calculationResult.SetPoolEntryExplicitly(pa.assemblerConf.ReclaimPoolName, entryNumaID, size-drained)
+
+		pa.logger.Infof("[qosaware-cpu] drain reclaim of numa %v by %v since %v, %.0f%% left", numaID, drained,
+			drain.startedAt, remaining*100)
+		_ = pa.emitter.StoreInt64(metricCPUProvisionNumaReclaimDrained, int64(drained), metrics.MetricTypeNameRaw,
+			metrics.MetricTag{Key: "numa_id", Val: strconv.Itoa(numaID)})
+	}
+}
@@ -0,0 +1,96 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	testingclock "k8s.io/utils/clock/testing"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestAssembleProvisionDrainNumaReclaim(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("8"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 16, 2, []*v1.Pod{makeTestPod("uid1"), makeTestPod("uid2")})
+	emitter := newFakeMetricEmitter()
+
+	dedicated0 := newFakeDedicatedRegion("dedicated-r0", 0, "uid1", 2)
+	dedicated1 := newFakeDedicatedRegion("dedicated-r1", 1, "uid2", 2)
+	regionMap := map[string]region.QoSRegion{dedicated0.Name(): dedicated0, dedicated1.Name(): dedicated1}
+	reservedForReclaim := map[int]int{0: 1, 1: 1}
+	numaAvailable := map[int]int{0: 6, 1: 6}
+	nonBindingNumas := machine.NewCPUSet()
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, emitter).(*ProvisionAssemblerCommon)
+	now := time.Now()
+	fakeClock := testingclock.NewFakePassiveClock(now)
+	pa.clock = fakeClock
+
+	assertReclaimPoolSizes := func(want0, want1 int) {
+		result, _, err := pa.AssembleProvision()
+		require.NoError(t, err)
+		size0, ok := result.GetPoolEntry(state.PoolNameReclaim, 0)
+		assert.True(t, ok)
+		assert.Equal(t, want0, size0)
+		size1, ok := result.GetPoolEntry(state.PoolNameReclaim, 1)
+		assert.True(t, ok)
+		assert.Equal(t, want1, size1)
+	}
+	assertReclaimPoolSizes(5, 5)
+
+	assert.Error(t, pa.DrainNumaReclaim(3, time.Minute))
+	require.NoError(t, pa.DrainNumaReclaim(1, 100*time.Second))
+
+	// reclaim of numa 1 decreases along with the duration elapsing, while numa 0 stays constant
+	assertReclaimPoolSizes(5, 5)
+	fakeClock.SetTime(now.Add(40 * time.Second))
+	assertReclaimPoolSizes(5, 3)
+	fakeClock.SetTime(now.Add(80 * time.Second))
+	assertReclaimPoolSizes(5, 1)
+	fakeClock.SetTime(now.Add(120 * time.Second))
+	assertReclaimPoolSizes(5, 0)
+	assert.Equal(t, []int64{0, 2, 4, 5}, emitter.get(metricCPUProvisionNumaReclaimDrained))
+
+	// draining is kept across resetting
+	pa.Reset()
+	assertReclaimPoolSizes(5, 0)
+
+	pa.UndrainNumaReclaim(1)
+	assertReclaimPoolSizes(5, 5)
+}
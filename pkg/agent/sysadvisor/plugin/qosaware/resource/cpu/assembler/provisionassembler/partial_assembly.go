@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+const (
+	metricCPUProvisionRegionFailed = "cpu_provision_region_failed"
+)
+
+// regionFailures tracks capacity of regions skipped in partial assembling
+type regionFailures struct {
+	// bindingNumas are binding numas of failed regions
+	bindingNumas machine.CPUSet
+	// nonBinding is true if any region on non-binding numas fails
+	nonBinding bool
+}
+
+func newRegionFailures() *regionFailures {
+	return &regionFailures{bindingNumas: machine.NewCPUSet()}
+}
+
+func (f *regionFailures) add(r region.QoSRegion) {
+	if bindingNumas := r.GetBindingNumas(); !bindingNumas.IsEmpty() {
+		f.bindingNumas = f.bindingNumas.Union(bindingNumas)
+		return
+	}
+	f.nonBinding = true
+}
+
+// recordRegionFailure records the region failing in assembling into the result's region errors, so that the
+// rest of the assembly completes in partial assembly mode; it returns false if the assembly should fail fast.
+func (pa *ProvisionAssemblerCommon) recordRegionFailure(result *types.InternalCPUCalculationResult, failures *regionFailures,
+	r region.QoSRegion, err error) bool {
+	if !pa.assemblerConf.EnablePartialAssembly {
+		return false
+	}
+
+	pa.logger.Errorf("[qosaware-cpu] skip region %v failing in assembling: %v", r.Name(), err)
+	_ = pa.emitter.StoreInt64(metricCPUProvisionRegionFailed, 1, metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "region_name", Val: r.Name()},
+		metrics.MetricTag{Key: "region_type", Val: string(r.Type())})
+
+	if result.RegionErrors == nil {
+		result.RegionErrors = make(map[string]string)
+	}
+	result.RegionErrors[r.Name()] = err.Error()
+	failures.add(r)
+	return true
+}
+
+// reserveFailedRegionNumas treats capacity of binding numas of failed regions as fully reserved, i.e. the
+// numas donate nothing but reserved for reclaim to reclaim pool
+func (pa *ProvisionAssemblerCommon) reserveFailedRegionNumas(result *types.InternalCPUCalculationResult,
+	breakdown ReclaimBreakdown, failedNumas machine.CPUSet) {
+	for _, numaID := range failedNumas.ToSliceInt() {
+		numas := machine.NewCPUSet(numaID)
+		reservedForReclaim := pa.getNumasReservedForReclaim(numas)
+		result.SetPoolEntryExplicitly(pa.assemblerConf.ReclaimPoolName, numaID, reservedForReclaim)
+
+		if _, ok := breakdown.Entries[numaID]; !ok {
+			available := getNumasAvailableResource(pa.availability, numas)
+			breakdown.setEntry(numaID, ReclaimBreakdownEntry{Available: available, NonReclaimed: available, ReservedForReclaim: reservedForReclaim})
+			continue
+		}
+		breakdown.adjust(numaID, reclaimAdjustmentFailedRegion, reservedForReclaim)
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestAssembleProvisionPartialAssembly(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                  string
+		enablePartialAssembly bool
+		failingRegion         string
+		wantErr               bool
+		wantPoolEntries       map[string]map[int]int
+		wantRegionErrors      []string
+	}{
+		{
+			name:          "fail fast by default",
+			failingRegion: "dedicated-1",
+			wantErr:       true,
+		},
+		{
+			name:                  "failed dedicated region keeps its numa reserved",
+			enablePartialAssembly: true,
+			failingRegion:         "dedicated-1",
+			wantPoolEntries: map[string]map[int]int{
+				state.PoolNameReserve: {cpuadvisor.FakedNUMAID: 3},
+				state.PoolNameShare:   {cpuadvisor.FakedNUMAID: 3},
+				state.PoolNameReclaim: {0: 4, 1: 1, cpuadvisor.FakedNUMAID: 5},
+			},
+			wantRegionErrors: []string{"dedicated-1"},
+		},
+		{
+			name:                  "failed share region keeps non-binding numas reserved",
+			enablePartialAssembly: true,
+			failingRegion:         "share",
+			wantPoolEntries: map[string]map[int]int{
+				state.PoolNameReserve: {cpuadvisor.FakedNUMAID: 3},
+				state.PoolNameReclaim: {0: 4, 1: 3, cpuadvisor.FakedNUMAID: 1},
+			},
+			wantRegionErrors: []string{"share"},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+			conf.EnablePartialAssembly = tt.enablePartialAssembly
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+				state.PoolNameReserve: {
+					PoolName: state.PoolNameReserve,
+					TopologyAwareAssignments: map[int]machine.CPUSet{
+						0: machine.MustParse("0"),
+						1: machine.MustParse("8"),
+						2: machine.MustParse("16"),
+					},
+				},
+			})
+			metaServer := generateTestMetaServer(t, 24, 3, []*v1.Pod{makeTestPod("uid0"), makeTestPod("uid1")})
+
+			regions := []*fakeRegion{
+				newFakeDedicatedRegion("dedicated-0", 0, "uid0", 4),
+				newFakeDedicatedRegion("dedicated-1", 1, "uid1", 5),
+				{
+					name:          "share",
+					regionType:    types.QoSRegionTypeShare,
+					ownerPoolName: state.PoolNameShare,
+					bindingNumas:  machine.NewCPUSet(),
+					controlKnob: types.ControlKnob{
+						types.ControlKnobNonReclaimedCPUSize: {Value: 3, Action: types.ControlKnobActionNone},
+					},
+				},
+			}
+			regionMap := make(map[string]region.QoSRegion)
+			for _, r := range regions {
+				if r.name == tt.failingRegion {
+					r.provisionErr = fmt.Errorf("fake provision error")
+				}
+				regionMap[r.Name()] = r
+			}
+			reservedForReclaim := map[int]int{0: 1, 1: 1, 2: 1}
+			numaAvailable := map[int]int{0: 7, 1: 7, 2: 7}
+			nonBindingNumas := machine.NewCPUSet(2)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, newFakeMetricEmitter())
+			result, _, err := pa.AssembleProvision()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantPoolEntries, result.PoolEntries)
+			regionErrors := make([]string, 0, len(result.RegionErrors))
+			for regionName := range result.RegionErrors {
+				regionErrors = append(regionErrors, regionName)
+			}
+			assert.ElementsMatch(t, tt.wantRegionErrors, regionErrors)
+		})
+	}
+}
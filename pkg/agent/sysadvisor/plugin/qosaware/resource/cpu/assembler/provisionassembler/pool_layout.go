@@ -0,0 +1,88 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"sort"
+	"strconv"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const (
+	metricCPUProvisionPoolLayout = "cpu_provision_pool_layout"
+
+	// poolLayoutSharedNumaLabel is the numa label value of pool entries not bound to any numa
+	poolLayoutSharedNumaLabel = "shared"
+)
+
+// poolLayoutSeries identifies a series of pool layout metric
+type poolLayoutSeries struct {
+	poolName string
+	numaID   int
+}
+
+// emitPoolLayout emits size of each (pool, numa) entry in the final provision result as one gauge family
+// labeled by pool name, pool type and numa id, where entries not bound to any numa are labeled as "shared";
+// series emitted in the last assembling but gone in this one are emitted as zero to avoid stale values.
+func (pa *ProvisionAssemblerCommon) emitPoolLayout(calculationResult types.InternalCPUCalculationResult) {
+	series := make(map[poolLayoutSeries]int)
+	currentSeries := make(map[poolLayoutSeries]struct{})
+	for poolName, poolEntry := range calculationResult.PoolEntries {
+		for numaID, size := range poolEntry {
+			s := poolLayoutSeries{poolName: poolName, numaID: numaID}
+			series[s] = size
+			currentSeries[s] = struct{}{}
+		}
+	}
+	for s := range pa.lastPoolLayoutSeries {
+		if _, ok := series[s]; !ok {
+			series[s] = 0
+		}
+	}
+	pa.lastPoolLayoutSeries = currentSeries
+
+	sortedSeries := make([]poolLayoutSeries, 0, len(series))
+	for s := range series {
+		sortedSeries = append(sortedSeries, s)
+	}
+	sort.Slice(sortedSeries, func(i, j int) bool {
+		if sortedSeries[i].poolName != sortedSeries[j].poolName {
+			return sortedSeries[i].poolName < sortedSeries[j].poolName
+		}
+		return sortedSeries[i].numaID < sortedSeries[j].numaID
+	})
+
+	for _, s := range sortedSeries {
+		numaLabel := poolLayoutSharedNumaLabel
+		if s.numaID != cpuadvisor.FakedNUMAID {
+			numaLabel = strconv.Itoa(s.numaID)
+		}
+		// reclaim pool may be named by configuration instead of the qrm convention
+		poolType := state.GetPoolType(s.poolName)
+		if s.poolName == pa.assemblerConf.ReclaimPoolName {
+			poolType = state.PoolNameReclaim
+		}
+		_ = pa.emitter.StoreInt64(metricCPUProvisionPoolLayout, int64(series[s]), metrics.MetricTypeNameRaw,
+			metrics.MetricTag{Key: "pool_name", Val: s.poolName},
+			metrics.MetricTag{Key: "pool_type", Val: poolType},
+			metrics.MetricTag{Key: "numa_id", Val: numaLabel})
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestEmitPoolLayout(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	metaCache := generateTestMetaCache(t, conf, nil)
+	metaServer := generateTestMetaServer(t, 16, 2, nil)
+
+	regionMap := make(map[string]region.QoSRegion)
+	reservedForReclaim := map[int]int{0: 0, 1: 0}
+	numaAvailable := map[int]int{0: 8, 1: 8}
+	nonBindingNumas := machine.NewCPUSet(0, 1)
+
+	emitter := newFakeMetricEmitter()
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, emitter).(*ProvisionAssemblerCommon)
+
+	first := types.InternalCPUCalculationResult{PoolEntries: make(map[string]map[int]int)}
+	first.SetPoolEntry(state.PoolNameShare, cpuadvisor.FakedNUMAID, 6)
+	first.SetPoolEntry(state.PoolNameReclaim, 0, 2)
+	first.SetPoolEntry(state.PoolNameReclaim, 1, 3)
+	pa.emitPoolLayout(first)
+
+	assert.Equal(t, []int64{2, 3, 6}, emitter.get(metricCPUProvisionPoolLayout))
+	assert.Equal(t, []map[string]string{
+		{"pool_name": state.PoolNameReclaim, "pool_type": state.PoolNameReclaim, "numa_id": "0"},
+		{"pool_name": state.PoolNameReclaim, "pool_type": state.PoolNameReclaim, "numa_id": "1"},
+		{"pool_name": state.PoolNameShare, "pool_type": state.PoolNameShare, "numa_id": poolLayoutSharedNumaLabel},
+	}, emitter.getTags(metricCPUProvisionPoolLayout))
+
+	// series gone from the layout are reset to zero once
+	second := types.InternalCPUCalculationResult{PoolEntries: make(map[string]map[int]int)}
+	second.SetPoolEntry(state.PoolNameShare, cpuadvisor.FakedNUMAID, 8)
+	second.SetPoolEntry(state.PoolNameReclaim, 0, 4)
+	pa.emitPoolLayout(second)
+	assert.Equal(t, []int64{2, 3, 6, 4, 0, 8}, emitter.get(metricCPUProvisionPoolLayout))
+
+	pa.emitPoolLayout(second)
+	assert.Equal(t, []int64{2, 3, 6, 4, 0, 8, 4, 8}, emitter.get(metricCPUProvisionPoolLayout))
+}
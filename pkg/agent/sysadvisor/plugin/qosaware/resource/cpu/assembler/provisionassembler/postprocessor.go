@@ -0,0 +1,47 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+// PostProcessor tweaks pool entries of the raw provision result built by assembler,
+// e.g. snapping pool sizes to even numbers or aligning them to socket boundaries.
+type PostProcessor interface {
+	// Name returns the name of post processor
+	Name() string
+	// Process returns the adjusted provision result, and error aborts current assembling
+	Process(result types.InternalCPUCalculationResult) (types.InternalCPUCalculationResult, error)
+}
+
+// PostProcessorNoop keeps the provision result unchanged
+type PostProcessorNoop struct{}
+
+var _ PostProcessor = &PostProcessorNoop{}
+
+func NewPostProcessorNoop() PostProcessor {
+	return &PostProcessorNoop{}
+}
+
+func (p *PostProcessorNoop) Name() string {
+	return "noop"
+}
+
+func (p *PostProcessorNoop) Process(result types.InternalCPUCalculationResult) (types.InternalCPUCalculationResult, error) {
+	return result, nil
+}
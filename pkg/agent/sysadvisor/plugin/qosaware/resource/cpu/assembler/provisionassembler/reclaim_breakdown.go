@@ -0,0 +1,160 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+// stages adjusting reclaim pool entries after they are derived from pool sizes
+const (
+	reclaimAdjustmentExhausted           = "exhausted"
+	reclaimAdjustmentFailedRegion        = "failed_region"
+	reclaimAdjustmentReclaimDisabled     = "reclaim_disabled"
+	reclaimAdjustmentExcludedNumas       = "excluded_numas"
+	reclaimAdjustmentReclaimReservation  = "reclaim_reservation"
+	reclaimAdjustmentPressureFeedback    = "pressure_feedback"
+	reclaimAdjustmentCeiling             = "ceiling"
+	reclaimAdjustmentFairness            = "fairness"
+	reclaimAdjustmentNumaDrain           = "numa_drain"
+	reclaimAdjustmentRampUp              = "ramp_up"
+	reclaimAdjustmentSmoothing           = "smoothing"
+	reclaimAdjustmentCriticalReservation = "critical_reservation"
+	reclaimAdjustmentPostProcessors      = "post_processors"
+)
+
+// ReclaimBreakdownEntry itemizes factors of a reclaim pool entry, which always reconcile as
+// Reclaim = Available - NonReclaimed - SharePools - IsolationPools + ReservedForReclaim + HandedOff + sum(Adjustments)
+type ReclaimBreakdownEntry struct {
+	// Available is resource available for non-reserve pools, i.e. reserve pool is excluded already;
+	// for non-binding numas, reserved for reclaim handed off from dedicated numas is excluded too
+	Available int
+	// NonReclaimed is requirement of dedicated workloads not donated to reclaim
+	NonReclaimed       int
+	SharePools         int
+	IsolationPools     int
+	ReservedForReclaim int
+	// HandedOff is reserved for reclaim handed off from saturated dedicated numas
+	HandedOff int
+	// Adjustments records changes made to the derived reclaim pool entry, keyed by stage
+	Adjustments map[string]int
+	Reclaim     int
+}
+
+// ReclaimBreakdown itemizes factors trimming reclaim pool of the last successful assembling
+type ReclaimBreakdown struct {
+	// ReservePool is size of reserve pool, which is excluded from available of all entries
+	ReservePool int
+	// Entries are keyed by numa id, and cpuadvisor.FakedNUMAID stands for non-binding numas
+	Entries map[int]*ReclaimBreakdownEntry
+
+	// reclaimPoolName is the name of reclaim pool entries reconciled with
+	reclaimPoolName string
+}
+
+func newReclaimBreakdown(reservePool int, reclaimPoolName string) ReclaimBreakdown {
+	return ReclaimBreakdown{ReservePool: reservePool, Entries: make(map[int]*ReclaimBreakdownEntry), reclaimPoolName: reclaimPoolName}
+}
+
+// setEntry records the factors of reclaim pool entry of the numa, and derives the reclaim size from them
+func (b ReclaimBreakdown) setEntry(numaID int, entry ReclaimBreakdownEntry) {
+	entry.Adjustments = make(map[string]int)
+	entry.Reclaim = entry.Available - entry.NonReclaimed - entry.SharePools - entry.IsolationPools +
+		entry.ReservedForReclaim + entry.HandedOff
+	b.Entries[numaID] = &entry
+}
+
+// adjust records the change of reclaim pool entry of the numa made by the stage
+func (b ReclaimBreakdown) adjust(numaID int, stage string, reclaim int) {
+	entry, ok := b.Entries[numaID]
+	if !ok {
+		entry = &ReclaimBreakdownEntry{Adjustments: make(map[string]int)}
+		b.Entries[numaID] = entry
+	}
+	if delta := reclaim - entry.Reclaim; delta != 0 {
+		entry.Adjustments[stage] += delta
+		entry.Reclaim = reclaim
+	}
+}
+
+// reconcile attributes differences between the breakdown and reclaim pool entries of the result to the stage
+func (b ReclaimBreakdown) reconcile(stage string, calculationResult types.InternalCPUCalculationResult) {
+	reclaimPoolEntries := calculationResult.PoolEntries[b.reclaimPoolName]
+	for numaID := range b.Entries {
+		if _, ok := reclaimPoolEntries[numaID]; !ok {
+			delete(b.Entries, numaID)
+		}
+	}
+	for numaID, size := range reclaimPoolEntries {
+		b.adjust(numaID, stage, size)
+	}
+}
+
+// Aggregate sums up factors of all entries
+func (b ReclaimBreakdown) Aggregate() ReclaimBreakdownEntry {
+	aggregate := ReclaimBreakdownEntry{Adjustments: make(map[string]int)}
+	for _, entry := range b.Entries {
+		aggregate.Available += entry.Available
+		aggregate.NonReclaimed += entry.NonReclaimed
+		aggregate.SharePools += entry.SharePools
+		aggregate.IsolationPools += entry.IsolationPools
+		aggregate.ReservedForReclaim += entry.ReservedForReclaim
+		aggregate.HandedOff += entry.HandedOff
+		for stage, delta := range entry.Adjustments {
+			aggregate.Adjustments[stage] += delta
+		}
+		aggregate.Reclaim += entry.Reclaim
+	}
+	return aggregate
+}
+
+func (e ReclaimBreakdownEntry) String() string {
+	stages := make([]string, 0, len(e.Adjustments))
+	for stage := range e.Adjustments {
+		stages = append(stages, stage)
+	}
+	sort.Strings(stages)
+
+	adjustments := make([]string, 0, len(stages))
+	for _, stage := range stages {
+		adjustments = append(adjustments, fmt.Sprintf("%v=%+d", stage, e.Adjustments[stage]))
+	}
+	return fmt.Sprintf("available %v - non-reclaimed %v - share %v - isolation %v + reserved-for-reclaim %v + handed-off %v + adjustments [%v] = reclaim %v",
+		e.Available, e.NonReclaimed, e.SharePools, e.IsolationPools, e.ReservedForReclaim, e.HandedOff,
+		strings.Join(adjustments, ", "), e.Reclaim)
+}
+
+// ReclaimBreakdown returns a copy of the reclaim breakdown of the last successful assembling
+func (pa *ProvisionAssemblerCommon) ReclaimBreakdown() ReclaimBreakdown {
+	pa.mutex.Lock()
+	defer pa.mutex.Unlock()
+
+	breakdown := newReclaimBreakdown(pa.reclaimBreakdown.ReservePool, pa.reclaimBreakdown.reclaimPoolName)
+	for numaID, entry := range pa.reclaimBreakdown.Entries {
+		entryCopy := *entry
+		entryCopy.Adjustments = make(map[string]int, len(entry.Adjustments))
+		for stage, delta := range entry.Adjustments {
+			entryCopy.Adjustments[stage] = delta
+		}
+		breakdown.Entries[numaID] = &entryCopy
+	}
+	return breakdown
+}
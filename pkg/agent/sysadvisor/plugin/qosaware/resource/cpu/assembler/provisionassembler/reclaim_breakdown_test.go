@@ -0,0 +1,130 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestReclaimBreakdown(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+	conf.ReclaimCeiling = 12
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("8"),
+				2: machine.MustParse("16"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 24, 3, []*v1.Pod{makeTestPod("uid1")})
+
+	dedicated := newFakeDedicatedRegion("dedicated-r", 0, "uid1", 4)
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 3},
+		},
+	}
+	isolation := &fakeRegion{
+		name:          "isolation-r",
+		regionType:    types.QoSRegionTypeIsolation,
+		ownerPoolName: "isolation-r",
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSizeUpper: {Value: 2},
+			types.ControlKnobNonReclaimedCPUSizeLower: {Value: 1},
+		},
+	}
+	bindingIsolation := &fakeRegion{
+		name:          "binding-isolation-r",
+		regionType:    types.QoSRegionTypeIsolation,
+		ownerPoolName: "binding-isolation-r",
+		bindingNumas:  machine.NewCPUSet(2),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSizeUpper: {Value: 3},
+			types.ControlKnobNonReclaimedCPUSizeLower: {Value: 1},
+		},
+	}
+	regionMap := map[string]region.QoSRegion{
+		dedicated.Name(): dedicated, share.Name(): share, isolation.Name(): isolation, bindingIsolation.Name(): bindingIsolation,
+	}
+	reservedForReclaim := map[int]int{0: 2, 1: 2, 2: 2}
+	numaAvailable := map[int]int{0: 7, 1: 7, 2: 7}
+	nonBindingNumas := machine.NewCPUSet(1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, newFakeMetricEmitter())
+	result, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+
+	breakdown := pa.ReclaimBreakdown()
+	assert.Equal(t, ReclaimBreakdownEntry{
+		Available: 7, NonReclaimed: 4, ReservedForReclaim: 2,
+		Adjustments: map[string]int{reclaimAdjustmentCeiling: -1}, Reclaim: 4,
+	}, *breakdown.Entries[0])
+	assert.Equal(t, ReclaimBreakdownEntry{
+		Available: 7, SharePools: 3, IsolationPools: 2, ReservedForReclaim: 2,
+		Adjustments: map[string]int{reclaimAdjustmentCeiling: -1}, Reclaim: 3,
+	}, *breakdown.Entries[cpuadvisor.FakedNUMAID])
+	assert.Equal(t, ReclaimBreakdownEntry{
+		Available: 7, IsolationPools: 3, ReservedForReclaim: 2,
+		Adjustments: map[string]int{reclaimAdjustmentCeiling: -2}, Reclaim: 4,
+	}, *breakdown.Entries[2])
+
+	// breakdown of each entry and aggregate reconciles to reclaim pool entries
+	assert.Equal(t, len(result.PoolEntries[state.PoolNameReclaim]), len(breakdown.Entries))
+	total := 0
+	for numaID, size := range result.PoolEntries[state.PoolNameReclaim] {
+		entry := breakdown.Entries[numaID]
+		require.NotNil(t, entry, "numa %v", numaID)
+		assert.Equal(t, size, entry.Reclaim, "numa %v", numaID)
+		assertReclaimBreakdownReconciled(t, *entry)
+		total += size
+	}
+	aggregate := breakdown.Aggregate()
+	assert.Equal(t, total, aggregate.Reclaim)
+	assertReclaimBreakdownReconciled(t, aggregate)
+	assert.Equal(t, "available 21 - non-reclaimed 4 - share 3 - isolation 5 + reserved-for-reclaim 6 + handed-off 0 + "+
+		"adjustments [ceiling=-4] = reclaim 11", aggregate.String())
+}
+
+func assertReclaimBreakdownReconciled(t *testing.T, entry ReclaimBreakdownEntry) {
+	adjusted := entry.Available - entry.NonReclaimed - entry.SharePools - entry.IsolationPools + entry.ReservedForReclaim + entry.HandedOff
+	for _, delta := range entry.Adjustments {
+		adjusted += delta
+	}
+	assert.Equal(t, entry.Reclaim, adjusted)
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	metricCPUProvisionReclaimCriticalReservation      = "cpu_provision_reclaim_critical_reservation"
+	metricCPUProvisionReclaimCriticalReservationUnmet = "cpu_provision_reclaim_critical_reservation_unmet"
+)
+
+// carveReclaimCriticalReservation carves the reclaim critical reservation out of available resource of numas
+// allowed to reclaim in ascending order, before the reclaim reservation and pools are sized; the unmet part is emitted.
+// numas being drained are skipped, so that their reclaim is still drained to zero
+func (pa *ProvisionAssemblerCommon) carveReclaimCriticalReservation() {
+	// reset both reservations before reading available resource, which excludes those of the last assembling otherwise;
+	// the reclaim reservation is carved again afterwards
+	pa.adjustments.reclaimCriticalReservation = make(map[int]int)
+	pa.adjustments.reclaimReservation = make(map[int]int)
+
+	remaining := pa.assemblerConf.ReclaimCriticalReservationSize
+	if remaining <= 0 {
+		return
+	}
+
+	for _, numaID := range pa.getReclaimEligibleNumas().ToSliceInt() {
+		if remaining <= 0 {
+			break
+		}
+		if _, ok := pa.numaReclaimDrains[numaID]; ok {
+			continue
+		}
+		available, ok := pa.availability.GetNumaAvailable(numaID)
+		if !ok || available <= 0 {
+			continue
+		}
+		reserved := general.Min(available, remaining)
+		pa.adjustments.reclaimCriticalReservation[numaID] = reserved
+		remaining -= reserved
+	}
+
+	if remaining > 0 {
+		pa.logger.Warningf("[qosaware-cpu] reclaim critical reservation of size %v is unmet by %v: carved %v",
+			pa.assemblerConf.ReclaimCriticalReservationSize, remaining, pa.adjustments.reclaimCriticalReservation)
+	}
+	_ = pa.emitter.StoreInt64(metricCPUProvisionReclaimCriticalReservationUnmet, int64(remaining), metrics.MetricTypeNameRaw)
+}
+
+// applyReclaimCriticalReservation adds the reclaim critical reservation carved out of each numa to its reclaim pool
+// entry after all adjustments squeezing reclaim, so that it's kept even if general reclaim is squeezed to zero
+func (pa *ProvisionAssemblerCommon) applyReclaimCriticalReservation(calculationResult *types.InternalCPUCalculationResult) {
+	pa.addCarvedReclaim(calculationResult, pa.adjustments.reclaimCriticalReservation)
+
+	carved := 0
+	for _, reserved := range pa.adjustments.reclaimCriticalReservation {
+		carved += reserved
+	}
+	_ = pa.emitter.StoreInt64(metricCPUProvisionReclaimCriticalReservation, int64(carved), metrics.MetricTypeNameRaw)
+}
+
+// ReclaimCriticalReservation returns the reclaim critical reservation applied to reclaim pool of the last
+// successful assembling in total, which is part of reclaim pool but never donatable to general batch
+func (pa *ProvisionAssemblerCommon) ReclaimCriticalReservation() int {
+	pa.mutex.Lock()
+	defer pa.mutex.Unlock()
+
+	applied := 0
+	for _, entry := range pa.reclaimBreakdown.Entries {
+		applied += entry.Adjustments[reclaimAdjustmentCriticalReservation]
+	}
+	return applied
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestAssembleProvisionReclaimCriticalReservation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                string
+		criticalReservation int
+		drainNumas          []int
+		reclaimCeiling      int
+		wantSharePool       int
+		wantReclaimEntry    map[int]int
+		wantCritical        int
+		wantCriticalUnmet   int64
+		// wantAppliedReserved is reserved for reclaim applied besides the critical reservation
+		wantAppliedReserved int
+	}{
+		{
+			name:                "no critical reservation",
+			wantSharePool:       7,
+			wantReclaimEntry:    map[int]int{0: 3, cpuadvisor.FakedNUMAID: 1},
+			wantAppliedReserved: 2,
+		},
+		{
+			name:                "critical reservation carved out of general reclaim first",
+			criticalReservation: 2,
+			wantSharePool:       7,
+			wantReclaimEntry:    map[int]int{0: 3, cpuadvisor.FakedNUMAID: 1},
+			wantCritical:        2,
+			wantAppliedReserved: 2,
+		},
+		{
+			name:                "critical reservation moved off drained numa",
+			criticalReservation: 2,
+			drainNumas:          []int{0},
+			wantSharePool:       5,
+			wantReclaimEntry:    map[int]int{0: 0, cpuadvisor.FakedNUMAID: 3},
+			wantCritical:        2,
+			wantAppliedReserved: 1,
+		},
+		{
+			name:                "critical reservation unmet with all numas drained",
+			criticalReservation: 2,
+			drainNumas:          []int{0, 1},
+			wantSharePool:       7,
+			wantReclaimEntry:    map[int]int{0: 0, cpuadvisor.FakedNUMAID: 0},
+			wantCriticalUnmet:   2,
+		},
+		{
+			name:                "critical reservation kept with general reclaim capped by ceiling",
+			criticalReservation: 2,
+			reclaimCeiling:      1,
+			wantSharePool:       7,
+			wantReclaimEntry:    map[int]int{0: 2, cpuadvisor.FakedNUMAID: 0},
+			wantCritical:        2,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+			conf.ReclaimCriticalReservationSize = tt.criticalReservation
+			conf.ReclaimCeiling = tt.reclaimCeiling
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{})
+			metaServer := generateTestMetaServer(t, 16, 2, []*v1.Pod{makeTestPod("uid0")})
+			emitter := newFakeMetricEmitter()
+
+			// share pool requires all available resource of the non-binding numa, and is squeezed by the critical
+			// reservation carved out of the numa
+			regions := []*fakeRegion{
+				newFakeDedicatedRegion("dedicated-0", 0, "uid0", 5),
+				{
+					name:          "share",
+					regionType:    types.QoSRegionTypeShare,
+					ownerPoolName: state.PoolNameShare,
+					bindingNumas:  machine.NewCPUSet(1),
+					controlKnob: types.ControlKnob{
+						types.ControlKnobNonReclaimedCPUSize: {Value: 7, Action: types.ControlKnobActionNone},
+					},
+				},
+			}
+			regionMap := make(map[string]region.QoSRegion)
+			for _, r := range regions {
+				regionMap[r.Name()] = r
+			}
+			reservedForReclaim := map[int]int{0: 1, 1: 1}
+			numaAvailable := map[int]int{0: 7, 1: 7}
+			nonBindingNumas := machine.NewCPUSet(1)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, emitter)
+			for _, numaID := range tt.drainNumas {
+				require.NoError(t, pa.DrainNumaReclaim(numaID, 0))
+			}
+
+			// critical reservation is carved out consistently across consecutive assembling
+			for i := 0; i < 2; i++ {
+				result, _, err := pa.AssembleProvision()
+				require.NoError(t, err)
+
+				sharePool, _ := result.GetPoolEntry(state.PoolNameShare, cpuadvisor.FakedNUMAID)
+				assert.Equal(t, tt.wantSharePool, sharePool)
+				assert.Equal(t, tt.wantReclaimEntry, result.PoolEntries[state.PoolNameReclaim])
+			}
+			assert.Equal(t, tt.wantCritical, pa.ReclaimCriticalReservation())
+			// reserved for reclaim and the critical reservation on the same numa never count the same cores twice
+			assert.Equal(t, tt.wantAppliedReserved, pa.AppliedReservedForReclaim())
+			totalReclaim := 0
+			for _, size := range tt.wantReclaimEntry {
+				totalReclaim += size
+			}
+			assert.LessOrEqual(t, pa.AppliedReservedForReclaim()+pa.ReclaimCriticalReservation(), totalReclaim)
+			if tt.criticalReservation > 0 {
+				assert.Equal(t, tt.wantCriticalUnmet, emitter.get(metricCPUProvisionReclaimCriticalReservationUnmet)[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"strconv"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+const (
+	metricCPUProvisionReclaimFairnessTrimmed = "cpu_provision_reclaim_fairness_trimmed"
+)
+
+// rebalanceReclaimPoolForFairness trims reclaim pool entries of binding numas exceeding the least one by more
+// than ReclaimFairnessMaxSkew, since entries below can't be raised beyond what their numas are able to donate;
+// entries are never trimmed below reserved for reclaim of their numas, including overrides of dedicated regions.
+func (pa *ProvisionAssemblerCommon) rebalanceReclaimPoolForFairness(calculationResult *types.InternalCPUCalculationResult) {
+	if !pa.assemblerConf.EnableReclaimFairness {
+		return
+	}
+
+	reclaimPoolSizes := calculationResult.PoolEntries[pa.assemblerConf.ReclaimPoolName]
+	bindingNumas := make([]int, 0, len(reclaimPoolSizes))
+	for numaID := range reclaimPoolSizes {
+		if numaID != cpuadvisor.FakedNUMAID && !pa.nonBindingNumas.Contains(numaID) {
+			bindingNumas = append(bindingNumas, numaID)
+		}
+	}
+	if len(bindingNumas) < 2 {
+		return
+	}
+
+	least := reclaimPoolSizes[bindingNumas[0]]
+	for _, numaID := range bindingNumas {
+		least = general.Min(least, reclaimPoolSizes[numaID])
+	}
+	fairLimit := least + pa.assemblerConf.ReclaimFairnessMaxSkew
+
+	floors := pa.getBindingNumasReservedForReclaim()
+	for _, numaID := range bindingNumas {
+		size := reclaimPoolSizes[numaID]
+		limit := general.Max(fairLimit, floors[numaID])
+		if size <= limit {
+			continue
+		}
+
+		pa.logger.Infof("[qosaware-cpu] trim reclaim pool on numa %v for fairness: size %v, least %v, limit %v",
+			numaID, size, least, limit)
+		reclaimPoolSizes[numaID] = limit
+		_ = pa.emitter.StoreInt64(metricCPUProvisionReclaimFairnessTrimmed, int64(size-limit), metrics.MetricTypeNameRaw,
+			metrics.MetricTag{Key: "numa_id", Val: strconv.Itoa(numaID)})
+	}
+}
+
+// getBindingNumasReservedForReclaim returns reserved for reclaim on each numa, taking overrides of
+// dedicated regions into account
+func (pa *ProvisionAssemblerCommon) getBindingNumasReservedForReclaim() map[int]int {
+	reserved := make(map[int]int)
+	for numaID := range pa.resolvedReservedForReclaim {
+		reserved[numaID] = pa.getNumasReservedForReclaim(machine.NewCPUSet(numaID))
+	}
+	for _, r := range *pa.regionMap {
+		if r.Type() != types.QoSRegionTypeDedicatedNumaExclusive {
+			continue
+		}
+		if numaIDs := r.GetBindingNumas().ToSliceInt(); len(numaIDs) == 1 {
+			reserved[numaIDs[0]] = pa.getRegionReservedForReclaim(r)
+		}
+	}
+	return reserved
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestAssembleProvisionReclaimFairness(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		enableFairness bool
+		maxSkew        int
+		overrides      map[string]int
+		wantReclaim    map[int]int
+	}{
+		{
+			name:        "imbalanced donations are kept by default",
+			wantReclaim: map[int]int{0: 7, 1: 2, 2: 4},
+		},
+		{
+			name:           "generous numas are trimmed within max skew",
+			enableFairness: true,
+			maxSkew:        2,
+			wantReclaim:    map[int]int{0: 4, 1: 2, 2: 4},
+		},
+		{
+			name:           "numas are equalized to the least one without skew",
+			enableFairness: true,
+			wantReclaim:    map[int]int{0: 2, 1: 2, 2: 2},
+		},
+		{
+			name:           "numas are never trimmed below reserved for reclaim",
+			enableFairness: true,
+			overrides:      map[string]int{"dedicated-r0": 5},
+			wantReclaim:    map[int]int{0: 5, 1: 2, 2: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+			conf.EnableReclaimFairness = tt.enableFairness
+			conf.ReclaimFairnessMaxSkew = tt.maxSkew
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{})
+			metaServer := generateTestMetaServer(t, 32, 4, []*v1.Pod{makeTestPod("uid0"), makeTestPod("uid1"), makeTestPod("uid2")})
+
+			// dedicated regions of different tenants donate 6, 1 and 3 cores besides reserved for reclaim
+			r0 := newFakeDedicatedRegion("dedicated-r0", 0, "uid0", 1)
+			r1 := newFakeDedicatedRegion("dedicated-r1", 1, "uid1", 6)
+			r2 := newFakeDedicatedRegion("dedicated-r2", 2, "uid2", 4)
+			regionMap := map[string]region.QoSRegion{r0.Name(): r0, r1.Name(): r1, r2.Name(): r2}
+			reservedForReclaim := map[int]int{0: 1, 1: 1, 2: 1, 3: 1}
+			numaAvailable := map[int]int{0: 7, 1: 7, 2: 7, 3: 7}
+			nonBindingNumas := machine.NewCPUSet(3)
+			emitter := newFakeMetricEmitter()
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, emitter)
+			for regionName, reserved := range tt.overrides {
+				require.NoError(t, pa.SetRegionReservedForReclaim(regionName, reserved))
+			}
+			result, _, err := pa.AssembleProvision()
+			require.NoError(t, err)
+
+			for numaID, want := range tt.wantReclaim {
+				assert.Equal(t, want, result.PoolEntries[state.PoolNameReclaim][numaID], "numa %v", numaID)
+			}
+			// non-binding numas are not involved in fairness
+			assert.Equal(t, 8, result.PoolEntries[state.PoolNameReclaim][cpuadvisor.FakedNUMAID])
+			if tt.enableFairness {
+				assert.NotEmpty(t, emitter.get(metricCPUProvisionReclaimFairnessTrimmed))
+			} else {
+				assert.Empty(t, emitter.get(metricCPUProvisionReclaimFairnessTrimmed))
+			}
+		})
+	}
+}
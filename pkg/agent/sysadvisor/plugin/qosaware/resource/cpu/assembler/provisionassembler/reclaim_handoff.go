@@ -0,0 +1,50 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+const (
+	metricCPUProvisionReservedForReclaimHandOff = "cpu_provision_reserved_for_reclaim_hand_off"
+)
+
+// handOffReservedForReclaim returns the part of reserved for reclaim lost on saturated numas that is handed off
+// to non-binding numas, so that reserved reclaim guarantee is preserved node-wide; the hand-off is bounded by
+// available resource of the non-binding numas allowed to reclaim, and the rest of the deficit is still lost.
+func (pa *ProvisionAssemblerCommon) handOffReservedForReclaim(deficit int, nonBindingReclaimNumas machine.CPUSet) int {
+	if !pa.assemblerConf.EnableReservedForReclaimHandOff || pa.assemblerConf.DisableNonBindingReclaim || deficit <= 0 {
+		return 0
+	}
+
+	handedOff := general.Max(general.Min(deficit, getNumasAvailableResource(pa.availability, nonBindingReclaimNumas)), 0)
+	if handedOff < deficit {
+		pa.logger.Warningf("[qosaware-cpu] reserved for reclaim deficit %v is partially handed off to numas %v: %v",
+			deficit, nonBindingReclaimNumas.String(), handedOff)
+	} else {
+		pa.logger.Infof("[qosaware-cpu] reserved for reclaim deficit %v is handed off to numas %v", deficit, nonBindingReclaimNumas.String())
+	}
+
+	_ = pa.emitter.StoreInt64(metricCPUProvisionReservedForReclaimHandOff, int64(handedOff), metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "kind", Val: "handed_off"})
+	_ = pa.emitter.StoreInt64(metricCPUProvisionReservedForReclaimHandOff, int64(deficit-handedOff), metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "kind", Val: "lost"})
+	return handedOff
+}
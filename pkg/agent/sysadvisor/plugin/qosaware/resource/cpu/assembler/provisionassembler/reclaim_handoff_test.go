@@ -0,0 +1,125 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestAssembleProvisionReservedForReclaimHandOff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		enableHandOff     bool
+		shareRequirement  float64
+		wantSharePoolSize int
+		wantReclaimSize   int
+		wantHandOff       []int64
+	}{
+		{
+			name:              "hand off disabled",
+			shareRequirement:  10,
+			wantSharePoolSize: 6,
+			wantReclaimSize:   1,
+		},
+		{
+			name:              "deficit fully covered by non-binding numa",
+			enableHandOff:     true,
+			shareRequirement:  10,
+			wantSharePoolSize: 4,
+			wantReclaimSize:   3,
+			wantHandOff:       []int64{2, 0},
+		},
+		{
+			name:              "deficit covered by existing slack",
+			enableHandOff:     true,
+			shareRequirement:  2,
+			wantSharePoolSize: 2,
+			wantReclaimSize:   5,
+			wantHandOff:       []int64{2, 0},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+			conf.EnableReservedForReclaimHandOff = tt.enableHandOff
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+				state.PoolNameReserve: {
+					PoolName: state.PoolNameReserve,
+					TopologyAwareAssignments: map[int]machine.CPUSet{
+						0: machine.MustParse("0"),
+						1: machine.MustParse("8"),
+					},
+				},
+			})
+			metaServer := generateTestMetaServer(t, 16, 2, []*v1.Pod{makeTestPod("uid1")})
+			emitter := newFakeMetricEmitter()
+
+			// dedicated pod saturates numa 0, leaving its reserved for reclaim unsatisfiable
+			dedicated := newFakeDedicatedRegion("dedicated-r", 0, "uid1", 10)
+			share := &fakeRegion{
+				name:          "share-r",
+				regionType:    types.QoSRegionTypeShare,
+				ownerPoolName: state.PoolNameShare,
+				bindingNumas:  machine.NewCPUSet(1),
+				controlKnob: types.ControlKnob{
+					types.ControlKnobNonReclaimedCPUSize: {Value: tt.shareRequirement},
+				},
+			}
+			regionMap := map[string]region.QoSRegion{dedicated.Name(): dedicated, share.Name(): share}
+			reservedForReclaim := map[int]int{0: 2, 1: 1}
+			numaAvailable := map[int]int{0: 6, 1: 6}
+			nonBindingNumas := machine.NewCPUSet(1)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, emitter)
+			result, _, err := pa.AssembleProvision()
+			require.NoError(t, err)
+
+			reclaimPoolSize, ok := result.GetPoolEntry(state.PoolNameReclaim, 0)
+			assert.True(t, ok)
+			assert.Equal(t, 0, reclaimPoolSize)
+
+			sharePoolSize, ok := result.GetPoolEntry(state.PoolNameShare, cpuadvisor.FakedNUMAID)
+			assert.True(t, ok)
+			assert.Equal(t, tt.wantSharePoolSize, sharePoolSize)
+
+			reclaimPoolSize, ok = result.GetPoolEntry(state.PoolNameReclaim, cpuadvisor.FakedNUMAID)
+			assert.True(t, ok)
+			assert.Equal(t, tt.wantReclaimSize, reclaimPoolSize)
+
+			assert.Equal(t, tt.wantHandOff, emitter.get(metricCPUProvisionReservedForReclaimHandOff))
+		})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+const (
+	metricCPUProvisionReclaimPressure       = "cpu_provision_reclaim_pressure"
+	metricCPUProvisionReclaimPressureAdjust = "cpu_provision_reclaim_pressure_adjust"
+)
+
+// applyReclaimPressureFeedback adjusts reclaim and share pool entries of non-binding numas by the
+// pressure of reclaim pool: sustained high pressure holds back the growth of reclaim pool and keeps
+// it in share pool as buffer, while sustained low pressure moves idle cores of share pool to reclaim.
+func (pa *ProvisionAssemblerCommon) applyReclaimPressureFeedback(calculationResult *types.InternalCPUCalculationResult,
+	shareAndIsolatePoolSizes map[string]int, nodeEnableReclaim bool) {
+	if !pa.assemblerConf.EnableReclaimPressureFeedback || !nodeEnableReclaim {
+		return
+	}
+
+	pressure, err := pa.getReclaimPressure()
+	if err != nil {
+		pa.logger.Warningf("[qosaware-cpu] skip reclaim pressure feedback: %v", err)
+		pa.reclaimPoolState.highPressureCycles, pa.reclaimPoolState.lowPressureCycles = 0, 0
+		return
+	}
+	_ = pa.emitter.StoreFloat64(metricCPUProvisionReclaimPressure, pressure, metrics.MetricTypeNameRaw)
+
+	switch {
+	case pressure >= pa.assemblerConf.ReclaimPressureHighThreshold:
+		pa.reclaimPoolState.highPressureCycles++
+		pa.reclaimPoolState.lowPressureCycles = 0
+	case pressure <= pa.assemblerConf.ReclaimPressureLowThreshold:
+		pa.reclaimPoolState.lowPressureCycles++
+		pa.reclaimPoolState.highPressureCycles = 0
+	default:
+		pa.reclaimPoolState.highPressureCycles, pa.reclaimPoolState.lowPressureCycles = 0, 0
+	}
+
+	reclaimPoolSize, ok := calculationResult.GetPoolEntry(pa.assemblerConf.ReclaimPoolName, cpuadvisor.FakedNUMAID)
+	if !ok {
+		return
+	}
+	sharePoolSize, ok := calculationResult.GetPoolEntry(state.PoolNameShare, cpuadvisor.FakedNUMAID)
+	if !ok {
+		return
+	}
+
+	delta := 0
+	if pa.reclaimPoolState.highPressureCycles >= pa.assemblerConf.ReclaimPressureSustainedCycles && pa.reclaimPoolState.highPressureCycles > 0 {
+		// hold back additional slack donated to reclaim pool since the last assembling
+		if lastSize, ok := pa.reclaimPoolState.lastSizes[cpuadvisor.FakedNUMAID]; ok && reclaimPoolSize > lastSize {
+			delta = lastSize - reclaimPoolSize
+		}
+	} else if pa.reclaimPoolState.lowPressureCycles >= pa.assemblerConf.ReclaimPressureSustainedCycles && pa.reclaimPoolState.lowPressureCycles > 0 {
+		// move idle cores of share pool to reclaim pool, and keep at least the used ones
+		usage := pa.getSharePoolUsage()
+		if sharePoolSize > 0 && usage/float64(sharePoolSize) <= pa.assemblerConf.SharePoolIdleRatio {
+			movable := sharePoolSize - general.Max(int(math.Ceil(usage)), 1)
+			delta = general.Max(general.Min(pa.assemblerConf.ReclaimPressureAdjustStep, movable), 0)
+		}
+	}
+	if delta == 0 {
+		return
+	}
+
+	pa.logger.Infof("[qosaware-cpu] adjust reclaim pool by pressure %.2f: reclaim %v -> %v, share %v -> %v",
+		pressure, reclaimPoolSize, reclaimPoolSize+delta, sharePoolSize, sharePoolSize-delta)
+	calculationResult.SetPoolEntryExplicitly(pa.assemblerConf.ReclaimPoolName, cpuadvisor.FakedNUMAID, reclaimPoolSize+delta)
+	calculationResult.SetPoolEntry(state.PoolNameShare, cpuadvisor.FakedNUMAID, sharePoolSize-delta)
+	shareAndIsolatePoolSizes[state.PoolNameShare] = sharePoolSize - delta
+	_ = pa.emitter.StoreInt64(metricCPUProvisionReclaimPressureAdjust, int64(delta), metrics.MetricTypeNameRaw)
+}
+
+// getReclaimPressure returns 1-min load of reclaim cgroup per core of current reclaim pool
+func (pa *ProvisionAssemblerCommon) getReclaimPressure() (float64, error) {
+	reclaimPoolSize, ok := pa.metaReader.GetPoolSize(pa.assemblerConf.ReclaimPoolName)
+	if !ok || reclaimPoolSize <= 0 {
+		return 0, fmt.Errorf("reclaim pool is empty or not found")
+	}
+
+	load, err := pa.metaServer.GetCgroupMetric(pa.assemblerConf.ReclaimRelativeRootCgroupPath, consts.MetricLoad1MinCgroup)
+	if err != nil {
+		return 0, fmt.Errorf("get load of reclaim cgroup failed: %v", err)
+	}
+	return load.Value / float64(reclaimPoolSize), nil
+}
+
+// getSharePoolUsage returns the sum of cpu usage of containers in share pool
+func (pa *ProvisionAssemblerCommon) getSharePoolUsage() float64 {
+	usage := 0.0
+	pa.metaReader.RangeContainer(func(podUID string, containerName string, ci *types.ContainerInfo) bool {
+		if ci.OwnerPoolName != state.PoolNameShare {
+			return true
+		}
+		m, err := pa.metaServer.GetContainerMetric(podUID, containerName, consts.MetricCPUUsageContainer)
+		if err != nil {
+			pa.logger.Warningf("[qosaware-cpu] get cpu usage of %v/%v failed: %v", podUID, containerName, err)
+			return true
+		}
+		usage += m.Value
+		return true
+	})
+	return usage
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/metric"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+	utilmetric "github.com/kubewharf/katalyst-core/pkg/util/metric"
+)
+
+func TestAssembleProvisionReclaimPressureFeedback(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		reclaimLoad float64
+		wantReclaim int
+		wantShare   int
+	}{
+		{
+			name:        "neutral pressure donates slack to reclaim",
+			reclaimLoad: 8,
+			wantReclaim: 16,
+			wantShare:   2,
+		},
+		{
+			name:        "high pressure holds back slack in share pool",
+			reclaimLoad: 16,
+			wantReclaim: 14,
+			wantShare:   4,
+		},
+		{
+			name:        "low pressure moves idle share cores to reclaim",
+			reclaimLoad: 2,
+			wantReclaim: 17,
+			wantShare:   1,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			now := time.Now()
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+			conf.EnableReclaimPressureFeedback = true
+			conf.ReclaimPressureHighThreshold = 1.5
+			conf.ReclaimPressureLowThreshold = 0.5
+			conf.ReclaimPressureSustainedCycles = 1
+			conf.ReclaimPressureAdjustStep = 2
+			conf.SharePoolIdleRatio = 0.3
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+				state.PoolNameReclaim: {
+					PoolName: state.PoolNameReclaim,
+					TopologyAwareAssignments: map[int]machine.CPUSet{
+						0: machine.MustParse("0-3"),
+						1: machine.MustParse("8-11"),
+					},
+				},
+			})
+			require.NoError(t, metaCache.SetContainerInfo("uid1", "c1", &types.ContainerInfo{
+				PodUID:        "uid1",
+				ContainerName: "c1",
+				OwnerPoolName: state.PoolNameShare,
+			}))
+
+			metricsFetcher := metric.NewFakeMetricsFetcher(metrics.DummyMetrics{}).(*metric.FakeMetricsFetcher)
+			metricsFetcher.SetContainerMetric("uid1", "c1", consts.MetricCPUUsageContainer, utilmetric.MetricData{Value: 0.2, Time: &now})
+			metaServer := generateTestMetaServer(t, 16, 2, nil)
+			metaServer.MetricsFetcher = metricsFetcher
+
+			share := &fakeRegion{
+				name:          "share-r",
+				regionType:    types.QoSRegionTypeShare,
+				ownerPoolName: state.PoolNameShare,
+				bindingNumas:  machine.NewCPUSet(0, 1),
+				controlKnob: types.ControlKnob{
+					types.ControlKnobNonReclaimedCPUSize: {Value: 4},
+				},
+			}
+			regionMap := map[string]region.QoSRegion{share.Name(): share}
+			reservedForReclaim := map[int]int{0: 1, 1: 1}
+			numaAvailable := map[int]int{0: 8, 1: 8}
+			nonBindingNumas := machine.NewCPUSet(0, 1)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, metrics.DummyMetrics{})
+
+			// baseline under neutral pressure
+			metricsFetcher.SetCgroupMetric(conf.ReclaimRelativeRootCgroupPath, consts.MetricLoad1MinCgroup, utilmetric.MetricData{Value: 8, Time: &now})
+			result, _, err := pa.AssembleProvision()
+			require.NoError(t, err)
+			assert.Equal(t, 14, result.PoolEntries[state.PoolNameReclaim][cpuadvisor.FakedNUMAID])
+			assert.Equal(t, 4, result.PoolEntries[state.PoolNameShare][cpuadvisor.FakedNUMAID])
+
+			// share requirement drops and leaves more slack
+			share.controlKnob[types.ControlKnobNonReclaimedCPUSize] = types.ControlKnobValue{Value: 2}
+			metricsFetcher.SetCgroupMetric(conf.ReclaimRelativeRootCgroupPath, consts.MetricLoad1MinCgroup, utilmetric.MetricData{Value: tt.reclaimLoad, Time: &now})
+			result, _, err = pa.AssembleProvision()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantReclaim, result.PoolEntries[state.PoolNameReclaim][cpuadvisor.FakedNUMAID])
+			assert.Equal(t, tt.wantShare, result.PoolEntries[state.PoolNameShare][cpuadvisor.FakedNUMAID])
+		})
+	}
+}
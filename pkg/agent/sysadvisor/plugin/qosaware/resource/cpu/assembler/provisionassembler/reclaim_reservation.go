@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+const (
+	metricCPUProvisionReclaimReservationUnmet = "cpu_provision_reclaim_reservation_unmet"
+)
+
+// reclaimReservedAvailability excludes reclaim reservations carved out of each numa, i.e. the reclaim reservation
+// or the reclaim critical reservation, from its available resource, so that share, isolation and dedicated pools
+// are sized without them
+type reclaimReservedAvailability struct {
+	AvailabilityProvider
+	reserved *map[int]int
+}
+
+var _ AvailabilityProvider = &reclaimReservedAvailability{}
+
+func (a *reclaimReservedAvailability) GetNumaAvailable(numaID int) (int, bool) {
+	available, ok := a.AvailabilityProvider.GetNumaAvailable(numaID)
+	if !ok {
+		return available, false
+	}
+	return available - (*a.reserved)[numaID], true
+}
+
+// carveReclaimReservation carves the reclaim reservation out of available resource of numas allowed to reclaim,
+// preferred numas first and then others in ascending order, before pools are sized; the unmet part is emitted
+func (pa *ProvisionAssemblerCommon) carveReclaimReservation() {
+	// reset before reading available resource, which excludes the reservation of the last assembling otherwise
+	pa.adjustments.reclaimReservation = make(map[int]int)
+
+	remaining := pa.assemblerConf.ReclaimReservationSize
+	if remaining <= 0 {
+		return
+	}
+
+	eligibleNumas := pa.getReclaimEligibleNumas()
+	orderedNumas := make([]int, 0, eligibleNumas.Size())
+	for _, numaID := range pa.assemblerConf.ReclaimReservationPreferredNumas {
+		if eligibleNumas.Contains(numaID) {
+			orderedNumas = append(orderedNumas, numaID)
+			eligibleNumas = eligibleNumas.Difference(machine.NewCPUSet(numaID))
+		}
+	}
+	orderedNumas = append(orderedNumas, eligibleNumas.ToSliceInt()...)
+
+	for _, numaID := range orderedNumas {
+		if remaining <= 0 {
+			break
+		}
+		available, ok := pa.availability.GetNumaAvailable(numaID)
+		if !ok || available <= 0 {
+			continue
+		}
+		reserved := general.Min(available, remaining)
+		pa.adjustments.reclaimReservation[numaID] = reserved
+		remaining -= reserved
+	}
+
+	if remaining > 0 {
+		pa.logger.Warningf("[qosaware-cpu] reclaim reservation %v of size %v is unmet by %v: carved %v",
+			pa.assemblerConf.ReclaimReservationName, pa.assemblerConf.ReclaimReservationSize, remaining, pa.adjustments.reclaimReservation)
+	}
+	_ = pa.emitter.StoreInt64(metricCPUProvisionReclaimReservationUnmet, int64(remaining), metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "reservation_name", Val: pa.assemblerConf.ReclaimReservationName})
+}
+
+// getReclaimEligibleNumas returns numas allowed to reclaim, i.e. those not excluded from reclaim, and
+// binding ones only if reclaim of non-binding numas is disabled
+func (pa *ProvisionAssemblerCommon) getReclaimEligibleNumas() machine.CPUSet {
+	eligibleNumas := pa.metaServer.CPUDetails.NUMANodes().Difference(machine.NewCPUSet(pa.assemblerConf.ExcludedReclaimNumas...))
+	if pa.assemblerConf.DisableNonBindingReclaim {
+		eligibleNumas = eligibleNumas.Difference(*pa.nonBindingNumas)
+	}
+	return eligibleNumas
+}
+
+// applyReclaimReservation adds the reclaim reservation carved out of each numa to its reclaim pool entry
+func (pa *ProvisionAssemblerCommon) applyReclaimReservation(calculationResult *types.InternalCPUCalculationResult) {
+	pa.addCarvedReclaim(calculationResult, pa.adjustments.reclaimReservation)
+}
+
+// addCarvedReclaim adds reclaim carved out of each numa to its reclaim pool entry, where non-binding numas
+// without their own entries share the entry of cpuadvisor.FakedNUMAID
+func (pa *ProvisionAssemblerCommon) addCarvedReclaim(calculationResult *types.InternalCPUCalculationResult, carved map[int]int) {
+	for numaID, reserved := range carved {
+		entryID := numaID
+		if _, ok := calculationResult.GetPoolEntry(pa.assemblerConf.ReclaimPoolName, numaID); !ok && pa.nonBindingNumas.Contains(numaID) {
+			entryID = cpuadvisor.FakedNUMAID
+		}
+		size, _ := calculationResult.GetPoolEntry(pa.assemblerConf.ReclaimPoolName, entryID)
+		calculationResult.SetPoolEntryExplicitly(pa.assemblerConf.ReclaimPoolName, entryID, size+reserved)
+	}
+}
@@ -0,0 +1,133 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestAssembleProvisionReclaimReservation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		reservationSize  int
+		preferredNumas   []int
+		wantSharePool    int
+		wantReclaimEntry map[int]int
+		wantUnmet        int64
+	}{
+		{
+			name:             "no reservation on loaded node",
+			wantSharePool:    7,
+			wantReclaimEntry: map[int]int{0: 3, cpuadvisor.FakedNUMAID: 1},
+		},
+		{
+			name:             "reservation carved out of preferred non-binding numa first",
+			reservationSize:  4,
+			preferredNumas:   []int{1},
+			wantSharePool:    3,
+			wantReclaimEntry: map[int]int{0: 3, cpuadvisor.FakedNUMAID: 5},
+		},
+		{
+			name:             "reservation carved out of preferred dedicated numa first",
+			reservationSize:  2,
+			preferredNumas:   []int{0},
+			wantSharePool:    7,
+			wantReclaimEntry: map[int]int{0: 3, cpuadvisor.FakedNUMAID: 1},
+		},
+		{
+			name:             "reservation unmet",
+			reservationSize:  16,
+			preferredNumas:   []int{1},
+			wantReclaimEntry: map[int]int{0: 7, cpuadvisor.FakedNUMAID: 8},
+			wantUnmet:        2,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+			conf.ReclaimReservationName = "high-priority-batch"
+			conf.ReclaimReservationSize = tt.reservationSize
+			conf.ReclaimReservationPreferredNumas = tt.preferredNumas
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+				state.PoolNameReserve: {
+					PoolName: state.PoolNameReserve,
+					TopologyAwareAssignments: map[int]machine.CPUSet{
+						0: machine.MustParse("0"),
+						1: machine.MustParse("8"),
+					},
+				},
+			})
+			metaServer := generateTestMetaServer(t, 16, 2, []*v1.Pod{makeTestPod("uid0")})
+			emitter := newFakeMetricEmitter()
+
+			// share pool requires all available resource of the non-binding numa
+			regions := []*fakeRegion{
+				newFakeDedicatedRegion("dedicated-0", 0, "uid0", 5),
+				{
+					name:          "share",
+					regionType:    types.QoSRegionTypeShare,
+					ownerPoolName: state.PoolNameShare,
+					bindingNumas:  machine.NewCPUSet(1),
+					controlKnob: types.ControlKnob{
+						types.ControlKnobNonReclaimedCPUSize: {Value: 7, Action: types.ControlKnobActionNone},
+					},
+				},
+			}
+			regionMap := make(map[string]region.QoSRegion)
+			for _, r := range regions {
+				regionMap[r.Name()] = r
+			}
+			reservedForReclaim := map[int]int{0: 1, 1: 1}
+			numaAvailable := map[int]int{0: 7, 1: 7}
+			nonBindingNumas := machine.NewCPUSet(1)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, emitter)
+
+			// reservation is carved out consistently across consecutive assembling
+			for i := 0; i < 2; i++ {
+				result, _, err := pa.AssembleProvision()
+				require.NoError(t, err)
+
+				sharePool, _ := result.GetPoolEntry(state.PoolNameShare, cpuadvisor.FakedNUMAID)
+				assert.Equal(t, tt.wantSharePool, sharePool)
+				assert.Equal(t, tt.wantReclaimEntry, result.PoolEntries[state.PoolNameReclaim])
+			}
+			if tt.reservationSize > 0 {
+				assert.Equal(t, tt.wantUnmet, emitter.get(metricCPUProvisionReclaimReservationUnmet)[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"math"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+// smoothReclaimPool low-pass filters the growth of each reclaim pool entry with the configured time constant,
+// so that advertised reclaim capacity follows oscillating slack smoothly; entries shrinking below the filtered
+// values take effect immediately for safety, and entries without history are not filtered.
+func (pa *ProvisionAssemblerCommon) smoothReclaimPool(calculationResult *types.InternalCPUCalculationResult) {
+	timeConstant := pa.assemblerConf.ReclaimSmoothingTimeConstant
+	if timeConstant <= 0 {
+		pa.reclaimPoolState.smoothedSizes = make(map[int]float64)
+		return
+	}
+
+	now := pa.clock.Now()
+	// weight of the target in this round, which approaches 1 as the elapsed time grows
+	alpha := 0.0
+	if elapsed := now.Sub(pa.reclaimPoolState.smoothedAt); !pa.reclaimPoolState.smoothedAt.IsZero() && elapsed > 0 {
+		alpha = 1 - math.Exp(-elapsed.Seconds()/timeConstant.Seconds())
+	}
+	pa.reclaimPoolState.smoothedAt = now
+
+	reclaimPoolSizes := calculationResult.PoolEntries[pa.assemblerConf.ReclaimPoolName]
+	smoothedReclaimPoolSizes := make(map[int]float64, len(reclaimPoolSizes))
+	for numaID, size := range reclaimPoolSizes {
+		target := float64(size)
+		smoothed, ok := pa.reclaimPoolState.smoothedSizes[numaID]
+		if !ok || target <= smoothed {
+			smoothedReclaimPoolSizes[numaID] = target
+			continue
+		}
+
+		smoothed += alpha * (target - smoothed)
+		// the filter approaches target asymptotically, so snap to it once within a core
+		if target-smoothed < 1 {
+			smoothed = target
+		}
+		smoothedReclaimPoolSizes[numaID] = smoothed
+
+		if filtered := int(math.Floor(smoothed)); filtered < size {
+			pa.logger.Infof("[qosaware-cpu] smooth reclaim pool growth on numa %v: target %v, filtered %v", numaID, size, filtered)
+			reclaimPoolSizes[numaID] = filtered
+		}
+	}
+	pa.reclaimPoolState.smoothedSizes = smoothedReclaimPoolSizes
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	testingclock "k8s.io/utils/clock/testing"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestAssembleProvisionReclaimSmoothing(t *testing.T) {
+	t.Parallel()
+
+	// noisy share pool requirements, making reclaim pool oscillate
+	requirements := []float64{4, 10, 3, 9, 2, 10, 4, 8, 3, 9}
+
+	assembleReclaim := func(timeConstant time.Duration) ([]int, *ProvisionAssemblerCommon, *fakeRegion) {
+		conf := generateTestConfiguration(t)
+		conf.GetDynamicConfiguration().EnableReclaim = true
+		conf.ReclaimSmoothingTimeConstant = timeConstant
+
+		metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{})
+		metaServer := generateTestMetaServer(t, 16, 2, nil)
+
+		share := &fakeRegion{
+			name:          "share-r",
+			regionType:    types.QoSRegionTypeShare,
+			ownerPoolName: state.PoolNameShare,
+			bindingNumas:  machine.NewCPUSet(0, 1),
+			controlKnob:   types.ControlKnob{},
+		}
+		regionMap := map[string]region.QoSRegion{share.Name(): share}
+		reservedForReclaim := map[int]int{0: 1, 1: 1}
+		numaAvailable := map[int]int{0: 8, 1: 8}
+		nonBindingNumas := machine.NewCPUSet(0, 1)
+
+		pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+			metaCache, metaServer, metrics.DummyMetrics{}).(*ProvisionAssemblerCommon)
+		fakeClock := testingclock.NewFakePassiveClock(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+		pa.clock = fakeClock
+
+		var reclaimSizes []int
+		for _, requirement := range requirements {
+			share.controlKnob[types.ControlKnobNonReclaimedCPUSize] = types.ControlKnobValue{Value: requirement}
+			result, _, err := pa.AssembleProvision()
+			require.NoError(t, err)
+			reclaimSizes = append(reclaimSizes, result.PoolEntries[state.PoolNameReclaim][cpuadvisor.FakedNUMAID])
+			fakeClock.SetTime(fakeClock.Now().Add(10 * time.Second))
+		}
+		return reclaimSizes, pa, share
+	}
+
+	variation := func(sizes []int) int {
+		res := 0
+		for i := 1; i < len(sizes); i++ {
+			if sizes[i] > sizes[i-1] {
+				res += sizes[i] - sizes[i-1]
+			} else {
+				res += sizes[i-1] - sizes[i]
+			}
+		}
+		return res
+	}
+
+	raw, _, _ := assembleReclaim(0)
+	smoothed, pa, share := assembleReclaim(time.Minute)
+
+	// smoothed output changes less, and never exceeds the raw one since shrinking passes immediately
+	assert.Less(t, variation(smoothed), variation(raw), "raw %v, smoothed %v", raw, smoothed)
+	assert.Equal(t, raw[0], smoothed[0])
+	for i := range raw {
+		assert.LessOrEqual(t, smoothed[i], raw[i], "cycle %v", i)
+		if i > 0 && raw[i] < smoothed[i-1] {
+			assert.Equal(t, raw[i], smoothed[i], "cycle %v", i)
+		}
+	}
+
+	// filter state is dropped on reset, and the next entry is not filtered
+	pa.Reset()
+	assert.Empty(t, pa.reclaimPoolState.smoothedSizes)
+	share.controlKnob[types.ControlKnobNonReclaimedCPUSize] = types.ControlKnobValue{Value: requirements[4]}
+	result, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+	assert.Equal(t, raw[4], result.PoolEntries[state.PoolNameReclaim][cpuadvisor.FakedNUMAID])
+}
@@ -0,0 +1,126 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	testingclock "k8s.io/utils/clock/testing"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/adminqos/reclaimedresource"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestAssembleProvisionReclaimSuppression(t *testing.T) {
+	t.Parallel()
+
+	// 2024-01-03 is a Wednesday
+	wednesday := time.Date(2024, 1, 3, 0, 0, 0, 0, time.Local)
+
+	tests := []struct {
+		name              string
+		now               time.Time
+		wantReclaimSize   int
+		wantSharePoolSize int
+	}{
+		{
+			name:              "inside business hours window",
+			now:               wednesday.Add(10 * time.Hour),
+			wantReclaimSize:   2,
+			wantSharePoolSize: 12,
+		},
+		{
+			name:              "outside business hours window",
+			now:               wednesday.Add(20 * time.Hour),
+			wantReclaimSize:   10,
+			wantSharePoolSize: 4,
+		},
+		{
+			name:              "inside window spanning midnight on the next day",
+			now:               wednesday.Add(3*24*time.Hour + time.Hour),
+			wantReclaimSize:   2,
+			wantSharePoolSize: 12,
+		},
+		{
+			name:              "outside window spanning midnight on weekend",
+			now:               wednesday.Add(4*24*time.Hour + time.Hour),
+			wantReclaimSize:   10,
+			wantSharePoolSize: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+			for _, windowStr := range []string{"mon-fri@09:00-18:00", "fri@22:00-06:00"} {
+				window, err := reclaimedresource.ParseReclaimSuppressionWindow(windowStr)
+				require.NoError(t, err)
+				conf.GetDynamicConfiguration().ReclaimSuppressionWindows = append(conf.GetDynamicConfiguration().ReclaimSuppressionWindows, window)
+			}
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+				state.PoolNameReserve: {
+					PoolName: state.PoolNameReserve,
+					TopologyAwareAssignments: map[int]machine.CPUSet{
+						0: machine.MustParse("0"),
+						1: machine.MustParse("8"),
+					},
+				},
+			})
+			metaServer := generateTestMetaServer(t, 16, 2, nil)
+
+			share := &fakeRegion{
+				name:          "share-r",
+				regionType:    types.QoSRegionTypeShare,
+				ownerPoolName: state.PoolNameShare,
+				bindingNumas:  machine.NewCPUSet(0, 1),
+				controlKnob: types.ControlKnob{
+					types.ControlKnobNonReclaimedCPUSize: {Value: 4},
+				},
+			}
+			regionMap := map[string]region.QoSRegion{share.Name(): share}
+			reservedForReclaim := map[int]int{0: 1, 1: 1}
+			numaAvailable := map[int]int{0: 6, 1: 6}
+			nonBindingNumas := machine.NewCPUSet(0, 1)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, newFakeMetricEmitter()).(*ProvisionAssemblerCommon)
+			pa.clock = testingclock.NewFakePassiveClock(tt.now)
+
+			result, _, err := pa.AssembleProvision()
+			require.NoError(t, err)
+
+			reclaimSize, ok := result.GetPoolEntry(state.PoolNameReclaim, cpuadvisor.FakedNUMAID)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantReclaimSize, reclaimSize)
+			sharePoolSize, ok := result.GetPoolEntry(state.PoolNameShare, cpuadvisor.FakedNUMAID)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantSharePoolSize, sharePoolSize)
+		})
+	}
+}
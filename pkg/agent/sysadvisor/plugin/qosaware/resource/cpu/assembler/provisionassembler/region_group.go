@@ -0,0 +1,76 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+// regulatePoolSizesByGroups regulates pool sizes as RegulatePoolSizes does, except that pools in region groups
+// are regulated against their group budget: each group is allocated its budget first (or its requirement if
+// smaller and reclaim is enabled), and pools in the group are then regulated within the allocation, so that
+// groups never take resource from each other; pools not in any group are regulated against what is left.
+func regulatePoolSizesByGroups(sizes map[string]int, available int, enableReclaim bool, priorities map[string]int,
+	utilizations map[string]float64, groups map[string]cpu.RegionGroup) (map[string]int, bool) {
+	if len(groups) == 0 {
+		return regulatePoolSizes(sizes, available, enableReclaim, priorities, utilizations)
+	}
+
+	ungroupedSizes := general.MergeMapInt(sizes, nil)
+	groupedSizes := make(map[string]map[string]int)
+	groupAllocations := make(map[string]int)
+	for groupName, group := range groups {
+		memberSizes := make(map[string]int)
+		for poolName, size := range sizes {
+			if group.OwnerPoolNames.Has(poolName) {
+				memberSizes[poolName] = size
+				delete(ungroupedSizes, poolName)
+			}
+		}
+		if len(memberSizes) == 0 {
+			continue
+		}
+
+		allocation := group.Budget
+		if enableReclaim {
+			allocation = general.Min(general.SumUpMapValues(memberSizes), group.Budget)
+		}
+		groupedSizes[groupName] = memberSizes
+		groupAllocations[groupName] = allocation
+	}
+
+	// groups are shrunk proportionally only if their allocations exceed available in total
+	groupAllocations, _ = RegulatePoolSizes(groupAllocations, available, true)
+
+	poolSizes := make(map[string]int, len(sizes))
+	for groupName, memberSizes := range groupedSizes {
+		regulated, _ := regulatePoolSizes(memberSizes, groupAllocations[groupName], enableReclaim, priorities, utilizations)
+		for poolName, size := range regulated {
+			poolSizes[poolName] = size
+		}
+	}
+
+	ungroupedAvailable := available - general.SumUpMapValues(poolSizes)
+	regulated, _ := regulatePoolSizes(ungroupedSizes, ungroupedAvailable, enableReclaim, priorities, utilizations)
+	for poolName, size := range regulated {
+		poolSizes[poolName] = size
+	}
+
+	boundUpper := enableReclaim && general.SumUpMapValues(sizes) >= available
+	return poolSizes, boundUpper
+}
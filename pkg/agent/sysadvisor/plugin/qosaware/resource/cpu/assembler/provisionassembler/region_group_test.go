@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu"
+)
+
+func TestRegulatePoolSizesByGroups(t *testing.T) {
+	t.Parallel()
+
+	groups := map[string]cpu.RegionGroup{
+		"tenant-a": {OwnerPoolNames: sets.NewString("share-a1", "share-a2"), Budget: 6},
+		"tenant-b": {OwnerPoolNames: sets.NewString("share-b"), Budget: 6},
+	}
+
+	tests := []struct {
+		name           string
+		sizes          map[string]int
+		available      int
+		enableReclaim  bool
+		groups         map[string]cpu.RegionGroup
+		wantSizes      map[string]int
+		wantBoundUpper bool
+	}{
+		{
+			name:           "no group falls back to global regulation",
+			sizes:          map[string]int{"share-a1": 6, "share-a2": 3, "share": 3},
+			available:      8,
+			enableReclaim:  true,
+			wantSizes:      map[string]int{"share-a1": 4, "share-a2": 2, "share": 2},
+			wantBoundUpper: true,
+		},
+		{
+			name:           "group over budget is shrunk without taking from group under budget",
+			sizes:          map[string]int{"share-a1": 6, "share-a2": 6, "share-b": 2, "share": 4},
+			available:      20,
+			enableReclaim:  true,
+			groups:         groups,
+			wantSizes:      map[string]int{"share-a1": 3, "share-a2": 3, "share-b": 2, "share": 4},
+			wantBoundUpper: false,
+		},
+		{
+			name:           "ungrouped pools are regulated against what is left by groups",
+			sizes:          map[string]int{"share-a1": 6, "share-a2": 6, "share-b": 2, "share": 4, "isolation": 4},
+			available:      12,
+			enableReclaim:  true,
+			groups:         groups,
+			wantSizes:      map[string]int{"share-a1": 3, "share-a2": 3, "share-b": 2, "share": 2, "isolation": 2},
+			wantBoundUpper: true,
+		},
+		{
+			name:           "groups are expanded to budget with reclaim disabled",
+			sizes:          map[string]int{"share-a1": 1, "share-a2": 1, "share-b": 2, "share": 4},
+			available:      20,
+			enableReclaim:  false,
+			groups:         groups,
+			wantSizes:      map[string]int{"share-a1": 3, "share-a2": 3, "share-b": 6, "share": 8},
+			wantBoundUpper: false,
+		},
+		{
+			name:           "groups are shrunk proportionally if budgets exceed available",
+			sizes:          map[string]int{"share-a1": 6, "share-a2": 6, "share-b": 6},
+			available:      8,
+			enableReclaim:  true,
+			groups:         groups,
+			wantSizes:      map[string]int{"share-a1": 2, "share-a2": 2, "share-b": 4},
+			wantBoundUpper: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sizes, boundUpper := regulatePoolSizesByGroups(tt.sizes, tt.available, tt.enableReclaim, nil, nil, tt.groups)
+			assert.Equal(t, tt.wantSizes, sizes)
+			assert.Equal(t, tt.wantBoundUpper, boundUpper)
+		})
+	}
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"fmt"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+)
+
+// SetRegionReservedForReclaim overrides reserved for reclaim on the binding numa of the dedicated numa exclusive
+// region, e.g. for a tenant guaranteed minimum batch capacity alongside their dedicated service; the override takes
+// precedence over node-level reserved for reclaim of the numa, and it's kept until cleared or the region is gone
+func (pa *ProvisionAssemblerCommon) SetRegionReservedForReclaim(regionName string, reserved int) error {
+	if reserved < 0 {
+		return fmt.Errorf("reserved for reclaim override of region %v must not be negative", regionName)
+	}
+
+	pa.mutex.Lock()
+	defer pa.mutex.Unlock()
+
+	pa.regionState.reservedForReclaim[regionName] = reserved
+	pa.logger.Infof("[qosaware-cpu] override reserved for reclaim of region %v: %v", regionName, reserved)
+	return nil
+}
+
+// ClearRegionReservedForReclaim clears the reserved for reclaim override of the region
+func (pa *ProvisionAssemblerCommon) ClearRegionReservedForReclaim(regionName string) {
+	pa.mutex.Lock()
+	defer pa.mutex.Unlock()
+
+	if _, ok := pa.regionState.reservedForReclaim[regionName]; ok {
+		delete(pa.regionState.reservedForReclaim, regionName)
+		pa.logger.Infof("[qosaware-cpu] clear reserved for reclaim override of region %v", regionName)
+	}
+}
+
+// getRegionReservedForReclaim returns reserved for reclaim on binding numas of the region, i.e. the override
+// of the region if any, otherwise node-level reserved for reclaim of the numas
+func (pa *ProvisionAssemblerCommon) getRegionReservedForReclaim(r region.QoSRegion) int {
+	if reserved, ok := pa.regionState.reservedForReclaim[r.Name()]; ok {
+		return reserved
+	}
+	return pa.getNumasReservedForReclaim(r.GetBindingNumas())
+}
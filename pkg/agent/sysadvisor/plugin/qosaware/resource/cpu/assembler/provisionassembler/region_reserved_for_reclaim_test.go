@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestAssembleProvisionRegionReservedForReclaim(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name              string
+		nodeEnableReclaim bool
+		requirement       float64
+	}{
+		{
+			name:              "saturated numa with reclaim enabled",
+			nodeEnableReclaim: true,
+			requirement:       6,
+		},
+		{
+			name:              "reclaim disabled",
+			nodeEnableReclaim: false,
+			requirement:       2,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = tt.nodeEnableReclaim
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{})
+			metaServer := generateTestMetaServer(t, 16, 2, []*v1.Pod{makeTestPod("uid1")})
+
+			r := newFakeDedicatedRegion("dedicated-r", 0, "uid1", tt.requirement)
+			regionMap := map[string]region.QoSRegion{r.Name(): r}
+			reservedForReclaim := map[int]int{0: 1, 1: 1}
+			numaAvailable := map[int]int{0: 6, 1: 6}
+			nonBindingNumas := machine.NewCPUSet(1)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, metrics.DummyMetrics{})
+			result, _, err := pa.AssembleProvision()
+			require.NoError(t, err)
+			nonBindingReclaim := result.PoolEntries[state.PoolNameReclaim][cpuadvisor.FakedNUMAID]
+			assertReclaim := func(want int) {
+				result, _, err := pa.AssembleProvision()
+				require.NoError(t, err)
+				assert.Equal(t, want, result.PoolEntries[state.PoolNameReclaim][0])
+				assert.Equal(t, nonBindingReclaim, result.PoolEntries[state.PoolNameReclaim][cpuadvisor.FakedNUMAID])
+			}
+
+			// node default
+			assertReclaim(1)
+
+			// override of the region raises reclaim floor of its numa only
+			assert.Error(t, pa.SetRegionReservedForReclaim(r.Name(), -1))
+			require.NoError(t, pa.SetRegionReservedForReclaim(r.Name(), 4))
+			assertReclaim(4)
+			assert.Equal(t, map[int]int{0: 1, 1: 1}, reservedForReclaim)
+
+			// override is kept across resetting
+			pa.Reset()
+			assertReclaim(4)
+
+			pa.ClearRegionReservedForReclaim(r.Name())
+			assertReclaim(1)
+		})
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const (
+	metricCPUProvisionRegionProvisionStale = "cpu_provision_region_provision_stale"
+)
+
+// regionProvisionChange records the last provision of a region and when it is changed
+type regionProvisionChange struct {
+	controlKnob types.ControlKnob
+	changedAt   time.Time
+}
+
+// checkRegionProvisionStaleness records when provision of the region is last changed, and emits metric
+// if it keeps unchanged longer than RegionProvisionStalenessThreshold although the region is refreshed
+// in each assembling, which helps to tell a stuck region updater from a stable workload.
+func (pa *ProvisionAssemblerCommon) checkRegionProvisionStaleness(r region.QoSRegion, controlKnob types.ControlKnob) {
+	threshold := pa.assemblerConf.RegionProvisionStalenessThreshold
+	if threshold <= 0 {
+		return
+	}
+
+	now := pa.clock.Now()
+	change, ok := pa.regionState.provisionChanges[r.Name()]
+	if !ok || !reflect.DeepEqual(change.controlKnob, controlKnob) {
+		pa.regionState.provisionChanges[r.Name()] = &regionProvisionChange{controlKnob: controlKnob.Clone(), changedAt: now}
+		return
+	}
+
+	unchanged := now.Sub(change.changedAt)
+	if unchanged <= threshold {
+		return
+	}
+
+	pa.logger.Warningf("[qosaware-cpu] provision of region %v keeps unchanged since %v, exceeding staleness threshold %v",
+		r.Name(), change.changedAt, threshold)
+	_ = pa.emitter.StoreInt64(metricCPUProvisionRegionProvisionStale, int64(unchanged.Seconds()), metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "region_name", Val: r.Name()},
+		metrics.MetricTag{Key: "region_type", Val: string(r.Type())})
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	testingclock "k8s.io/utils/clock/testing"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestAssembleProvisionRegionProvisionStaleness(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+	conf.RegionProvisionStalenessThreshold = time.Minute
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("8"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 16, 2, nil)
+
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(0, 1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 4},
+		},
+	}
+	regionMap := map[string]region.QoSRegion{share.Name(): share}
+	reservedForReclaim := map[int]int{0: 1, 1: 1}
+	numaAvailable := map[int]int{0: 6, 1: 6}
+	nonBindingNumas := machine.NewCPUSet(0, 1)
+
+	emitter := newFakeMetricEmitter()
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, emitter).(*ProvisionAssemblerCommon)
+	now := time.Now()
+	fakeClock := testingclock.NewFakePassiveClock(now)
+	pa.clock = fakeClock
+
+	// identical provision within threshold is regarded as stable
+	for i := 0; i <= 6; i++ {
+		fakeClock.SetTime(now.Add(time.Duration(i) * 10 * time.Second))
+		_, _, err := pa.AssembleProvision()
+		require.NoError(t, err)
+	}
+	assert.Empty(t, emitter.get(metricCPUProvisionRegionProvisionStale))
+
+	// identical provision beyond threshold is reported as stale in each cycle
+	for i := 7; i <= 9; i++ {
+		fakeClock.SetTime(now.Add(time.Duration(i) * 10 * time.Second))
+		_, _, err := pa.AssembleProvision()
+		require.NoError(t, err)
+	}
+	assert.Equal(t, []int64{70, 80, 90}, emitter.get(metricCPUProvisionRegionProvisionStale))
+	assert.Equal(t, map[string]string{"region_name": "share-r", "region_type": string(types.QoSRegionTypeShare)},
+		emitter.getTags(metricCPUProvisionRegionProvisionStale)[0])
+
+	// changed provision resets staleness
+	share.controlKnob = types.ControlKnob{
+		types.ControlKnobNonReclaimedCPUSize: {Value: 6},
+	}
+	fakeClock.SetTime(now.Add(100 * time.Second))
+	_, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+	fakeClock.SetTime(now.Add(110 * time.Second))
+	_, _, err = pa.AssembleProvision()
+	require.NoError(t, err)
+	assert.Len(t, emitter.get(metricCPUProvisionRegionProvisionStale), 3)
+}
@@ -0,0 +1,139 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"strconv"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const (
+	metricCPUProvisionReservePoolHeldBack = "cpu_provision_reserve_pool_held_back"
+	metricCPUProvisionSubReserveSize      = "cpu_provision_sub_reserve_size"
+)
+
+// reserveAdjustedAvailability gives the held back growth of reserve pool back to numa available resource,
+// since available resource provided by caller has excluded the whole (target) reserve pool already; and if
+// reserve pool is composed of sub-reserves, the difference from reserve pool in metacache is given back too,
+// and so is reserve pool clamped to leave a minimum working set on node
+type reserveAdjustedAvailability struct {
+	AvailabilityProvider
+	heldBack      *map[int]int
+	composedDelta *map[int]int
+	clamped       *map[int]int
+}
+
+var _ AvailabilityProvider = &reserveAdjustedAvailability{}
+
+func (a *reserveAdjustedAvailability) GetNumaAvailable(numaID int) (int, bool) {
+	available, ok := a.AvailabilityProvider.GetNumaAvailable(numaID)
+	if !ok {
+		return available, false
+	}
+	return available + (*a.heldBack)[numaID] + (*a.composedDelta)[numaID] + (*a.clamped)[numaID], true
+}
+
+// getReservePoolTargetSizes returns the target reserve pool size on each numa. By default, it's the reserve pool
+// in metacache; if ReservePoolComposition is configured, it's the sum of sub-reserves on each numa instead, and
+// if pod scaled reserve pool is enabled, it's scaled with pod count regardless of composition. In boot phase,
+// it's raised to the boot reserve pool at least. The difference from metacache is recorded to adjust numa
+// available resource.
+func (pa *ProvisionAssemblerCommon) getReservePoolTargetSizes() (map[int]int, bool) {
+	sizes, ok := pa.getConfiguredReservePoolTargetSizes()
+	return pa.applyBootReservePool(sizes, ok)
+}
+
+// getConfiguredReservePoolTargetSizes returns the target reserve pool size on each numa regardless of boot phase
+func (pa *ProvisionAssemblerCommon) getConfiguredReservePoolTargetSizes() (map[int]int, bool) {
+	pa.adjustments.reserveComposedDelta = make(map[int]int)
+
+	reservePoolSizes := make(map[int]int)
+	reservePoolInfo, ok := pa.metaReader.GetPoolInfo(state.PoolNameReserve)
+	if ok && reservePoolInfo != nil {
+		for numaID, cpuset := range reservePoolInfo.TopologyAwareAssignments {
+			reservePoolSizes[numaID] = cpuset.Size()
+		}
+	}
+	if podScaledSizes, scaled := pa.getPodScaledReservePoolSizes(); scaled {
+		for numaID, size := range podScaledSizes {
+			pa.adjustments.reserveComposedDelta[numaID] = reservePoolSizes[numaID] - size
+		}
+		return podScaledSizes, true
+	}
+	if len(pa.assemblerConf.ReservePoolComposition) == 0 {
+		return reservePoolSizes, ok && reservePoolInfo != nil
+	}
+
+	composedSize := 0
+	numaIDs := pa.metaServer.CPUDetails.NUMANodes().ToSliceInt()
+	for name, size := range pa.assemblerConf.ReservePoolComposition {
+		composedSize += size
+		_ = pa.emitter.StoreInt64(metricCPUProvisionSubReserveSize, int64(size*len(numaIDs)), metrics.MetricTypeNameRaw,
+			metrics.MetricTag{Key: "name", Val: name})
+	}
+
+	composedSizes := make(map[int]int, len(numaIDs))
+	for _, numaID := range numaIDs {
+		composedSizes[numaID] = composedSize
+		pa.adjustments.reserveComposedDelta[numaID] = reservePoolSizes[numaID] - composedSize
+	}
+	return composedSizes, true
+}
+
+// limitReservePoolGrowth returns the effective reserve pool size, which grows toward the target (either from
+// metacache or composed of sub-reserves) by at most
+// ReservePoolGrowthStep on each numa between consecutive updates to avoid shrinking reclaim pool abruptly;
+// shrinking is not limited, and the held back growth is recorded to be added back to numa available resource.
+func (pa *ProvisionAssemblerCommon) limitReservePoolGrowth() int {
+	step := pa.assemblerConf.ReservePoolGrowthStep
+	pa.adjustments.reservePoolHeldBack = make(map[int]int)
+
+	if step <= 0 && len(pa.assemblerConf.ReservePoolComposition) == 0 && !pa.assemblerConf.EnablePodScaledReservePool &&
+		!pa.inBootPhase() {
+		pa.adjustments.reserveComposedDelta = make(map[int]int)
+		pa.reservePoolState.lastSizes = make(map[int]int)
+		reservePoolSize, _ := pa.metaReader.GetPoolSize(state.PoolNameReserve)
+		return reservePoolSize
+	}
+
+	targetSizes, ok := pa.getReservePoolTargetSizes()
+	if !ok {
+		pa.reservePoolState.lastSizes = make(map[int]int)
+		return 0
+	}
+
+	reservePoolSize := 0
+	lastReservePoolSizes := make(map[int]int)
+	for numaID, size := range targetSizes {
+		if lastSize, ok := pa.reservePoolState.lastSizes[numaID]; ok && step > 0 && size > lastSize+step {
+			pa.logger.Infof("[qosaware-cpu] limit reserve pool growing on numa %v: last %v, target %v, step %v",
+				numaID, lastSize, size, step)
+			pa.adjustments.reservePoolHeldBack[numaID] = size - lastSize - step
+			size = lastSize + step
+		}
+		_ = pa.emitter.StoreInt64(metricCPUProvisionReservePoolHeldBack, int64(pa.adjustments.reservePoolHeldBack[numaID]), metrics.MetricTypeNameRaw,
+			metrics.MetricTag{Key: "numa_id", Val: strconv.Itoa(numaID)})
+
+		lastReservePoolSizes[numaID] = size
+		reservePoolSize += size
+	}
+	pa.reservePoolState.lastSizes = lastReservePoolSizes
+
+	return reservePoolSize
+}
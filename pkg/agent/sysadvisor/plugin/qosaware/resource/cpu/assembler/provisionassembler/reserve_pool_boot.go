@@ -0,0 +1,95 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+)
+
+const (
+	metricCPUProvisionBootPhase = "cpu_provision_boot_phase"
+)
+
+// inBootPhase returns whether the node is still in boot phase, which ends once the node has BootPhaseMinPods
+// healthy pods or the assembler has been up for BootPhaseMinUptime, and never comes back after that
+func (pa *ProvisionAssemblerCommon) inBootPhase() bool {
+	if pa.assemblerConf.BootReservePoolSize <= 0 || pa.reservePoolState.bootPhaseEnded {
+		return false
+	}
+
+	now := pa.clock.Now()
+	if pa.reservePoolState.bootPhaseStartedAt.IsZero() {
+		pa.reservePoolState.bootPhaseStartedAt = now
+	}
+
+	uptime := now.Sub(pa.reservePoolState.bootPhaseStartedAt)
+	healthyPods := pa.getHealthyPodCount()
+	minPods, minUptime := pa.assemblerConf.BootPhaseMinPods, pa.assemblerConf.BootPhaseMinUptime
+	if (minPods <= 0 && minUptime <= 0) || (minPods > 0 && healthyPods >= minPods) || (minUptime > 0 && uptime >= minUptime) {
+		pa.logger.Infof("[qosaware-cpu] boot phase ended: healthy pods %v, uptime %v", healthyPods, uptime)
+		pa.reservePoolState.bootPhaseEnded = true
+		_ = pa.emitter.StoreInt64(metricCPUProvisionBootPhase, 0, metrics.MetricTypeNameRaw)
+		return false
+	}
+
+	_ = pa.emitter.StoreInt64(metricCPUProvisionBootPhase, 1, metrics.MetricTypeNameRaw)
+	return true
+}
+
+// getHealthyPodCount returns the number of active and ready pods on node, and zero if pods are unavailable
+func (pa *ProvisionAssemblerCommon) getHealthyPodCount() int {
+	if pa.metaServer == nil {
+		return 0
+	}
+	pods, err := pa.metaServer.GetPodList(context.Background(), func(pod *v1.Pod) bool {
+		return native.PodIsActive(pod) && native.PodIsReady(pod)
+	})
+	if err != nil {
+		pa.logger.Errorf("[qosaware-cpu] get pod list for boot phase failed: %v", err)
+		return 0
+	}
+	return len(pods)
+}
+
+// applyBootReservePool raises target reserve pool on each numa to BootReservePoolSize in boot phase, even
+// if reserve pool in metacache is not initialized yet, and records the raise to adjust numa available resource
+func (pa *ProvisionAssemblerCommon) applyBootReservePool(sizes map[int]int, ok bool) (map[int]int, bool) {
+	if pa.metaServer == nil || !pa.inBootPhase() {
+		return sizes, ok
+	}
+
+	bootSize := pa.assemblerConf.BootReservePoolSize
+	bootSizes := make(map[int]int)
+	for _, numaID := range pa.metaServer.CPUDetails.NUMANodes().ToSliceInt() {
+		size := 0
+		if ok {
+			size = sizes[numaID]
+		}
+		if size < bootSize {
+			pa.logger.Infof("[qosaware-cpu] raise reserve pool on numa %v to %v in boot phase", numaID, bootSize)
+			pa.adjustments.reserveComposedDelta[numaID] -= bootSize - size
+			size = bootSize
+		}
+		bootSizes[numaID] = size
+	}
+	return bootSizes, true
+}
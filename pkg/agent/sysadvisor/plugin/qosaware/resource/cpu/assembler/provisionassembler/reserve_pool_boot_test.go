@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	testingclock "k8s.io/utils/clock/testing"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/pod"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestAssembleProvisionBootReservePool(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// endBootPhase lets the node pass boot phase thresholds
+		endBootPhase func(clock *testingclock.FakePassiveClock, podFetcher *pod.PodFetcherStub)
+	}{
+		{
+			name: "boot phase ended by uptime",
+			endBootPhase: func(clock *testingclock.FakePassiveClock, _ *pod.PodFetcherStub) {
+				clock.SetTime(clock.Now().Add(10 * time.Minute))
+			},
+		},
+		{
+			name: "boot phase ended by healthy pods",
+			endBootPhase: func(_ *testingclock.FakePassiveClock, podFetcher *pod.PodFetcherStub) {
+				podFetcher.PodList = []*v1.Pod{makeTestPod("uid1"), makeTestPod("uid2")}
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+			conf.BootReservePoolSize = 2
+			conf.BootPhaseMinPods = 2
+			conf.BootPhaseMinUptime = 10 * time.Minute
+
+			// reserve pool in metacache is not initialized on freshly booted node
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{})
+			metaServer := generateTestMetaServer(t, 96, 2, []*v1.Pod{makeTestPod("uid1")})
+			podFetcher := metaServer.PodFetcher.(*pod.PodFetcherStub)
+
+			share := &fakeRegion{
+				name:          "share-r",
+				regionType:    types.QoSRegionTypeShare,
+				ownerPoolName: state.PoolNameShare,
+				bindingNumas:  machine.NewCPUSet(0, 1),
+				controlKnob: types.ControlKnob{
+					types.ControlKnobNonReclaimedCPUSize: {Value: 4},
+				},
+			}
+			regionMap := map[string]region.QoSRegion{share.Name(): share}
+			reservedForReclaim := map[int]int{0: 0, 1: 0}
+			numaAvailable := map[int]int{0: 48, 1: 48}
+			nonBindingNumas := machine.NewCPUSet(0, 1)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, metrics.DummyMetrics{}).(*ProvisionAssemblerCommon)
+			fakeClock := testingclock.NewFakePassiveClock(time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC))
+			pa.clock = fakeClock
+
+			assertReserve := func(wantReserve, wantReclaim int) {
+				result, _, err := pa.AssembleProvision()
+				require.NoError(t, err)
+				reserve, _ := result.GetPoolEntry(state.PoolNameReserve, cpuadvisor.FakedNUMAID)
+				assert.Equal(t, wantReserve, reserve)
+				reclaim, ok := result.GetPoolEntry(state.PoolNameReclaim, cpuadvisor.FakedNUMAID)
+				require.True(t, ok)
+				assert.Equal(t, wantReclaim, reclaim)
+			}
+
+			// boot reserve is carved out of availability before thresholds are reached
+			assertReserve(4, 88)
+			fakeClock.SetTime(fakeClock.Now().Add(5 * time.Minute))
+			assertReserve(4, 88)
+
+			// normal reserve applies after boot phase ends, and boot phase never comes back
+			tt.endBootPhase(fakeClock, podFetcher)
+			assertReserve(0, 92)
+			podFetcher.PodList = nil
+			pa.Reset()
+			assertReserve(0, 92)
+		})
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"context"
+	"math"
+
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const (
+	metricCPUProvisionPodScaledReserveSize = "cpu_provision_pod_scaled_reserve_size"
+)
+
+// getPodScaledReservePoolSizes returns reserve pool size on each numa scaled with the number of pods on node,
+// i.e. the base plus per-pod increment times pod count (rounded up), capped at the max if it's positive;
+// false is returned if pod scaled reserve pool is disabled or pods are unavailable.
+func (pa *ProvisionAssemblerCommon) getPodScaledReservePoolSizes() (map[int]int, bool) {
+	if !pa.assemblerConf.EnablePodScaledReservePool || pa.metaServer == nil {
+		return nil, false
+	}
+
+	pods, err := pa.metaServer.GetPodList(context.Background(), nil)
+	if err != nil {
+		pa.logger.Errorf("[qosaware-cpu] get pod list for pod scaled reserve pool failed: %v", err)
+		return nil, false
+	}
+
+	size := pa.assemblerConf.PodScaledReservePoolBase +
+		int(math.Ceil(pa.assemblerConf.PodScaledReservePoolPerPod*float64(len(pods))))
+	if maxSize := pa.assemblerConf.PodScaledReservePoolMax; maxSize > 0 && size > maxSize {
+		size = maxSize
+	}
+	pa.logger.Infof("[qosaware-cpu] pod scaled reserve pool size on each numa: %v, pod count %v", size, len(pods))
+
+	numaIDs := pa.metaServer.CPUDetails.NUMANodes().ToSliceInt()
+	sizes := make(map[int]int, len(numaIDs))
+	for _, numaID := range numaIDs {
+		sizes[numaID] = size
+	}
+	_ = pa.emitter.StoreInt64(metricCPUProvisionPodScaledReserveSize, int64(size*len(numaIDs)), metrics.MetricTypeNameRaw)
+	return sizes, true
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestAssembleProvisionPodScaledReservePool(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		podCount    int
+		wantReserve int
+		wantReclaim int
+	}{
+		{
+			name:        "reserve pool scales with pods",
+			podCount:    10,
+			wantReserve: 4,
+			wantReclaim: 88,
+		},
+		{
+			name:        "reserve pool is capped at max",
+			podCount:    100,
+			wantReserve: 8,
+			wantReclaim: 84,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+			// composition is replaced by pod scaled reserve pool
+			conf.ReservePoolComposition = map[string]int{"system": 10}
+			conf.EnablePodScaledReservePool = true
+			conf.PodScaledReservePoolBase = 1
+			conf.PodScaledReservePoolPerPod = 0.05
+			conf.PodScaledReservePoolMax = 4
+
+			// reserve pool from qrm takes 1 core on each numa
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+				state.PoolNameReserve: {
+					PoolName: state.PoolNameReserve,
+					TopologyAwareAssignments: map[int]machine.CPUSet{
+						0: machine.MustParse("0"),
+						1: machine.MustParse("48"),
+					},
+				},
+			})
+			pods := make([]*v1.Pod, 0, tt.podCount)
+			for i := 0; i < tt.podCount; i++ {
+				pods = append(pods, makeTestPod(fmt.Sprintf("uid%v", i)))
+			}
+			metaServer := generateTestMetaServer(t, 96, 2, pods)
+			emitter := newFakeMetricEmitter()
+
+			share := &fakeRegion{
+				name:          "share-r",
+				regionType:    types.QoSRegionTypeShare,
+				ownerPoolName: state.PoolNameShare,
+				bindingNumas:  machine.NewCPUSet(0, 1),
+				controlKnob: types.ControlKnob{
+					types.ControlKnobNonReclaimedCPUSize: {Value: 4},
+				},
+			}
+			regionMap := map[string]region.QoSRegion{share.Name(): share}
+			reservedForReclaim := map[int]int{0: 0, 1: 0}
+			numaAvailable := map[int]int{0: 47, 1: 47}
+			nonBindingNumas := machine.NewCPUSet(0, 1)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, emitter)
+			result, _, err := pa.AssembleProvision()
+			require.NoError(t, err)
+
+			// scaled reserve pool is subtracted from availability before reclaim pool is derived
+			reserve, ok := result.GetPoolEntry(state.PoolNameReserve, cpuadvisor.FakedNUMAID)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantReserve, reserve)
+			reclaim, ok := result.GetPoolEntry(state.PoolNameReclaim, cpuadvisor.FakedNUMAID)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantReclaim, reclaim)
+			assert.Equal(t, 96, reserve+reclaim+4)
+			assert.Equal(t, []int64{int64(tt.wantReserve)}, emitter.get(metricCPUProvisionPodScaledReserveSize))
+		})
+	}
+}
@@ -0,0 +1,156 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestAssembleProvisionReservePoolGrowth(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+	conf.ReservePoolGrowthStep = 4
+
+	metaCache := generateTestMetaCache(t, conf, nil)
+	metaServer := generateTestMetaServer(t, 96, 2, nil)
+
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(0, 1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 4},
+		},
+	}
+	regionMap := map[string]region.QoSRegion{share.Name(): share}
+	reservedForReclaim := map[int]int{0: 0, 1: 0}
+	numaAvailable := map[int]int{}
+	nonBindingNumas := machine.NewCPUSet(0, 1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, metrics.DummyMetrics{})
+
+	// setReserve updates reserve pool and numa available resource in the same way as advisor
+	setReserve := func(numa0, numa1 string) {
+		require.NoError(t, metaCache.SetPoolInfo(state.PoolNameReserve, &types.PoolInfo{
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse(numa0),
+				1: machine.MustParse(numa1),
+			},
+		}))
+		numaAvailable[0] = 48 - machine.MustParse(numa0).Size()
+		numaAvailable[1] = 48 - machine.MustParse(numa1).Size()
+	}
+
+	assemble := func() (int, int) {
+		result, _, err := pa.AssembleProvision()
+		require.NoError(t, err)
+		reserve, ok := result.GetPoolEntry(state.PoolNameReserve, cpuadvisor.FakedNUMAID)
+		require.True(t, ok)
+		reclaim, ok := result.GetPoolEntry(state.PoolNameReclaim, cpuadvisor.FakedNUMAID)
+		require.True(t, ok)
+		share, ok := result.GetPoolEntry(state.PoolNameShare, cpuadvisor.FakedNUMAID)
+		require.True(t, ok)
+		assert.Equal(t, 96, reserve+reclaim+share)
+		return reserve, reclaim
+	}
+
+	setReserve("0-1", "48-49")
+	reserve, reclaim := assemble()
+	assert.Equal(t, 4, reserve)
+	assert.Equal(t, 88, reclaim)
+
+	// growing from 4 to 32 ramps up by at most 4 cores on each numa in each cycle
+	setReserve("0-15", "48-63")
+	for _, want := range []struct{ reserve, reclaim int }{{12, 80}, {20, 72}, {28, 64}, {32, 60}, {32, 60}} {
+		reserve, reclaim = assemble()
+		assert.Equal(t, want.reserve, reserve)
+		assert.Equal(t, want.reclaim, reclaim)
+	}
+
+	// shrinking is not limited
+	setReserve("0-1", "48-49")
+	reserve, reclaim = assemble()
+	assert.Equal(t, 4, reserve)
+	assert.Equal(t, 88, reclaim)
+}
+
+func TestAssembleProvisionReservePoolComposition(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+	conf.ReservePoolComposition = map[string]int{"system": 1, "kubelet": 2}
+
+	// reserve pool from qrm takes 1 core on each numa
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("48"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 96, 2, nil)
+	emitter := newFakeMetricEmitter()
+
+	share := &fakeRegion{
+		name:          "share-r",
+		regionType:    types.QoSRegionTypeShare,
+		ownerPoolName: state.PoolNameShare,
+		bindingNumas:  machine.NewCPUSet(0, 1),
+		controlKnob: types.ControlKnob{
+			types.ControlKnobNonReclaimedCPUSize: {Value: 4},
+		},
+	}
+	regionMap := map[string]region.QoSRegion{share.Name(): share}
+	reservedForReclaim := map[int]int{0: 0, 1: 0}
+	numaAvailable := map[int]int{0: 47, 1: 47}
+	nonBindingNumas := machine.NewCPUSet(0, 1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, emitter)
+	result, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+
+	// sub-reserves take 3 cores on each numa, i.e. 6 cores in total, which are subtracted from availability
+	reserve, ok := result.GetPoolEntry(state.PoolNameReserve, cpuadvisor.FakedNUMAID)
+	require.True(t, ok)
+	assert.Equal(t, 6, reserve)
+	reclaim, ok := result.GetPoolEntry(state.PoolNameReclaim, cpuadvisor.FakedNUMAID)
+	require.True(t, ok)
+	assert.Equal(t, 86, reclaim)
+	assert.Equal(t, 96, reserve+reclaim+4)
+
+	assert.ElementsMatch(t, []int64{2, 4}, emitter.get(metricCPUProvisionSubReserveSize))
+	assert.ElementsMatch(t, []map[string]string{{"name": "system"}, {"name": "kubelet"}}, emitter.getTags(metricCPUProvisionSubReserveSize))
+}
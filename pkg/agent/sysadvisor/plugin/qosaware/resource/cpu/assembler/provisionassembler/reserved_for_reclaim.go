@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"math"
+
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+// reservedForReclaimAdjustedAvailability gives the difference between reserved for reclaim provided by caller
+// and the resolved one back to numa available resource, since available resource provided by caller has
+// excluded reserved for reclaim provided by caller already
+type reservedForReclaimAdjustedAvailability struct {
+	AvailabilityProvider
+	delta *map[int]int
+}
+
+var _ AvailabilityProvider = &reservedForReclaimAdjustedAvailability{}
+
+func (a *reservedForReclaimAdjustedAvailability) GetNumaAvailable(numaID int) (int, bool) {
+	available, ok := a.AvailabilityProvider.GetNumaAvailable(numaID)
+	if !ok {
+		return available, false
+	}
+	return available + (*a.delta)[numaID], true
+}
+
+// resolveReservedForReclaim resolves reserved for reclaim of each numa for this assembling by the configured
+// percentage of numa capacity from metaserver, and overrides given numas with absolute values; reserved for
+// reclaim provided by caller is taken as is if neither is configured. It's resolved into the assembler's own
+// map, and the map provided by caller is never modified, so that removing the percentage or overrides at
+// runtime restores the provided values.
+func (pa *ProvisionAssemblerCommon) resolveReservedForReclaim() {
+	provided := make(map[int]int)
+	if pa.reservedForReclaim != nil {
+		for numaID, reserved := range *pa.reservedForReclaim {
+			provided[numaID] = reserved
+		}
+	}
+
+	resolved := provided
+	if (pa.assemblerConf.ReservedForReclaimPercentage > 0 || len(pa.assemblerConf.ReservedForReclaimOverrides) > 0) &&
+		pa.metaServer != nil {
+		resolved = make(map[int]int)
+		for _, numaID := range pa.metaServer.CPUDetails.NUMANodes().ToSliceInt() {
+			if pa.assemblerConf.ReservedForReclaimPercentage > 0 {
+				capacity := pa.metaServer.CPUDetails.CPUsInNUMANodes(numaID).Size()
+				resolved[numaID] = ResolveReservedByPercentage(capacity, pa.assemblerConf.ReservedForReclaimPercentage, pa.assemblerConf.ReservedForReclaimRoundUp)
+			} else if reserved, ok := provided[numaID]; ok {
+				resolved[numaID] = reserved
+			}
+			if reserved, ok := pa.assemblerConf.ReservedForReclaimOverrides[numaID]; ok {
+				resolved[numaID] = reserved
+			}
+		}
+	}
+
+	delta := make(map[int]int)
+	for numaID, reserved := range provided {
+		delta[numaID] += reserved
+	}
+	for numaID, reserved := range resolved {
+		delta[numaID] -= reserved
+	}
+	pa.resolvedReservedForReclaim = resolved
+	pa.adjustments.reservedForReclaimDelta = delta
+}
+
+// AppliedReservedForReclaim returns reserved for reclaim actually applied to reclaim pool entries of the last
+// successful assembling in total, including that handed off from saturated dedicated numas; numas without reclaim
+// pool entries, e.g. excluded from reclaim, apply none, and entries trimmed below their reserved for reclaim, e.g.
+// by ceiling or draining, apply no more than their sizes excluding the reclaim critical reservation, so that
+// reserved for reclaim and the critical reservation never count the same cores twice
+func (pa *ProvisionAssemblerCommon) AppliedReservedForReclaim() int {
+	pa.mutex.Lock()
+	defer pa.mutex.Unlock()
+
+	applied := 0
+	for _, entry := range pa.reclaimBreakdown.Entries {
+		generalReclaim := entry.Reclaim - entry.Adjustments[reclaimAdjustmentCriticalReservation]
+		applied += general.Max(general.Min(entry.ReservedForReclaim+entry.HandedOff, generalReclaim), 0)
+	}
+	return applied
+}
+
+// ResolveReservedByPercentage returns the percentage of capacity, rounded up or down as required
+func ResolveReservedByPercentage(capacity int, percentage float64, roundUp bool) int {
+	reserved := float64(capacity) * percentage / 100
+	if roundUp {
+		return int(math.Ceil(reserved))
+	}
+	return int(math.Floor(reserved))
+}
@@ -0,0 +1,194 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestResolveReservedForReclaim(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                   string
+		percentage             float64
+		roundUp                bool
+		overrides              map[int]int
+		wantReservedForReclaim map[int]int
+		wantReclaimSize        int
+	}{
+		{
+			name:                   "absolute values are kept without percentage",
+			wantReservedForReclaim: map[int]int{0: 4, 1: 4},
+			wantReclaimSize:        20,
+		},
+		{
+			name:                   "10% of 16 cores rounded down",
+			percentage:             10,
+			wantReservedForReclaim: map[int]int{0: 1, 1: 1},
+			wantReclaimSize:        20,
+		},
+		{
+			name:                   "10% of 16 cores rounded up",
+			percentage:             10,
+			roundUp:                true,
+			wantReservedForReclaim: map[int]int{0: 2, 1: 2},
+			wantReclaimSize:        20,
+		},
+		{
+			name:                   "absolute overrides take precedence over percentage",
+			percentage:             10,
+			overrides:              map[int]int{1: 3},
+			wantReservedForReclaim: map[int]int{0: 1, 1: 3},
+			wantReclaimSize:        20,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+			conf.ReservedForReclaimPercentage = tt.percentage
+			conf.ReservedForReclaimRoundUp = tt.roundUp
+			conf.ReservedForReclaimOverrides = tt.overrides
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+				state.PoolNameReserve: {
+					PoolName: state.PoolNameReserve,
+					TopologyAwareAssignments: map[int]machine.CPUSet{
+						0: machine.MustParse("0"),
+						1: machine.MustParse("16"),
+					},
+				},
+			})
+			metaServer := generateTestMetaServer(t, 32, 2, nil)
+
+			share := &fakeRegion{
+				name:          "share-r",
+				regionType:    types.QoSRegionTypeShare,
+				ownerPoolName: state.PoolNameShare,
+				bindingNumas:  machine.NewCPUSet(0, 1),
+				controlKnob: types.ControlKnob{
+					types.ControlKnobNonReclaimedCPUSize: {Value: 12},
+				},
+			}
+			regionMap := map[string]region.QoSRegion{share.Name(): share}
+			reservedForReclaim := map[int]int{0: 4, 1: 4}
+			numaAvailable := map[int]int{0: 12, 1: 12}
+			nonBindingNumas := machine.NewCPUSet(0, 1)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, newFakeMetricEmitter())
+			result, _, err := pa.AssembleProvision()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantReservedForReclaim, pa.(*ProvisionAssemblerCommon).resolvedReservedForReclaim)
+			// the map provided by the advisor is never modified
+			assert.Equal(t, map[int]int{0: 4, 1: 4}, reservedForReclaim)
+
+			// available resource excludes the resolved values instead of the provided ones, so reclaim
+			// pool is sized consistently with numa capacity
+			reclaimSize, ok := result.GetPoolEntry(state.PoolNameReclaim, cpuadvisor.FakedNUMAID)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantReclaimSize, reclaimSize)
+
+			// provided values are restored once percentage and overrides are removed
+			conf.ReservedForReclaimPercentage = 0
+			conf.ReservedForReclaimOverrides = nil
+			_, _, err = pa.AssembleProvision()
+			require.NoError(t, err)
+			assert.Equal(t, map[int]int{0: 4, 1: 4}, pa.(*ProvisionAssemblerCommon).resolvedReservedForReclaim)
+		})
+	}
+}
+
+func TestAppliedReservedForReclaim(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                     string
+		excludedNumas            []int
+		disableNonBindingReclaim bool
+		regionReserved           int
+		wantApplied              int
+	}{
+		{
+			name:        "reserved for reclaim of all numas is applied",
+			wantApplied: 3,
+		},
+		{
+			name:          "excluded numas apply none",
+			excludedNumas: []int{1},
+			wantApplied:   2,
+		},
+		{
+			name:                     "non-binding numas apply none with non-binding reclaim disabled",
+			disableNonBindingReclaim: true,
+			wantApplied:              2,
+		},
+		{
+			name:           "override of region is applied instead",
+			regionReserved: 3,
+			wantApplied:    5,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+			conf.ExcludedReclaimNumas = tt.excludedNumas
+			conf.DisableNonBindingReclaim = tt.disableNonBindingReclaim
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{})
+			metaServer := generateTestMetaServer(t, 24, 3, []*v1.Pod{makeTestPod("uid0"), makeTestPod("uid1")})
+
+			r0 := newFakeDedicatedRegion("dedicated-r0", 0, "uid0", 2)
+			r1 := newFakeDedicatedRegion("dedicated-r1", 1, "uid1", 2)
+			regionMap := map[string]region.QoSRegion{r0.Name(): r0, r1.Name(): r1}
+			reservedForReclaim := map[int]int{0: 1, 1: 1, 2: 1}
+			numaAvailable := map[int]int{0: 7, 1: 7, 2: 7}
+			nonBindingNumas := machine.NewCPUSet(2)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, newFakeMetricEmitter())
+			assert.Equal(t, 0, pa.AppliedReservedForReclaim())
+			if tt.regionReserved > 0 {
+				require.NoError(t, pa.SetRegionReservedForReclaim(r0.Name(), tt.regionReserved))
+			}
+
+			_, _, err := pa.AssembleProvision()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantApplied, pa.AppliedReservedForReclaim())
+		})
+	}
+}
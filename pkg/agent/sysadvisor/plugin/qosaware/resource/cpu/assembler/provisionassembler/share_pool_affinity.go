@@ -0,0 +1,88 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+// getSharePoolNUMAAffinity returns the numa the share pool is pinned to; pinning takes effect only
+// if the numa is a non-binding numa with known availability, otherwise the pool is left unpinned.
+func (pa *ProvisionAssemblerCommon) getSharePoolNUMAAffinity(poolName string) (int, bool) {
+	numaID, ok := pa.assemblerConf.SharePoolNUMAAffinity[poolName]
+	if !ok {
+		return 0, false
+	}
+
+	if !pa.nonBindingNumas.Contains(numaID) {
+		pa.logger.Warningf("[qosaware-cpu] ignore numa affinity of share pool %v: numa %v is not a non-binding numa", poolName, numaID)
+		return 0, false
+	}
+	if _, ok := pa.availability.GetNumaAvailable(numaID); !ok {
+		pa.logger.Warningf("[qosaware-cpu] ignore numa affinity of share pool %v: availability of numa %v is unknown", poolName, numaID)
+		return 0, false
+	}
+	return numaID, true
+}
+
+// assemblePinnedSharePools fills in pool entries of share pools pinned to non-binding numas, and the reclaim
+// pool entries of those numas; it returns the pinned numas, which are excluded from the share-and-isolate budget.
+func (pa *ProvisionAssemblerCommon) assemblePinnedSharePools(calculationResult *types.InternalCPUCalculationResult,
+	breakdown ReclaimBreakdown, pinnedSizes map[int]map[string]int, enableReclaim bool) machine.CPUSet {
+	pinnedNumas := machine.NewCPUSet()
+	for numaID, sizes := range pinnedSizes {
+		available, _ := pa.availability.GetNumaAvailable(numaID)
+		reservedForReclaim := pa.getNumasReservedForReclaim(machine.NewCPUSet(numaID))
+
+		poolSizes := general.MergeMapInt(sizes, nil)
+		applyPoolMinSizes(poolSizes, pa.assemblerConf.SharePoolMinSizes, available)
+		poolSizes, _ = RegulatePoolSizesByPriority(poolSizes, available, enableReclaim, pa.assemblerConf.SharePoolPriorities)
+
+		pa.logger.InfoS("[qosaware-cpu] pinned share pool sizes", "numaID", numaID, "share size", sizes,
+			"pinnedSharePoolSizes", poolSizes, "available", available)
+
+		for poolName, poolSize := range poolSizes {
+			calculationResult.SetPoolEntry(poolName, numaID, poolSize)
+		}
+
+		breakdown.setEntry(numaID, ReclaimBreakdownEntry{Available: available, SharePools: general.SumUpMapValues(poolSizes),
+			ReservedForReclaim: reservedForReclaim})
+		reclaimed := reservedForReclaim
+		if enableReclaim {
+			reclaimed = available - general.SumUpMapValues(poolSizes) + reservedForReclaim
+		} else {
+			breakdown.adjust(numaID, reclaimAdjustmentReclaimDisabled, reclaimed)
+		}
+		if !pa.assemblerConf.DisableNonBindingReclaim {
+			calculationResult.SetPoolEntryExplicitly(pa.assemblerConf.ReclaimPoolName, numaID, reclaimed)
+		}
+		pinnedNumas = pinnedNumas.Union(machine.NewCPUSet(numaID))
+	}
+	return pinnedNumas
+}
+
+// isPinnedSharePool returns true if the share pool is pinned to any numa
+func isPinnedSharePool(pinnedSizes map[int]map[string]int, poolName string) bool {
+	for _, sizes := range pinnedSizes {
+		if _, ok := sizes[poolName]; ok {
+			return true
+		}
+	}
+	return false
+}
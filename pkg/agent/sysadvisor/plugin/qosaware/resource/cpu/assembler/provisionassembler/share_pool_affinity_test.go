@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestAssembleProvisionSharePoolNUMAAffinity(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		affinity         map[string]int
+		wantPinnedEntry  map[int]int
+		wantReclaimEntry map[int]int
+	}{
+		{
+			name:             "no affinity",
+			affinity:         map[string]int{},
+			wantPinnedEntry:  map[int]int{cpuadvisor.FakedNUMAID: 3},
+			wantReclaimEntry: map[int]int{cpuadvisor.FakedNUMAID: 7},
+		},
+		{
+			name:             "share pool pinned to numa 0",
+			affinity:         map[string]int{"share-a": 0},
+			wantPinnedEntry:  map[int]int{0: 3},
+			wantReclaimEntry: map[int]int{0: 4, cpuadvisor.FakedNUMAID: 3},
+		},
+		{
+			name:             "affinity to unknown numa is ignored",
+			affinity:         map[string]int{"share-a": 2},
+			wantPinnedEntry:  map[int]int{cpuadvisor.FakedNUMAID: 3},
+			wantReclaimEntry: map[int]int{cpuadvisor.FakedNUMAID: 7},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+			conf.SharePoolNUMAAffinity = tt.affinity
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+				state.PoolNameReserve: {
+					PoolName: state.PoolNameReserve,
+					TopologyAwareAssignments: map[int]machine.CPUSet{
+						0: machine.MustParse("0"),
+						1: machine.MustParse("8"),
+					},
+				},
+			})
+			metaServer := generateTestMetaServer(t, 16, 2, nil)
+
+			share := &fakeRegion{
+				name:          "share-r",
+				regionType:    types.QoSRegionTypeShare,
+				ownerPoolName: state.PoolNameShare,
+				bindingNumas:  machine.NewCPUSet(0, 1),
+				controlKnob: types.ControlKnob{
+					types.ControlKnobNonReclaimedCPUSize: {Value: 4},
+				},
+			}
+			pinned := &fakeRegion{
+				name:          "share-a-r",
+				regionType:    types.QoSRegionTypeShare,
+				ownerPoolName: "share-a",
+				bindingNumas:  machine.NewCPUSet(0, 1),
+				controlKnob: types.ControlKnob{
+					types.ControlKnobNonReclaimedCPUSize: {Value: 3},
+				},
+			}
+			regionMap := map[string]region.QoSRegion{share.Name(): share, pinned.Name(): pinned}
+			reservedForReclaim := map[int]int{0: 1, 1: 1}
+			numaAvailable := map[int]int{0: 6, 1: 6}
+			nonBindingNumas := machine.NewCPUSet(0, 1)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, newFakeMetricEmitter())
+
+			result, _, err := pa.AssembleProvision()
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.wantPinnedEntry, result.PoolEntries["share-a"])
+			assert.Equal(t, tt.wantReclaimEntry, result.PoolEntries[state.PoolNameReclaim])
+			sharePoolSize, ok := result.GetPoolEntry(state.PoolNameShare, cpuadvisor.FakedNUMAID)
+			require.True(t, ok)
+			assert.Equal(t, 4, sharePoolSize)
+			assert.Equal(t, 3, result.RegionContributions[pinned.Name()].GrantedSize)
+
+			// reclaim on the pinned numa is shrunk by the pinned pool
+			if numaID, ok := tt.affinity["share-a"]; ok && numaID == 0 {
+				assert.Less(t, result.PoolEntries[state.PoolNameReclaim][0], numaAvailable[0]+reservedForReclaim[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"math"
+
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const (
+	metricCPUProvisionSharePoolForecast = "cpu_provision_share_pool_forecast"
+)
+
+// forecastSharePoolSize records the share pool requirement and returns it raised to the requirement forecasted
+// SharePoolForecastHorizon cycles ahead, so that reclaim pool is shrunk before share pool actually grows; the
+// reactive requirement is kept as floor, so the forecast never donates more to reclaim pool than reactive sizing.
+func (pa *ProvisionAssemblerCommon) forecastSharePoolSize(poolName string, requirement int) int {
+	if !pa.assemblerConf.EnableSharePoolForecast {
+		return requirement
+	}
+
+	window := pa.assemblerConf.SharePoolForecastWindow
+	history := append(pa.sharePoolState.history[poolName], requirement)
+	if window > 0 && len(history) > window {
+		history = history[len(history)-window:]
+	}
+	pa.sharePoolState.history[poolName] = history
+
+	forecast := requirement
+	if len(history) >= 2 {
+		var trend float64
+		switch pa.assemblerConf.SharePoolForecastModel {
+		case cpu.SharePoolForecastModelLinear:
+			trend = linearTrend(history)
+		default:
+			trend = emaTrend(history, pa.assemblerConf.SharePoolForecastEMAAlpha)
+		}
+		forecast = int(math.Ceil(float64(requirement) + trend*float64(pa.assemblerConf.SharePoolForecastHorizon)))
+	}
+	_ = pa.emitter.StoreInt64(metricCPUProvisionSharePoolForecast, int64(forecast), metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "pool_name", Val: poolName})
+
+	if forecast <= requirement {
+		return requirement
+	}
+	pa.logger.InfoS("[qosaware-cpu] share pool raised by forecast", "pool", poolName, "requirement", requirement,
+		"forecast", forecast, "history", history)
+	return forecast
+}
+
+// emaTrend returns the exponential moving average of changes between consecutive values
+func emaTrend(values []int, alpha float64) float64 {
+	trend := float64(values[1] - values[0])
+	for i := 2; i < len(values); i++ {
+		trend = alpha*float64(values[i]-values[i-1]) + (1-alpha)*trend
+	}
+	return trend
+}
+
+// linearTrend returns the slope of least squares linear fit of values against their indexes
+func linearTrend(values []int) float64 {
+	n := float64(len(values))
+	var sumX, sumY, sumXY, sumXX float64
+	for i, v := range values {
+		x, y := float64(i), float64(v)
+		sumX += x
+		sumY += y
+		sumXY += x * y
+		sumXX += x * x
+	}
+	return (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
+}
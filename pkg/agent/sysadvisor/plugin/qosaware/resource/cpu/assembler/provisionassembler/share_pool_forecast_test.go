@@ -0,0 +1,134 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestAssembleProvisionSharePoolForecast(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name             string
+		enableForecast   bool
+		model            string
+		requirements     []int
+		wantSharePool    int
+		wantReclaimSizes []int
+	}{
+		{
+			name:             "reactive without forecast",
+			requirements:     []int{4, 6, 8, 10},
+			wantSharePool:    10,
+			wantReclaimSizes: []int{42, 40, 38, 36},
+		},
+		{
+			name:             "rising trend by ema pre-shrinks reclaim",
+			enableForecast:   true,
+			model:            cpu.SharePoolForecastModelEMA,
+			requirements:     []int{4, 6, 8, 10},
+			wantSharePool:    12,
+			wantReclaimSizes: []int{42, 38, 36, 34},
+		},
+		{
+			name:             "rising trend by linear fit pre-shrinks reclaim",
+			enableForecast:   true,
+			model:            cpu.SharePoolForecastModelLinear,
+			requirements:     []int{4, 6, 8, 10},
+			wantSharePool:    12,
+			wantReclaimSizes: []int{42, 38, 36, 34},
+		},
+		{
+			name:             "falling trend keeps reactive result as floor",
+			enableForecast:   true,
+			model:            cpu.SharePoolForecastModelLinear,
+			requirements:     []int{10, 8, 6, 4},
+			wantSharePool:    4,
+			wantReclaimSizes: []int{36, 38, 40, 42},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+			conf.EnableSharePoolForecast = tt.enableForecast
+			conf.SharePoolForecastModel = tt.model
+			conf.SharePoolForecastWindow = 4
+			conf.SharePoolForecastHorizon = 1
+			conf.SharePoolForecastEMAAlpha = 0.5
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+				state.PoolNameReserve: {
+					PoolName: state.PoolNameReserve,
+					TopologyAwareAssignments: map[int]machine.CPUSet{
+						0: machine.MustParse("0"),
+						1: machine.MustParse("24"),
+					},
+				},
+			})
+			metaServer := generateTestMetaServer(t, 48, 2, nil)
+
+			share := &fakeRegion{
+				name:          "share-r",
+				regionType:    types.QoSRegionTypeShare,
+				ownerPoolName: state.PoolNameShare,
+				bindingNumas:  machine.NewCPUSet(0, 1),
+			}
+			regionMap := map[string]region.QoSRegion{share.Name(): share}
+			reservedForReclaim := map[int]int{0: 1, 1: 1}
+			numaAvailable := map[int]int{0: 22, 1: 22}
+			nonBindingNumas := machine.NewCPUSet(0, 1)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, newFakeMetricEmitter())
+
+			var result types.InternalCPUCalculationResult
+			for i, requirement := range tt.requirements {
+				share.controlKnob = types.ControlKnob{
+					types.ControlKnobNonReclaimedCPUSize: {Value: float64(requirement)},
+				}
+
+				var err error
+				result, _, err = pa.AssembleProvision()
+				require.NoError(t, err)
+
+				reclaimSize, ok := result.GetPoolEntry(state.PoolNameReclaim, cpuadvisor.FakedNUMAID)
+				require.True(t, ok)
+				assert.Equal(t, tt.wantReclaimSizes[i], reclaimSize, "cycle %v", i)
+			}
+
+			sharePoolSize, ok := result.GetPoolEntry(state.PoolNameShare, cpuadvisor.FakedNUMAID)
+			require.True(t, ok)
+			assert.Equal(t, tt.wantSharePool, sharePoolSize)
+		})
+	}
+}
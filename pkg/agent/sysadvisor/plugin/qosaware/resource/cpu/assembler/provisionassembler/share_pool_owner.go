@@ -0,0 +1,55 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const (
+	metricCPUProvisionDuplicateSharePool = "cpu_provision_duplicate_share_pool"
+)
+
+// sharePoolRequirement is the summed requirement of share regions owning the same pool.
+//
+// share regions returning the same owner pool name are regarded as parts of one pool, and their requirements
+// are summed up instead of overwriting each other; growth cooldown, forecast and numa affinity are then applied
+// to the pool as a whole, and contributions of all these regions refer to the whole pool.
+type sharePoolRequirement struct {
+	size        int
+	regionNames []string
+}
+
+// addSharePoolRequirement accumulates requirement of the share region into its owner pool, and reports
+// regions joining a pool already owned by others, since duplicate owner pool names are usually unintended
+func (pa *ProvisionAssemblerCommon) addSharePoolRequirement(requirements map[string]*sharePoolRequirement,
+	r region.QoSRegion, size int) {
+	poolName := r.OwnerPoolName()
+	requirement, ok := requirements[poolName]
+	if !ok {
+		requirements[poolName] = &sharePoolRequirement{size: size, regionNames: []string{r.Name()}}
+		return
+	}
+
+	pa.logger.Warningf("[qosaware-cpu] share region %v owns pool %v together with regions %v, sum up their sizes",
+		r.Name(), poolName, requirement.regionNames)
+	_ = pa.emitter.StoreInt64(metricCPUProvisionDuplicateSharePool, int64(len(requirement.regionNames)+1), metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "pool_name", Val: poolName})
+	requirement.size += size
+	requirement.regionNames = append(requirement.regionNames, r.Name())
+}
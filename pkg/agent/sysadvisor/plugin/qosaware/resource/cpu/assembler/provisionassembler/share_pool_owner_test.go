@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestAssembleProvisionDuplicateSharePool(t *testing.T) {
+	t.Parallel()
+
+	conf := generateTestConfiguration(t)
+	conf.GetDynamicConfiguration().EnableReclaim = true
+
+	metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+		state.PoolNameReserve: {
+			PoolName: state.PoolNameReserve,
+			TopologyAwareAssignments: map[int]machine.CPUSet{
+				0: machine.MustParse("0"),
+				1: machine.MustParse("8"),
+			},
+		},
+	})
+	metaServer := generateTestMetaServer(t, 16, 2, nil)
+	emitter := newFakeMetricEmitter()
+
+	newShareRegion := func(name string, size float64) *fakeRegion {
+		return &fakeRegion{
+			name:          name,
+			regionType:    types.QoSRegionTypeShare,
+			ownerPoolName: state.PoolNameShare,
+			bindingNumas:  machine.NewCPUSet(0, 1),
+			controlKnob: types.ControlKnob{
+				types.ControlKnobNonReclaimedCPUSize: {Value: size},
+			},
+		}
+	}
+	share1 := newShareRegion("share-r1", 3)
+	share2 := newShareRegion("share-r2", 4)
+	regionMap := map[string]region.QoSRegion{share1.Name(): share1, share2.Name(): share2}
+	reservedForReclaim := map[int]int{0: 1, 1: 1}
+	numaAvailable := map[int]int{0: 6, 1: 6}
+	nonBindingNumas := machine.NewCPUSet(0, 1)
+
+	pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		metaCache, metaServer, emitter)
+	result, _, err := pa.AssembleProvision()
+	require.NoError(t, err)
+
+	// sizes of both regions are summed up into the share pool
+	sharePoolSize, ok := result.GetPoolEntry(state.PoolNameShare, cpuadvisor.FakedNUMAID)
+	assert.True(t, ok)
+	assert.Equal(t, 3+4, sharePoolSize)
+
+	reclaimPoolSize, ok := result.GetPoolEntry(state.PoolNameReclaim, cpuadvisor.FakedNUMAID)
+	assert.True(t, ok)
+	assert.Equal(t, 12-7+2, reclaimPoolSize)
+
+	// contributions of both regions refer to the whole pool
+	for _, regionName := range []string{share1.Name(), share2.Name()} {
+		contribution, ok := result.RegionContributions[regionName]
+		assert.True(t, ok)
+		assert.Equal(t, state.PoolNameShare, contribution.PoolName)
+		assert.Equal(t, 3+4, contribution.RequestedSize)
+	}
+
+	assert.Equal(t, []int64{2}, emitter.get(metricCPUProvisionDuplicateSharePool))
+	assert.Equal(t, []map[string]string{{"pool_name": state.PoolNameShare}}, emitter.getTags(metricCPUProvisionDuplicateSharePool))
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const (
+	metricCPUProvisionSharePoolRateLimited = "cpu_provision_share_pool_rate_limited"
+)
+
+// limitSharePoolRate returns the size to provision for the share pool, which moves toward the requirement
+// by at most SharePoolGrowStep or SharePoolShrinkStep from the size of the last assembling; pools seen for
+// the first time are sized by the requirement as it is. the gap between the size and the requirement is
+// emitted, which is positive if shrinking is limited and negative if growing is limited
+func (pa *ProvisionAssemblerCommon) limitSharePoolRate(poolName string, requirement int) int {
+	growStep, shrinkStep := pa.assemblerConf.SharePoolGrowStep, pa.assemblerConf.SharePoolShrinkStep
+	if growStep <= 0 && shrinkStep <= 0 {
+		delete(pa.sharePoolState.lastSizes, poolName)
+		return requirement
+	}
+
+	size := requirement
+	if lastSize, ok := pa.sharePoolState.lastSizes[poolName]; ok {
+		if growStep > 0 && size > lastSize+growStep {
+			size = lastSize + growStep
+		} else if shrinkStep > 0 && size < lastSize-shrinkStep {
+			size = lastSize - shrinkStep
+		}
+
+		if size != requirement {
+			pa.logger.InfoS("[qosaware-cpu] share pool rate limited", "pool", poolName, "last", lastSize,
+				"requirement", requirement, "size", size)
+		}
+	}
+	_ = pa.emitter.StoreInt64(metricCPUProvisionSharePoolRateLimited, int64(size-requirement), metrics.MetricTypeNameRaw,
+		metrics.MetricTag{Key: "pool_name", Val: poolName})
+
+	pa.sharePoolState.lastSizes[poolName] = size
+	return size
+}
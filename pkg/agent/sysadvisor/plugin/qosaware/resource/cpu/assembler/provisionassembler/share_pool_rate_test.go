@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestAssembleProvisionSharePoolRate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		growStep     int
+		shrinkStep   int
+		requirements []float64
+		wantSizes    []int
+	}{
+		{
+			name:         "no limitation by default",
+			requirements: []float64{10, 2, 12},
+			wantSizes:    []int{10, 2, 12},
+		},
+		{
+			name:         "sharp drop is rate limited while sharp rise is applied immediately",
+			shrinkStep:   2,
+			requirements: []float64{10, 2, 2, 12, 4},
+			wantSizes:    []int{10, 8, 6, 12, 10},
+		},
+		{
+			name:         "rise is rate limited by grow step",
+			growStep:     3,
+			shrinkStep:   2,
+			requirements: []float64{4, 12, 12, 12, 2},
+			wantSizes:    []int{4, 7, 10, 12, 10},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+			conf.SharePoolGrowStep = tt.growStep
+			conf.SharePoolShrinkStep = tt.shrinkStep
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{})
+			metaServer := generateTestMetaServer(t, 16, 2, nil)
+
+			share := &fakeRegion{
+				name:          "share-r",
+				regionType:    types.QoSRegionTypeShare,
+				ownerPoolName: state.PoolNameShare,
+				bindingNumas:  machine.NewCPUSet(0, 1),
+				controlKnob:   types.ControlKnob{},
+			}
+			regionMap := map[string]region.QoSRegion{share.Name(): share}
+			reservedForReclaim := map[int]int{0: 1, 1: 1}
+			numaAvailable := map[int]int{0: 8, 1: 8}
+			nonBindingNumas := machine.NewCPUSet(0, 1)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, metrics.DummyMetrics{})
+			for i, requirement := range tt.requirements {
+				share.controlKnob[types.ControlKnobNonReclaimedCPUSize] = types.ControlKnobValue{Value: requirement}
+				result, _, err := pa.AssembleProvision()
+				require.NoError(t, err)
+				assert.Equal(t, tt.wantSizes[i], result.PoolEntries[state.PoolNameShare][cpuadvisor.FakedNUMAID], "cycle %v", i)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+// SocketReclaimView returns a copy of reclaim pool size of each socket in the last successful assembling, keyed by
+// socket id; nil is returned if socket reclaim view is disabled. Reclaim pool of non-binding numas is attributed to
+// their socket if they are all in one socket, otherwise it's kept under cpuadvisor.FakedNUMAID since the split among
+// sockets is decided by cpu plugin.
+func (pa *ProvisionAssemblerCommon) SocketReclaimView() map[int]int {
+	pa.mutex.Lock()
+	defer pa.mutex.Unlock()
+
+	if pa.socketReclaimView == nil {
+		return nil
+	}
+	view := make(map[int]int, len(pa.socketReclaimView))
+	for socketID, size := range pa.socketReclaimView {
+		view[socketID] = size
+	}
+	return view
+}
+
+// rollUpReclaimBySocket sums up reclaim pool entries of numas by their sockets in machine topology;
+// on nodes where each socket has a single numa, the view equals reclaim pool entries of numas
+func (pa *ProvisionAssemblerCommon) rollUpReclaimBySocket(calculationResult types.InternalCPUCalculationResult) map[int]int {
+	if !pa.assemblerConf.EnableSocketReclaimView {
+		return nil
+	}
+
+	view := make(map[int]int)
+	reclaimPoolEntries := calculationResult.PoolEntries[pa.assemblerConf.ReclaimPoolName]
+	for numaID, size := range reclaimPoolEntries {
+		if numaID == cpuadvisor.FakedNUMAID {
+			continue
+		}
+		for _, socketID := range pa.metaServer.CPUDetails.SocketsInNUMANodes(numaID).ToSliceInt() {
+			view[socketID] += size
+		}
+	}
+
+	size, ok := reclaimPoolEntries[cpuadvisor.FakedNUMAID]
+	if !ok {
+		return view
+	}
+
+	// numas covered by reclaim pool entry of non-binding numas, i.e. those without their own entries
+	sharedNumas := machine.NewCPUSet()
+	for _, numaID := range pa.nonBindingNumas.Difference(machine.NewCPUSet(pa.assemblerConf.ExcludedReclaimNumas...)).ToSliceInt() {
+		if _, ok := reclaimPoolEntries[numaID]; !ok {
+			sharedNumas = sharedNumas.Union(machine.NewCPUSet(numaID))
+		}
+	}
+	if sockets := pa.metaServer.CPUDetails.SocketsInNUMANodes(sharedNumas.ToSliceInt()...); sockets.Size() == 1 {
+		view[sockets.ToSliceInt()[0]] += size
+	} else {
+		view[cpuadvisor.FakedNUMAID] += size
+	}
+	return view
+}
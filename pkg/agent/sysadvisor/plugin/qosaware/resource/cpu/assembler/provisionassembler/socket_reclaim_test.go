@@ -0,0 +1,117 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestSocketReclaimView(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                    string
+		enableSocketReclaimView bool
+		socketNum               int
+		wantView                map[int]int
+	}{
+		{
+			name:      "disabled",
+			socketNum: 2,
+		},
+		{
+			name:                    "two sockets with two numas each",
+			enableSocketReclaimView: true,
+			socketNum:               2,
+			wantView:                map[int]int{0: 10, 1: 8},
+		},
+		{
+			name:                    "one numa per socket",
+			enableSocketReclaimView: true,
+			socketNum:               4,
+			wantView:                map[int]int{0: 4, 1: 6, 2: 3, 3: 5},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+			conf.EnableSocketReclaimView = tt.enableSocketReclaimView
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+				state.PoolNameReserve: {
+					PoolName: state.PoolNameReserve,
+					TopologyAwareAssignments: map[int]machine.CPUSet{
+						0: machine.MustParse("0"),
+						1: machine.MustParse("4"),
+						2: machine.MustParse("8"),
+						3: machine.MustParse("12"),
+					},
+				},
+			})
+			metaServer := generateTestMetaServer(t, 32, 4, []*v1.Pod{makeTestPod("uid0"), makeTestPod("uid1"), makeTestPod("uid2")})
+			cpuTopology, err := machine.GenerateDummyCPUTopology(32, tt.socketNum, 4)
+			require.NoError(t, err)
+			metaServer.KatalystMachineInfo.CPUTopology = cpuTopology
+
+			regions := []*fakeRegion{
+				newFakeDedicatedRegion("dedicated-0", 0, "uid0", 4),
+				newFakeDedicatedRegion("dedicated-1", 1, "uid1", 2),
+				newFakeDedicatedRegion("dedicated-2", 2, "uid2", 5),
+				{
+					name:          "share",
+					regionType:    types.QoSRegionTypeShare,
+					ownerPoolName: state.PoolNameShare,
+					bindingNumas:  machine.NewCPUSet(3),
+					controlKnob: types.ControlKnob{
+						types.ControlKnobNonReclaimedCPUSize: {Value: 3, Action: types.ControlKnobActionNone},
+					},
+				},
+			}
+			regionMap := make(map[string]region.QoSRegion)
+			for _, r := range regions {
+				regionMap[r.Name()] = r
+			}
+			reservedForReclaim := map[int]int{0: 1, 1: 1, 2: 1, 3: 1}
+			numaAvailable := map[int]int{0: 7, 1: 7, 2: 7, 3: 7}
+			nonBindingNumas := machine.NewCPUSet(3)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, newFakeMetricEmitter())
+			result, _, err := pa.AssembleProvision()
+			require.NoError(t, err)
+
+			// reclaim pool entries of numas are kept intact
+			assert.Equal(t, map[int]int{0: 4, 1: 6, 2: 3, cpuadvisor.FakedNUMAID: 5}, result.PoolEntries[state.PoolNameReclaim])
+			assert.Equal(t, tt.wantView, pa.SocketReclaimView())
+		})
+	}
+}
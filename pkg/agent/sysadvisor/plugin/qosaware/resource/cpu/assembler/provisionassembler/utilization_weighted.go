@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"math"
+	"sort"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+)
+
+// getRegulationUtilizations returns recent utilizations of share pools, i.e. cpu usage of containers in the pool
+// divided by its current size, to weight the shrinking of pools exceeding available; nil is returned if weighting
+// is disabled or pools fit into available, so that pools are regulated proportionally.
+func (pa *ProvisionAssemblerCommon) getRegulationUtilizations(poolSizes map[string]int, available int) map[string]float64 {
+	if !pa.assemblerConf.EnableUtilizationWeightedRegulation || general.SumUpMapValues(poolSizes) <= available {
+		return nil
+	}
+
+	usages := make(map[string]float64)
+	pa.metaReader.RangeContainer(func(podUID string, containerName string, ci *types.ContainerInfo) bool {
+		if _, ok := poolSizes[ci.OwnerPoolName]; !ok {
+			return true
+		}
+		m, err := pa.metaServer.GetContainerMetric(podUID, containerName, consts.MetricCPUUsageContainer)
+		if err != nil {
+			pa.logger.Warningf("[qosaware-cpu] get cpu usage of %v/%v failed: %v", podUID, containerName, err)
+			return true
+		}
+		usages[ci.OwnerPoolName] += m.Value
+		return true
+	})
+
+	utilizations := make(map[string]float64, len(usages))
+	for poolName, usage := range usages {
+		poolSize, ok := pa.metaReader.GetPoolSize(poolName)
+		if !ok || poolSize <= 0 {
+			continue
+		}
+		utilizations[poolName] = math.Min(usage/float64(poolSize), 1)
+	}
+	pa.logger.InfoS("[qosaware-cpu] pool utilizations for regulation", "utilizations", utilizations)
+	return utilizations
+}
+
+// shrinkPoolSizes scales pool sizes to targetSum, weighted by utilizations if given when shrinking
+func shrinkPoolSizes(poolSizes map[string]int, targetSum int, utilizations map[string]float64) error {
+	if utilizations == nil || general.SumUpMapValues(poolSizes) <= targetSum {
+		return normalizePoolSizes(poolSizes, targetSum)
+	}
+	return shrinkPoolSizesByUtilization(poolSizes, targetSum, utilizations)
+}
+
+// shrinkPoolSizesByUtilization shrinks pool sizes to targetSum, and the shortfall is shared among pools in
+// proportion to their idle cores, i.e. size multiplied by one minus utilization, keeping at least one core
+// for each pool; pools missing in utilizations are regarded as fully utilized. shortfall left over, e.g. if
+// all pools are fully utilized, is absorbed by scaling down all pools proportionally.
+func shrinkPoolSizesByUtilization(poolSizes map[string]int, targetSum int, utilizations map[string]float64) error {
+	shortfall := general.SumUpMapValues(poolSizes) - targetSum
+
+	idleCores := make(map[string]float64, len(poolSizes))
+	totalIdleCores := 0.0
+	for poolName, size := range poolSizes {
+		utilization, ok := utilizations[poolName]
+		if !ok || size <= 1 {
+			continue
+		}
+		idle := float64(size) * (1 - math.Max(math.Min(utilization, 1), 0))
+		if idle > 0 {
+			idleCores[poolName] = idle
+			totalIdleCores += idle
+		}
+	}
+
+	if totalIdleCores > 0 {
+		type fraction struct {
+			poolName string
+			value    float64
+		}
+		fractions := make([]fraction, 0, len(idleCores))
+		shrunk := 0
+		for poolName, idle := range idleCores {
+			share := math.Min(float64(shortfall)*idle/totalIdleCores, float64(poolSizes[poolName]-1))
+			poolSizes[poolName] -= int(share)
+			shrunk += int(share)
+			fractions = append(fractions, fraction{poolName: poolName, value: share - math.Floor(share)})
+		}
+
+		// cores left by rounding down are shrunk from pools of the largest fractions
+		sort.Slice(fractions, func(i, j int) bool {
+			if fractions[i].value != fractions[j].value {
+				return fractions[i].value > fractions[j].value
+			}
+			return fractions[i].poolName < fractions[j].poolName
+		})
+		for _, f := range fractions {
+			if shrunk >= shortfall {
+				break
+			}
+			if f.value > 0 && poolSizes[f.poolName] > 1 {
+				poolSizes[f.poolName]--
+				shrunk++
+			}
+		}
+	}
+
+	if general.SumUpMapValues(poolSizes) > targetSum {
+		return normalizePoolSizes(poolSizes, targetSum)
+	}
+	return nil
+}
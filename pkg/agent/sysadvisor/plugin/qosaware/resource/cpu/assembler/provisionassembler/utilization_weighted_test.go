@@ -0,0 +1,173 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provisionassembler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/metric"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+	utilmetric "github.com/kubewharf/katalyst-core/pkg/util/metric"
+)
+
+func TestShrinkPoolSizesByUtilization(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		sizes        map[string]int
+		targetSum    int
+		utilizations map[string]float64
+		want         map[string]int
+	}{
+		{
+			name:         "idle pool absorbs most of the shortfall",
+			sizes:        map[string]int{"busy": 8, "idle": 8},
+			targetSum:    10,
+			utilizations: map[string]float64{"busy": 0.9, "idle": 0.1},
+			want:         map[string]int{"busy": 7, "idle": 3},
+		},
+		{
+			name:         "pools are kept at least one core",
+			sizes:        map[string]int{"busy": 8, "idle": 4},
+			targetSum:    6,
+			utilizations: map[string]float64{"busy": 0.9, "idle": 0},
+			want:         map[string]int{"busy": 5, "idle": 1},
+		},
+		{
+			name:         "pools of the same utilization are shrunk proportionally",
+			sizes:        map[string]int{"a": 8, "b": 4},
+			targetSum:    6,
+			utilizations: map[string]float64{"a": 0.5, "b": 0.5},
+			want:         map[string]int{"a": 4, "b": 2},
+		},
+		{
+			name:         "fully utilized pools are shrunk proportionally",
+			sizes:        map[string]int{"a": 8, "b": 8},
+			targetSum:    8,
+			utilizations: map[string]float64{"a": 1},
+			want:         map[string]int{"a": 4, "b": 4},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			sizes := tt.sizes
+			require.NoError(t, shrinkPoolSizesByUtilization(sizes, tt.targetSum, tt.utilizations))
+			assert.Equal(t, tt.want, sizes)
+		})
+	}
+}
+
+func TestAssembleProvisionUtilizationWeightedRegulation(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		enable    bool
+		wantShare int
+		wantBatch int
+	}{
+		{
+			name:      "pools are shrunk proportionally by default",
+			wantShare: 6,
+			wantBatch: 6,
+		},
+		{
+			name:      "busy pool is favored under contention",
+			enable:    true,
+			wantShare: 8,
+			wantBatch: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			now := time.Now()
+			conf := generateTestConfiguration(t)
+			conf.GetDynamicConfiguration().EnableReclaim = true
+			conf.EnableUtilizationWeightedRegulation = tt.enable
+
+			metaCache := generateTestMetaCache(t, conf, map[string]*types.PoolInfo{
+				state.PoolNameShare: {
+					PoolName:                 state.PoolNameShare,
+					TopologyAwareAssignments: map[int]machine.CPUSet{0: machine.MustParse("0-7")},
+				},
+				"batch": {
+					PoolName:                 "batch",
+					TopologyAwareAssignments: map[int]machine.CPUSet{1: machine.MustParse("8-15")},
+				},
+			})
+			require.NoError(t, metaCache.SetContainerInfo("uid1", "c1", &types.ContainerInfo{
+				PodUID:        "uid1",
+				ContainerName: "c1",
+				OwnerPoolName: state.PoolNameShare,
+			}))
+			require.NoError(t, metaCache.SetContainerInfo("uid2", "c2", &types.ContainerInfo{
+				PodUID:        "uid2",
+				ContainerName: "c2",
+				OwnerPoolName: "batch",
+			}))
+
+			metricsFetcher := metric.NewFakeMetricsFetcher(metrics.DummyMetrics{}).(*metric.FakeMetricsFetcher)
+			metricsFetcher.SetContainerMetric("uid1", "c1", consts.MetricCPUUsageContainer, utilmetric.MetricData{Value: 7.2, Time: &now})
+			metricsFetcher.SetContainerMetric("uid2", "c2", consts.MetricCPUUsageContainer, utilmetric.MetricData{Value: 0.8, Time: &now})
+			metaServer := generateTestMetaServer(t, 16, 2, nil)
+			metaServer.MetricsFetcher = metricsFetcher
+
+			regionMap := make(map[string]region.QoSRegion)
+			for _, poolName := range []string{state.PoolNameShare, "batch"} {
+				r := &fakeRegion{
+					name:          poolName + "-r",
+					regionType:    types.QoSRegionTypeShare,
+					ownerPoolName: poolName,
+					bindingNumas:  machine.NewCPUSet(0, 1),
+					controlKnob: types.ControlKnob{
+						types.ControlKnobNonReclaimedCPUSize: {Value: 8},
+					},
+				}
+				regionMap[r.Name()] = r
+			}
+			reservedForReclaim := map[int]int{0: 1, 1: 1}
+			numaAvailable := map[int]int{0: 6, 1: 6}
+			nonBindingNumas := machine.NewCPUSet(0, 1)
+
+			pa := NewProvisionAssemblerCommon(conf, nil, &regionMap, &reservedForReclaim, NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+				metaCache, metaServer, metrics.DummyMetrics{})
+			result, _, err := pa.AssembleProvision()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantShare, result.PoolEntries[state.PoolNameShare][cpuadvisor.FakedNUMAID])
+			assert.Equal(t, tt.wantBatch, result.PoolEntries["batch"][cpuadvisor.FakedNUMAID])
+		})
+	}
+}
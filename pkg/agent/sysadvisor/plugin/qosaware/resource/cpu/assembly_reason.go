@@ -0,0 +1,43 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"time"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+// getUpdateReason returns what drives the periodic update started at startTime: non-binding numas changing in
+// this update comes first, then dynamic configuration swapped since the last update; the first update is
+// always periodic, since there is nothing to compare with. must be called with lock held
+func (cra *cpuResourceAdvisor) getUpdateReason(startTime time.Time) types.AssemblyReason {
+	dynamicConf := cra.conf.GetDynamicConfiguration()
+	dynamicConfChanged := cra.lastDynamicConf != nil && cra.lastDynamicConf != dynamicConf
+	cra.lastDynamicConf = dynamicConf
+
+	switch {
+	case !cra.advisorUpdated:
+		return types.AssemblyReasonPeriodic
+	case !cra.topologyChangedAt.Before(startTime):
+		return types.AssemblyReasonTopologyChange
+	case dynamicConfChanged:
+		return types.AssemblyReasonDynamicConfigChange
+	default:
+		return types.AssemblyReasonPeriodic
+	}
+}
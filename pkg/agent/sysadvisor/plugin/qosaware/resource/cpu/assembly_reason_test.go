@@ -0,0 +1,61 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	testingclock "k8s.io/utils/clock/testing"
+
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic"
+)
+
+func TestGetUpdateReason(t *testing.T) {
+	t.Parallel()
+
+	conf, err := options.NewOptions().Config()
+	require.NoError(t, err)
+
+	now := time.Now()
+	fakeClock := testingclock.NewFakePassiveClock(now)
+	cra := &cpuResourceAdvisor{conf: conf, clock: fakeClock}
+
+	// the first update is periodic even if topology is seen for the first time
+	cra.topologyChangedAt = now
+	assert.Equal(t, types.AssemblyReasonPeriodic, cra.getUpdateReason(now))
+	cra.advisorUpdated = true
+
+	fakeClock.SetTime(now.Add(time.Minute))
+	assert.Equal(t, types.AssemblyReasonPeriodic, cra.getUpdateReason(fakeClock.Now()))
+
+	// dynamic configuration swapped since the last update
+	conf.SetDynamicConfiguration(dynamic.NewConfiguration())
+	fakeClock.SetTime(now.Add(2 * time.Minute))
+	assert.Equal(t, types.AssemblyReasonDynamicConfigChange, cra.getUpdateReason(fakeClock.Now()))
+	assert.Equal(t, types.AssemblyReasonPeriodic, cra.getUpdateReason(fakeClock.Now()))
+
+	// topology changing in this update comes before dynamic configuration
+	conf.SetDynamicConfiguration(dynamic.NewConfiguration())
+	fakeClock.SetTime(now.Add(3 * time.Minute))
+	cra.topologyChangedAt = fakeClock.Now()
+	assert.Equal(t, types.AssemblyReasonTopologyChange, cra.getUpdateReason(fakeClock.Now()))
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const (
+	metricCPUAdvisorAssemblyTimedOut = "cpu_advisor_assembly_timed_out"
+)
+
+// assembleFunc assembles provision result, and is supposed to give up once ctx is done
+type assembleFunc func(ctx context.Context) (types.InternalCPUCalculationResult, bool, error)
+
+// assembledProvision is the outcome of an assembling run in background
+type assembledProvision struct {
+	result     types.InternalCPUCalculationResult
+	boundUpper bool
+	err        error
+}
+
+// assembleWithTimeout runs assemble bounded by ProvisionAssemblyTimeout, so that a hanging assembling never stalls
+// advisor update; on timeout, the in-flight assembling is cancelled via ctx and timedOut is returned. since the
+// assembler is serialized, later assembling is skipped as timed out as well until the cancelled one returns.
+func (cra *cpuResourceAdvisor) assembleWithTimeout(assemble assembleFunc) (types.InternalCPUCalculationResult, bool, bool, error) {
+	timeout := cra.conf.ProvisionAssemblyTimeout
+	if timeout <= 0 {
+		result, boundUpper, err := assemble(context.Background())
+		return result, boundUpper, false, err
+	}
+
+	if cra.assemblyInFlight() {
+		klog.Warningf("[qosaware-cpu] skip assembling: the timed out one is still in flight")
+		return types.InternalCPUCalculationResult{}, false, true, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	done := make(chan assembledProvision, 1)
+	go func() {
+		result, boundUpper, err := assemble(ctx)
+		done <- assembledProvision{result: result, boundUpper: boundUpper, err: err}
+	}()
+
+	select {
+	case assembled := <-done:
+		return assembled.result, assembled.boundUpper, false, assembled.err
+	case <-ctx.Done():
+		klog.Errorf("[qosaware-cpu] assembling timed out after %v", timeout)
+		cra.inFlightAssembly = done
+		return types.InternalCPUCalculationResult{}, false, true, nil
+	}
+}
+
+// assemblyInFlight returns true if the timed out assembling is still running in background, which keeps reading
+// regions, so that regions must never be changed until it returns
+func (cra *cpuResourceAdvisor) assemblyInFlight() bool {
+	if cra.inFlightAssembly == nil {
+		return false
+	}
+	select {
+	case <-cra.inFlightAssembly:
+		cra.inFlightAssembly = nil
+		return false
+	default:
+		return true
+	}
+}
+
+// serveTimedOutProvision returns the last-known-good provision result flagged as timed out
+func (cra *cpuResourceAdvisor) serveTimedOutProvision() (types.InternalCPUCalculationResult, bool, error) {
+	_ = cra.emitter.StoreInt64(metricCPUAdvisorAssemblyTimedOut, 1, metrics.MetricTypeNameRaw)
+
+	if cra.circuitBreaker.lastGoodResult == nil {
+		return types.InternalCPUCalculationResult{}, false, fmt.Errorf("assembling timed out without last-known-good result")
+	}
+	result := *cra.circuitBreaker.lastGoodResult
+	result.TimedOut = true
+	klog.Warningf("[qosaware-cpu] serve last-known-good result at %v for timed out assembling", result.TimeStamp)
+	return result, false, nil
+}
@@ -0,0 +1,127 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	testingclock "k8s.io/utils/clock/testing"
+
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+func TestAssembleProvisionTimeout(t *testing.T) {
+	t.Parallel()
+
+	conf, err := options.NewOptions().Config()
+	require.NoError(t, err)
+	conf.ProvisionAssemblyTimeout = 20 * time.Millisecond
+
+	fakeClock := testingclock.NewFakePassiveClock(time.Now())
+	goodResult := types.InternalCPUCalculationResult{
+		PoolEntries: map[string]map[int]int{
+			state.PoolNameReclaim: {0: 4, 1: 6},
+		},
+	}
+	assembler := &fakeProvisionAssembler{result: goodResult}
+	cra := &cpuResourceAdvisor{
+		conf:               conf,
+		provisionAssembler: assembler,
+		circuitBreaker:     newProvisionCircuitBreaker(0, 0, fakeClock),
+		emitter:            metrics.DummyMetrics{},
+		clock:              fakeClock,
+	}
+
+	// no last-known-good result to serve yet
+	assembler.setDelay(time.Minute)
+	_, _, err = cra.assembleProvision()
+	assert.Error(t, err)
+	require.Eventually(t, func() bool { return len(cra.inFlightAssembly) == 1 }, time.Second, time.Millisecond)
+
+	assembler.setDelay(0)
+	result, _, err := cra.assembleProvision()
+	require.NoError(t, err)
+	assert.False(t, result.TimedOut)
+	assert.Equal(t, goodResult, result)
+
+	// slow region provision is cancelled on timeout, and the last-known-good result is served
+	assembler.setDelay(time.Minute)
+	assembler.result = types.InternalCPUCalculationResult{}
+	result, _, err = cra.assembleProvision()
+	require.NoError(t, err)
+	assert.True(t, result.TimedOut)
+	assert.Equal(t, goodResult.PoolEntries, result.PoolEntries)
+	require.Eventually(t, func() bool { return len(cra.inFlightAssembly) == 1 }, time.Second, time.Millisecond)
+
+	// assembling not cancellable keeps later ones skipped until it returns
+	release := make(chan struct{})
+	result, _, err = cra.assembleProvisionWithCircuitBreaker(func(_ context.Context) (types.InternalCPUCalculationResult, bool, error) {
+		<-release
+		return types.InternalCPUCalculationResult{}, false, nil
+	})
+	require.NoError(t, err)
+	assert.True(t, result.TimedOut)
+
+	assembler.setDelay(0)
+	calls := assembler.calls
+	result, _, err = cra.assembleProvision()
+	require.NoError(t, err)
+	assert.True(t, result.TimedOut)
+	assert.Equal(t, calls, assembler.calls)
+
+	close(release)
+	require.Eventually(t, func() bool { return len(cra.inFlightAssembly) == 1 }, time.Second, time.Millisecond)
+	result, _, err = cra.assembleProvision()
+	require.NoError(t, err)
+	assert.False(t, result.TimedOut)
+	assert.Equal(t, calls+1, assembler.calls)
+}
+
+func TestUpdateSkippedWithAssemblyInFlight(t *testing.T) {
+	t.Parallel()
+
+	conf, err := options.NewOptions().Config()
+	require.NoError(t, err)
+	conf.ProvisionAssemblyTimeout = 20 * time.Millisecond
+
+	// empty region is garbage collected once updating goes on
+	r := region.NewQoSRegionBase("share-r", state.PoolNameShare, types.QoSRegionTypeShare, conf, struct{}{}, nil, nil, nil)
+	inFlight := make(chan assembledProvision, 1)
+	cra := &cpuResourceAdvisor{
+		conf:             conf,
+		regionMap:        map[string]region.QoSRegion{r.Name(): r},
+		emitter:          metrics.DummyMetrics{},
+		inFlightAssembly: inFlight,
+	}
+
+	// regions read by the timed out assembling are never changed
+	cra.update()
+	assert.Contains(t, cra.regionMap, r.Name())
+	assert.True(t, cra.assemblyInFlight())
+
+	inFlight <- assembledProvision{}
+	assert.False(t, cra.assemblyInFlight())
+	assert.Nil(t, cra.inFlightAssembly)
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"time"
+
+	"k8s.io/utils/clock"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+type circuitState string
+
+const (
+	circuitClosed   circuitState = "closed"
+	circuitOpen     circuitState = "open"
+	circuitHalfOpen circuitState = "half-open"
+)
+
+// provisionCircuitBreaker freezes the last-known-good provision result after consecutive
+// assembling failures, to avoid degrading pools during outage of dependencies (e.g. metaserver).
+// after cooldown, it turns half-open to let one assembling through to test recovery.
+type provisionCircuitBreaker struct {
+	// failureThreshold is the number of consecutive failures to open the circuit, and zero disables it
+	failureThreshold int
+	cooldown         time.Duration
+	clock            clock.PassiveClock
+
+	state               circuitState
+	consecutiveFailures int
+	openedAt            time.Time
+	lastGoodResult      *types.InternalCPUCalculationResult
+}
+
+func newProvisionCircuitBreaker(failureThreshold int, cooldown time.Duration, clock clock.PassiveClock) *provisionCircuitBreaker {
+	return &provisionCircuitBreaker{
+		failureThreshold: failureThreshold,
+		cooldown:         cooldown,
+		clock:            clock,
+		state:            circuitClosed,
+	}
+}
+
+// allow returns true if assembling should be tried, i.e. the circuit is closed or turns half-open after cooldown
+func (cb *provisionCircuitBreaker) allow() bool {
+	if cb.state == circuitOpen && cb.clock.Since(cb.openedAt) >= cb.cooldown {
+		cb.state = circuitHalfOpen
+	}
+	return cb.state != circuitOpen
+}
+
+// onSuccess closes the circuit and records the result as last-known-good
+func (cb *provisionCircuitBreaker) onSuccess(result types.InternalCPUCalculationResult) {
+	cb.state = circuitClosed
+	cb.consecutiveFailures = 0
+	cb.lastGoodResult = &result
+}
+
+// onFailure counts the failure, and opens the circuit if failures reach threshold or recovery test fails
+func (cb *provisionCircuitBreaker) onFailure() {
+	cb.consecutiveFailures++
+	if cb.failureThreshold <= 0 || cb.lastGoodResult == nil {
+		return
+	}
+	if cb.state == circuitHalfOpen || cb.consecutiveFailures >= cb.failureThreshold {
+		cb.state = circuitOpen
+		cb.openedAt = cb.clock.Now()
+	}
+}
+
+// frozenResult returns the last-known-good result if the circuit is not closed
+func (cb *provisionCircuitBreaker) frozenResult() (types.InternalCPUCalculationResult, bool) {
+	if cb.state == circuitClosed || cb.lastGoodResult == nil {
+		return types.InternalCPUCalculationResult{}, false
+	}
+	return *cb.lastGoodResult, true
+}
@@ -0,0 +1,233 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	testingclock "k8s.io/utils/clock/testing"
+
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/assembler/provisionassembler"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+type fakeProvisionAssembler struct {
+	result      types.InternalCPUCalculationResult
+	boundUpper  bool
+	err         error
+	calls       int
+	attribution map[string]int
+	// appliedReservedForReclaim and criticalReservation are reported by the fake as they are
+	appliedReservedForReclaim int
+	criticalReservation       int
+	// delay simulates slow region provision, during which assembling is cancellable
+	delay      time.Duration
+	delayMutex sync.Mutex
+}
+
+func (a *fakeProvisionAssembler) setDelay(delay time.Duration) {
+	a.delayMutex.Lock()
+	defer a.delayMutex.Unlock()
+	a.delay = delay
+}
+
+func (a *fakeProvisionAssembler) AssembleProvision() (types.InternalCPUCalculationResult, bool, error) {
+	a.calls++
+	return a.result, a.boundUpper, a.err
+}
+
+func (a *fakeProvisionAssembler) AssembleProvisionWithContext(ctx context.Context) (types.InternalCPUCalculationResult, bool, error) {
+	a.delayMutex.Lock()
+	delay := a.delay
+	a.delayMutex.Unlock()
+
+	if delay > 0 {
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return types.InternalCPUCalculationResult{}, false, ctx.Err()
+		}
+	}
+	return a.AssembleProvision()
+}
+
+func (a *fakeProvisionAssembler) AssembleProvisionPartial(_ []string) (types.InternalCPUCalculationResult, bool, error) {
+	return a.AssembleProvision()
+}
+
+func (a *fakeProvisionAssembler) Reset() {}
+
+func (a *fakeProvisionAssembler) ReclaimBreakdown() provisionassembler.ReclaimBreakdown {
+	return provisionassembler.ReclaimBreakdown{}
+}
+
+func (a *fakeProvisionAssembler) SocketReclaimView() map[int]int {
+	return nil
+}
+
+func (a *fakeProvisionAssembler) HeadroomAttribution() map[string]int {
+	return a.attribution
+}
+
+func (a *fakeProvisionAssembler) AppliedReservedForReclaim() int {
+	return a.appliedReservedForReclaim
+}
+
+func (a *fakeProvisionAssembler) ReclaimCriticalReservation() int {
+	return a.criticalReservation
+}
+
+func (a *fakeProvisionAssembler) DrainNumaReclaim(_ int, _ time.Duration) error {
+	return nil
+}
+
+func (a *fakeProvisionAssembler) UndrainNumaReclaim(_ int) {
+}
+
+func (a *fakeProvisionAssembler) SetRegionReservedForReclaim(_ string, _ int) error {
+	return nil
+}
+
+func (a *fakeProvisionAssembler) ClearRegionReservedForReclaim(_ string) {
+}
+
+func TestProvisionCircuitBreaker(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	fakeClock := testingclock.NewFakePassiveClock(now)
+	goodResult := types.InternalCPUCalculationResult{
+		PoolEntries: map[string]map[int]int{
+			state.PoolNameReclaim: {0: 4, 1: 6},
+		},
+	}
+	assembler := &fakeProvisionAssembler{result: goodResult, boundUpper: true}
+	conf, err := options.NewOptions().Config()
+	require.NoError(t, err)
+	cra := &cpuResourceAdvisor{
+		conf:               conf,
+		advisorUpdated:     true,
+		provisionAssembler: assembler,
+		circuitBreaker:     newProvisionCircuitBreaker(2, time.Minute, fakeClock),
+		emitter:            metrics.DummyMetrics{},
+		clock:              fakeClock,
+	}
+
+	result, boundUpper, err := cra.assembleProvision()
+	require.NoError(t, err)
+	assert.True(t, boundUpper)
+	assert.Equal(t, goodResult, result)
+
+	// failures below threshold are returned as they are
+	assembler.result = types.InternalCPUCalculationResult{}
+	assembler.err = fmt.Errorf("metaserver unavailable")
+	_, _, err = cra.assembleProvision()
+	assert.Error(t, err)
+
+	// failures reaching threshold open the circuit and freeze the last-known-good result
+	result, boundUpper, err = cra.assembleProvision()
+	require.NoError(t, err)
+	assert.False(t, boundUpper)
+	assert.Equal(t, goodResult, result)
+
+	headroom, err := cra.getHeadroom()
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), headroom.Value())
+
+	// assembler is not called during cooldown
+	calls := assembler.calls
+	fakeClock.SetTime(now.Add(30 * time.Second))
+	result, _, err = cra.assembleProvision()
+	require.NoError(t, err)
+	assert.Equal(t, goodResult, result)
+	assert.Equal(t, calls, assembler.calls)
+
+	// failed recovery test after cooldown opens the circuit again
+	fakeClock.SetTime(now.Add(2 * time.Minute))
+	result, _, err = cra.assembleProvision()
+	require.NoError(t, err)
+	assert.Equal(t, goodResult, result)
+	assert.Equal(t, calls+1, assembler.calls)
+	assert.Equal(t, circuitOpen, cra.circuitBreaker.state)
+
+	// successful recovery test closes the circuit
+	recoveredResult := types.InternalCPUCalculationResult{
+		PoolEntries: map[string]map[int]int{
+			state.PoolNameReclaim: {0: 8, 1: 8},
+		},
+	}
+	assembler.result = recoveredResult
+	assembler.err = nil
+	fakeClock.SetTime(now.Add(4 * time.Minute))
+	result, boundUpper, err = cra.assembleProvision()
+	require.NoError(t, err)
+	assert.True(t, boundUpper)
+	assert.Equal(t, recoveredResult, result)
+	assert.Equal(t, circuitClosed, cra.circuitBreaker.state)
+	_, frozen := cra.circuitBreaker.frozenResult()
+	assert.False(t, frozen)
+}
+
+func TestLastBoundUpper(t *testing.T) {
+	t.Parallel()
+
+	fakeClock := testingclock.NewFakePassiveClock(time.Now())
+	assembler := &fakeProvisionAssembler{result: types.InternalCPUCalculationResult{}}
+	conf, err := options.NewOptions().Config()
+	require.NoError(t, err)
+	cra := &cpuResourceAdvisor{
+		conf:               conf,
+		provisionAssembler: assembler,
+		circuitBreaker:     newProvisionCircuitBreaker(1, time.Minute, fakeClock),
+		emitter:            metrics.DummyMetrics{},
+		clock:              fakeClock,
+	}
+	assert.False(t, cra.LastBoundUpper())
+
+	for _, boundUpper := range []bool{true, false, true} {
+		assembler.boundUpper = boundUpper
+		_, got, err := cra.assembleProvision()
+		require.NoError(t, err)
+		assert.Equal(t, got, cra.LastBoundUpper())
+	}
+
+	// failed assembling keeps the value of the last successful one
+	assembler.boundUpper, assembler.err = false, fmt.Errorf("metaserver unavailable")
+	_, _, _ = cra.assembleProvision()
+	assert.True(t, cra.LastBoundUpper())
+
+	// concurrent reads are safe along with assembling
+	assembler.err = nil
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			_ = cra.LastBoundUpper()
+		}
+	}()
+	_, _, _ = cra.assembleProvisionPartial(nil)
+	<-done
+}
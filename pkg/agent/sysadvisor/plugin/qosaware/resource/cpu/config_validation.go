@@ -0,0 +1,163 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"fmt"
+	"sort"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/assembler/provisionassembler"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+// ValidationIssue describes an inconsistency of configuration, either by itself or against node topology
+type ValidationIssue struct {
+	// Field is the name of the configuration field (or fields) the issue is about
+	Field   string
+	Message string
+}
+
+func (i ValidationIssue) String() string {
+	return fmt.Sprintf("%v: %v", i.Field, i.Message)
+}
+
+// ValidateConfig cross-checks the given configuration against current node topology, and returns all issues
+// found without performing any assembly, so that operators can tell whether a new configuration is applicable
+// before rolling it out; empty result means no issue is found.
+func (cra *cpuResourceAdvisor) ValidateConfig(conf *config.Configuration) []ValidationIssue {
+	var issues []ValidationIssue
+	addIssue := func(field, format string, args ...interface{}) {
+		issues = append(issues, ValidationIssue{Field: field, Message: fmt.Sprintf(format, args...)})
+	}
+
+	assemblerConf, err := provisionassembler.ResolveAssemblerConfig(conf, cra.clock.Now())
+	if err != nil {
+		addIssue("CPUProvisionAssemblerConfiguration", "%v", err)
+		return issues
+	}
+
+	numas := cra.metaServer.CPUDetails.NUMANodes()
+	nodeCapacity := cra.metaServer.CPUDetails.CPUs().Size()
+
+	// numas referenced by configuration must exist
+	checkNuma := func(field string, numaID int) {
+		if !numas.Contains(numaID) {
+			addIssue(field, "numa %v doesn't exist, valid numas are %v", numaID, numas.String())
+		}
+	}
+	for _, poolName := range sets.StringKeySet(assemblerConf.SharePoolNUMAAffinity).List() {
+		checkNuma(fmt.Sprintf("SharePoolNUMAAffinity[%v]", poolName), assemblerConf.SharePoolNUMAAffinity[poolName])
+	}
+	for _, numaID := range assemblerConf.ExcludedReclaimNumas {
+		checkNuma("ExcludedReclaimNumas", numaID)
+	}
+	for _, numaID := range assemblerConf.ReclaimReservationPreferredNumas {
+		checkNuma("ReclaimReservationPreferredNumas", numaID)
+	}
+	overriddenNumas := make([]int, 0, len(assemblerConf.ReservedForReclaimOverrides))
+	for numaID := range assemblerConf.ReservedForReclaimOverrides {
+		overriddenNumas = append(overriddenNumas, numaID)
+	}
+	sort.Ints(overriddenNumas)
+	for _, numaID := range overriddenNumas {
+		checkNuma("ReservedForReclaimOverrides", numaID)
+	}
+
+	// reserved values must fit numa capacity
+	reservePool := general.SumUpMapValues(assemblerConf.ReservePoolComposition)
+	reservedForReclaim := cra.resolveReservedForReclaim(conf, assemblerConf)
+	for _, numaID := range numas.ToSliceInt() {
+		capacity := cra.metaServer.CPUDetails.CPUsInNUMANodes(numaID).Size()
+		if reserved := reservePool + reservedForReclaim[numaID]; reserved > capacity {
+			addIssue("ReservePoolComposition,ReservedForReclaim", "reserved %v (reserve pool %v, reserved for reclaim %v) exceeds capacity %v of numa %v",
+				reserved, reservePool, reservedForReclaim[numaID], capacity, numaID)
+		}
+	}
+	if assemblerConf.ReclaimReservationSize > nodeCapacity {
+		addIssue("ReclaimReservationSize", "reclaim reservation size %v exceeds node capacity %v", assemblerConf.ReclaimReservationSize, nodeCapacity)
+	}
+	if reserved := assemblerConf.ReclaimReservationSize + assemblerConf.ReclaimCriticalReservationSize; assemblerConf.ReclaimCriticalReservationSize > 0 &&
+		reserved > nodeCapacity {
+		addIssue("ReclaimCriticalReservationSize", "reclaim critical reservation size %v plus reclaim reservation size %v exceeds node capacity %v",
+			assemblerConf.ReclaimCriticalReservationSize, assemblerConf.ReclaimReservationSize, nodeCapacity)
+	}
+	if chunkSize := conf.ReclaimHeadroomChunkSize; chunkSize < 0 || chunkSize > nodeCapacity {
+		addIssue("ReclaimHeadroomChunkSize", "reclaim headroom chunk size %v must be within [0, %v]", chunkSize, nodeCapacity)
+	}
+
+	// ceilings must not be below floors
+	if ceiling := assemblerConf.ReclaimCeiling; ceiling > 0 {
+		if ceiling < assemblerConf.ReclaimReservationSize {
+			addIssue("ReclaimCeiling", "reclaim ceiling %v is below reclaim reservation size %v", ceiling, assemblerConf.ReclaimReservationSize)
+		}
+		total := 0
+		for _, reserved := range reservedForReclaim {
+			total += reserved
+		}
+		if ceiling < total {
+			addIssue("ReclaimCeiling", "reclaim ceiling %v is below reserved for reclaim %v in total", ceiling, total)
+		}
+	}
+	groupBudget := 0
+	for _, groupName := range sets.StringKeySet(assemblerConf.RegionGroups).List() {
+		group := assemblerConf.RegionGroups[groupName]
+		minSize := 0
+		for _, poolName := range group.OwnerPoolNames.List() {
+			minSize += assemblerConf.SharePoolMinSizes[poolName]
+		}
+		if group.Budget < minSize {
+			addIssue(fmt.Sprintf("RegionGroups[%v]", groupName), "budget %v is below min sizes %v of its pools in total", group.Budget, minSize)
+		}
+		groupBudget += group.Budget
+	}
+	if groupBudget > nodeCapacity {
+		addIssue("RegionGroups", "budgets %v in total exceed node capacity %v", groupBudget, nodeCapacity)
+	}
+	if minSize := general.SumUpMapValues(assemblerConf.SharePoolMinSizes); minSize > nodeCapacity {
+		addIssue("SharePoolMinSizes", "min sizes %v in total exceed node capacity %v", minSize, nodeCapacity)
+	}
+
+	return issues
+}
+
+// resolveReservedForReclaim resolves reserved for reclaim of each numa as provision assembler does, i.e.
+// from dynamic configuration, overridden by percentage of numa capacity and absolute values of numas
+func (cra *cpuResourceAdvisor) resolveReservedForReclaim(conf *config.Configuration,
+	assemblerConf *provisionassembler.AssemblerConfig) map[int]int {
+	coreNumReservedForReclaim := conf.GetDynamicConfiguration().MinReclaimedResourceForAllocate[v1.ResourceCPU]
+	reserved := make(map[int]int)
+	if coreNumReservedForReclaim.Value() > 0 {
+		reserved = machine.GetCoreNumReservedForReclaim(int(coreNumReservedForReclaim.Value()), cra.metaServer.NumNUMANodes)
+	}
+
+	for _, numaID := range cra.metaServer.CPUDetails.NUMANodes().ToSliceInt() {
+		if assemblerConf.ReservedForReclaimPercentage > 0 {
+			capacity := cra.metaServer.CPUDetails.CPUsInNUMANodes(numaID).Size()
+			reserved[numaID] = provisionassembler.ResolveReservedByPercentage(capacity,
+				assemblerConf.ReservedForReclaimPercentage, assemblerConf.ReservedForReclaimRoundUp)
+		}
+		if v, ok := assemblerConf.ReservedForReclaimOverrides[numaID]; ok {
+			reserved[numaID] = v
+		}
+	}
+	return reserved
+}
@@ -0,0 +1,141 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/util/sets"
+
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/metric"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+func TestValidateConfig(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		modify     func(conf *config.Configuration)
+		wantIssues []ValidationIssue
+	}{
+		{
+			name:   "valid config",
+			modify: func(conf *config.Configuration) {},
+		},
+		{
+			name: "invalid assembler config",
+			modify: func(conf *config.Configuration) {
+				conf.ReclaimCeiling = -1
+			},
+			wantIssues: []ValidationIssue{
+				{Field: "CPUProvisionAssemblerConfiguration", Message: "reclaim ceiling -1 must not be negative"},
+			},
+		},
+		{
+			name: "nonexistent numas",
+			modify: func(conf *config.Configuration) {
+				conf.SharePoolNUMAAffinity = map[string]int{"share-a": 1, "share-b": 2}
+				conf.ExcludedReclaimNumas = []int{3}
+				conf.ReclaimReservationPreferredNumas = []int{0, 4}
+				conf.ReservedForReclaimOverrides = map[int]int{5: 1}
+			},
+			wantIssues: []ValidationIssue{
+				{Field: "SharePoolNUMAAffinity[share-b]", Message: "numa 2 doesn't exist, valid numas are 0-1"},
+				{Field: "ExcludedReclaimNumas", Message: "numa 3 doesn't exist, valid numas are 0-1"},
+				{Field: "ReclaimReservationPreferredNumas", Message: "numa 4 doesn't exist, valid numas are 0-1"},
+				{Field: "ReservedForReclaimOverrides", Message: "numa 5 doesn't exist, valid numas are 0-1"},
+			},
+		},
+		{
+			name: "reserved exceeding capacity",
+			modify: func(conf *config.Configuration) {
+				conf.ReservePoolComposition = map[string]int{"system": 10, "kubelet": 30}
+				conf.ReservedForReclaimOverrides = map[int]int{1: 10}
+				conf.ReclaimReservationSize = 100
+				conf.ReclaimHeadroomChunkSize = 200
+			},
+			wantIssues: []ValidationIssue{
+				{Field: "ReservePoolComposition,ReservedForReclaim", Message: "reserved 50 (reserve pool 40, reserved for reclaim 10) exceeds capacity 48 of numa 1"},
+				{Field: "ReclaimReservationSize", Message: "reclaim reservation size 100 exceeds node capacity 96"},
+				{Field: "ReclaimHeadroomChunkSize", Message: "reclaim headroom chunk size 200 must be within [0, 96]"},
+			},
+		},
+		{
+			name: "ceilings below floors",
+			modify: func(conf *config.Configuration) {
+				conf.ReclaimCeiling = 6
+				conf.ReclaimReservationSize = 8
+				conf.ReservedForReclaimOverrides = map[int]int{0: 4, 1: 4}
+				conf.SharePoolMinSizes = map[string]int{"share-a": 10, "share-b": 20, "share-c": 60}
+				conf.RegionGroups = map[string]cpu.RegionGroup{
+					"group-a": {OwnerPoolNames: sets.NewString("share-a", "share-b"), Budget: 20},
+					"group-b": {OwnerPoolNames: sets.NewString("share-c"), Budget: 80},
+				}
+			},
+			wantIssues: []ValidationIssue{
+				{Field: "ReclaimCeiling", Message: "reclaim ceiling 6 is below reclaim reservation size 8"},
+				{Field: "ReclaimCeiling", Message: "reclaim ceiling 6 is below reserved for reclaim 8 in total"},
+				{Field: "RegionGroups[group-a]", Message: "budget 20 is below min sizes 30 of its pools in total"},
+				{Field: "RegionGroups", Message: "budgets 100 in total exceed node capacity 96"},
+			},
+		},
+		{
+			name: "reserved for reclaim from dynamic config",
+			modify: func(conf *config.Configuration) {
+				conf.GetDynamicConfiguration().MinReclaimedResourceForAllocate = v1.ResourceList{
+					v1.ResourceCPU: resource.MustParse("8"),
+				}
+				conf.ReclaimCeiling = 6
+			},
+			wantIssues: []ValidationIssue{
+				{Field: "ReclaimCeiling", Message: "reclaim ceiling 6 is below reserved for reclaim 8 in total"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ckDir, err := ioutil.TempDir("", "checkpoint-TestValidateConfig")
+			require.NoError(t, err)
+			defer func() { _ = os.RemoveAll(ckDir) }()
+
+			sfDir, err := ioutil.TempDir("", "statefile")
+			require.NoError(t, err)
+			defer func() { _ = os.RemoveAll(sfDir) }()
+
+			conf := generateTestConfiguration(t, ckDir, sfDir)
+			mf := metric.NewFakeMetricsFetcher(metrics.DummyMetrics{}).(*metric.FakeMetricsFetcher)
+			advisor, _ := newTestCPUResourceAdvisor(t, nil, conf, mf, nil)
+
+			newConf := generateTestConfiguration(t, ckDir, sfDir)
+			tt.modify(newConf)
+			assert.Equal(t, tt.wantIssues, advisor.ValidateConfig(newConf))
+		})
+	}
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"math"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+// evictionRiskVolatilityFactor is the number of standard deviations added to the usage, so that
+// the estimated usage is rarely exceeded if usage is normally distributed around the latest sample
+const evictionRiskVolatilityFactor = 2
+
+// GetEvictionRisk returns eviction risk of reclaimed workloads in [0, 1] estimated in the latest update
+func (cra *cpuResourceAdvisor) GetEvictionRisk() float64 {
+	cra.mutex.RLock()
+	defer cra.mutex.RUnlock()
+
+	return cra.evictionRisk
+}
+
+// updateEvictionRisk samples share and isolation usage on non-binding numas, and estimates eviction risk of
+// reclaimed workloads as the ratio of the pessimistic usage, i.e. the latest usage plus evictionRiskVolatilityFactor
+// standard deviations of recent samples, to resource available on non-binding numas, capped into [0, 1].
+// reclaimed workloads are squeezed out once share and isolation usage reaches available, so the closer and the
+// more volatile the usage is, the higher the risk is; must be called with lock held
+func (cra *cpuResourceAdvisor) updateEvictionRisk() {
+	usage := cra.getNonBindingUsage()
+	cra.nonBindingUsageHistory = append(cra.nonBindingUsageHistory, usage)
+	if window := cra.conf.EvictionRiskWindow; window > 0 && len(cra.nonBindingUsageHistory) > window {
+		cra.nonBindingUsageHistory = cra.nonBindingUsageHistory[len(cra.nonBindingUsageHistory)-window:]
+	}
+
+	available := 0
+	for _, numaID := range cra.nonBindingNumas.ToSliceInt() {
+		available += cra.numaAvailable[numaID]
+	}
+
+	stdDev := standardDeviation(cra.nonBindingUsageHistory)
+	estimated := usage + evictionRiskVolatilityFactor*stdDev
+	risk := 0.0
+	if available > 0 {
+		risk = math.Max(math.Min(estimated/float64(available), 1), 0)
+	} else if estimated > 0 {
+		risk = 1
+	}
+	cra.evictionRisk = risk
+
+	klog.Infof("[qosaware-cpu] eviction risk %.2f: usage %.2f, standard deviation %.2f, available %v",
+		risk, usage, stdDev, available)
+	_ = cra.emitter.StoreFloat64(metricCPUAdvisorEvictionRisk, risk, metrics.MetricTypeNameRaw)
+}
+
+// getNonBindingUsage returns the sum of cpu usage of containers in share and isolation regions on non-binding numas
+func (cra *cpuResourceAdvisor) getNonBindingUsage() float64 {
+	usage := 0.0
+	for _, r := range cra.regionMap {
+		if r.Type() != types.QoSRegionTypeShare && r.Type() != types.QoSRegionTypeIsolation {
+			continue
+		}
+		if !r.GetBindingNumas().IsSubsetOf(cra.nonBindingNumas) {
+			continue
+		}
+		for podUID, containerNames := range r.GetPods() {
+			for containerName := range containerNames {
+				m, err := cra.metaServer.GetContainerMetric(podUID, containerName, consts.MetricCPUUsageContainer)
+				if err != nil {
+					klog.Warningf("[qosaware-cpu] get cpu usage of %v/%v failed: %v", podUID, containerName, err)
+					continue
+				}
+				usage += m.Value
+			}
+		}
+	}
+	return usage
+}
+
+// standardDeviation returns the population standard deviation of samples
+func standardDeviation(samples []float64) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+
+	mean := 0.0
+	for _, v := range samples {
+		mean += v
+	}
+	mean /= float64(len(samples))
+
+	variance := 0.0
+	for _, v := range samples {
+		variance += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(variance / float64(len(samples)))
+}
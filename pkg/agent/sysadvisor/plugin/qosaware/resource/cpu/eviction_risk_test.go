@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/metric"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+	utilmetric "github.com/kubewharf/katalyst-core/pkg/util/metric"
+)
+
+func TestGetEvictionRisk(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name           string
+		shareUsages    []float64
+		dedicatedUsage float64
+		wantMinRisk    float64
+		wantMaxRisk    float64
+	}{
+		{
+			name:        "near saturation with volatile usage",
+			shareUsages: []float64{8, 9.5, 9},
+			wantMinRisk: 0.95,
+			wantMaxRisk: 1,
+		},
+		{
+			name:           "abundant headroom with stable usage",
+			shareUsages:    []float64{2, 2.2, 2},
+			dedicatedUsage: 20,
+			wantMinRisk:    0.15,
+			wantMaxRisk:    0.3,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf, err := options.NewOptions().Config()
+			require.NoError(t, err)
+
+			share := region.NewQoSRegionBase("share-r", state.PoolNameShare, types.QoSRegionTypeShare,
+				conf, struct{}{}, nil, nil, nil)
+			share.SetBindingNumas(machine.NewCPUSet(0))
+			require.NoError(t, share.AddContainer(&types.ContainerInfo{PodUID: "uid1", ContainerName: "c"}))
+			dedicated := region.NewQoSRegionBase("dedicated-r", state.PoolNameDedicated, types.QoSRegionTypeDedicatedNumaExclusive,
+				conf, struct{}{}, nil, nil, nil)
+			dedicated.SetBindingNumas(machine.NewCPUSet(1))
+			require.NoError(t, dedicated.AddContainer(&types.ContainerInfo{PodUID: "uid2", ContainerName: "c"}))
+
+			mf := metric.NewFakeMetricsFetcher(metrics.DummyMetrics{}).(*metric.FakeMetricsFetcher)
+			advisor := &cpuResourceAdvisor{
+				conf: conf,
+				regionMap: map[string]region.QoSRegion{
+					share.Name():     share,
+					dedicated.Name(): dedicated,
+				},
+				numaAvailable:   map[int]int{0: 10, 1: 22},
+				nonBindingNumas: machine.NewCPUSet(0),
+				metaServer: &metaserver.MetaServer{
+					MetaAgent: &agent.MetaAgent{MetricsFetcher: mf},
+				},
+				emitter: metrics.DummyMetrics{},
+			}
+
+			now := time.Now()
+			mf.SetContainerMetric("uid2", "c", consts.MetricCPUUsageContainer, utilmetric.MetricData{Value: tt.dedicatedUsage, Time: &now})
+			for _, usage := range tt.shareUsages {
+				mf.SetContainerMetric("uid1", "c", consts.MetricCPUUsageContainer, utilmetric.MetricData{Value: usage, Time: &now})
+				advisor.updateEvictionRisk()
+			}
+
+			risk := advisor.GetEvictionRisk()
+			assert.GreaterOrEqual(t, risk, tt.wantMinRisk)
+			assert.LessOrEqual(t, risk, tt.wantMaxRisk)
+		})
+	}
+}
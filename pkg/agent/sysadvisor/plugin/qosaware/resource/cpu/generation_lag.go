@@ -0,0 +1,66 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const (
+	metricCPUAdvisorGenerationLag = "cpu_advisor_generation_lag"
+)
+
+// nextGeneration returns the generation of the next assembled result; must be called with lock held
+func (cra *cpuResourceAdvisor) nextGeneration() uint64 {
+	cra.generation++
+	return cra.generation
+}
+
+// Ack acknowledges that results up to the generation are consumed by cpu server, and generations
+// older than the acknowledged one are ignored, since results may be consumed out of order
+func (cra *cpuResourceAdvisor) Ack(generation uint64) {
+	for {
+		acked := cra.ackedGeneration.Load()
+		if generation <= acked {
+			return
+		}
+		if cra.ackedGeneration.CAS(acked, generation) {
+			break
+		}
+	}
+	cra.emitGenerationLag()
+}
+
+// generationLag returns the number of generations the latest result pushed to cpu server is ahead of
+// the latest one acknowledged; growing lag indicates cpu server is not keeping up with the advisor
+func (cra *cpuResourceAdvisor) generationLag() uint64 {
+	pushed, acked := cra.pushedGeneration.Load(), cra.ackedGeneration.Load()
+	if pushed <= acked {
+		return 0
+	}
+	return pushed - acked
+}
+
+func (cra *cpuResourceAdvisor) emitGenerationLag() {
+	lag := cra.generationLag()
+	if lag > 0 {
+		klog.V(4).Infof("[qosaware-cpu] cpu server lags behind by %v generations", lag)
+	}
+	_ = cra.emitter.StoreInt64(metricCPUAdvisorGenerationLag, int64(lag), metrics.MetricTypeNameRaw)
+}
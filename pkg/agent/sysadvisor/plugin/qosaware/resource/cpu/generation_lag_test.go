@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	testingclock "k8s.io/utils/clock/testing"
+
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+func TestGenerationLag(t *testing.T) {
+	t.Parallel()
+
+	conf, err := options.NewOptions().Config()
+	require.NoError(t, err)
+	conf.ProvisionForcePushInterval = 0
+
+	fakeClock := testingclock.NewFakePassiveClock(time.Now())
+	assembler := &fakeProvisionAssembler{result: types.InternalCPUCalculationResult{
+		PoolEntries: map[string]map[int]int{
+			state.PoolNameReclaim: {-1: 4},
+		},
+	}}
+	cra := &cpuResourceAdvisor{
+		conf:               conf,
+		sendCh:             make(chan types.InternalCPUCalculationResult, 4),
+		provisionAssembler: assembler,
+		circuitBreaker:     newProvisionCircuitBreaker(0, time.Minute, fakeClock),
+		emitter:            metrics.DummyMetrics{},
+		clock:              fakeClock,
+	}
+	assembleAndNotify := func() {
+		result, boundUpper, err := cra.assembleProvision()
+		require.NoError(t, err)
+		cra.notifyProvision(result, boundUpper)
+	}
+	consume := func() {
+		result := <-cra.sendCh
+		cra.Ack(result.Generation)
+	}
+
+	// lag grows while consumer falls behind
+	for i := 1; i <= 3; i++ {
+		assembleAndNotify()
+		assert.Equal(t, uint64(i), cra.generationLag())
+	}
+	assert.Equal(t, uint64(3), cra.lastCalculationResult.Generation)
+
+	// lag recovers as consumer catches up
+	consume()
+	assert.Equal(t, uint64(2), cra.generationLag())
+	consume()
+	consume()
+	assert.Equal(t, uint64(0), cra.generationLag())
+
+	// acknowledging an older generation never grows lag back
+	assembleAndNotify()
+	consume()
+	cra.Ack(1)
+	assert.Equal(t, uint64(0), cra.generationLag())
+	assert.Equal(t, uint64(4), cra.ackedGeneration.Load())
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/assembler/headroomassembler"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+// HeadroomCalculator derives cpu headroom from the latest provision result and cores reserved for reclaim
+// of each numa, so that how much headroom is offered is decoupled from how pools are assembled
+type HeadroomCalculator interface {
+	Calculate(result types.InternalCPUCalculationResult, reserved map[int]int) (resource.Quantity, error)
+}
+
+// assemblerHeadroomCalculator is the built-in calculator delegating to the configured headroom assembler,
+// which estimates headroom from regions and pool info in meta cache rather than the given result
+type assemblerHeadroomCalculator struct {
+	assembler headroomassembler.HeadroomAssembler
+}
+
+func (c *assemblerHeadroomCalculator) Calculate(_ types.InternalCPUCalculationResult, _ map[int]int) (resource.Quantity, error) {
+	if c.assembler == nil {
+		return resource.Quantity{}, fmt.Errorf("no legal assembler")
+	}
+	return c.assembler.GetHeadroom()
+}
+
+// reclaimPoolHeadroomCalculator is the built-in calculator serving frozen provision results, which offers
+// the whole reclaim pool in the given result, since meta cache is not trusted while provision circuit is open
+type reclaimPoolHeadroomCalculator struct {
+	reclaimPoolName string
+}
+
+func (c *reclaimPoolHeadroomCalculator) Calculate(result types.InternalCPUCalculationResult, _ map[int]int) (resource.Quantity, error) {
+	reclaimPoolSize := 0
+	for _, size := range result.PoolEntries[c.reclaimPoolName] {
+		reclaimPoolSize += size
+	}
+	return *resource.NewQuantity(int64(reclaimPoolSize), resource.DecimalSI), nil
+}
+
+// SetHeadroomCalculator injects a custom headroom calculator in place of the built-in one,
+// and nil restores the built-in calculator
+func (cra *cpuResourceAdvisor) SetHeadroomCalculator(calculator HeadroomCalculator) {
+	cra.mutex.Lock()
+	defer cra.mutex.Unlock()
+
+	cra.headroomCalculator = calculator
+	klog.Infof("[qosaware-cpu] headroom calculator set to %T", calculator)
+}
+
+// getHeadroomCalculator returns the injected calculator if any, or the built-in one; must be called with lock held
+func (cra *cpuResourceAdvisor) getHeadroomCalculator() HeadroomCalculator {
+	if cra.headroomCalculator != nil {
+		return cra.headroomCalculator
+	}
+	return &assemblerHeadroomCalculator{assembler: cra.headroomAssembler}
+}
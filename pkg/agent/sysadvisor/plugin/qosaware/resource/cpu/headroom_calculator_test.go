@@ -0,0 +1,111 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/clock"
+
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+type fakeHeadroomCalculator struct {
+	result   types.InternalCPUCalculationResult
+	reserved map[int]int
+}
+
+// Calculate offers half of the reclaim pool in the given result, besides cores reserved for reclaim
+func (c *fakeHeadroomCalculator) Calculate(result types.InternalCPUCalculationResult, reserved map[int]int) (resource.Quantity, error) {
+	c.result, c.reserved = result, reserved
+
+	headroom := 0
+	for _, size := range result.PoolEntries[state.PoolNameReclaim] {
+		headroom += size / 2
+	}
+	for _, size := range reserved {
+		headroom += size
+	}
+	return *resource.NewQuantity(int64(headroom), resource.DecimalSI), nil
+}
+
+func TestSetHeadroomCalculator(t *testing.T) {
+	t.Parallel()
+
+	result := types.InternalCPUCalculationResult{
+		PoolEntries: map[string]map[int]int{
+			state.PoolNameReclaim: {0: 8, 1: 4},
+		},
+	}
+	reservedForReclaim := map[int]int{0: 1, 1: 1}
+	conf, err := options.NewOptions().Config()
+	require.NoError(t, err)
+	advisor := &cpuResourceAdvisor{
+		conf:                  conf,
+		advisorUpdated:        true,
+		headroomAssembler:     &fakeHeadroomAssembler{headroom: resource.MustParse("20")},
+		reservedForReclaim:    reservedForReclaim,
+		lastCalculationResult: result,
+		circuitBreaker:        newProvisionCircuitBreaker(0, 0, clock.RealClock{}),
+	}
+
+	// built-in calculator delegates to headroom assembler
+	headroom, err := advisor.GetHeadroom()
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), headroom.Value())
+
+	calculator := &fakeHeadroomCalculator{}
+	advisor.SetHeadroomCalculator(calculator)
+	headroom, err = advisor.GetHeadroom()
+	require.NoError(t, err)
+	assert.Equal(t, int64(4+2+1+1), headroom.Value())
+	assert.Equal(t, result, calculator.result)
+	assert.Equal(t, reservedForReclaim, calculator.reserved)
+
+	// frozen provision result is served through the injected calculator if circuit is open
+	frozenResult := types.InternalCPUCalculationResult{
+		PoolEntries: map[string]map[int]int{
+			state.PoolNameReclaim: {0: 4, 1: 4},
+		},
+	}
+	advisor.circuitBreaker = newProvisionCircuitBreaker(1, time.Minute, clock.RealClock{})
+	advisor.circuitBreaker.onSuccess(frozenResult)
+	advisor.circuitBreaker.onFailure()
+	headroom, err = advisor.GetHeadroom()
+	require.NoError(t, err)
+	assert.Equal(t, int64(2+2+1+1), headroom.Value())
+	assert.Equal(t, frozenResult, calculator.result)
+
+	// built-in calculator offers the whole reclaim pool of the frozen result
+	advisor.SetHeadroomCalculator(nil)
+	headroom, err = advisor.GetHeadroom()
+	require.NoError(t, err)
+	assert.Equal(t, int64(8), headroom.Value())
+
+	// nil restores the built-in calculator
+	advisor.circuitBreaker = newProvisionCircuitBreaker(0, 0, clock.RealClock{})
+	advisor.SetHeadroomCalculator(nil)
+	headroom, err = advisor.GetHeadroom()
+	require.NoError(t, err)
+	assert.Equal(t, int64(20), headroom.Value())
+}
@@ -0,0 +1,46 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+)
+
+// GetRawHeadroom returns reclaim headroom before rounding to ReclaimHeadroomChunkSize, for diagnostics
+func (cra *cpuResourceAdvisor) GetRawHeadroom() (resource.Quantity, error) {
+	cra.mutex.RLock()
+	defer cra.mutex.RUnlock()
+
+	return cra.getHeadroom()
+}
+
+// roundHeadroomToChunk rounds headroom down to a multiple of ReclaimHeadroomChunkSize, and headroom
+// below one chunk is reported as zero, so that schedulers never admit sub-viable slices
+func (cra *cpuResourceAdvisor) roundHeadroomToChunk(headroom resource.Quantity) resource.Quantity {
+	chunkSize := int64(cra.conf.ReclaimHeadroomChunkSize)
+	if chunkSize <= 0 {
+		return headroom
+	}
+
+	chunkMilli := chunkSize * 1000
+	rounded := *resource.NewQuantity(headroom.MilliValue()/chunkMilli*chunkSize, resource.DecimalSI)
+	if rounded.Cmp(headroom) != 0 {
+		klog.Infof("[qosaware-cpu] round headroom %v down to %v by chunk size %v", headroom.String(), rounded.String(), chunkSize)
+	}
+	return rounded
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/clock"
+
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
+)
+
+func TestReclaimHeadroomChunkSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		chunkSize    int
+		rawHeadroom  string
+		wantHeadroom int64
+	}{
+		{
+			name:         "headroom is kept as is without chunk size",
+			rawHeadroom:  "3",
+			wantHeadroom: 3,
+		},
+		{
+			name:         "headroom is rounded down to a multiple of chunk size",
+			chunkSize:    2,
+			rawHeadroom:  "3",
+			wantHeadroom: 2,
+		},
+		{
+			name:         "fractional headroom is rounded down as well",
+			chunkSize:    2,
+			rawHeadroom:  "5500m",
+			wantHeadroom: 4,
+		},
+		{
+			name:         "headroom below one chunk is reported as zero",
+			chunkSize:    4,
+			rawHeadroom:  "3",
+			wantHeadroom: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf, err := options.NewOptions().Config()
+			require.NoError(t, err)
+			conf.ReclaimHeadroomChunkSize = tt.chunkSize
+
+			rawHeadroom := resource.MustParse(tt.rawHeadroom)
+			advisor := &cpuResourceAdvisor{
+				conf:              conf,
+				advisorUpdated:    true,
+				headroomAssembler: &fakeHeadroomAssembler{headroom: rawHeadroom},
+				circuitBreaker:    newProvisionCircuitBreaker(0, 0, clock.RealClock{}),
+			}
+
+			headroom, err := advisor.GetHeadroom()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantHeadroom, headroom.Value())
+
+			raw, err := advisor.GetRawHeadroom()
+			require.NoError(t, err)
+			assert.Equal(t, 0, raw.Cmp(rawHeadroom))
+		})
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"math"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+// GetHeadroomConfidence returns confidence in [0, 1] of the latest headroom, which is the product of:
+//  1. maturity: average over regions of time passed since each region is first seen, or since
+//     the last topology (non-binding numas) change if later, divided by HeadroomConfidenceWindow;
+//  2. reliability: 1 / (1 + consecutive provision assembling failures);
+//  3. freshness: 1 minus time passed since the last successful assembling beyond one update period,
+//     divided by HeadroomConfidenceWindow.
+//
+// each factor is capped into [0, 1], and confidence is zero before advisor is ever updated;
+// zero HeadroomConfidenceWindow disables maturity and freshness factors.
+func (cra *cpuResourceAdvisor) GetHeadroomConfidence() float64 {
+	cra.mutex.RLock()
+	defer cra.mutex.RUnlock()
+
+	if !cra.advisorUpdated {
+		return 0
+	}
+
+	now := cra.clock.Now()
+	window := cra.conf.HeadroomConfidenceWindow
+
+	maturity, freshness := 1.0, 1.0
+	if window > 0 {
+		if len(cra.regionMap) > 0 {
+			maturity = 0
+			for regionName := range cra.regionMap {
+				since := cra.regionFirstSeen[regionName]
+				if cra.topologyChangedAt.After(since) {
+					since = cra.topologyChangedAt
+				}
+				maturity += capRatio(now.Sub(since), window)
+			}
+			maturity /= float64(len(cra.regionMap))
+		} else {
+			maturity = capRatio(now.Sub(cra.topologyChangedAt), window)
+		}
+
+		freshness = 1 - capRatio(now.Sub(cra.lastAssembledAt)-cra.period, window)
+	}
+	reliability := 1 / float64(1+cra.circuitBreaker.consecutiveFailures)
+
+	confidence := maturity * reliability * freshness
+	klog.Infof("[qosaware-cpu] headroom confidence %.2f: maturity %.2f, reliability %.2f, freshness %.2f",
+		confidence, maturity, reliability, freshness)
+	_ = cra.emitter.StoreFloat64(metricCPUAdvisorHeadroomConfidence, confidence, metrics.MetricTypeNameRaw)
+
+	return confidence
+}
+
+// updateRegionFirstSeen records first seen time for new regions and cleans up those already gone
+func (cra *cpuResourceAdvisor) updateRegionFirstSeen() {
+	now := cra.clock.Now()
+	for regionName := range cra.regionMap {
+		if _, ok := cra.regionFirstSeen[regionName]; !ok {
+			cra.regionFirstSeen[regionName] = now
+		}
+	}
+	for regionName := range cra.regionFirstSeen {
+		if _, ok := cra.regionMap[regionName]; !ok {
+			delete(cra.regionFirstSeen, regionName)
+		}
+	}
+}
+
+// capRatio returns d divided by window, capped into [0, 1]
+func capRatio(d, window time.Duration) float64 {
+	return math.Max(math.Min(float64(d)/float64(window), 1), 0)
+}
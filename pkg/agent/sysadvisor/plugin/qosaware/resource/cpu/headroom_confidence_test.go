@@ -0,0 +1,123 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	testingclock "k8s.io/utils/clock/testing"
+
+	"github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/metric"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestGetHeadroomConfidence(t *testing.T) {
+	t.Parallel()
+
+	ckDir, err := ioutil.TempDir("", "checkpoint-TestGetHeadroomConfidence")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(ckDir) }()
+
+	sfDir, err := ioutil.TempDir("", "statefile")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(sfDir) }()
+
+	conf := generateTestConfiguration(t, ckDir, sfDir)
+	conf.HeadroomConfidenceWindow = 10 * time.Minute
+	mf := metric.NewFakeMetricsFetcher(metrics.DummyMetrics{}).(*metric.FakeMetricsFetcher)
+	pods := []*v1.Pod{
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "default", UID: "uid1"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "pod2", Namespace: "default", UID: "uid2"}},
+	}
+
+	advisor, metaCache := newTestCPUResourceAdvisor(t, pods, conf, mf, nil)
+	now := time.Now()
+	fakeClock := testingclock.NewFakePassiveClock(now)
+	advisor.clock = fakeClock
+	advisor.startTime = now.Add(-types.StartUpPeriod)
+	advisor.conf.GetDynamicConfiguration().EnableReclaim = true
+
+	_ = metaCache.SetPoolInfo(state.PoolNameReserve, &types.PoolInfo{
+		PoolName: state.PoolNameReserve,
+		TopologyAwareAssignments: map[int]machine.CPUSet{
+			0: machine.MustParse("0"),
+			1: machine.MustParse("24"),
+		},
+	})
+	_ = metaCache.SetPoolInfo(state.PoolNameShare, &types.PoolInfo{
+		PoolName: state.PoolNameShare,
+		TopologyAwareAssignments: map[int]machine.CPUSet{
+			1: machine.MustParse("25"),
+		},
+	})
+	_ = metaCache.SetContainerInfo("uid1", "c1", makeContainerInfo("uid1", "default", "pod1", "c1",
+		consts.PodAnnotationQoSLevelSharedCores, state.PoolNameShare, nil,
+		map[int]machine.CPUSet{
+			1: machine.MustParse("25"),
+		}, 4))
+
+	update := func(elapsed time.Duration) {
+		fakeClock.SetTime(now.Add(elapsed))
+		advisor.update()
+		<-advisor.sendCh
+	}
+
+	// no confidence before advisor is updated
+	assert.Equal(t, 0.0, advisor.GetHeadroomConfidence())
+
+	// newly created regions are immature
+	update(0)
+	assert.Equal(t, 0.0, advisor.GetHeadroomConfidence())
+
+	update(5 * time.Minute)
+	assert.InDelta(t, 0.5, advisor.GetHeadroomConfidence(), 1e-6)
+
+	update(10 * time.Minute)
+	assert.InDelta(t, 1.0, advisor.GetHeadroomConfidence(), 1e-6)
+
+	// confidence drops after topology changes, i.e. numa 0 is bound by dedicated pod
+	_ = metaCache.SetContainerInfo("uid2", "c1", makeContainerInfo("uid2", "default", "pod2", "c1",
+		consts.PodAnnotationQoSLevelDedicatedCores, state.PoolNameDedicated,
+		map[string]string{consts.PodAnnotationMemoryEnhancementNumaBinding: consts.PodAnnotationMemoryEnhancementNumaBindingEnable},
+		map[int]machine.CPUSet{
+			0: machine.MustParse("1-23,48-71"),
+		}, 36))
+	update(11 * time.Minute)
+	assert.Equal(t, 0.0, advisor.GetHeadroomConfidence())
+
+	// and recovers over time
+	update(16 * time.Minute)
+	assert.InDelta(t, 0.5, advisor.GetHeadroomConfidence(), 1e-6)
+
+	update(21 * time.Minute)
+	assert.InDelta(t, 1.0, advisor.GetHeadroomConfidence(), 1e-6)
+
+	// confidence decays if provision is not assembled any more
+	fakeClock.SetTime(now.Add(26 * time.Minute).Add(advisor.period))
+	assert.InDelta(t, 0.5, advisor.GetHeadroomConfidence(), 1e-6)
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// HeadroomInfo is reclaim headroom together with the context to interpret it, e.g. zero headroom
+// means no slack if reclaim is enabled, but is enforced regardless of slack otherwise
+type HeadroomInfo struct {
+	// Quantity is the headroom returned by GetHeadroom
+	Quantity resource.Quantity
+	// ReclaimEnabled is whether reclaim is effectively enabled, i.e. it's enabled in dynamic
+	// configuration and not suppressed by time windows
+	ReclaimEnabled bool
+	// ReservedFloor is reserved for reclaim in total, which is offered even without slack
+	ReservedFloor resource.Quantity
+}
+
+// GetHeadroomInfo returns reclaim headroom as GetHeadroom does, along with whether reclaim is enabled
+// and the reserved for reclaim floor, so that consumers can tell why headroom is zero
+func (cra *cpuResourceAdvisor) GetHeadroomInfo() (HeadroomInfo, error) {
+	cra.mutex.RLock()
+	defer cra.mutex.RUnlock()
+
+	headroom, err := cra.getHeadroom()
+	if err != nil {
+		return HeadroomInfo{}, err
+	}
+
+	dynamicConf := cra.conf.GetDynamicConfiguration()
+	reservedFloor := 0
+	for _, reserved := range cra.reservedForReclaim {
+		reservedFloor += reserved
+	}
+	return HeadroomInfo{
+		Quantity:       cra.roundHeadroomToChunk(headroom),
+		ReclaimEnabled: dynamicConf.EnableReclaim && !dynamicConf.ReclaimSuppressed(cra.clock.Now()),
+		ReservedFloor:  *resource.NewQuantity(int64(reservedFloor), resource.DecimalSI),
+	}, nil
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/clock"
+
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
+)
+
+func TestGetHeadroomInfo(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		enableReclaim bool
+		headroom      resource.Quantity
+		wantInfo      HeadroomInfo
+	}{
+		{
+			name:          "zero headroom with reclaim disabled",
+			enableReclaim: false,
+			headroom:      resource.MustParse("0"),
+			wantInfo: HeadroomInfo{
+				Quantity:       resource.MustParse("0"),
+				ReclaimEnabled: false,
+				ReservedFloor:  resource.MustParse("2"),
+			},
+		},
+		{
+			name:          "zero headroom without slack",
+			enableReclaim: true,
+			headroom:      resource.MustParse("0"),
+			wantInfo: HeadroomInfo{
+				Quantity:       resource.MustParse("0"),
+				ReclaimEnabled: true,
+				ReservedFloor:  resource.MustParse("2"),
+			},
+		},
+		{
+			name:          "headroom with slack",
+			enableReclaim: true,
+			headroom:      resource.MustParse("10"),
+			wantInfo: HeadroomInfo{
+				Quantity:       resource.MustParse("10"),
+				ReclaimEnabled: true,
+				ReservedFloor:  resource.MustParse("2"),
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf, err := options.NewOptions().Config()
+			require.NoError(t, err)
+			conf.GetDynamicConfiguration().EnableReclaim = tt.enableReclaim
+
+			advisor := &cpuResourceAdvisor{
+				conf:               conf,
+				advisorUpdated:     true,
+				headroomAssembler:  &fakeHeadroomAssembler{headroom: tt.headroom},
+				reservedForReclaim: map[int]int{0: 1, 1: 1},
+				circuitBreaker:     newProvisionCircuitBreaker(0, 0, clock.RealClock{}),
+				clock:              clock.RealClock{},
+			}
+
+			info, err := advisor.GetHeadroomInfo()
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantInfo.Quantity.Value(), info.Quantity.Value())
+			assert.Equal(t, tt.wantInfo.ReclaimEnabled, info.ReclaimEnabled)
+			assert.Equal(t, tt.wantInfo.ReservedFloor.Value(), info.ReservedFloor.Value())
+
+			// quantity is consistent with the simple headroom
+			headroom, err := advisor.GetHeadroom()
+			require.NoError(t, err)
+			assert.Equal(t, headroom.Value(), info.Quantity.Value())
+		})
+	}
+
+	// error is returned as GetHeadroom does before advisor is updated
+	_, err := (&cpuResourceAdvisor{}).GetHeadroomInfo()
+	assert.Error(t, err)
+}
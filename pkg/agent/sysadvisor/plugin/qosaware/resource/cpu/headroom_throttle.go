@@ -0,0 +1,64 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"math"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const (
+	metricCPUAdvisorEffectiveCapacityRatio = "cpu_advisor_effective_capacity_ratio"
+)
+
+// scaleHeadroomByEffectiveCapacity scales headroom by the ratio of effective cpu capacity to the nominal one if
+// EnableThrottleAwareHeadroom is set; headroom is never inflated, and kept as it is if the ratio is unavailable
+func (cra *cpuResourceAdvisor) scaleHeadroomByEffectiveCapacity(headroom resource.Quantity) resource.Quantity {
+	if !cra.conf.EnableThrottleAwareHeadroom {
+		return headroom
+	}
+
+	ratio, ok := cra.getEffectiveCapacityRatio()
+	if !ok {
+		return headroom
+	}
+	_ = cra.emitter.StoreFloat64(metricCPUAdvisorEffectiveCapacityRatio, ratio, metrics.MetricTypeNameRaw)
+
+	scaled := *resource.NewMilliQuantity(int64(math.Floor(float64(headroom.MilliValue())*ratio)), resource.DecimalSI)
+	klog.Infof("[qosaware-cpu] scale headroom %v to %v by effective capacity ratio %v", headroom.String(), scaled.String(), ratio)
+	return scaled
+}
+
+// getEffectiveCapacityRatio returns effective cpu capacity ratio from metaserver capped at 1, and false
+// if it's missing or not positive, since no compute at all is more likely to be bad data than reality
+func (cra *cpuResourceAdvisor) getEffectiveCapacityRatio() (float64, bool) {
+	m, err := cra.metaServer.GetNodeMetric(consts.MetricCPUEffectiveCapacityRatioSystem)
+	if err != nil {
+		klog.V(4).Infof("[qosaware-cpu] effective capacity ratio is unavailable: %v", err)
+		return 0, false
+	}
+	if m.Value <= 0 || math.IsNaN(m.Value) {
+		klog.Warningf("[qosaware-cpu] ignore invalid effective capacity ratio %v", m.Value)
+		return 0, false
+	}
+	return math.Min(m.Value, 1), true
+}
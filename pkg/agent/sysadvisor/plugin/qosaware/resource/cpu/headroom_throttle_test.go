@@ -0,0 +1,108 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/utils/clock"
+
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/metric"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	utilmetric "github.com/kubewharf/katalyst-core/pkg/util/metric"
+)
+
+func TestThrottleAwareHeadroom(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name         string
+		enable       bool
+		noRatio      bool
+		ratio        float64
+		wantHeadroom string
+	}{
+		{
+			name:         "headroom is kept as it is if disabled",
+			ratio:        0.75,
+			wantHeadroom: "20",
+		},
+		{
+			name:         "headroom is scaled by throttled capacity",
+			enable:       true,
+			ratio:        0.75,
+			wantHeadroom: "15",
+		},
+		{
+			name:         "headroom is kept as it is without throttle data",
+			enable:       true,
+			noRatio:      true,
+			wantHeadroom: "20",
+		},
+		{
+			name:         "headroom is never inflated",
+			enable:       true,
+			ratio:        1.2,
+			wantHeadroom: "20",
+		},
+		{
+			name:         "invalid ratio is ignored",
+			enable:       true,
+			ratio:        0,
+			wantHeadroom: "20",
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			conf, err := options.NewOptions().Config()
+			require.NoError(t, err)
+			conf.EnableThrottleAwareHeadroom = tt.enable
+
+			mf := metric.NewFakeMetricsFetcher(metrics.DummyMetrics{}).(*metric.FakeMetricsFetcher)
+			if !tt.noRatio {
+				now := time.Now()
+				mf.SetNodeMetric(consts.MetricCPUEffectiveCapacityRatioSystem, utilmetric.MetricData{Value: tt.ratio, Time: &now})
+			}
+			advisor := &cpuResourceAdvisor{
+				conf:              conf,
+				advisorUpdated:    true,
+				headroomAssembler: &fakeHeadroomAssembler{headroom: resource.MustParse("20")},
+				circuitBreaker:    newProvisionCircuitBreaker(0, 0, clock.RealClock{}),
+				metaServer: &metaserver.MetaServer{
+					MetaAgent: &agent.MetaAgent{MetricsFetcher: mf},
+				},
+				emitter: metrics.DummyMetrics{},
+			}
+
+			headroom, err := advisor.GetHeadroom()
+			require.NoError(t, err)
+			assert.Equal(t, 0, headroom.Cmp(resource.MustParse(tt.wantHeadroom)), "got %v", headroom.String())
+		})
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+// headroomSample is the reclaim pool size of an assembly result together with the time it is published
+type headroomSample struct {
+	timestamp time.Time
+	headroom  float64
+}
+
+// GetHeadroomTrend returns the slope of headroom in cores per second over the recent window, estimated by
+// least squares regression on headroom of assembly results published within the window, and negative slope
+// means headroom is shrinking; window is capped by HeadroomTrendRetention, and at least two samples are required
+func (cra *cpuResourceAdvisor) GetHeadroomTrend(window time.Duration) (float64, error) {
+	cra.mutex.RLock()
+	defer cra.mutex.RUnlock()
+
+	if window <= 0 {
+		return 0, fmt.Errorf("illegal headroom trend window %v", window)
+	}
+
+	since := cra.clock.Now().Add(-window)
+	var samples []headroomSample
+	for _, sample := range cra.headroomHistory {
+		if !sample.timestamp.Before(since) {
+			samples = append(samples, sample)
+		}
+	}
+	if len(samples) < 2 {
+		return 0, fmt.Errorf("insufficient headroom samples in window %v: %v", window, len(samples))
+	}
+
+	slope := headroomSlope(samples)
+	klog.Infof("[qosaware-cpu] headroom trend %.4f/s over %v samples in window %v", slope, len(samples), window)
+	return slope, nil
+}
+
+// recordHeadroomSample appends reclaim pool size of the result to headroom history, and drops samples
+// older than HeadroomTrendRetention; must be called with lock held
+func (cra *cpuResourceAdvisor) recordHeadroomSample(calculationResult types.InternalCPUCalculationResult) {
+	reclaimPoolSize := 0
+	for _, size := range calculationResult.PoolEntries[cra.conf.ReclaimPoolName] {
+		reclaimPoolSize += size
+	}
+
+	now := cra.clock.Now()
+	cra.headroomHistory = append(cra.headroomHistory, headroomSample{timestamp: now, headroom: float64(reclaimPoolSize)})
+
+	since := now.Add(-cra.conf.HeadroomTrendRetention)
+	expired := 0
+	for expired < len(cra.headroomHistory) && cra.headroomHistory[expired].timestamp.Before(since) {
+		expired++
+	}
+	cra.headroomHistory = cra.headroomHistory[expired:]
+}
+
+// headroomSlope returns the least squares slope of headroom against seconds elapsed since the first sample,
+// and zero if all samples are taken at the same time
+func headroomSlope(samples []headroomSample) float64 {
+	n := float64(len(samples))
+	sumX, sumY, sumXY, sumXX := 0.0, 0.0, 0.0, 0.0
+	for _, sample := range samples {
+		x := sample.timestamp.Sub(samples[0].timestamp).Seconds()
+		sumX += x
+		sumY += sample.headroom
+		sumXY += x * sample.headroom
+		sumXX += x * x
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if denominator == 0 {
+		return 0
+	}
+	return (n*sumXY - sumX*sumY) / denominator
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	testingclock "k8s.io/utils/clock/testing"
+
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+func TestGetHeadroomTrend(t *testing.T) {
+	t.Parallel()
+
+	conf, err := options.NewOptions().Config()
+	require.NoError(t, err)
+	conf.HeadroomTrendRetention = 5 * time.Minute
+
+	now := time.Now()
+	fakeClock := testingclock.NewFakePassiveClock(now)
+	cra := &cpuResourceAdvisor{conf: conf, clock: fakeClock}
+
+	resultOfReclaim := func(size int) types.InternalCPUCalculationResult {
+		return types.InternalCPUCalculationResult{
+			PoolEntries: map[string]map[int]int{
+				state.PoolNameReclaim: {0: size / 2, 1: size - size/2},
+			},
+		}
+	}
+
+	// insufficient samples
+	cra.recordHeadroomSample(resultOfReclaim(20))
+	_, err = cra.GetHeadroomTrend(time.Minute)
+	assert.Error(t, err)
+
+	// headroom declines by 2 cores every 10 seconds
+	for i := 1; i <= 6; i++ {
+		fakeClock.SetTime(now.Add(time.Duration(i) * 10 * time.Second))
+		cra.recordHeadroomSample(resultOfReclaim(20 - 2*i))
+	}
+	trend, err := cra.GetHeadroomTrend(time.Minute)
+	require.NoError(t, err)
+	assert.InDelta(t, -0.2, trend, 1e-9)
+
+	// headroom stays flat in the recent window
+	for i := 7; i <= 9; i++ {
+		fakeClock.SetTime(now.Add(time.Duration(i) * 10 * time.Second))
+		cra.recordHeadroomSample(resultOfReclaim(8))
+	}
+	trend, err = cra.GetHeadroomTrend(25 * time.Second)
+	require.NoError(t, err)
+	assert.InDelta(t, 0, trend, 1e-9)
+
+	// samples beyond retention are dropped
+	fakeClock.SetTime(now.Add(10 * time.Minute))
+	cra.recordHeadroomSample(resultOfReclaim(8))
+	assert.Len(t, cra.headroomHistory, 1)
+
+	_, err = cra.GetHeadroomTrend(0)
+	assert.Error(t, err)
+}
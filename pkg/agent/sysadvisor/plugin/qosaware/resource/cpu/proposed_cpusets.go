@@ -0,0 +1,107 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"sort"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+// ProposedCPUSets suggests candidate cpus of each pool in the last provision result to help consumers align
+// affinity, which is only a hint and never authoritative, since cpus are finally assigned by cpu plugin.
+// Each entry is proposed as a contiguous range of the cpus on its numa, and entries without numa binding
+// are proposed out of the cpus on non-binding numas left by binding entries. Reserve pool takes the lowest
+// cpus and reclaim pool the highest, and pools exceeding the cpus left are proposed with fewer cpus.
+func (cra *cpuResourceAdvisor) ProposedCPUSets() map[string]machine.CPUSet {
+	cra.mutex.RLock()
+	defer cra.mutex.RUnlock()
+
+	reclaimPoolName := cra.conf.ReclaimPoolName
+	if reclaimPoolName == "" {
+		reclaimPoolName = state.PoolNameReclaim
+	}
+
+	// numa id to pool sizes, with the faked numa id for entries without numa binding
+	numaPoolSizes := make(map[int]map[string]int)
+	for poolName, entries := range cra.lastCalculationResult.PoolEntries {
+		for numaID, size := range entries {
+			if size <= 0 {
+				continue
+			}
+			if _, ok := numaPoolSizes[numaID]; !ok {
+				numaPoolSizes[numaID] = make(map[string]int)
+			}
+			numaPoolSizes[numaID][poolName] = size
+		}
+	}
+
+	proposed := make(map[string]machine.CPUSet)
+	nonBindingCPUs := cra.metaServer.CPUDetails.CPUsInNUMANodes(cra.nonBindingNumas.ToSliceInt()...)
+	for numaID, poolSizes := range numaPoolSizes {
+		if numaID == cpuadvisor.FakedNUMAID {
+			continue
+		}
+		cpus := cra.metaServer.CPUDetails.CPUsInNUMANodes(numaID)
+		proposeCPUSets(proposed, cpus, poolSizes, reclaimPoolName)
+		nonBindingCPUs = nonBindingCPUs.Difference(cpus)
+	}
+	if poolSizes, ok := numaPoolSizes[cpuadvisor.FakedNUMAID]; ok {
+		proposeCPUSets(proposed, nonBindingCPUs, poolSizes, reclaimPoolName)
+	}
+	return proposed
+}
+
+// proposeCPUSets carves contiguous ranges out of the given cpus for pools in order, and merges them into proposed
+func proposeCPUSets(proposed map[string]machine.CPUSet, cpus machine.CPUSet, poolSizes map[string]int, reclaimPoolName string) {
+	poolNames := make([]string, 0, len(poolSizes))
+	for poolName := range poolSizes {
+		poolNames = append(poolNames, poolName)
+	}
+	// reserve pool first and reclaim pool last, and the others by name
+	rank := func(poolName string) int {
+		switch poolName {
+		case state.PoolNameReserve:
+			return 0
+		case reclaimPoolName:
+			return 2
+		default:
+			return 1
+		}
+	}
+	sort.Slice(poolNames, func(i, j int) bool {
+		if rank(poolNames[i]) != rank(poolNames[j]) {
+			return rank(poolNames[i]) < rank(poolNames[j])
+		}
+		return poolNames[i] < poolNames[j]
+	})
+
+	cpuIDs := cpus.ToSliceInt()
+	for _, poolName := range poolNames {
+		size := poolSizes[poolName]
+		if size > len(cpuIDs) {
+			size = len(cpuIDs)
+		}
+		if _, ok := proposed[poolName]; !ok {
+			proposed[poolName] = machine.NewCPUSet()
+		}
+		proposed[poolName].Add(cpuIDs[:size]...)
+		cpuIDs = cpuIDs[size:]
+	}
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/metric"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestProposedCPUSets(t *testing.T) {
+	t.Parallel()
+
+	ckDir, err := ioutil.TempDir("", "checkpoint-TestProposedCPUSets")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(ckDir) }()
+
+	sfDir, err := ioutil.TempDir("", "statefile")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(sfDir) }()
+
+	conf := generateTestConfiguration(t, ckDir, sfDir)
+	mf := metric.NewFakeMetricsFetcher(metrics.DummyMetrics{}).(*metric.FakeMetricsFetcher)
+	advisor, _ := newTestCPUResourceAdvisor(t, nil, conf, mf, nil)
+
+	// numa node0 cpu(s): 0-23,48-71, bound by dedicated pool
+	// numa node1 cpu(s): 24-47,72-95, non-binding
+	advisor.nonBindingNumas = machine.NewCPUSet(1)
+	advisor.lastCalculationResult = types.InternalCPUCalculationResult{
+		PoolEntries: map[string]map[int]int{
+			"dedicated":           {0: 40},
+			state.PoolNameReclaim: {0: 8, cpuadvisor.FakedNUMAID: 10},
+			state.PoolNameReserve: {cpuadvisor.FakedNUMAID: 4},
+			state.PoolNameShare:   {cpuadvisor.FakedNUMAID: 28},
+			"isolation-1":         {cpuadvisor.FakedNUMAID: 6},
+			"empty":               {cpuadvisor.FakedNUMAID: 0},
+		},
+	}
+
+	proposed := advisor.ProposedCPUSets()
+	assert.NotContains(t, proposed, "empty")
+
+	// proposed sets are disjoint, and sum up to pool sizes
+	assigned := machine.NewCPUSet()
+	for poolName, entries := range advisor.lastCalculationResult.PoolEntries {
+		size := 0
+		for _, s := range entries {
+			size += s
+		}
+		if size == 0 {
+			continue
+		}
+		assert.Equal(t, size, proposed[poolName].Size(), "pool %v", poolName)
+		assert.True(t, assigned.Intersection(proposed[poolName]).IsEmpty(), "pool %v", poolName)
+		assigned = assigned.Union(proposed[poolName])
+	}
+
+	// entries are proposed as contiguous ranges on their numas, reserve pool first and reclaim pool last
+	assert.Equal(t, machine.MustParse("0-23,48-63"), proposed["dedicated"])
+	assert.Equal(t, machine.MustParse("64-71,86-95"), proposed[state.PoolNameReclaim])
+	assert.Equal(t, machine.MustParse("24-27"), proposed[state.PoolNameReserve])
+	assert.Equal(t, machine.MustParse("28-33"), proposed["isolation-1"])
+	assert.Equal(t, machine.MustParse("34-47,72-85"), proposed[state.PoolNameShare])
+
+	// pools exceeding cpus left are proposed with fewer cpus
+	advisor.lastCalculationResult.PoolEntries[state.PoolNameShare][cpuadvisor.FakedNUMAID] = 60
+	proposed = advisor.ProposedCPUSets()
+	assert.Equal(t, 38, proposed[state.PoolNameShare].Size())
+	assert.Equal(t, 8, proposed[state.PoolNameReclaim].Size())
+}
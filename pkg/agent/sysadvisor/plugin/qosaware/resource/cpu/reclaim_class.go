@@ -0,0 +1,83 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"context"
+
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/util/general"
+	"github.com/kubewharf/katalyst-core/pkg/util/native"
+)
+
+// GetHeadroomForClass returns reclaim headroom available to the given reclaim workload class, i.e. the unallocated
+// headroom (headroom minus reclaimed cpu allocated by all active reclaimed pods), further capped by what is left
+// of the class quota if configured; allocation is tracked from reclaimed_millicpu requests of pods in metaserver.
+func (cra *cpuResourceAdvisor) GetHeadroomForClass(className string) (resource.Quantity, error) {
+	klog.Infof("[qosaware-cpu] receive get headroom request for class %v", className)
+
+	cra.mutex.RLock()
+	defer cra.mutex.RUnlock()
+
+	headroom, err := cra.getHeadroom()
+	if err != nil {
+		return resource.Quantity{}, err
+	}
+
+	allocated, err := cra.getReclaimClassAllocated()
+	if err != nil {
+		return resource.Quantity{}, err
+	}
+
+	totalAllocated := int64(0)
+	for _, milliCPU := range allocated {
+		totalAllocated += milliCPU
+	}
+	available := general.MaxInt64(headroom.MilliValue()-totalAllocated, 0)
+	if quota, ok := cra.conf.ReclaimClassQuotas[className]; ok {
+		available = general.MinInt64(available, general.MaxInt64(int64(quota)*1000-allocated[className], 0))
+	}
+
+	klog.Infof("[qosaware-cpu] get headroom for class %v: %vm, headroom: %v, allocated: %v",
+		className, available, headroom.String(), allocated)
+	return *resource.NewMilliQuantity(available, resource.DecimalSI), nil
+}
+
+// getReclaimClassAllocated returns reclaimed milli cpu allocated by active reclaimed pods of each class,
+// and pods without class label are accounted to the empty class
+func (cra *cpuResourceAdvisor) getReclaimClassAllocated() (map[string]int64, error) {
+	pods, err := cra.metaServer.GetPodList(context.Background(), func(pod *v1.Pod) bool {
+		if !native.PodIsActive(pod) {
+			return false
+		}
+		reclaimed, err := cra.conf.CheckReclaimedQoSForPod(pod)
+		return err == nil && reclaimed
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	allocated := make(map[string]int64)
+	for _, pod := range pods {
+		qosResource, _, _ := native.CalculateQoSResource(pod)
+		allocated[pod.Labels[cra.conf.ReclaimClassLabelKey]] += qosResource.ReclaimedMilliCPU
+	}
+	return allocated, nil
+}
@@ -0,0 +1,97 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/utils/clock"
+
+	"github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/pod"
+)
+
+func makeReclaimedPod(uid, className, milliCPU string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "pod-" + uid,
+			Namespace:   "default",
+			UID:         k8stypes.UID(uid),
+			Labels:      map[string]string{"katalyst.kubewharf.io/reclaim-class": className},
+			Annotations: map[string]string{consts.PodAnnotationQoSLevelKey: consts.PodAnnotationQoSLevelReclaimedCores},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name: "c",
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{consts.ReclaimedResourceMilliCPU: resource.MustParse(milliCPU)},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestGetHeadroomForClass(t *testing.T) {
+	t.Parallel()
+
+	conf, err := options.NewOptions().Config()
+	require.NoError(t, err)
+	conf.ReclaimClassQuotas = map[string]int{"batch": 8}
+
+	pods := []*v1.Pod{
+		makeReclaimedPod("uid1", "batch", "6000"),
+		makeReclaimedPod("uid2", "flink", "4000"),
+	}
+	advisor := &cpuResourceAdvisor{
+		conf:              conf,
+		advisorUpdated:    true,
+		headroomAssembler: &fakeHeadroomAssembler{headroom: resource.MustParse("30")},
+		circuitBreaker:    newProvisionCircuitBreaker(0, 0, clock.RealClock{}),
+		metaServer: &metaserver.MetaServer{
+			MetaAgent: &agent.MetaAgent{
+				PodFetcher: &pod.PodFetcherStub{PodList: pods},
+			},
+		},
+	}
+
+	// 20 cores of reclaim is unallocated, but batch is capped by what is left of its quota
+	headroom, err := advisor.GetHeadroomForClass("batch")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2000), headroom.MilliValue())
+
+	// class without quota is only capped by unallocated headroom
+	headroom, err = advisor.GetHeadroomForClass("flink")
+	require.NoError(t, err)
+	assert.Equal(t, int64(20000), headroom.MilliValue())
+
+	// quota never raises headroom above unallocated one
+	advisor.headroomAssembler = &fakeHeadroomAssembler{headroom: resource.MustParse("11")}
+	headroom, err = advisor.GetHeadroomForClass("batch")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1000), headroom.MilliValue())
+}
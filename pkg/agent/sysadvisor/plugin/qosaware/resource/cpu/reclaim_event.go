@@ -0,0 +1,110 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"time"
+
+	v1 "k8s.io/api/core/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/events"
+	"k8s.io/utils/clock"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+)
+
+// reclaimEventEmitter emits events on the node object when reclaim decisions transit, i.e. cpu contention
+// (bound upper) starts or ends, and reclaim pool shrinks below or recovers above the threshold; events of
+// the same reason are rate-limited, and transitions are still tracked while events are suppressed
+type reclaimEventEmitter struct {
+	recorder  events.EventRecorder
+	node      *v1.ObjectReference
+	threshold int
+	interval  time.Duration
+	clock     clock.PassiveClock
+
+	reclaimPoolName string
+
+	observed       bool
+	lastBoundUpper bool
+	lastShrunk     bool
+	lastEmittedAt  map[string]time.Time // map[reason]lastEmittedTime
+}
+
+func newReclaimEventEmitter(recorder events.EventRecorder, nodeName, reclaimPoolName string, threshold int,
+	interval time.Duration, clock clock.PassiveClock) *reclaimEventEmitter {
+	return &reclaimEventEmitter{
+		recorder: recorder,
+		node: &v1.ObjectReference{
+			Kind: "Node",
+			Name: nodeName,
+			UID:  k8stypes.UID(nodeName),
+		},
+		threshold:       threshold,
+		interval:        interval,
+		clock:           clock,
+		reclaimPoolName: reclaimPoolName,
+		lastEmittedAt:   make(map[string]time.Time),
+	}
+}
+
+// observe compares the result with the last observed one and emits events on transitions;
+// the first observation only records states, since there is nothing to transit from
+func (e *reclaimEventEmitter) observe(calculationResult types.InternalCPUCalculationResult, boundUpper bool) {
+	if e == nil || e.recorder == nil {
+		return
+	}
+
+	reclaimSize := 0
+	for _, size := range calculationResult.PoolEntries[e.reclaimPoolName] {
+		reclaimSize += size
+	}
+	shrunk := e.threshold > 0 && reclaimSize < e.threshold
+
+	if e.observed {
+		if boundUpper && !e.lastBoundUpper {
+			e.emit(v1.EventTypeWarning, consts.EventReasonCPUContentionStarted,
+				"cpu contention started, share and isolation pools are bound by upper limit, reclaim pool size %v", reclaimSize)
+		} else if !boundUpper && e.lastBoundUpper {
+			e.emit(v1.EventTypeNormal, consts.EventReasonCPUContentionEnded,
+				"cpu contention ended, reclaim pool size %v", reclaimSize)
+		}
+
+		if shrunk && !e.lastShrunk {
+			e.emit(v1.EventTypeWarning, consts.EventReasonReclaimPoolShrunk,
+				"reclaim pool size %v shrinks below threshold %v", reclaimSize, e.threshold)
+		} else if !shrunk && e.lastShrunk {
+			e.emit(v1.EventTypeNormal, consts.EventReasonReclaimPoolRecovered,
+				"reclaim pool size %v recovers above threshold %v", reclaimSize, e.threshold)
+		}
+	}
+
+	e.observed = true
+	e.lastBoundUpper = boundUpper
+	e.lastShrunk = shrunk
+}
+
+// emit records the event unless an event of the same reason is emitted within interval
+func (e *reclaimEventEmitter) emit(eventType, reason, note string, args ...interface{}) {
+	now := e.clock.Now()
+	if last, ok := e.lastEmittedAt[reason]; ok && now.Sub(last) < e.interval {
+		return
+	}
+	e.lastEmittedAt[reason] = now
+	e.recorder.Eventf(e.node, nil, eventType, reason, consts.EventActionCPUProvisioning, note, args...)
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/client-go/tools/events"
+	testingclock "k8s.io/utils/clock/testing"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+func drainEvents(recorder *events.FakeRecorder) []string {
+	var res []string
+	for {
+		select {
+		case event := <-recorder.Events:
+			res = append(res, event)
+		default:
+			return res
+		}
+	}
+}
+
+func TestReclaimEventEmitter(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	fakeClock := testingclock.NewFakePassiveClock(now)
+	recorder := events.NewFakeRecorder(16)
+	e := newReclaimEventEmitter(recorder, "node-1", state.PoolNameReclaim, 4, time.Minute, fakeClock)
+
+	resultOfReclaim := func(size int) types.InternalCPUCalculationResult {
+		return types.InternalCPUCalculationResult{
+			PoolEntries: map[string]map[int]int{
+				state.PoolNameReclaim: {-1: size},
+			},
+		}
+	}
+
+	// the first observation emits nothing
+	e.observe(resultOfReclaim(10), false)
+	assert.Empty(t, drainEvents(recorder))
+
+	// contention transition
+	e.observe(resultOfReclaim(10), true)
+	assert.Equal(t, []string{"Warning CPUContentionStarted cpu contention started, share and isolation pools " +
+		"are bound by upper limit, reclaim pool size 10"}, drainEvents(recorder))
+
+	// no transition, no event
+	e.observe(resultOfReclaim(10), true)
+	assert.Empty(t, drainEvents(recorder))
+
+	// reclaim pool shrinks below threshold, and contention ends
+	e.observe(resultOfReclaim(2), false)
+	assert.Equal(t, []string{
+		"Normal CPUContentionEnded cpu contention ended, reclaim pool size 2",
+		"Warning ReclaimPoolShrunk reclaim pool size 2 shrinks below threshold 4",
+	}, drainEvents(recorder))
+
+	// flapping within interval is rate-limited
+	e.observe(resultOfReclaim(2), true)
+	assert.Empty(t, drainEvents(recorder))
+
+	fakeClock.SetTime(now.Add(2 * time.Minute))
+	e.observe(resultOfReclaim(8), false)
+	assert.Equal(t, []string{
+		"Normal CPUContentionEnded cpu contention ended, reclaim pool size 8",
+		"Normal ReclaimPoolRecovered reclaim pool size 8 recovers above threshold 4",
+	}, drainEvents(recorder))
+
+	// nil emitter is a no-op
+	var nilEmitter *reclaimEventEmitter
+	nilEmitter.observe(resultOfReclaim(8), true)
+}
@@ -25,7 +25,6 @@ import (
 	"k8s.io/kubelet/pkg/apis/resourceplugin/v1alpha1"
 
 	workloadapis "github.com/kubewharf/katalyst-api/pkg/apis/workload/v1alpha1"
-	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/helper"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
@@ -138,7 +137,7 @@ func (r *QoSRegionDedicatedNumaExclusive) updateProvisionPolicy() {
 
 func (r *QoSRegionDedicatedNumaExclusive) getControlKnobs() types.ControlKnob {
 	reclaimedCPUSize := 0
-	if reclaimedInfo, ok := r.metaReader.GetPoolInfo(state.PoolNameReclaim); ok {
+	if reclaimedInfo, ok := r.metaReader.GetPoolInfo(r.conf.ReclaimPoolName); ok {
 		for _, numaID := range r.bindingNumas.ToSliceInt() {
 			reclaimedCPUSize += reclaimedInfo.TopologyAwareAssignments[numaID].Size()
 		}
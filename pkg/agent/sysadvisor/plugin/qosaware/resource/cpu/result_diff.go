@@ -0,0 +1,121 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const (
+	metricCPUAdvisorResultDiffEntries = "cpu_advisor_result_diff_entries"
+)
+
+// PoolEntryDelta is the change of a pool entry on a numa between two provision results;
+// Prev is zero for added entries, and Curr is zero for removed ones
+type PoolEntryDelta struct {
+	PoolName string
+	NumaID   int
+	Prev     int
+	Curr     int
+}
+
+func (d PoolEntryDelta) String() string {
+	return fmt.Sprintf("%v/%v: %v -> %v", d.PoolName, d.NumaID, d.Prev, d.Curr)
+}
+
+// ResultDiff is the structured delta of pool entries between two provision results,
+// and entries of each kind are ordered by pool name and numa id
+type ResultDiff struct {
+	Added   []PoolEntryDelta
+	Removed []PoolEntryDelta
+	Changed []PoolEntryDelta
+}
+
+// IsEmpty returns whether no pool entry is added, removed or changed
+func (d ResultDiff) IsEmpty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+func (d ResultDiff) String() string {
+	format := func(deltas []PoolEntryDelta) string {
+		items := make([]string, 0, len(deltas))
+		for _, delta := range deltas {
+			items = append(items, delta.String())
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+	return fmt.Sprintf("added: %v, removed: %v, changed: %v", format(d.Added), format(d.Removed), format(d.Changed))
+}
+
+// Diff returns pool entries added, removed and changed per numa from prev to curr;
+// fields other than pool entries (e.g. timestamp and reason) are not compared
+func Diff(prev, curr types.InternalCPUCalculationResult) ResultDiff {
+	var diff ResultDiff
+	for poolName, currEntries := range curr.PoolEntries {
+		prevEntries := prev.PoolEntries[poolName]
+		for numaID, currSize := range currEntries {
+			prevSize, ok := prevEntries[numaID]
+			if !ok {
+				diff.Added = append(diff.Added, PoolEntryDelta{PoolName: poolName, NumaID: numaID, Curr: currSize})
+			} else if prevSize != currSize {
+				diff.Changed = append(diff.Changed, PoolEntryDelta{PoolName: poolName, NumaID: numaID, Prev: prevSize, Curr: currSize})
+			}
+		}
+	}
+	for poolName, prevEntries := range prev.PoolEntries {
+		currEntries := curr.PoolEntries[poolName]
+		for numaID, prevSize := range prevEntries {
+			if _, ok := currEntries[numaID]; !ok {
+				diff.Removed = append(diff.Removed, PoolEntryDelta{PoolName: poolName, NumaID: numaID, Prev: prevSize})
+			}
+		}
+	}
+
+	for _, deltas := range [][]PoolEntryDelta{diff.Added, diff.Removed, diff.Changed} {
+		sort.Slice(deltas, func(i, j int) bool {
+			if deltas[i].PoolName != deltas[j].PoolName {
+				return deltas[i].PoolName < deltas[j].PoolName
+			}
+			return deltas[i].NumaID < deltas[j].NumaID
+		})
+	}
+	return diff
+}
+
+// logResultDiff logs changes of pool entries since the last result if result diff logging is enabled;
+// must be called with lock held and before the last result is replaced
+func (cra *cpuResourceAdvisor) logResultDiff(calculationResult types.InternalCPUCalculationResult) {
+	if !cra.conf.EnableResultDiffLogging || cra.lastCalculationResult.PoolEntries == nil {
+		return
+	}
+
+	diff := Diff(cra.lastCalculationResult, calculationResult)
+	if diff.IsEmpty() {
+		return
+	}
+	klog.Infof("[qosaware-cpu] provision result of generation %v changed (%v): %v",
+		calculationResult.Generation, calculationResult.Reason, diff)
+	_ = cra.emitter.StoreInt64(metricCPUAdvisorResultDiffEntries, int64(len(diff.Added)+len(diff.Removed)+len(diff.Changed)),
+		metrics.MetricTypeNameRaw)
+}
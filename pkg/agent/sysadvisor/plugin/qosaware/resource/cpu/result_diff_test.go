@@ -0,0 +1,89 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	prev := types.InternalCPUCalculationResult{
+		PoolEntries: map[string]map[int]int{
+			state.PoolNameShare:   {-1: 20},
+			state.PoolNameReserve: {0: 2, 1: 2},
+			state.PoolNameReclaim: {0: 10, 1: 6, -1: 8},
+			"isolation-1":         {-1: 4},
+		},
+		Reason: types.AssemblyReasonPeriodic,
+	}
+	curr := types.InternalCPUCalculationResult{
+		PoolEntries: map[string]map[int]int{
+			state.PoolNameShare:   {-1: 24},
+			state.PoolNameReserve: {0: 2, 1: 2},
+			state.PoolNameReclaim: {0: 8, -1: 4},
+			"batch":               {-1: 6},
+			"isolation-1":         {-1: 4, 1: 2},
+		},
+		Reason: types.AssemblyReasonDynamicConfigChange,
+	}
+
+	diff := Diff(prev, curr)
+	assert.Equal(t, ResultDiff{
+		Added: []PoolEntryDelta{
+			{PoolName: "batch", NumaID: -1, Curr: 6},
+			{PoolName: "isolation-1", NumaID: 1, Curr: 2},
+		},
+		Removed: []PoolEntryDelta{
+			{PoolName: state.PoolNameReclaim, NumaID: 1, Prev: 6},
+		},
+		Changed: []PoolEntryDelta{
+			{PoolName: state.PoolNameReclaim, NumaID: -1, Prev: 8, Curr: 4},
+			{PoolName: state.PoolNameReclaim, NumaID: 0, Prev: 10, Curr: 8},
+			{PoolName: state.PoolNameShare, NumaID: -1, Prev: 20, Curr: 24},
+		},
+	}, diff)
+	assert.False(t, diff.IsEmpty())
+	assert.Equal(t, "added: [batch/-1: 0 -> 6, isolation-1/1: 0 -> 2], removed: [reclaim/1: 6 -> 0], "+
+		"changed: [reclaim/-1: 8 -> 4, reclaim/0: 10 -> 8, share/-1: 20 -> 24]", diff.String())
+
+	// reverse diff swaps added and removed
+	reverse := Diff(curr, prev)
+	assert.Equal(t, diff.Added, swapDeltas(reverse.Removed))
+	assert.Equal(t, diff.Removed, swapDeltas(reverse.Added))
+	assert.Equal(t, diff.Changed, swapDeltas(reverse.Changed))
+
+	// fields other than pool entries are ignored
+	curr.Reason = prev.Reason
+	assert.True(t, Diff(curr, curr).IsEmpty())
+	assert.True(t, Diff(types.InternalCPUCalculationResult{}, types.InternalCPUCalculationResult{}).IsEmpty())
+}
+
+func swapDeltas(deltas []PoolEntryDelta) []PoolEntryDelta {
+	swapped := make([]PoolEntryDelta, 0, len(deltas))
+	for _, delta := range deltas {
+		delta.Prev, delta.Curr = delta.Curr, delta.Prev
+		swapped = append(swapped, delta)
+	}
+	return swapped
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const (
+	metricCPUAdvisorResultSinkDropped = "cpu_advisor_result_sink_dropped"
+	metricCPUAdvisorResultSinkFailed  = "cpu_advisor_result_sink_failed"
+
+	// resultSinkBufferSize is the number of results buffered for each sink,
+	// and results will be dropped if the sink falls behind beyond it
+	resultSinkBufferSize = 16
+)
+
+// ResultSink ships cpu provision results to external systems, e.g. a time-series database
+// or a control-plane aggregator, besides notifying cpu server
+type ResultSink interface {
+	Publish(result types.InternalCPUCalculationResult) error
+}
+
+// resultSinkPublisher publishes results to a sink asynchronously with bounded buffering,
+// so that a slow sink never blocks the assembly loop
+type resultSinkPublisher struct {
+	name    string
+	sink    ResultSink
+	buffer  chan types.InternalCPUCalculationResult
+	emitter metrics.MetricEmitter
+}
+
+func newResultSinkPublisher(name string, sink ResultSink, emitter metrics.MetricEmitter) *resultSinkPublisher {
+	return &resultSinkPublisher{
+		name:    name,
+		sink:    sink,
+		buffer:  make(chan types.InternalCPUCalculationResult, resultSinkBufferSize),
+		emitter: emitter,
+	}
+}
+
+// run publishes buffered results to sink one by one; it keeps running along with the advisor
+func (p *resultSinkPublisher) run() {
+	for result := range p.buffer {
+		if err := p.sink.Publish(result); err != nil {
+			klog.Errorf("[qosaware-cpu] publish result to sink %v failed: %v", p.name, err)
+			_ = p.emitter.StoreInt64(metricCPUAdvisorResultSinkFailed, 1, metrics.MetricTypeNameRaw,
+				metrics.MetricTag{Key: "sink", Val: p.name})
+		}
+	}
+}
+
+// enqueue buffers the result without blocking, and drops it if buffer is full
+func (p *resultSinkPublisher) enqueue(result types.InternalCPUCalculationResult) bool {
+	select {
+	case p.buffer <- result:
+		return true
+	default:
+		klog.Warningf("[qosaware-cpu] buffer of result sink %v is full, drop result at %v", p.name, result.TimeStamp)
+		_ = p.emitter.StoreInt64(metricCPUAdvisorResultSinkDropped, 1, metrics.MetricTypeNameRaw,
+			metrics.MetricTag{Key: "sink", Val: p.name})
+		return false
+	}
+}
+
+// RegisterResultSink registers a sink to be invoked asynchronously after each successful assembly
+func (cra *cpuResourceAdvisor) RegisterResultSink(name string, sink ResultSink) {
+	cra.mutex.Lock()
+	defer cra.mutex.Unlock()
+
+	publisher := newResultSinkPublisher(name, sink, cra.emitter)
+	cra.resultSinks = append(cra.resultSinks, publisher)
+	go publisher.run()
+	klog.Infof("[qosaware-cpu] result sink %v registered", name)
+}
+
+// publishResult hands the result over to all registered sinks and subscribers; must be called with lock held
+func (cra *cpuResourceAdvisor) publishResult(result types.InternalCPUCalculationResult) {
+	for _, publisher := range cra.resultSinks {
+		publisher.enqueue(result)
+	}
+	cra.notifySubscribers(result)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+type fakeResultSink struct {
+	published chan types.InternalCPUCalculationResult
+	// block holds Publish until closed to simulate a slow sink
+	block chan struct{}
+}
+
+func (s *fakeResultSink) Publish(result types.InternalCPUCalculationResult) error {
+	if s.block != nil {
+		<-s.block
+	}
+	s.published <- result
+	return nil
+}
+
+func TestResultSink(t *testing.T) {
+	t.Parallel()
+
+	cra := &cpuResourceAdvisor{emitter: metrics.DummyMetrics{}}
+
+	fastSink := &fakeResultSink{published: make(chan types.InternalCPUCalculationResult, 10*resultSinkBufferSize)}
+	slowSink := &fakeResultSink{
+		published: make(chan types.InternalCPUCalculationResult, 10*resultSinkBufferSize),
+		block:     make(chan struct{}),
+	}
+	cra.RegisterResultSink("fast", fastSink)
+	cra.RegisterResultSink("slow", slowSink)
+
+	result := types.InternalCPUCalculationResult{
+		PoolEntries: map[string]map[int]int{
+			state.PoolNameShare: {-1: 8},
+		},
+		TimeStamp: time.Now(),
+	}
+
+	// result reaches the sink
+	cra.publishResult(result)
+	select {
+	case got := <-fastSink.published:
+		assert.Equal(t, result, got)
+	case <-time.After(time.Second):
+		t.Fatalf("result doesn't reach the sink")
+	}
+	// wait until slow sink takes the result and blocks, so that no buffer slot is freed by it afterwards
+	require.Eventually(t, func() bool {
+		return len(cra.resultSinks[1].buffer) == 0
+	}, time.Second, time.Millisecond)
+
+	// slow sink doesn't block publishing, and overflowed results are dropped
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 3*resultSinkBufferSize; i++ {
+			cra.publishResult(result)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatalf("publishing is blocked by slow sink")
+	}
+	assert.False(t, cra.resultSinks[1].enqueue(result))
+
+	// slow sink drains buffered results once it recovers
+	close(slowSink.block)
+	require.Eventually(t, func() bool {
+		return len(slowSink.published) > 0 && len(cra.resultSinks[1].buffer) == 0
+	}, time.Second, 10*time.Millisecond)
+	assert.LessOrEqual(t, len(slowSink.published), resultSinkBufferSize+2)
+}
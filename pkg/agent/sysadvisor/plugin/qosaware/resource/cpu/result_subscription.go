@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"sync"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+const (
+	metricCPUAdvisorResultSubscriberDropped = "cpu_advisor_result_subscriber_dropped"
+
+	// resultSubscriberBufferSize is the number of results buffered for each subscriber,
+	// and the oldest results will be dropped if the subscriber falls behind beyond it
+	resultSubscriberBufferSize = 16
+)
+
+// Subscribe returns a channel receiving each new provision result after successful assembly, along with
+// a function to unsubscribe, which closes the channel; results are delivered without blocking the assembly
+// loop, and the oldest buffered result is dropped if the subscriber falls behind.
+func (cra *cpuResourceAdvisor) Subscribe() (<-chan types.InternalCPUCalculationResult, func()) {
+	cra.mutex.Lock()
+	defer cra.mutex.Unlock()
+
+	if cra.subscribers == nil {
+		cra.subscribers = make(map[int]chan types.InternalCPUCalculationResult)
+	}
+	id := cra.nextSubscriberID
+	cra.nextSubscriberID++
+	ch := make(chan types.InternalCPUCalculationResult, resultSubscriberBufferSize)
+	cra.subscribers[id] = ch
+	klog.Infof("[qosaware-cpu] result subscriber %v subscribed", id)
+
+	var once sync.Once
+	unsubscribe := func() {
+		once.Do(func() {
+			cra.mutex.Lock()
+			defer cra.mutex.Unlock()
+
+			delete(cra.subscribers, id)
+			close(ch)
+			klog.Infof("[qosaware-cpu] result subscriber %v unsubscribed", id)
+		})
+	}
+	return ch, unsubscribe
+}
+
+// notifySubscribers sends the result to all subscribers, dropping the oldest buffered result of those
+// falling behind; must be called with lock held, so that channels are never closed while sending
+func (cra *cpuResourceAdvisor) notifySubscribers(result types.InternalCPUCalculationResult) {
+	for id, ch := range cra.subscribers {
+		for sent := false; !sent; {
+			select {
+			case ch <- result:
+				sent = true
+			default:
+				select {
+				case dropped := <-ch:
+					klog.Warningf("[qosaware-cpu] buffer of result subscriber %v is full, drop result at %v", id, dropped.TimeStamp)
+					_ = cra.emitter.StoreInt64(metricCPUAdvisorResultSubscriberDropped, 1, metrics.MetricTypeNameRaw)
+				default:
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+)
+
+func TestSubscribe(t *testing.T) {
+	t.Parallel()
+
+	cra := &cpuResourceAdvisor{emitter: metrics.DummyMetrics{}}
+	now := time.Now()
+	newResult := func(i int) types.InternalCPUCalculationResult {
+		return types.InternalCPUCalculationResult{
+			PoolEntries: map[string]map[int]int{state.PoolNameShare: {-1: i}},
+			TimeStamp:   now.Add(time.Duration(i) * time.Second),
+		}
+	}
+	receive := func(ch <-chan types.InternalCPUCalculationResult, n int) []time.Time {
+		timestamps := make([]time.Time, 0, n)
+		for i := 0; i < n; i++ {
+			result := <-ch
+			timestamps = append(timestamps, result.TimeStamp)
+		}
+		return timestamps
+	}
+
+	ch1, unsubscribe1 := cra.Subscribe()
+	ch2, unsubscribe2 := cra.Subscribe()
+	defer unsubscribe2()
+
+	// both subscribers receive the same sequence of results
+	for i := 0; i < 3; i++ {
+		cra.publishResult(newResult(i))
+	}
+	want := []time.Time{now, now.Add(time.Second), now.Add(2 * time.Second)}
+	assert.Equal(t, want, receive(ch1, 3))
+	assert.Equal(t, want, receive(ch2, 3))
+
+	// unsubscribe closes the channel and stops delivery, and it's idempotent
+	unsubscribe1()
+	unsubscribe1()
+	cra.publishResult(newResult(3))
+	_, ok := <-ch1
+	assert.False(t, ok)
+	assert.Equal(t, []time.Time{now.Add(3 * time.Second)}, receive(ch2, 1))
+	assert.Len(t, cra.subscribers, 1)
+
+	// the oldest results are dropped for subscribers falling behind
+	for i := 4; i < 4+resultSubscriberBufferSize+2; i++ {
+		cra.publishResult(newResult(i))
+	}
+	assert.Len(t, ch2, resultSubscriberBufferSize)
+	assert.Equal(t, now.Add(6*time.Second), receive(ch2, 1)[0])
+}
@@ -0,0 +1,295 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"context"
+	"fmt"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/assembler/provisionassembler"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu/region"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/pod"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+// RegionOverrideAction defines how a RegionOverride is applied to the simulated regions
+type RegionOverrideAction string
+
+const (
+	// RegionOverrideAdd adds a hypothetical region, which must not exist currently
+	RegionOverrideAdd RegionOverrideAction = "add"
+	// RegionOverrideModify replaces the non-empty fields of an existing region
+	RegionOverrideModify RegionOverrideAction = "modify"
+	// RegionOverrideRemove removes an existing region
+	RegionOverrideRemove RegionOverrideAction = "remove"
+)
+
+// RegionOverride describes a hypothetical change of region inputs for SimulateAssembly;
+// for added regions, Type and ControlKnob are required, and BindingNumas is required
+// for numa binding regions. Modified regions keep original values for empty fields.
+type RegionOverride struct {
+	Action        RegionOverrideAction
+	Name          string
+	Type          types.QoSRegionType
+	OwnerPoolName string
+	BindingNumas  machine.CPUSet
+	ControlKnob   types.ControlKnob
+}
+
+// SimulateAssembly runs provision assembling against the current region inputs with the
+// given overrides applied, and returns the assembled result without touching live state.
+// A fresh assembler is used, so history based adjustments (e.g. reclaim ramp-up limit)
+// don't take effect, and all regions are regarded as newly seen if warm-up is enabled.
+func (cra *cpuResourceAdvisor) SimulateAssembly(overrides []RegionOverride) (types.InternalCPUCalculationResult, error) {
+	cra.mutex.RLock()
+	defer cra.mutex.RUnlock()
+
+	initializer, ok := provisionassembler.GetRegisteredInitializers()[cra.conf.CPUAdvisorConfiguration.ProvisionAssembler]
+	if !ok {
+		return types.InternalCPUCalculationResult{}, fmt.Errorf("unsupported provision assembler %v", cra.conf.CPUAdvisorConfiguration.ProvisionAssembler)
+	}
+
+	regionMap := make(map[string]region.QoSRegion, len(cra.regionMap))
+	for regionName, r := range cra.regionMap {
+		regionMap[regionName] = r
+	}
+
+	hypotheticalPods := make(map[string]*v1.Pod)
+	for _, override := range overrides {
+		if err := applyRegionOverride(regionMap, hypotheticalPods, override); err != nil {
+			return types.InternalCPUCalculationResult{}, err
+		}
+	}
+
+	// re-calculate non-binding numas in the same way as updateAdvisorEssentials,
+	// and bind share regions to them without mutating the live regions
+	nonBindingNumas := cra.metaServer.CPUDetails.NUMANodes()
+	for _, r := range regionMap {
+		if r.Type() == types.QoSRegionTypeDedicatedNumaExclusive || r.Type() == types.QoSRegionTypeIsolation {
+			nonBindingNumas = nonBindingNumas.Difference(r.GetBindingNumas())
+		}
+	}
+	for regionName, r := range regionMap {
+		if r.Type() == types.QoSRegionTypeShare {
+			sr := newSimulatedRegion(r)
+			sr.bindingNumas = nonBindingNumas
+			regionMap[regionName] = sr
+		}
+	}
+
+	reservedForReclaim := make(map[int]int, len(cra.reservedForReclaim))
+	for numaID, reserved := range cra.reservedForReclaim {
+		reservedForReclaim[numaID] = reserved
+	}
+	numaAvailable := make(map[int]int, len(cra.numaAvailable))
+	for numaID, available := range cra.numaAvailable {
+		numaAvailable[numaID] = available
+	}
+
+	assembler := initializer(cra.conf, cra.extraConf, &regionMap, &reservedForReclaim,
+		provisionassembler.NewMapAvailabilityProvider(&numaAvailable), &nonBindingNumas,
+		cra.metaCache, newSimulationMetaServer(cra.metaServer, hypotheticalPods), metrics.DummyMetrics{})
+
+	result, _, err := assembler.AssembleProvision()
+	if err != nil {
+		return types.InternalCPUCalculationResult{}, fmt.Errorf("simulate assembly failed: %v", err)
+	}
+	return result, nil
+}
+
+// applyRegionOverride applies a single override to the simulated region map; hypothetical
+// pods are generated for added dedicated regions to make them resolvable by assembler
+func applyRegionOverride(regionMap map[string]region.QoSRegion, hypotheticalPods map[string]*v1.Pod, override RegionOverride) error {
+	original, exist := regionMap[override.Name]
+
+	switch override.Action {
+	case RegionOverrideRemove:
+		if !exist {
+			return fmt.Errorf("failed to remove region %v: not found", override.Name)
+		}
+		delete(regionMap, override.Name)
+	case RegionOverrideModify:
+		if !exist {
+			return fmt.Errorf("failed to modify region %v: not found", override.Name)
+		}
+		sr := newSimulatedRegion(original)
+		if override.Type != "" {
+			sr.regionType = override.Type
+		}
+		if override.OwnerPoolName != "" {
+			sr.ownerPoolName = override.OwnerPoolName
+		}
+		if override.BindingNumas.Size() > 0 {
+			sr.bindingNumas = override.BindingNumas
+		}
+		if override.ControlKnob != nil {
+			sr.controlKnob = override.ControlKnob
+		}
+		regionMap[override.Name] = sr
+	case RegionOverrideAdd:
+		if exist {
+			return fmt.Errorf("failed to add region %v: already exists", override.Name)
+		}
+		if override.Type == "" || override.ControlKnob == nil {
+			return fmt.Errorf("failed to add region %v: type and control knob are required", override.Name)
+		}
+
+		sr := &simulatedRegion{
+			name:          override.Name,
+			regionType:    override.Type,
+			ownerPoolName: override.OwnerPoolName,
+			bindingNumas:  override.BindingNumas,
+			pods:          make(types.PodSet),
+			controlKnob:   override.ControlKnob,
+		}
+		switch override.Type {
+		case types.QoSRegionTypeDedicatedNumaExclusive:
+			if override.BindingNumas.Size() != 1 {
+				return fmt.Errorf("failed to add region %v: exactly one binding numa is required", override.Name)
+			}
+			if sr.ownerPoolName == "" {
+				sr.ownerPoolName = state.PoolNameDedicated
+			}
+			podUID := "simulated-" + override.Name
+			sr.pods.Insert(podUID, "")
+			hypotheticalPods[podUID] = &v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: podUID, UID: k8stypes.UID(podUID)}}
+		case types.QoSRegionTypeIsolation:
+			if sr.ownerPoolName == "" {
+				sr.ownerPoolName = override.Name
+			}
+		}
+		if sr.ownerPoolName == "" {
+			return fmt.Errorf("failed to add region %v: owner pool name is required", override.Name)
+		}
+		regionMap[override.Name] = sr
+	default:
+		return fmt.Errorf("unknown action %v for region %v", override.Action, override.Name)
+	}
+
+	return nil
+}
+
+// simulatedRegion is a read-only region used by SimulateAssembly; it takes identity and
+// provision from its own fields, and delegates other methods to the original region if any
+type simulatedRegion struct {
+	region.QoSRegion
+
+	name          string
+	regionType    types.QoSRegionType
+	ownerPoolName string
+	bindingNumas  machine.CPUSet
+	pods          types.PodSet
+	controlKnob   types.ControlKnob
+}
+
+func newSimulatedRegion(r region.QoSRegion) *simulatedRegion {
+	if sr, ok := r.(*simulatedRegion); ok {
+		copied := *sr
+		return &copied
+	}
+
+	return &simulatedRegion{
+		QoSRegion:     r,
+		name:          r.Name(),
+		regionType:    r.Type(),
+		ownerPoolName: r.OwnerPoolName(),
+		bindingNumas:  r.GetBindingNumas(),
+		pods:          r.GetPods(),
+	}
+}
+
+func (sr *simulatedRegion) Name() string {
+	return sr.name
+}
+
+func (sr *simulatedRegion) Type() types.QoSRegionType {
+	return sr.regionType
+}
+
+func (sr *simulatedRegion) OwnerPoolName() string {
+	return sr.ownerPoolName
+}
+
+func (sr *simulatedRegion) IsEmpty() bool {
+	return len(sr.pods) == 0
+}
+
+func (sr *simulatedRegion) GetBindingNumas() machine.CPUSet {
+	return sr.bindingNumas.Clone()
+}
+
+func (sr *simulatedRegion) GetPods() types.PodSet {
+	return sr.pods.Clone()
+}
+
+func (sr *simulatedRegion) SetBindingNumas(_ machine.CPUSet) {}
+
+func (sr *simulatedRegion) GetProvision() (types.ControlKnob, error) {
+	if sr.controlKnob != nil {
+		return sr.controlKnob, nil
+	}
+	if sr.QoSRegion == nil {
+		return nil, fmt.Errorf("no provision for simulated region %v", sr.name)
+	}
+	return sr.QoSRegion.GetProvision()
+}
+
+// simulationPodFetcher serves hypothetical pods besides those from the original fetcher
+type simulationPodFetcher struct {
+	pod.PodFetcher
+	hypotheticalPods map[string]*v1.Pod
+}
+
+func (f *simulationPodFetcher) GetPod(ctx context.Context, podUID string) (*v1.Pod, error) {
+	if p, ok := f.hypotheticalPods[podUID]; ok {
+		return p, nil
+	}
+	return f.PodFetcher.GetPod(ctx, podUID)
+}
+
+// newSimulationMetaServer builds a meta server sharing all fetchers and managers with the
+// original one, except that the pod fetcher is able to serve hypothetical pods
+func newSimulationMetaServer(ms *metaserver.MetaServer, hypotheticalPods map[string]*v1.Pod) *metaserver.MetaServer {
+	if len(hypotheticalPods) == 0 {
+		return ms
+	}
+
+	return &metaserver.MetaServer{
+		MetaAgent: &agent.MetaAgent{
+			PodFetcher:           &simulationPodFetcher{PodFetcher: ms.PodFetcher, hypotheticalPods: hypotheticalPods},
+			NodeFetcher:          ms.NodeFetcher,
+			MetricsFetcher:       ms.MetricsFetcher,
+			CNRFetcher:           ms.CNRFetcher,
+			CNCFetcher:           ms.CNCFetcher,
+			KubeletConfigFetcher: ms.KubeletConfigFetcher,
+			KatalystMachineInfo:  ms.KatalystMachineInfo,
+			Conf:                 ms.Conf,
+		},
+		ConfigurationManager:    ms.ConfigurationManager,
+		ServiceProfilingManager: ms.ServiceProfilingManager,
+		ExternalManager:         ms.ExternalManager,
+	}
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent/metric"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestSimulateAssembly(t *testing.T) {
+	t.Parallel()
+
+	ckDir, err := ioutil.TempDir("", "checkpoint-TestSimulateAssembly")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(ckDir) }()
+
+	sfDir, err := ioutil.TempDir("", "statefile")
+	require.NoError(t, err)
+	defer func() { _ = os.RemoveAll(sfDir) }()
+
+	conf := generateTestConfiguration(t, ckDir, sfDir)
+	mf := metric.NewFakeMetricsFetcher(metrics.DummyMetrics{}).(*metric.FakeMetricsFetcher)
+	pods := []*v1.Pod{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod1",
+				Namespace: "default",
+				UID:       "uid1",
+			},
+		},
+	}
+
+	advisor, metaCache := newTestCPUResourceAdvisor(t, pods, conf, mf, nil)
+	advisor.startTime = time.Now().Add(-types.StartUpPeriod)
+	advisor.conf.GetDynamicConfiguration().EnableReclaim = true
+
+	_ = metaCache.SetPoolInfo(state.PoolNameReserve, &types.PoolInfo{
+		PoolName: state.PoolNameReserve,
+		TopologyAwareAssignments: map[int]machine.CPUSet{
+			0: machine.MustParse("0"),
+			1: machine.MustParse("24"),
+		},
+	})
+	_ = metaCache.SetPoolInfo(state.PoolNameShare, &types.PoolInfo{
+		PoolName: state.PoolNameShare,
+		TopologyAwareAssignments: map[int]machine.CPUSet{
+			0: machine.MustParse("1"),
+			1: machine.MustParse("25"),
+		},
+	})
+	_ = metaCache.SetContainerInfo("uid1", "c1", makeContainerInfo("uid1", "default", "pod1", "c1",
+		consts.PodAnnotationQoSLevelSharedCores, state.PoolNameShare, nil,
+		map[int]machine.CPUSet{
+			0: machine.MustParse("1"),
+			1: machine.MustParse("25"),
+		}, 4))
+
+	advisor.update()
+	regionsBefore := advisor.ListRegions()
+	nonBindingNumasBefore := advisor.nonBindingNumas.Clone()
+
+	baseline, err := advisor.SimulateAssembly(nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[int]int{-1: 86}, baseline.PoolEntries[state.PoolNameReclaim])
+
+	simulated, err := advisor.SimulateAssembly([]RegionOverride{
+		{
+			Action:       RegionOverrideAdd,
+			Name:         "dedicated-numa1",
+			Type:         types.QoSRegionTypeDedicatedNumaExclusive,
+			BindingNumas: machine.NewCPUSet(1),
+			ControlKnob: types.ControlKnob{
+				types.ControlKnobNonReclaimedCPUSize: {Value: 20},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	// reclaim on numa 1 shrinks from the whole numa to what the dedicated region leaves
+	simulatedReclaim := simulated.PoolEntries[state.PoolNameReclaim]
+	assert.Equal(t, 27, simulatedReclaim[1])
+	assert.Less(t, simulatedReclaim[1], baseline.PoolEntries[state.PoolNameReclaim][-1]/2)
+	assert.Less(t, simulatedReclaim[-1]+simulatedReclaim[1], baseline.PoolEntries[state.PoolNameReclaim][-1])
+
+	// live state is left untouched
+	assert.Equal(t, regionsBefore, advisor.ListRegions())
+	assert.True(t, nonBindingNumasBefore.Equals(advisor.nonBindingNumas))
+
+	_, err = advisor.SimulateAssembly([]RegionOverride{{Action: RegionOverrideRemove, Name: "not-exist"}})
+	assert.Error(t, err)
+}
@@ -68,6 +68,64 @@ func GetAvailableNUMAsAndReclaimedCores(conf *config.Configuration, metaReader m
 	return availNUMAs, reclaimedCoresContainers, nil
 }
 
+// GetNUMAMemoryLimitOfDedicatedCores returns the memory limit of numa exclusive containers with
+// reclaim enabled on each numa, and the limit is evenly split if a container binds to multiple numas.
+// containers without memory limit are skipped, and their numas fall back to usage-based estimation.
+func GetNUMAMemoryLimitOfDedicatedCores(conf *config.Configuration, metaReader metacache.MetaReader,
+	metaServer *metaserver.MetaServer) (map[int]float64, error) {
+	var errList []error
+	numaLimits := make(map[int]float64)
+
+	metaReader.RangeContainer(func(podUID string, containerName string, containerInfo *types.ContainerInfo) bool {
+		if !containerInfo.IsNumaExclusive() {
+			return true
+		}
+
+		reclaimEnable, err := PodEnableReclaim(context.Background(), metaServer, podUID, conf.GetDynamicConfiguration().EnableReclaim)
+		if err != nil {
+			errList = append(errList, err)
+			return true
+		} else if !reclaimEnable {
+			// numas of containers without reclaim are excluded totally
+			return true
+		}
+
+		pod, err := metaServer.GetPod(context.Background(), podUID)
+		if err != nil {
+			errList = append(errList, err)
+			return true
+		}
+
+		var limit float64
+		for _, container := range pod.Spec.Containers {
+			if container.Name == containerName {
+				limit = float64(container.Resources.Limits.Memory().Value())
+				break
+			}
+		}
+		if limit <= 0 {
+			return true
+		}
+
+		memset := machine.GetCPUAssignmentNUMAs(containerInfo.TopologyAwareAssignments)
+		if memset.IsEmpty() {
+			errList = append(errList, fmt.Errorf("container(%v/%v) TopologyAwareAssignments is empty", containerInfo.PodName, containerName))
+			return true
+		}
+		for _, numaID := range memset.ToSliceInt() {
+			numaLimits[numaID] += limit / float64(memset.Size())
+		}
+		return true
+	})
+
+	err := errors.NewAggregate(errList)
+	if err != nil {
+		return nil, err
+	}
+
+	return numaLimits, nil
+}
+
 func reclaimedContainersFilter(ci *types.ContainerInfo) bool {
 	return ci != nil && ci.QoSLevel == apiconsts.PodAnnotationQoSLevelReclaimedCores
 }
@@ -23,6 +23,7 @@ import (
 	"sync"
 	"time"
 
+	"go.uber.org/atomic"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/apimachinery/pkg/util/wait"
@@ -83,6 +84,9 @@ type memoryResourceAdvisor struct {
 
 	recvCh   chan types.TriggerInfo
 	sendChan chan types.InternalMemoryCalculationResult
+
+	// paused skips periodic updates, while the last results are still served
+	paused atomic.Bool
 }
 
 // NewMemoryResourceAdvisor returns a memoryResourceAdvisor instance
@@ -129,15 +133,42 @@ func NewMemoryResourceAdvisor(conf *config.Configuration, extraConf interface{},
 }
 
 func (ra *memoryResourceAdvisor) Run(ctx context.Context) {
-	period := ra.conf.SysAdvisorPluginsConfiguration.QoSAwarePluginConfiguration.SyncPeriod
+	period := ra.getUpdateInterval()
 
 	general.InfoS("wait to list containers")
-	<-ra.recvCh
-	general.InfoS("list containers successfully")
+	select {
+	case <-ra.recvCh:
+		general.InfoS("list containers successfully")
+	case <-ctx.Done():
+		general.InfoS("stopped before listing containers")
+		return
+	}
 
 	go wait.Until(ra.update, period, ctx.Done())
 }
 
+// getUpdateInterval returns the configured update interval of memory advisor, or sync period if not specified
+func (ra *memoryResourceAdvisor) getUpdateInterval() time.Duration {
+	if interval, ok := ra.conf.ResourceUpdateIntervals[string(types.QoSResourceMemory)]; ok {
+		return interval
+	}
+	return ra.conf.SysAdvisorPluginsConfiguration.QoSAwarePluginConfiguration.SyncPeriod
+}
+
+// KickOff starts updating without waiting for memory server to list containers, and does nothing
+// if a trigger is already pending
+func (ra *memoryResourceAdvisor) KickOff() {
+	select {
+	case ra.recvCh <- types.TriggerInfo{TimeStamp: time.Now()}:
+	default:
+	}
+}
+
+// SetPaused pauses or resumes updating, and the last results are still served while paused
+func (ra *memoryResourceAdvisor) SetPaused(paused bool) {
+	ra.paused.Store(paused)
+}
+
 func (ra *memoryResourceAdvisor) GetChannels() (interface{}, interface{}) {
 	return ra.recvCh, ra.sendChan
 }
@@ -160,6 +191,22 @@ func (ra *memoryResourceAdvisor) GetHeadroom() (resource.Quantity, error) {
 	return resource.Quantity{}, fmt.Errorf("failed to get valid headroom")
 }
 
+// GetHeadroomByNuma returns headroom of each numa from the same policy serving GetHeadroom,
+// so that the sum of numa headroom always equals to the aggregate one
+func (ra *memoryResourceAdvisor) GetHeadroomByNuma() (map[int]resource.Quantity, error) {
+	ra.mutex.RLock()
+	defer ra.mutex.RUnlock()
+
+	for _, headroomPolicy := range ra.headroomPolices {
+		if _, err := headroomPolicy.GetHeadroom(); err != nil {
+			continue
+		}
+		return headroomPolicy.GetHeadroomByNuma()
+	}
+
+	return nil, fmt.Errorf("failed to get valid numa headroom")
+}
+
 func (ra *memoryResourceAdvisor) sendAdvices() {
 	// send to server
 	result := types.InternalMemoryCalculationResult{TimeStamp: time.Now()}
@@ -178,6 +225,11 @@ func (ra *memoryResourceAdvisor) sendAdvices() {
 }
 
 func (ra *memoryResourceAdvisor) update() {
+	if ra.paused.Load() {
+		general.InfoS("[qosaware-memory] advisor is paused, skip updating")
+		return
+	}
+
 	ra.mutex.Lock()
 	defer ra.mutex.Unlock()
 
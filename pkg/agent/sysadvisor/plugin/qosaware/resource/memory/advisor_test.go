@@ -1017,3 +1017,63 @@ func TestUpdate(t *testing.T) {
 		})
 	}
 }
+
+func TestGetUpdateInterval(t *testing.T) {
+	t.Parallel()
+
+	conf, err := options.NewOptions().Config()
+	require.NoError(t, err)
+	conf.QoSAwarePluginConfiguration.SyncPeriod = 5 * time.Second
+
+	ra := &memoryResourceAdvisor{conf: conf}
+	assert.Equal(t, 5*time.Second, ra.getUpdateInterval())
+
+	conf.ResourceUpdateIntervals[string(types.QoSResourceMemory)] = 30 * time.Second
+	assert.Equal(t, 30*time.Second, ra.getUpdateInterval())
+}
+
+func TestUpdateWhilePaused(t *testing.T) {
+	t.Parallel()
+
+	conf, err := options.NewOptions().Config()
+	require.NoError(t, err)
+
+	// paused advisor returns before touching any input, so update never panics without meta reader
+	ra := &memoryResourceAdvisor{conf: conf, sendChan: make(chan types.InternalMemoryCalculationResult, 1)}
+	ra.SetPaused(true)
+	assert.NotPanics(t, ra.update)
+	assert.Empty(t, ra.sendChan)
+}
+
+func TestRunStoppedBeforeListingContainers(t *testing.T) {
+	t.Parallel()
+
+	conf, err := options.NewOptions().Config()
+	require.NoError(t, err)
+
+	ra := &memoryResourceAdvisor{conf: conf, recvCh: make(chan types.TriggerInfo, 1)}
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		ra.Run(ctx)
+		close(stopped)
+	}()
+
+	cancel()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Errorf("run doesn't return after context is done")
+	}
+}
+
+func TestKickOff(t *testing.T) {
+	t.Parallel()
+
+	ra := &memoryResourceAdvisor{recvCh: make(chan types.TriggerInfo, 1)}
+
+	// kicking off never blocks even if the trigger is pending
+	ra.KickOff()
+	ra.KickOff()
+	assert.Len(t, ra.recvCh, 1)
+}
@@ -44,6 +44,10 @@ type HeadroomPolicy interface {
 
 	// GetHeadroom returns the latest headroom estimation
 	GetHeadroom() (resource.Quantity, error)
+
+	// GetHeadroomByNuma returns the latest headroom estimation of each numa,
+	// and the sum of which equals to GetHeadroom
+	GetHeadroomByNuma() (map[int]resource.Quantity, error)
 }
 
 type InitFunc func(conf *config.Configuration, extraConfig interface{}, metaReader metacache.MetaReader,
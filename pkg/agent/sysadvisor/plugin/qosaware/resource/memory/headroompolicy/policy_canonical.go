@@ -169,3 +169,7 @@ func (p *PolicyCanonical) GetHeadroom() (resource.Quantity, error) {
 
 	return *resource.NewQuantity(int64(p.memoryHeadroom), resource.BinarySI), nil
 }
+
+func (p *PolicyCanonical) GetHeadroomByNuma() (map[int]resource.Quantity, error) {
+	return nil, fmt.Errorf("policy %v doesn't support numa level headroom", p.Name())
+}
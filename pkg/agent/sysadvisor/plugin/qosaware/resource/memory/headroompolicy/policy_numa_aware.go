@@ -37,9 +37,10 @@ import (
 type PolicyNUMAAware struct {
 	*PolicyBase
 
-	// memoryHeadroom is valid to be used iff updateStatus successes
-	memoryHeadroom float64
-	updateStatus   types.PolicyUpdateStatus
+	// memoryHeadroom and numaMemoryHeadroom are valid to be used iff updateStatus successes
+	memoryHeadroom     float64
+	numaMemoryHeadroom map[int]float64
+	updateStatus       types.PolicyUpdateStatus
 
 	conf *config.Configuration
 }
@@ -78,6 +79,7 @@ func (p *PolicyNUMAAware) Update() (err error) {
 		reservedForAllocate float64 = 0
 		data                metric.MetricData
 	)
+	numaMemoryHeadroom := make(map[int]float64)
 	dynamicConfig := p.conf.GetDynamicConfiguration()
 
 	availNUMAs, reclaimedCoresContainers, err := helper.GetAvailableNUMAsAndReclaimedCores(p.conf, p.metaReader, p.metaServer)
@@ -85,6 +87,23 @@ func (p *PolicyNUMAAware) Update() (err error) {
 		return err
 	}
 
+	dedicatedNUMALimits, err := helper.GetNUMAMemoryLimitOfDedicatedCores(p.conf, p.metaReader, p.metaServer)
+	if err != nil {
+		return err
+	}
+
+	watermarkScaleFactor, err := p.metaServer.GetNodeMetric(consts.MetricMemScaleFactorSystem)
+	if err != nil {
+		general.InfoS("Can not get system watermark scale factor")
+		return err
+	}
+
+	// memory of reclaimed_cores containers is spread evenly among available numas
+	var reclaimedCoresMemory float64 = 0
+	for _, container := range reclaimedCoresContainers {
+		reclaimedCoresMemory += container.MemoryRequest
+	}
+
 	for _, numaID := range availNUMAs.ToSliceInt() {
 		data, err = p.metaServer.GetNumaMetric(numaID, consts.MetricMemFreeNuma)
 		if err != nil {
@@ -106,30 +125,34 @@ func (p *PolicyNUMAAware) Update() (err error) {
 		}
 		total := data.Value
 		availNUMATotal += total
-		reservedForAllocate += p.essentials.ReservedForAllocate / float64(p.metaServer.NumNUMANodes)
+		numaReservedForAllocate := p.essentials.ReservedForAllocate / float64(p.metaServer.NumNUMANodes)
+		reservedForAllocate += numaReservedForAllocate
 
 		numaReclaimable := free + inactiveFile*dynamicConfig.CacheBasedRatio
 
+		// memory locked by limits of dedicated containers can't be reclaimed even if it's free now
+		dedicatedLimit, ok := dedicatedNUMALimits[numaID]
+		if ok {
+			numaReclaimable = math.Min(numaReclaimable, math.Max(total-dedicatedLimit, 0))
+		}
+
 		general.InfoS("NUMA memory info", "numaID", numaID,
 			"total", general.FormatMemoryQuantity(total), "free", general.FormatMemoryQuantity(free),
 			"inactiveFile", general.FormatMemoryQuantity(inactiveFile), "CacheBasedRatio", dynamicConfig.CacheBasedRatio,
+			"dedicatedLimit", general.FormatMemoryQuantity(dedicatedLimit),
 			"numaReclaimable", general.FormatMemoryQuantity(numaReclaimable),
 		)
 
+		numaReclaimable += reclaimedCoresMemory / float64(availNUMAs.Size())
 		reclaimableMemory += numaReclaimable
-	}
 
-	for _, container := range reclaimedCoresContainers {
-		reclaimableMemory += container.MemoryRequest
+		// reserve memory for watermark_scale_factor to make kswapd less happened
+		numaWatermarkReserved := total * watermarkScaleFactor.Value / 10000
+		numaMemoryHeadroom[numaID] = math.Floor(math.Max(numaReclaimable-numaWatermarkReserved-numaReservedForAllocate, 0))
 	}
 
-	watermarkScaleFactor, err := p.metaServer.GetNodeMetric(consts.MetricMemScaleFactorSystem)
-	if err != nil {
-		general.InfoS("Can not get system watermark scale factor")
-		return err
-	}
+	p.boundInterleaveHeadroom(numaMemoryHeadroom)
 
-	// reserve memory for watermark_scale_factor to make kswapd less happened
 	systemWatermarkReserved := availNUMATotal * watermarkScaleFactor.Value / 10000
 
 	general.InfoS("total memory reclaimable",
@@ -137,11 +160,42 @@ func (p *PolicyNUMAAware) Update() (err error) {
 		"ResourceUpperBound", general.FormatMemoryQuantity(p.essentials.ResourceUpperBound),
 		"systemWatermarkReserved", general.FormatMemoryQuantity(systemWatermarkReserved),
 		"reservedForAllocate", general.FormatMemoryQuantity(reservedForAllocate))
-	p.memoryHeadroom = math.Max(reclaimableMemory-systemWatermarkReserved-reservedForAllocate, 0)
+
+	// aggregate headroom is summed up by numas, so that numas without free memory can't offset others
+	p.memoryHeadroom = 0
+	for _, numaHeadroom := range numaMemoryHeadroom {
+		p.memoryHeadroom += numaHeadroom
+	}
+	p.numaMemoryHeadroom = numaMemoryHeadroom
 
 	return nil
 }
 
+// boundInterleaveHeadroom bounds headroom of each interleave numa by the least one among them, since memory of
+// interleaved reclaimed workloads is allocated from all of them evenly, and the numa with the least headroom
+// is exhausted first; interleave numas not available for reclaimed workloads are ignored.
+func (p *PolicyNUMAAware) boundInterleaveHeadroom(numaMemoryHeadroom map[int]float64) {
+	interleaveNUMAs := make([]int, 0, len(p.conf.InterleaveNUMAs))
+	minHeadroom := math.MaxFloat64
+	for _, numaID := range p.conf.InterleaveNUMAs {
+		headroom, ok := numaMemoryHeadroom[numaID]
+		if !ok {
+			continue
+		}
+		interleaveNUMAs = append(interleaveNUMAs, numaID)
+		minHeadroom = math.Min(minHeadroom, headroom)
+	}
+	if len(interleaveNUMAs) < 2 {
+		return
+	}
+
+	general.InfoS("bound headroom of interleave numas", "interleaveNUMAs", interleaveNUMAs,
+		"minHeadroom", general.FormatMemoryQuantity(minHeadroom))
+	for _, numaID := range interleaveNUMAs {
+		numaMemoryHeadroom[numaID] = minHeadroom
+	}
+}
+
 func (p *PolicyNUMAAware) GetHeadroom() (resource.Quantity, error) {
 	if p.updateStatus != types.PolicyUpdateSucceeded {
 		return resource.Quantity{}, fmt.Errorf("last update failed")
@@ -149,3 +203,15 @@ func (p *PolicyNUMAAware) GetHeadroom() (resource.Quantity, error) {
 
 	return *resource.NewQuantity(int64(p.memoryHeadroom), resource.BinarySI), nil
 }
+
+func (p *PolicyNUMAAware) GetHeadroomByNuma() (map[int]resource.Quantity, error) {
+	if p.updateStatus != types.PolicyUpdateSucceeded {
+		return nil, fmt.Errorf("last update failed")
+	}
+
+	numaHeadroom := make(map[int]resource.Quantity, len(p.numaMemoryHeadroom))
+	for numaID, headroom := range p.numaMemoryHeadroom {
+		numaHeadroom[numaID] = *resource.NewQuantity(int64(headroom), resource.BinarySI)
+	}
+	return numaHeadroom, nil
+}
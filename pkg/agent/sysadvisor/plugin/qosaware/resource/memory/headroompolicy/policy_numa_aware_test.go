@@ -26,6 +26,7 @@ import (
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 
 	"github.com/kubewharf/katalyst-api/pkg/consts"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
@@ -47,15 +48,18 @@ func TestPolicyNUMAAware(t *testing.T) {
 	type fields struct {
 		podList                     []*v1.Pod
 		containers                  []*types.ContainerInfo
+		nodeEnableReclaim           bool
 		memoryHeadroomConfiguration *memoryheadroom.MemoryHeadroomConfiguration
 		essentials                  types.ResourceEssentials
+		interleaveNUMAs             []int
 		setFakeMetric               func(store *metric.FakeMetricsFetcher)
 	}
 	tests := []struct {
-		name    string
-		fields  fields
-		want    resource.Quantity
-		wantErr bool
+		name             string
+		fields           fields
+		want             resource.Quantity
+		wantNumaHeadroom map[int]resource.Quantity
+		wantErr          bool
 	}{
 		{
 			name: "numa metrics missing",
@@ -120,6 +124,10 @@ func TestPolicyNUMAAware(t *testing.T) {
 			},
 			wantErr: false,
 			want:    resource.MustParse("221Gi"),
+			wantNumaHeadroom: map[int]resource.Quantity{
+				0: resource.MustParse("110.5Gi"),
+				1: resource.MustParse("110.5Gi"),
+			},
 		},
 		{
 			name: "normal: reclaimed_cores containers only",
@@ -195,6 +203,210 @@ func TestPolicyNUMAAware(t *testing.T) {
 			wantErr: false,
 			want:    resource.MustParse("130.5Gi"),
 		},
+		{
+			name: "normal: numa-exclusive containers with and without memory limit",
+			fields: fields{
+				podList: []*v1.Pod{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "pod2",
+							Namespace: "default",
+							UID:       "pod2",
+						},
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{
+								{
+									Name: "container2",
+									Resources: v1.ResourceRequirements{
+										Limits: v1.ResourceList{
+											v1.ResourceMemory: resource.MustParse("200Gi"),
+										},
+									},
+								},
+							},
+						},
+					},
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "pod3",
+							Namespace: "default",
+							UID:       "pod3",
+						},
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{
+								{
+									Name: "container3",
+								},
+							},
+						},
+					},
+				},
+				containers: []*types.ContainerInfo{
+					makeContainerInfo("pod1", "default",
+						"pod1", "container1",
+						consts.PodAnnotationQoSLevelReclaimedCores, nil,
+						nil, 20<<30),
+					makeContainerInfo("pod2", "default",
+						"pod2", "container2",
+						consts.PodAnnotationQoSLevelDedicatedCores, map[string]string{
+							consts.PodAnnotationMemoryEnhancementNumaBinding:   consts.PodAnnotationMemoryEnhancementNumaBindingEnable,
+							consts.PodAnnotationMemoryEnhancementNumaExclusive: consts.PodAnnotationMemoryEnhancementNumaExclusiveEnable,
+						},
+						types.TopologyAwareAssignment{
+							0: machine.NewCPUSet(0),
+						}, 30<<30),
+					makeContainerInfo("pod3", "default",
+						"pod3", "container3",
+						consts.PodAnnotationQoSLevelDedicatedCores, map[string]string{
+							consts.PodAnnotationMemoryEnhancementNumaBinding:   consts.PodAnnotationMemoryEnhancementNumaBindingEnable,
+							consts.PodAnnotationMemoryEnhancementNumaExclusive: consts.PodAnnotationMemoryEnhancementNumaExclusiveEnable,
+						},
+						types.TopologyAwareAssignment{
+							1: machine.NewCPUSet(24),
+						}, 30<<30),
+				},
+				nodeEnableReclaim: true,
+				memoryHeadroomConfiguration: &memoryheadroom.MemoryHeadroomConfiguration{
+					MemoryUtilBasedConfiguration: &memoryheadroom.MemoryUtilBasedConfiguration{
+						CacheBasedRatio: 0.5,
+					},
+				},
+				essentials: types.ResourceEssentials{
+					EnableReclaim:       true,
+					ResourceUpperBound:  400 << 30,
+					ReservedForAllocate: 4 << 30,
+				},
+				setFakeMetric: func(store *metric.FakeMetricsFetcher) {
+					store.SetNodeMetric(pkgconsts.MetricMemScaleFactorSystem, utilmetric.MetricData{Value: 500, Time: &now})
+					store.SetNumaMetric(0, pkgconsts.MetricMemTotalNuma, utilmetric.MetricData{Value: 250 << 30, Time: &now})
+					store.SetNumaMetric(1, pkgconsts.MetricMemTotalNuma, utilmetric.MetricData{Value: 250 << 30, Time: &now})
+					store.SetNumaMetric(0, pkgconsts.MetricMemFreeNuma, utilmetric.MetricData{Value: 100 << 30, Time: &now})
+					store.SetNumaMetric(1, pkgconsts.MetricMemFreeNuma, utilmetric.MetricData{Value: 100 << 30, Time: &now})
+					store.SetNumaMetric(0, pkgconsts.MetricMemInactiveFileNuma, utilmetric.MetricData{Value: 50 << 30, Time: &now})
+					store.SetNumaMetric(1, pkgconsts.MetricMemInactiveFileNuma, utilmetric.MetricData{Value: 50 << 30, Time: &now})
+				},
+			},
+			wantErr: false,
+			// numa 0 is capped by the limit of pod2 as 50Gi, and numa 1 falls back to 125Gi based on usage
+			want: resource.MustParse("166Gi"),
+			wantNumaHeadroom: map[int]resource.Quantity{
+				0: resource.MustParse("45.5Gi"),
+				1: resource.MustParse("120.5Gi"),
+			},
+		},
+		{
+			name: "normal: numa-exclusive container consuming a whole numa",
+			fields: fields{
+				podList: []*v1.Pod{
+					{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "pod2",
+							Namespace: "default",
+							UID:       "pod2",
+						},
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{
+								{
+									Name: "container2",
+									Resources: v1.ResourceRequirements{
+										Limits: v1.ResourceList{
+											v1.ResourceMemory: resource.MustParse("250Gi"),
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+				containers: []*types.ContainerInfo{
+					makeContainerInfo("pod2", "default",
+						"pod2", "container2",
+						consts.PodAnnotationQoSLevelDedicatedCores, map[string]string{
+							consts.PodAnnotationMemoryEnhancementNumaBinding:   consts.PodAnnotationMemoryEnhancementNumaBindingEnable,
+							consts.PodAnnotationMemoryEnhancementNumaExclusive: consts.PodAnnotationMemoryEnhancementNumaExclusiveEnable,
+						},
+						types.TopologyAwareAssignment{
+							0: machine.NewCPUSet(0),
+						}, 250<<30),
+				},
+				nodeEnableReclaim: true,
+				memoryHeadroomConfiguration: &memoryheadroom.MemoryHeadroomConfiguration{
+					MemoryUtilBasedConfiguration: &memoryheadroom.MemoryUtilBasedConfiguration{
+						CacheBasedRatio: 0.5,
+					},
+				},
+				essentials: types.ResourceEssentials{
+					EnableReclaim:       true,
+					ResourceUpperBound:  400 << 30,
+					ReservedForAllocate: 4 << 30,
+				},
+				setFakeMetric: func(store *metric.FakeMetricsFetcher) {
+					store.SetNodeMetric(pkgconsts.MetricMemScaleFactorSystem, utilmetric.MetricData{Value: 500, Time: &now})
+					store.SetNumaMetric(0, pkgconsts.MetricMemTotalNuma, utilmetric.MetricData{Value: 250 << 30, Time: &now})
+					store.SetNumaMetric(1, pkgconsts.MetricMemTotalNuma, utilmetric.MetricData{Value: 250 << 30, Time: &now})
+					store.SetNumaMetric(0, pkgconsts.MetricMemFreeNuma, utilmetric.MetricData{Value: 100 << 30, Time: &now})
+					store.SetNumaMetric(1, pkgconsts.MetricMemFreeNuma, utilmetric.MetricData{Value: 100 << 30, Time: &now})
+					store.SetNumaMetric(0, pkgconsts.MetricMemInactiveFileNuma, utilmetric.MetricData{Value: 50 << 30, Time: &now})
+					store.SetNumaMetric(1, pkgconsts.MetricMemInactiveFileNuma, utilmetric.MetricData{Value: 50 << 30, Time: &now})
+				},
+			},
+			wantErr: false,
+			// numa 0 has no free memory left by pod2, and can't offset headroom of numa 1
+			want: resource.MustParse("110.5Gi"),
+			wantNumaHeadroom: map[int]resource.Quantity{
+				0: resource.MustParse("0"),
+				1: resource.MustParse("110.5Gi"),
+			},
+		},
+		{
+			name: "imbalanced numas: headroom summed up without interleave",
+			fields: fields{
+				podList:    []*v1.Pod{},
+				containers: []*types.ContainerInfo{},
+				memoryHeadroomConfiguration: &memoryheadroom.MemoryHeadroomConfiguration{
+					MemoryUtilBasedConfiguration: &memoryheadroom.MemoryUtilBasedConfiguration{
+						CacheBasedRatio: 0.5,
+					},
+				},
+				essentials: types.ResourceEssentials{
+					EnableReclaim:       true,
+					ResourceUpperBound:  400 << 30,
+					ReservedForAllocate: 4 << 30,
+				},
+				setFakeMetric: setImbalancedNUMAMetrics(now),
+			},
+			wantErr: false,
+			want:    resource.MustParse("161Gi"),
+			wantNumaHeadroom: map[int]resource.Quantity{
+				0: resource.MustParse("50.5Gi"),
+				1: resource.MustParse("110.5Gi"),
+			},
+		},
+		{
+			name: "imbalanced numas: headroom bounded by the least numa with interleave",
+			fields: fields{
+				podList:    []*v1.Pod{},
+				containers: []*types.ContainerInfo{},
+				memoryHeadroomConfiguration: &memoryheadroom.MemoryHeadroomConfiguration{
+					MemoryUtilBasedConfiguration: &memoryheadroom.MemoryUtilBasedConfiguration{
+						CacheBasedRatio: 0.5,
+					},
+				},
+				essentials: types.ResourceEssentials{
+					EnableReclaim:       true,
+					ResourceUpperBound:  400 << 30,
+					ReservedForAllocate: 4 << 30,
+				},
+				interleaveNUMAs: []int{0, 1},
+				setFakeMetric:   setImbalancedNUMAMetrics(now),
+			},
+			wantErr: false,
+			want:    resource.MustParse("101Gi"),
+			wantNumaHeadroom: map[int]resource.Quantity{
+				0: resource.MustParse("50.5Gi"),
+				1: resource.MustParse("50.5Gi"),
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -208,6 +420,8 @@ func TestPolicyNUMAAware(t *testing.T) {
 
 			conf := generateTestConfiguration(t, ckDir, sfDir)
 			conf.GetDynamicConfiguration().MemoryHeadroomConfiguration = tt.fields.memoryHeadroomConfiguration
+			conf.GetDynamicConfiguration().EnableReclaim = tt.fields.nodeEnableReclaim
+			conf.InterleaveNUMAs = tt.fields.interleaveNUMAs
 
 			metricsFetcher := metric.NewFakeMetricsFetcher(metrics.DummyMetrics{})
 			metaCache, err := metacache.NewMetaCacheImp(conf, metricspool.DummyMetricsEmitterPool{}, metricsFetcher)
@@ -238,6 +452,38 @@ func TestPolicyNUMAAware(t *testing.T) {
 				return
 			}
 			assert.Equal(t, tt.want.MilliValue(), got.MilliValue())
+			if tt.wantErr {
+				return
+			}
+
+			gotNumaHeadroom, err := p.GetHeadroomByNuma()
+			require.NoError(t, err)
+			var sum int64 = 0
+			for numaID, headroom := range gotNumaHeadroom {
+				sum += headroom.Value()
+				if tt.wantNumaHeadroom != nil {
+					want, ok := tt.wantNumaHeadroom[numaID]
+					require.True(t, ok)
+					assert.Equal(t, want.Value(), headroom.Value(), "numa %v", numaID)
+				}
+			}
+			if tt.wantNumaHeadroom != nil {
+				assert.Equal(t, len(tt.wantNumaHeadroom), len(gotNumaHeadroom))
+			}
+			assert.Equal(t, got.Value(), sum)
 		})
 	}
 }
+
+// setImbalancedNUMAMetrics sets metrics of two numas, where numa 0 has much less free memory than numa 1
+func setImbalancedNUMAMetrics(now time.Time) func(store *metric.FakeMetricsFetcher) {
+	return func(store *metric.FakeMetricsFetcher) {
+		store.SetNodeMetric(pkgconsts.MetricMemScaleFactorSystem, utilmetric.MetricData{Value: 500, Time: &now})
+		store.SetNumaMetric(0, pkgconsts.MetricMemTotalNuma, utilmetric.MetricData{Value: 250 << 30, Time: &now})
+		store.SetNumaMetric(1, pkgconsts.MetricMemTotalNuma, utilmetric.MetricData{Value: 250 << 30, Time: &now})
+		store.SetNumaMetric(0, pkgconsts.MetricMemFreeNuma, utilmetric.MetricData{Value: 40 << 30, Time: &now})
+		store.SetNumaMetric(1, pkgconsts.MetricMemFreeNuma, utilmetric.MetricData{Value: 100 << 30, Time: &now})
+		store.SetNumaMetric(0, pkgconsts.MetricMemInactiveFileNuma, utilmetric.MetricData{Value: 50 << 30, Time: &now})
+		store.SetNumaMetric(1, pkgconsts.MetricMemInactiveFileNuma, utilmetric.MetricData{Value: 50 << 30, Time: &now})
+	}
+}
@@ -19,9 +19,13 @@ package resource
 import (
 	"context"
 	"fmt"
+	"math"
+	"sync"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/klog/v2"
 
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource/cpu"
@@ -43,6 +47,37 @@ type ResourceAdvisor interface {
 
 	// GetHeadroom returns the corresponding headroom quantity according to resource name
 	GetHeadroom(resourceName v1.ResourceName) (resource.Quantity, error)
+
+	// GetHeadroomFraction returns the headroom of resource name divided by node capacity of it,
+	// and zero if the capacity is unknown
+	GetHeadroomFraction(resourceName v1.ResourceName) (float64, error)
+
+	// GetHeadroomWithConfidence returns headroom of resource name, together with a confidence in [0, 1]
+	// estimated by its sub advisor; see HeadroomConfidenceProvider for how confidence is derived
+	GetHeadroomWithConfidence(resourceName v1.ResourceName) (resource.Quantity, float64, error)
+
+	// GetHeadroomTrend returns the slope of headroom of resource name per second over the recent window,
+	// and negative slope means headroom is shrinking; see HeadroomTrendProvider for how trend is derived
+	GetHeadroomTrend(resourceName v1.ResourceName, window time.Duration) (float64, error)
+
+	// GetCompositeHeadroom returns a score blending headroom of multiple resources, each of which
+	// is normalized against node capacity and then weighted according to the given weights
+	GetCompositeHeadroom(weights map[v1.ResourceName]float64) (float64, error)
+
+	// SetReclaimCordoned cordons or uncordons reclaim; headroom is reported as zero while cordoned to drain
+	// reclaim workloads, but sub advisors keep updating provision and headroom as usual
+	SetReclaimCordoned(cordoned bool)
+
+	// Reconfigure updates sub resource advisors to the set in config without restart;
+	// advisors remaining enabled keep running with their states
+	Reconfigure(conf *config.Configuration) error
+
+	// Pause stops the sub advisor of resource name from producing new results until resumed, while it
+	// keeps running and serving its last results, e.g. to isolate a misbehaving advisor without teardown
+	Pause(resourceName types.QoSResourceName) error
+
+	// Resume lets the paused sub advisor of resource name produce new results again
+	Resume(resourceName types.QoSResourceName) error
 }
 
 // SubResourceAdvisor updates resource provision of a certain dimension based on the latest
@@ -60,8 +95,68 @@ type SubResourceAdvisor interface {
 	GetHeadroom() (resource.Quantity, error)
 }
 
+// HeadroomConfidenceProvider is optionally implemented by sub resource advisors able to estimate how
+// confident they are in their headroom, e.g. according to maturity of regions and freshness of inputs.
+// Headroom of sub advisors not implementing it is regarded as fully confident; headroom reported as zero
+// for cordoned reclaim is fully confident too, while that for absent advisors is of zero confidence.
+type HeadroomConfidenceProvider interface {
+	// GetHeadroomConfidence returns confidence in [0, 1] of the latest headroom
+	GetHeadroomConfidence() float64
+}
+
+// HeadroomTrendProvider is optionally implemented by sub resource advisors retaining headroom history,
+// and trend of sub advisors not implementing it is unavailable; headroom reported as zero for cordoned
+// reclaim is regarded as flat.
+type HeadroomTrendProvider interface {
+	// GetHeadroomTrend returns the slope of headroom per second estimated from history within the window
+	GetHeadroomTrend(window time.Duration) (float64, error)
+}
+
+// CalculationResultAcker is optionally implemented by sub resource advisors tracking how far consumers of
+// their calculation results lag behind, and consumers acknowledge the generation of each result consumed.
+type CalculationResultAcker interface {
+	// Ack acknowledges that results up to the generation are consumed
+	Ack(generation uint64)
+}
+
+// PausableSubResourceAdvisor is optionally implemented by sub resource advisors able to be paused, and
+// sub advisors not implementing it can't be paused
+type PausableSubResourceAdvisor interface {
+	// SetPaused pauses or resumes updating, and paused advisors skip updates but keep serving last results
+	SetPaused(paused bool)
+}
+
+// SelfDrivenSubResourceAdvisor is optionally implemented by sub resource advisors able to keep updating once
+// kicked off, and only they can be enabled at runtime, since qrm servers triggering updates of the others only
+// bind to sub advisors existing at startup
+type SelfDrivenSubResourceAdvisor interface {
+	// KickOff starts updating without waiting for the trigger from qrm server
+	KickOff()
+}
+
 type resourceAdvisorWrapper struct {
+	mutex            sync.RWMutex
 	subAdvisorsToRun map[types.QoSResourceName]SubResourceAdvisor
+
+	// ctx is the context passed in Run, and nil means the wrapper is not running yet;
+	// each sub advisor runs with a child context to be stopped individually
+	ctx               context.Context
+	subAdvisorCancels map[types.QoSResourceName]context.CancelFunc
+
+	// absentAdvisorAsZeroHeadroom regards headroom of resources without active advisors as zero
+	absentAdvisorAsZeroHeadroom bool
+
+	// reclaimCordoned reports headroom of all resources as zero, e.g. during node maintenance
+	reclaimCordoned bool
+
+	// newSubAdvisor constructs sub advisors enabled at runtime
+	newSubAdvisor func(resourceName types.QoSResourceName, conf *config.Configuration, extraConf interface{},
+		metaCache metacache.MetaCache, metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter) (SubResourceAdvisor, error)
+
+	extraConf  interface{}
+	metaCache  metacache.MetaCache
+	metaServer *metaserver.MetaServer
+	emitter    metrics.MetricEmitter
 }
 
 // NewResourceAdvisor returns a resource advisor wrapper instance, initializing all required
@@ -69,7 +164,15 @@ type resourceAdvisorWrapper struct {
 func NewResourceAdvisor(conf *config.Configuration, extraConf interface{}, metaCache metacache.MetaCache,
 	metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter) (ResourceAdvisor, error) {
 	resourceAdvisor := resourceAdvisorWrapper{
-		subAdvisorsToRun: make(map[types.QoSResourceName]SubResourceAdvisor),
+		subAdvisorsToRun:            make(map[types.QoSResourceName]SubResourceAdvisor),
+		subAdvisorCancels:           make(map[types.QoSResourceName]context.CancelFunc),
+		absentAdvisorAsZeroHeadroom: conf.AbsentAdvisorAsZeroHeadroom,
+		newSubAdvisor:               NewSubResourceAdvisor,
+
+		extraConf:  extraConf,
+		metaCache:  metaCache,
+		metaServer: metaServer,
+		emitter:    emitter,
 	}
 
 	for _, resourceNameStr := range conf.ResourceAdvisors {
@@ -89,7 +192,7 @@ func NewSubResourceAdvisor(resourceName types.QoSResourceName, conf *config.Conf
 	metaCache metacache.MetaCache, metaServer *metaserver.MetaServer, emitter metrics.MetricEmitter) (SubResourceAdvisor, error) {
 	switch resourceName {
 	case types.QoSResourceCPU:
-		return cpu.NewCPUResourceAdvisor(conf, extraConf, metaCache, metaServer, emitter), nil
+		return cpu.NewCPUResourceAdvisor(conf, extraConf, metaCache, metaServer, emitter, nil), nil
 	case types.QoSResourceMemory:
 		return memory.NewMemoryResourceAdvisor(conf, extraConf, metaCache, metaServer, emitter), nil
 	default:
@@ -98,12 +201,80 @@ func NewSubResourceAdvisor(resourceName types.QoSResourceName, conf *config.Conf
 }
 
 func (ra *resourceAdvisorWrapper) Run(ctx context.Context) {
-	for _, subAdvisor := range ra.subAdvisorsToRun {
-		go subAdvisor.Run(ctx)
+	ra.mutex.Lock()
+	defer ra.mutex.Unlock()
+
+	ra.ctx = ctx
+	for resourceName, subAdvisor := range ra.subAdvisorsToRun {
+		ra.runSubAdvisor(resourceName, subAdvisor)
 	}
 }
 
+// Reconfigure diffs the sub advisors in config against the running ones, constructs, starts and kicks off
+// newly-enabled advisors, and stops removed ones. Since qrm servers only bind to sub advisors existing at
+// startup, newly-enabled advisors must be self-driven. If any of them fails to be constructed or isn't
+// self-driven, the previous set is kept intact.
+func (ra *resourceAdvisorWrapper) Reconfigure(conf *config.Configuration) error {
+	ra.mutex.Lock()
+	defer ra.mutex.Unlock()
+
+	desired := make(map[types.QoSResourceName]bool)
+	for _, resourceNameStr := range conf.ResourceAdvisors {
+		desired[types.QoSResourceName(resourceNameStr)] = true
+	}
+
+	// construct all newly-enabled advisors before touching running ones
+	added := make(map[types.QoSResourceName]SubResourceAdvisor)
+	for resourceName := range desired {
+		if _, ok := ra.subAdvisorsToRun[resourceName]; ok {
+			continue
+		}
+		subAdvisor, err := ra.newSubAdvisor(resourceName, conf, ra.extraConf, ra.metaCache, ra.metaServer, ra.emitter)
+		if err != nil {
+			return fmt.Errorf("new sub resource advisor for %v failed: %v", resourceName, err)
+		}
+		if _, ok := subAdvisor.(SelfDrivenSubResourceAdvisor); !ok {
+			return fmt.Errorf("sub resource advisor for %v can't be enabled at runtime", resourceName)
+		}
+		added[resourceName] = subAdvisor
+	}
+
+	for resourceName := range ra.subAdvisorsToRun {
+		if desired[resourceName] {
+			continue
+		}
+		if cancel, ok := ra.subAdvisorCancels[resourceName]; ok {
+			cancel()
+			delete(ra.subAdvisorCancels, resourceName)
+		}
+		delete(ra.subAdvisorsToRun, resourceName)
+		klog.Infof("[qosaware-resource] sub resource advisor %v removed", resourceName)
+	}
+
+	for resourceName, subAdvisor := range added {
+		ra.subAdvisorsToRun[resourceName] = subAdvisor
+		if ra.ctx != nil {
+			ra.runSubAdvisor(resourceName, subAdvisor)
+		}
+		subAdvisor.(SelfDrivenSubResourceAdvisor).KickOff()
+		klog.Infof("[qosaware-resource] sub resource advisor %v added", resourceName)
+	}
+
+	ra.absentAdvisorAsZeroHeadroom = conf.AbsentAdvisorAsZeroHeadroom
+	return nil
+}
+
+// runSubAdvisor starts the sub advisor with a child context; must be called with lock held
+func (ra *resourceAdvisorWrapper) runSubAdvisor(resourceName types.QoSResourceName, subAdvisor SubResourceAdvisor) {
+	ctx, cancel := context.WithCancel(ra.ctx)
+	ra.subAdvisorCancels[resourceName] = cancel
+	go subAdvisor.Run(ctx)
+}
+
 func (ra *resourceAdvisorWrapper) GetSubAdvisor(resourceName types.QoSResourceName) (SubResourceAdvisor, error) {
+	ra.mutex.RLock()
+	defer ra.mutex.RUnlock()
+
 	if subAdvisor, ok := ra.subAdvisorsToRun[resourceName]; ok {
 		return subAdvisor, nil
 	}
@@ -122,9 +293,183 @@ func (ra *resourceAdvisorWrapper) GetHeadroom(resourceName v1.ResourceName) (res
 }
 
 func (ra *resourceAdvisorWrapper) getSubAdvisorHeadroom(resourceName types.QoSResourceName) (resource.Quantity, error) {
+	ra.mutex.RLock()
+	defer ra.mutex.RUnlock()
+
 	subAdvisor, ok := ra.subAdvisorsToRun[resourceName]
 	if !ok {
+		if ra.absentAdvisorAsZeroHeadroom || ra.reclaimCordoned {
+			return *resource.NewQuantity(0, resource.DecimalSI), nil
+		}
 		return resource.Quantity{}, fmt.Errorf("no sub resource advisor for %v", resourceName)
 	}
-	return subAdvisor.GetHeadroom()
+
+	headroom, err := subAdvisor.GetHeadroom()
+	if ra.reclaimCordoned {
+		klog.Infof("[qosaware-resource] reclaim cordoned, report zero headroom for %v instead of %v (err: %v)",
+			resourceName, headroom.String(), err)
+		return *resource.NewQuantity(0, resource.DecimalSI), nil
+	}
+	return headroom, err
+}
+
+func (ra *resourceAdvisorWrapper) SetReclaimCordoned(cordoned bool) {
+	ra.mutex.Lock()
+	defer ra.mutex.Unlock()
+
+	if ra.reclaimCordoned != cordoned {
+		klog.Infof("[qosaware-resource] set reclaim cordoned: %v", cordoned)
+	}
+	ra.reclaimCordoned = cordoned
+}
+
+func (ra *resourceAdvisorWrapper) Pause(resourceName types.QoSResourceName) error {
+	return ra.setSubAdvisorPaused(resourceName, true)
+}
+
+func (ra *resourceAdvisorWrapper) Resume(resourceName types.QoSResourceName) error {
+	return ra.setSubAdvisorPaused(resourceName, false)
+}
+
+func (ra *resourceAdvisorWrapper) setSubAdvisorPaused(resourceName types.QoSResourceName, paused bool) error {
+	ra.mutex.RLock()
+	defer ra.mutex.RUnlock()
+
+	subAdvisor, ok := ra.subAdvisorsToRun[resourceName]
+	if !ok {
+		return fmt.Errorf("no sub resource advisor for %v", resourceName)
+	}
+	pausable, ok := subAdvisor.(PausableSubResourceAdvisor)
+	if !ok {
+		return fmt.Errorf("sub resource advisor for %v does not support pausing", resourceName)
+	}
+	pausable.SetPaused(paused)
+	klog.Infof("[qosaware-resource] set sub resource advisor %v paused: %v", resourceName, paused)
+	return nil
+}
+
+func (ra *resourceAdvisorWrapper) GetHeadroomFraction(resourceName v1.ResourceName) (float64, error) {
+	_, capacity, err := ra.getResourceCapacity(resourceName)
+	if err != nil {
+		return 0, err
+	}
+
+	headroom, err := ra.GetHeadroom(resourceName)
+	if err != nil {
+		return 0, err
+	}
+
+	if capacity <= 0 {
+		klog.Warningf("[qosaware-resource] capacity of resource %v is %v, regard headroom fraction as zero", resourceName, capacity)
+		return 0, nil
+	}
+	return headroom.AsApproximateFloat64() / capacity, nil
+}
+
+func (ra *resourceAdvisorWrapper) GetHeadroomWithConfidence(resourceName v1.ResourceName) (resource.Quantity, float64, error) {
+	var qosResourceName types.QoSResourceName
+	switch resourceName {
+	case v1.ResourceCPU:
+		qosResourceName = types.QoSResourceCPU
+	case v1.ResourceMemory:
+		qosResourceName = types.QoSResourceMemory
+	default:
+		return resource.Quantity{}, 0, fmt.Errorf("illegal resource %v", resourceName)
+	}
+
+	headroom, err := ra.getSubAdvisorHeadroom(qosResourceName)
+	if err != nil {
+		return resource.Quantity{}, 0, err
+	}
+	return headroom, ra.getSubAdvisorHeadroomConfidence(qosResourceName), nil
+}
+
+func (ra *resourceAdvisorWrapper) getSubAdvisorHeadroomConfidence(resourceName types.QoSResourceName) float64 {
+	ra.mutex.RLock()
+	defer ra.mutex.RUnlock()
+
+	if ra.reclaimCordoned {
+		return 1
+	}
+
+	subAdvisor, ok := ra.subAdvisorsToRun[resourceName]
+	if !ok {
+		return 0
+	}
+	provider, ok := subAdvisor.(HeadroomConfidenceProvider)
+	if !ok {
+		return 1
+	}
+	return math.Max(math.Min(provider.GetHeadroomConfidence(), 1), 0)
+}
+
+func (ra *resourceAdvisorWrapper) GetHeadroomTrend(resourceName v1.ResourceName, window time.Duration) (float64, error) {
+	var qosResourceName types.QoSResourceName
+	switch resourceName {
+	case v1.ResourceCPU:
+		qosResourceName = types.QoSResourceCPU
+	case v1.ResourceMemory:
+		qosResourceName = types.QoSResourceMemory
+	default:
+		return 0, fmt.Errorf("illegal resource %v", resourceName)
+	}
+
+	ra.mutex.RLock()
+	defer ra.mutex.RUnlock()
+
+	if ra.reclaimCordoned {
+		return 0, nil
+	}
+
+	subAdvisor, ok := ra.subAdvisorsToRun[qosResourceName]
+	if !ok {
+		return 0, fmt.Errorf("no sub resource advisor for %v", qosResourceName)
+	}
+	provider, ok := subAdvisor.(HeadroomTrendProvider)
+	if !ok {
+		return 0, fmt.Errorf("sub resource advisor for %v does not support headroom trend", qosResourceName)
+	}
+	return provider.GetHeadroomTrend(window)
+}
+
+// getResourceCapacity returns the qos resource name and node capacity of the given resource name
+func (ra *resourceAdvisorWrapper) getResourceCapacity(resourceName v1.ResourceName) (types.QoSResourceName, float64, error) {
+	switch resourceName {
+	case v1.ResourceCPU:
+		return types.QoSResourceCPU, float64(ra.metaServer.NumCPUs), nil
+	case v1.ResourceMemory:
+		return types.QoSResourceMemory, float64(ra.metaServer.MemoryCapacity), nil
+	default:
+		return "", 0, fmt.Errorf("illegal resource %v", resourceName)
+	}
+}
+
+func (ra *resourceAdvisorWrapper) GetCompositeHeadroom(weights map[v1.ResourceName]float64) (float64, error) {
+	composite := 0.0
+	for resourceName, weight := range weights {
+		if weight < 0 {
+			return 0, fmt.Errorf("weight of resource %v must not be negative", resourceName)
+		}
+
+		qosResourceName, capacity, err := ra.getResourceCapacity(resourceName)
+		if err != nil {
+			return 0, err
+		}
+		if capacity <= 0 {
+			return 0, fmt.Errorf("illegal capacity %v of resource %v", capacity, resourceName)
+		}
+
+		// absent advisors are never regarded as zero for composite headroom
+		if _, err := ra.GetSubAdvisor(qosResourceName); err != nil {
+			return 0, err
+		}
+		headroom, err := ra.getSubAdvisorHeadroom(qosResourceName)
+		if err != nil {
+			return 0, fmt.Errorf("get headroom of resource %v failed: %v", resourceName, err)
+		}
+
+		composite += weight * headroom.AsApproximateFloat64() / capacity
+	}
+
+	return composite, nil
 }
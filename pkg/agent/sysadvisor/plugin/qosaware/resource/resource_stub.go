@@ -20,11 +20,13 @@ import (
 	"context"
 	"fmt"
 	"sync"
+	"time"
 
 	v1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config"
 )
 
 type ResourceAdvisorStub struct {
@@ -57,6 +59,41 @@ func (r *ResourceAdvisorStub) GetHeadroom(resourceName v1.ResourceName) (resourc
 	return resource.Quantity{}, fmt.Errorf("not exist")
 }
 
+func (r *ResourceAdvisorStub) GetHeadroomFraction(_ v1.ResourceName) (float64, error) {
+	return 0, nil
+}
+
+func (r *ResourceAdvisorStub) GetHeadroomWithConfidence(resourceName v1.ResourceName) (resource.Quantity, float64, error) {
+	quantity, err := r.GetHeadroom(resourceName)
+	if err != nil {
+		return resource.Quantity{}, 0, err
+	}
+	return quantity, 1, nil
+}
+
+func (r *ResourceAdvisorStub) GetHeadroomTrend(_ v1.ResourceName, _ time.Duration) (float64, error) {
+	return 0, nil
+}
+
+func (r *ResourceAdvisorStub) GetCompositeHeadroom(_ map[v1.ResourceName]float64) (float64, error) {
+	return 0, nil
+}
+
+func (r *ResourceAdvisorStub) SetReclaimCordoned(_ bool) {
+}
+
+func (r *ResourceAdvisorStub) Reconfigure(_ *config.Configuration) error {
+	return nil
+}
+
+func (r *ResourceAdvisorStub) Pause(_ types.QoSResourceName) error {
+	return nil
+}
+
+func (r *ResourceAdvisorStub) Resume(_ types.QoSResourceName) error {
+	return nil
+}
+
 func (r *ResourceAdvisorStub) SetHeadroom(resourceName v1.ResourceName, quantity resource.Quantity) {
 	r.Lock()
 	defer r.Unlock()
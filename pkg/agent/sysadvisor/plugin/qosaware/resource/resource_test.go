@@ -0,0 +1,613 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resource
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	info "github.com/google/cadvisor/info/v1"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/atomic"
+	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+
+	"github.com/kubewharf/katalyst-core/cmd/katalyst-agent/app/options"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
+	"github.com/kubewharf/katalyst-core/pkg/config"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver"
+	"github.com/kubewharf/katalyst-core/pkg/metaserver/agent"
+	"github.com/kubewharf/katalyst-core/pkg/metrics"
+	"github.com/kubewharf/katalyst-core/pkg/util/machine"
+)
+
+func TestGetHeadroomWithAbsentSubAdvisor(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name                        string
+		absentAdvisorAsZeroHeadroom bool
+		wantMemoryHeadroom          resource.Quantity
+		wantErr                     bool
+	}{
+		{
+			name:                        "return error for absent advisor",
+			absentAdvisorAsZeroHeadroom: false,
+			wantErr:                     true,
+		},
+		{
+			name:                        "return zero for absent advisor",
+			absentAdvisorAsZeroHeadroom: true,
+			wantMemoryHeadroom:          *resource.NewQuantity(0, resource.DecimalSI),
+			wantErr:                     false,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cpuAdvisor := NewSubResourceAdvisorStub()
+			cpuAdvisor.SetHeadroom(resource.MustParse("10"))
+
+			ra := &resourceAdvisorWrapper{
+				subAdvisorsToRun: map[types.QoSResourceName]SubResourceAdvisor{
+					types.QoSResourceCPU: cpuAdvisor,
+				},
+				absentAdvisorAsZeroHeadroom: tt.absentAdvisorAsZeroHeadroom,
+			}
+
+			cpuHeadroom, err := ra.GetHeadroom(v1.ResourceCPU)
+			assert.NoError(t, err)
+			assert.Equal(t, int64(10), cpuHeadroom.Value())
+
+			memoryHeadroom, err := ra.GetHeadroom(v1.ResourceMemory)
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, tt.wantMemoryHeadroom.Value(), memoryHeadroom.Value())
+			}
+
+			// illegal resource always returns error
+			_, err = ra.GetHeadroom(v1.ResourceStorage)
+			assert.Error(t, err)
+		})
+	}
+}
+
+// selfDrivenSubResourceAdvisor keeps updating once kicked off, until its context is done
+type selfDrivenSubResourceAdvisor struct {
+	*SubResourceAdvisorStub
+	triggerCh chan struct{}
+	updates   atomic.Int64
+	exited    chan struct{}
+}
+
+func newSelfDrivenSubResourceAdvisor() *selfDrivenSubResourceAdvisor {
+	return &selfDrivenSubResourceAdvisor{
+		SubResourceAdvisorStub: NewSubResourceAdvisorStub(),
+		triggerCh:              make(chan struct{}, 1),
+		exited:                 make(chan struct{}),
+	}
+}
+
+func (s *selfDrivenSubResourceAdvisor) Run(ctx context.Context) {
+	defer close(s.exited)
+
+	select {
+	case <-s.triggerCh:
+	case <-ctx.Done():
+		return
+	}
+	for {
+		select {
+		case <-time.After(time.Millisecond):
+			s.updates.Add(1)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (s *selfDrivenSubResourceAdvisor) KickOff() {
+	select {
+	case s.triggerCh <- struct{}{}:
+	default:
+	}
+}
+
+func TestReconfigure(t *testing.T) {
+	t.Parallel()
+
+	conf, err := options.NewOptions().Config()
+	require.NoError(t, err)
+
+	cpuAdvisor := newSelfDrivenSubResourceAdvisor()
+	cpuAdvisor.SetHeadroom(resource.MustParse("10"))
+	cpuAdvisor.KickOff()
+	memoryAdvisor := newSelfDrivenSubResourceAdvisor()
+
+	ra := &resourceAdvisorWrapper{
+		subAdvisorsToRun: map[types.QoSResourceName]SubResourceAdvisor{
+			types.QoSResourceCPU: cpuAdvisor,
+		},
+		subAdvisorCancels: make(map[types.QoSResourceName]context.CancelFunc),
+		newSubAdvisor: func(resourceName types.QoSResourceName, _ *config.Configuration, _ interface{},
+			_ metacache.MetaCache, _ *metaserver.MetaServer, _ metrics.MetricEmitter) (SubResourceAdvisor, error) {
+			switch resourceName {
+			case types.QoSResourceMemory:
+				return memoryAdvisor, nil
+			case types.QoSResourceCPU:
+				// advisors triggered by qrm servers can't be enabled at runtime
+				return NewSubResourceAdvisorStub(), nil
+			default:
+				return nil, fmt.Errorf("unsupported resource %v", resourceName)
+			}
+		},
+		emitter: metrics.DummyMetrics{},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ra.Run(ctx)
+
+	_, err = ra.GetSubAdvisor(types.QoSResourceMemory)
+	assert.Error(t, err)
+
+	// construction failure keeps the previous set intact
+	conf.ResourceAdvisors = []string{string(types.QoSResourceCPU), string(types.QoSResourceMemory), "unknown"}
+	assert.Error(t, ra.Reconfigure(conf))
+	_, err = ra.GetSubAdvisor(types.QoSResourceMemory)
+	assert.Error(t, err)
+
+	// add memory advisor at runtime, which is kicked off to update, and cpu advisor keeps its state
+	conf.ResourceAdvisors = []string{string(types.QoSResourceCPU), string(types.QoSResourceMemory)}
+	require.NoError(t, ra.Reconfigure(conf))
+
+	subAdvisor, err := ra.GetSubAdvisor(types.QoSResourceMemory)
+	assert.NoError(t, err)
+	assert.Same(t, memoryAdvisor, subAdvisor)
+	assert.Eventually(t, func() bool { return memoryAdvisor.updates.Load() > 0 }, 5*time.Second, time.Millisecond)
+
+	subAdvisor, err = ra.GetSubAdvisor(types.QoSResourceCPU)
+	assert.NoError(t, err)
+	assert.Same(t, cpuAdvisor, subAdvisor)
+	cpuHeadroom, err := ra.GetHeadroom(v1.ResourceCPU)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), cpuHeadroom.Value())
+
+	// remove cpu advisor at runtime, whose goroutine exits
+	conf.ResourceAdvisors = []string{string(types.QoSResourceMemory)}
+	require.NoError(t, ra.Reconfigure(conf))
+	_, err = ra.GetSubAdvisor(types.QoSResourceCPU)
+	assert.Error(t, err)
+	assert.NotContains(t, ra.subAdvisorCancels, types.QoSResourceCPU)
+	select {
+	case <-cpuAdvisor.exited:
+	case <-time.After(5 * time.Second):
+		t.Errorf("goroutine of removed cpu advisor doesn't exit")
+	}
+
+	// advisors not self-driven can't be enabled at runtime, and the previous set is kept intact
+	conf.ResourceAdvisors = []string{string(types.QoSResourceCPU), string(types.QoSResourceMemory)}
+	assert.Error(t, ra.Reconfigure(conf))
+	_, err = ra.GetSubAdvisor(types.QoSResourceCPU)
+	assert.Error(t, err)
+	_, err = ra.GetSubAdvisor(types.QoSResourceMemory)
+	assert.NoError(t, err)
+}
+
+func TestGetCompositeHeadroom(t *testing.T) {
+	t.Parallel()
+
+	cpuTopology, err := machine.GenerateDummyCPUTopology(16, 1, 2)
+	require.NoError(t, err)
+	metaServer := &metaserver.MetaServer{
+		MetaAgent: &agent.MetaAgent{
+			KatalystMachineInfo: &machine.KatalystMachineInfo{
+				MachineInfo: &info.MachineInfo{
+					NumCores:       16,
+					MemoryCapacity: 64 << 30,
+				},
+				CPUTopology: cpuTopology,
+			},
+		},
+	}
+
+	cpuAdvisor := NewSubResourceAdvisorStub()
+	cpuAdvisor.SetHeadroom(resource.MustParse("8"))
+	memoryAdvisor := NewSubResourceAdvisorStub()
+	memoryAdvisor.SetHeadroom(resource.MustParse("16Gi"))
+
+	tests := []struct {
+		name          string
+		subAdvisors   map[types.QoSResourceName]SubResourceAdvisor
+		weights       map[v1.ResourceName]float64
+		wantComposite float64
+		wantErr       bool
+	}{
+		{
+			name: "cpu only",
+			subAdvisors: map[types.QoSResourceName]SubResourceAdvisor{
+				types.QoSResourceCPU: cpuAdvisor,
+			},
+			weights:       map[v1.ResourceName]float64{v1.ResourceCPU: 1},
+			wantComposite: 0.5,
+		},
+		{
+			name: "blend cpu and memory by weights",
+			subAdvisors: map[types.QoSResourceName]SubResourceAdvisor{
+				types.QoSResourceCPU:    cpuAdvisor,
+				types.QoSResourceMemory: memoryAdvisor,
+			},
+			weights:       map[v1.ResourceName]float64{v1.ResourceCPU: 0.2, v1.ResourceMemory: 0.8},
+			wantComposite: 0.2*0.5 + 0.8*0.25,
+		},
+		{
+			name: "requested resource without sub advisor",
+			subAdvisors: map[types.QoSResourceName]SubResourceAdvisor{
+				types.QoSResourceCPU: cpuAdvisor,
+			},
+			weights: map[v1.ResourceName]float64{v1.ResourceCPU: 0.5, v1.ResourceMemory: 0.5},
+			wantErr: true,
+		},
+		{
+			name: "negative weight",
+			subAdvisors: map[types.QoSResourceName]SubResourceAdvisor{
+				types.QoSResourceCPU: cpuAdvisor,
+			},
+			weights: map[v1.ResourceName]float64{v1.ResourceCPU: -1},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ra := &resourceAdvisorWrapper{
+				subAdvisorsToRun: tt.subAdvisors,
+				// absent advisor is not regarded as zero for composite headroom
+				absentAdvisorAsZeroHeadroom: true,
+				metaServer:                  metaServer,
+			}
+
+			composite, err := ra.GetCompositeHeadroom(tt.weights)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.InDelta(t, tt.wantComposite, composite, 1e-9)
+		})
+	}
+}
+
+func TestGetHeadroomFraction(t *testing.T) {
+	t.Parallel()
+
+	cpuTopology, err := machine.GenerateDummyCPUTopology(16, 1, 2)
+	require.NoError(t, err)
+	metaServer := &metaserver.MetaServer{
+		MetaAgent: &agent.MetaAgent{
+			KatalystMachineInfo: &machine.KatalystMachineInfo{
+				MachineInfo: &info.MachineInfo{
+					NumCores:       16,
+					MemoryCapacity: 64 << 30,
+				},
+				CPUTopology: cpuTopology,
+			},
+		},
+	}
+	zeroCapacityMetaServer := &metaserver.MetaServer{
+		MetaAgent: &agent.MetaAgent{
+			KatalystMachineInfo: &machine.KatalystMachineInfo{
+				MachineInfo: &info.MachineInfo{},
+				CPUTopology: &machine.CPUTopology{},
+			},
+		},
+	}
+
+	cpuAdvisor := NewSubResourceAdvisorStub()
+	cpuAdvisor.SetHeadroom(resource.MustParse("6"))
+	memoryAdvisor := NewSubResourceAdvisorStub()
+	memoryAdvisor.SetHeadroom(resource.MustParse("16Gi"))
+	subAdvisors := map[types.QoSResourceName]SubResourceAdvisor{
+		types.QoSResourceCPU:    cpuAdvisor,
+		types.QoSResourceMemory: memoryAdvisor,
+	}
+
+	tests := []struct {
+		name         string
+		metaServer   *metaserver.MetaServer
+		resourceName v1.ResourceName
+		wantFraction float64
+		wantErr      bool
+	}{
+		{
+			name:         "cpu",
+			metaServer:   metaServer,
+			resourceName: v1.ResourceCPU,
+			wantFraction: 6. / 16,
+		},
+		{
+			name:         "memory",
+			metaServer:   metaServer,
+			resourceName: v1.ResourceMemory,
+			wantFraction: 0.25,
+		},
+		{
+			name:         "zero capacity",
+			metaServer:   zeroCapacityMetaServer,
+			resourceName: v1.ResourceCPU,
+			wantFraction: 0,
+		},
+		{
+			name:         "illegal resource",
+			metaServer:   metaServer,
+			resourceName: v1.ResourceStorage,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ra := &resourceAdvisorWrapper{
+				subAdvisorsToRun: subAdvisors,
+				metaServer:       tt.metaServer,
+			}
+
+			fraction, err := ra.GetHeadroomFraction(tt.resourceName)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.wantFraction, fraction, 1e-9)
+
+			// fraction is consistent with headroom quantity
+			_, capacity, err := ra.getResourceCapacity(tt.resourceName)
+			require.NoError(t, err)
+			if capacity > 0 {
+				headroom, err := ra.GetHeadroom(tt.resourceName)
+				require.NoError(t, err)
+				assert.InDelta(t, headroom.AsApproximateFloat64(), fraction*capacity, 1)
+			}
+		})
+	}
+}
+
+type countingSubResourceAdvisor struct {
+	*SubResourceAdvisorStub
+	calls int
+}
+
+func (c *countingSubResourceAdvisor) GetHeadroom() (resource.Quantity, error) {
+	c.calls++
+	return c.SubResourceAdvisorStub.GetHeadroom()
+}
+
+func TestSetReclaimCordoned(t *testing.T) {
+	t.Parallel()
+
+	cpuAdvisor := &countingSubResourceAdvisor{SubResourceAdvisorStub: NewSubResourceAdvisorStub()}
+	cpuAdvisor.SetHeadroom(resource.MustParse("10"))
+
+	ra := &resourceAdvisorWrapper{
+		subAdvisorsToRun: map[types.QoSResourceName]SubResourceAdvisor{
+			types.QoSResourceCPU: cpuAdvisor,
+		},
+	}
+
+	headroom, err := ra.GetHeadroom(v1.ResourceCPU)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), headroom.Value())
+
+	// headroom is zero while cordoned, but sub advisor is still consulted
+	ra.SetReclaimCordoned(true)
+	headroom, err = ra.GetHeadroom(v1.ResourceCPU)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), headroom.Value())
+	assert.Equal(t, 2, cpuAdvisor.calls)
+
+	// absent advisors are regarded as zero too
+	headroom, err = ra.GetHeadroom(v1.ResourceMemory)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), headroom.Value())
+
+	// uncordoning restores normal headroom
+	ra.SetReclaimCordoned(false)
+	headroom, err = ra.GetHeadroom(v1.ResourceCPU)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), headroom.Value())
+	_, err = ra.GetHeadroom(v1.ResourceMemory)
+	assert.Error(t, err)
+}
+
+type confidentSubResourceAdvisor struct {
+	*SubResourceAdvisorStub
+	confidence float64
+}
+
+func (c *confidentSubResourceAdvisor) GetHeadroomConfidence() float64 {
+	return c.confidence
+}
+
+func TestGetHeadroomWithConfidence(t *testing.T) {
+	t.Parallel()
+
+	cpuAdvisor := &confidentSubResourceAdvisor{SubResourceAdvisorStub: NewSubResourceAdvisorStub(), confidence: 0.6}
+	cpuAdvisor.SetHeadroom(resource.MustParse("10"))
+	memoryAdvisor := NewSubResourceAdvisorStub()
+	memoryAdvisor.SetHeadroom(resource.MustParse("10Gi"))
+
+	ra := &resourceAdvisorWrapper{
+		subAdvisorsToRun: map[types.QoSResourceName]SubResourceAdvisor{
+			types.QoSResourceCPU:    cpuAdvisor,
+			types.QoSResourceMemory: memoryAdvisor,
+		},
+	}
+
+	headroom, confidence, err := ra.GetHeadroomWithConfidence(v1.ResourceCPU)
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), headroom.Value())
+	assert.Equal(t, 0.6, confidence)
+
+	// advisors not estimating confidence are fully confident
+	headroom, confidence, err = ra.GetHeadroomWithConfidence(v1.ResourceMemory)
+	require.NoError(t, err)
+	assert.Equal(t, resource.MustParse("10Gi"), headroom)
+	assert.Equal(t, 1.0, confidence)
+
+	// confidence is capped into [0, 1]
+	cpuAdvisor.confidence = 1.5
+	_, confidence, err = ra.GetHeadroomWithConfidence(v1.ResourceCPU)
+	require.NoError(t, err)
+	assert.Equal(t, 1.0, confidence)
+
+	// zero headroom of cordoned reclaim is fully confident
+	ra.SetReclaimCordoned(true)
+	headroom, confidence, err = ra.GetHeadroomWithConfidence(v1.ResourceCPU)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), headroom.Value())
+	assert.Equal(t, 1.0, confidence)
+	ra.SetReclaimCordoned(false)
+
+	// zero headroom of absent advisors is of no confidence
+	delete(ra.subAdvisorsToRun, types.QoSResourceMemory)
+	ra.absentAdvisorAsZeroHeadroom = true
+	headroom, confidence, err = ra.GetHeadroomWithConfidence(v1.ResourceMemory)
+	require.NoError(t, err)
+	assert.Equal(t, int64(0), headroom.Value())
+	assert.Equal(t, 0.0, confidence)
+
+	_, _, err = ra.GetHeadroomWithConfidence(v1.ResourceStorage)
+	assert.Error(t, err)
+}
+
+type trendingSubResourceAdvisor struct {
+	*SubResourceAdvisorStub
+	trend float64
+}
+
+func (c *trendingSubResourceAdvisor) GetHeadroomTrend(_ time.Duration) (float64, error) {
+	return c.trend, nil
+}
+
+func TestGetHeadroomTrend(t *testing.T) {
+	t.Parallel()
+
+	ra := &resourceAdvisorWrapper{
+		subAdvisorsToRun: map[types.QoSResourceName]SubResourceAdvisor{
+			types.QoSResourceCPU:    &trendingSubResourceAdvisor{SubResourceAdvisorStub: NewSubResourceAdvisorStub(), trend: -0.5},
+			types.QoSResourceMemory: NewSubResourceAdvisorStub(),
+		},
+	}
+
+	trend, err := ra.GetHeadroomTrend(v1.ResourceCPU, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, -0.5, trend)
+
+	// advisors not retaining headroom history have no trend
+	_, err = ra.GetHeadroomTrend(v1.ResourceMemory, time.Minute)
+	assert.Error(t, err)
+
+	// zero headroom of cordoned reclaim is flat
+	ra.SetReclaimCordoned(true)
+	trend, err = ra.GetHeadroomTrend(v1.ResourceCPU, time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 0.0, trend)
+
+	_, err = ra.GetHeadroomTrend(v1.ResourceStorage, time.Minute)
+	assert.Error(t, err)
+}
+
+// pausableSubResourceAdvisor updates headroom to the next value on each update unless paused
+type pausableSubResourceAdvisor struct {
+	*SubResourceAdvisorStub
+	paused  bool
+	updates int
+}
+
+func (p *pausableSubResourceAdvisor) SetPaused(paused bool) {
+	p.paused = paused
+}
+
+func (p *pausableSubResourceAdvisor) update() {
+	if p.paused {
+		return
+	}
+	p.updates++
+	p.SetHeadroom(*resource.NewQuantity(int64(p.updates), resource.DecimalSI))
+}
+
+func TestPauseAndResume(t *testing.T) {
+	t.Parallel()
+
+	cpuAdvisor := &pausableSubResourceAdvisor{SubResourceAdvisorStub: NewSubResourceAdvisorStub()}
+	memoryAdvisor := &pausableSubResourceAdvisor{SubResourceAdvisorStub: NewSubResourceAdvisorStub()}
+	ra := &resourceAdvisorWrapper{
+		subAdvisorsToRun: map[types.QoSResourceName]SubResourceAdvisor{
+			types.QoSResourceCPU:    cpuAdvisor,
+			types.QoSResourceMemory: memoryAdvisor,
+		},
+	}
+
+	assertHeadroom := func(resourceName v1.ResourceName, want int64) {
+		headroom, err := ra.GetHeadroom(resourceName)
+		require.NoError(t, err)
+		assert.Equal(t, want, headroom.Value(), "resource %v", resourceName)
+	}
+
+	cpuAdvisor.update()
+	memoryAdvisor.update()
+
+	// paused memory advisor skips updates and keeps serving its last headroom, while cpu continues
+	require.NoError(t, ra.Pause(types.QoSResourceMemory))
+	for i := 0; i < 3; i++ {
+		cpuAdvisor.update()
+		memoryAdvisor.update()
+	}
+	assert.Equal(t, 4, cpuAdvisor.updates)
+	assert.Equal(t, 1, memoryAdvisor.updates)
+	assertHeadroom(v1.ResourceCPU, 4)
+	assertHeadroom(v1.ResourceMemory, 1)
+
+	// resumed memory advisor updates again
+	require.NoError(t, ra.Resume(types.QoSResourceMemory))
+	cpuAdvisor.update()
+	memoryAdvisor.update()
+	assert.Equal(t, 5, cpuAdvisor.updates)
+	assert.Equal(t, 2, memoryAdvisor.updates)
+	assertHeadroom(v1.ResourceMemory, 2)
+
+	// absent advisors and advisors not supporting pausing can't be paused
+	ra.subAdvisorsToRun = map[types.QoSResourceName]SubResourceAdvisor{types.QoSResourceCPU: NewSubResourceAdvisorStub()}
+	assert.Error(t, ra.Pause(types.QoSResourceMemory))
+	assert.Error(t, ra.Pause(types.QoSResourceCPU))
+	assert.Error(t, ra.Resume(types.QoSResourceCPU))
+}
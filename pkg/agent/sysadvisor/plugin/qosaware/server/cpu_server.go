@@ -32,6 +32,7 @@ import (
 	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/cpuadvisor"
 	qrmstate "github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/metacache"
+	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/plugin/qosaware/resource"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
 	"github.com/kubewharf/katalyst-core/pkg/config"
 	"github.com/kubewharf/katalyst-core/pkg/metaserver"
@@ -48,6 +49,13 @@ type cpuServer struct {
 	*baseServer
 	getCheckpointCalled bool
 	cpuPluginClient     cpuadvisor.CPUPluginClient
+	// reclaimPoolName is the name of reclaim pool in advisor results, whose blocks are joined
+	// by numa binding containers
+	reclaimPoolName string
+
+	// acker is acknowledged with the generation of each advisor result consumed, and nil means
+	// the advisor doesn't track how far cpu server lags behind
+	acker resource.CalculationResultAcker
 }
 
 func NewCPUServer(recvCh chan types.InternalCPUCalculationResult, sendCh chan types.TriggerInfo, conf *config.Configuration,
@@ -57,6 +65,10 @@ func NewCPUServer(recvCh chan types.InternalCPUCalculationResult, sendCh chan ty
 	cs.advisorSocketPath = conf.CPUAdvisorSocketAbsPath
 	cs.pluginSocketPath = conf.CPUPluginSocketAbsPath
 	cs.resourceRequestName = "CPURequest"
+	cs.reclaimPoolName = conf.ReclaimPoolName
+	if cs.reclaimPoolName == "" {
+		cs.reclaimPoolName = qrmstate.PoolNameReclaim
+	}
 	return cs, nil
 }
 
@@ -95,6 +107,7 @@ func (cs *cpuServer) ListAndWatch(_ *advisorsvc.Empty, server cpuadvisor.CPUAdvi
 			}
 			if advisorResp.TimeStamp.Add(cs.period * 2).Before(time.Now()) {
 				general.Warningf("advisorResp is expired")
+				cs.ack(advisorResp.Generation)
 				continue
 			}
 
@@ -122,10 +135,18 @@ func (cs *cpuServer) ListAndWatch(_ *advisorsvc.Empty, server cpuadvisor.CPUAdvi
 			}
 			klog.Infof("[qosaware-server-cpu] send calculation result: %v", general.ToString(calculationEntriesMap))
 			_ = cs.emitter.StoreInt64(cs.genMetricsName(metricServerLWSendResponseSucceeded), int64(cs.period.Seconds()), metrics.MetricTypeNameCount)
+			cs.ack(advisorResp.Generation)
 		}
 	}
 }
 
+// ack acknowledges the advisor result of the generation as consumed, whether it's sent or discarded
+func (cs *cpuServer) ack(generation uint64) {
+	if cs.acker != nil {
+		cs.acker.Ack(generation)
+	}
+}
+
 func (cs *cpuServer) getCheckpoint() {
 	ctx := context.Background()
 	// get checkpoint
@@ -344,7 +365,7 @@ func (cs *cpuServer) assemblePodEntries(calculationEntriesMap map[string]*cpuadv
 			} else {
 				// if this podUID appears firstly, we should generate a new Block
 
-				reclaimPoolCalculationResults, ok := getNumaCalculationResult(calculationEntriesMap, qrmstate.PoolNameReclaim,
+				reclaimPoolCalculationResults, ok := getNumaCalculationResult(calculationEntriesMap, cs.reclaimPoolName,
 					cpuadvisor.FakedContainerName, int64(numaID))
 				if !ok {
 					// if no reclaimed pool exists, return the generated Block
@@ -68,9 +68,12 @@ func generateTestConfiguration(t *testing.T) *config.Configuration {
 }
 
 func newTestCPUServer(t *testing.T, podList []*v1.Pod) *cpuServer {
+	return newTestCPUServerWithConf(t, generateTestConfiguration(t), podList)
+}
+
+func newTestCPUServerWithConf(t *testing.T, conf *config.Configuration, podList []*v1.Pod) *cpuServer {
 	recvCh := make(chan types.InternalCPUCalculationResult)
 	sendCh := make(chan types.TriggerInfo)
-	conf := generateTestConfiguration(t)
 
 	metricsFetcher := metric.NewFakeMetricsFetcher(metrics.DummyMetrics{})
 	metaCache, err := metacache.NewMetaCacheImp(conf, metricspool.DummyMetricsEmitterPool{}, metricsFetcher)
@@ -252,12 +255,13 @@ func TestCPUServerListAndWatch(t *testing.T) {
 	}
 
 	tests := []struct {
-		name      string
-		empty     *advisorsvc.Empty
-		provision types.InternalCPUCalculationResult
-		infos     []*ContainerInfo
-		wantErr   bool
-		wantRes   *cpuadvisor.ListAndWatchResponse
+		name            string
+		reclaimPoolName string
+		empty           *advisorsvc.Empty
+		provision       types.InternalCPUCalculationResult
+		infos           []*ContainerInfo
+		wantErr         bool
+		wantRes         *cpuadvisor.ListAndWatchResponse
 	}{
 		{
 			name:  "reclaim pool with shared pool",
@@ -492,6 +496,137 @@ func TestCPUServerListAndWatch(t *testing.T) {
 				},
 			},
 		},
+		{
+			name:            "custom reclaim pool with dedicated pod",
+			reclaimPoolName: "reclaim-custom",
+			empty:           &advisorsvc.Empty{},
+			provision: types.InternalCPUCalculationResult{
+				TimeStamp: time.Now(),
+				PoolEntries: map[string]map[int]int{
+					"reclaim-custom": {
+						0: 4,
+						1: 8,
+					},
+				}},
+			infos: []*ContainerInfo{
+				{
+					request: &advisorsvc.ContainerMetadata{
+						PodUid:        "pod1",
+						ContainerName: "c1",
+						Annotations: map[string]string{
+							consts.PodAnnotationMemoryEnhancementNumaBinding: consts.PodAnnotationMemoryEnhancementNumaBindingEnable,
+						},
+						QosLevel: consts.PodAnnotationQoSLevelDedicatedCores,
+					},
+					podInfo: &v1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Namespace: "default",
+							Name:      "pod1",
+							UID:       "pod1",
+							Annotations: map[string]string{
+								consts.PodAnnotationQoSLevelKey:          consts.PodAnnotationQoSLevelDedicatedCores,
+								consts.PodAnnotationMemoryEnhancementKey: "{\"numa_exclusive\":true}",
+							},
+						},
+						Spec: v1.PodSpec{
+							Containers: []v1.Container{
+								{
+									Name: "c1",
+								},
+							},
+						},
+					},
+					allocationInfo: &cpuadvisor.AllocationInfo{
+						OwnerPoolName: state.PoolNameDedicated,
+						TopologyAwareAssignments: map[uint64]string{
+							0: "0-3",
+							1: "24-47",
+						},
+					},
+				},
+			},
+			wantErr: false,
+			wantRes: &cpuadvisor.ListAndWatchResponse{
+				Entries: map[string]*cpuadvisor.CalculationEntries{
+					"reclaim-custom": {
+						Entries: map[string]*cpuadvisor.CalculationInfo{
+							"": {
+								OwnerPoolName: "reclaim-custom",
+								CalculationResultsByNumas: map[int64]*cpuadvisor.NumaCalculationResult{
+									0: {
+										Blocks: []*cpuadvisor.Block{
+											{
+												Result: 4,
+												OverlapTargets: []*cpuadvisor.OverlapTarget{
+													{
+														OverlapTargetPodUid:        "pod1",
+														OverlapTargetContainerName: "c1",
+														OverlapType:                cpuadvisor.OverlapType_OverlapWithPod,
+													},
+												},
+											},
+										},
+									},
+									1: {
+										Blocks: []*cpuadvisor.Block{
+											{
+												Result: 8,
+												OverlapTargets: []*cpuadvisor.OverlapTarget{
+													{
+														OverlapTargetPodUid:        "pod1",
+														OverlapTargetContainerName: "c1",
+														OverlapType:                cpuadvisor.OverlapType_OverlapWithPod,
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+					"pod1": {
+						Entries: map[string]*cpuadvisor.CalculationInfo{
+							"c1": {
+								OwnerPoolName: state.PoolNameDedicated,
+								CalculationResultsByNumas: map[int64]*cpuadvisor.NumaCalculationResult{
+									0: {
+										Blocks: []*cpuadvisor.Block{
+											{
+												Result: 4,
+												OverlapTargets: []*cpuadvisor.OverlapTarget{
+													{
+														OverlapTargetPoolName: "reclaim-custom",
+														OverlapType:           cpuadvisor.OverlapType_OverlapWithPool,
+													},
+												},
+											},
+										},
+									},
+									1: {
+										Blocks: []*cpuadvisor.Block{
+											{
+												Result:         16,
+												OverlapTargets: nil,
+											},
+											{
+												Result: 8,
+												OverlapTargets: []*cpuadvisor.OverlapTarget{
+													{
+														OverlapTargetPoolName: "reclaim-custom",
+														OverlapType:           cpuadvisor.OverlapType_OverlapWithPool,
+													},
+												},
+											},
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 		{
 			name:  "reclaim pool colocated with dedicated pod(2 containers)",
 			empty: &advisorsvc.Empty{},
@@ -1120,7 +1255,11 @@ func TestCPUServerListAndWatch(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			cs := newTestCPUServer(t, []*v1.Pod{})
+			conf := generateTestConfiguration(t)
+			if tt.reclaimPoolName != "" {
+				conf.ReclaimPoolName = tt.reclaimPoolName
+			}
+			cs := newTestCPUServerWithConf(t, conf, []*v1.Pod{})
 			s := &mockCPUServerService_ListAndWatchServer{ResultsChan: make(chan *cpuadvisor.ListAndWatchResponse)}
 			for _, info := range tt.infos {
 				assert.NoError(t, cs.addContainer(info.request))
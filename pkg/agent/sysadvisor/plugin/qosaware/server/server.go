@@ -99,7 +99,14 @@ func newSubQRMServer(resourceName v1.ResourceName, advisorWrapper resource.Resou
 		advisorRecvChInterface, advisorSendChInterface := subAdvisor.GetChannels()
 		advisorRecvCh := advisorRecvChInterface.(chan types.TriggerInfo)
 		advisorSendCh := advisorSendChInterface.(chan types.InternalCPUCalculationResult)
-		return NewCPUServer(advisorSendCh, advisorRecvCh, conf, metaCache, metaServer, emitter)
+		cs, err := NewCPUServer(advisorSendCh, advisorRecvCh, conf, metaCache, metaServer, emitter)
+		if err != nil {
+			return nil, err
+		}
+		if acker, ok := subAdvisor.(resource.CalculationResultAcker); ok {
+			cs.acker = acker
+		}
+		return cs, nil
 	case v1.ResourceMemory:
 		subAdvisor, err := advisorWrapper.GetSubAdvisor(types.QoSResourceMemory)
 		if err != nil {
@@ -195,6 +195,74 @@ type RegionInfo struct {
 type InternalCPUCalculationResult struct {
 	PoolEntries map[string]map[int]int // map[poolName][numaId]cpuSize
 	TimeStamp   time.Time
+
+	// PoolEntryTimeStamps records the assembly timestamp refreshing each pool entry,
+	// so that consumers can age out entries missing from newer assemblies
+	PoolEntryTimeStamps map[string]map[int]time.Time // map[poolName][numaId]timestamp
+
+	// RegionContributions records how each region's requirement is honored after regulation
+	RegionContributions map[string]RegionContribution // map[regionName]contribution
+
+	// RegionErrors records regions skipped in partial assembling along with their errors
+	RegionErrors map[string]string // map[regionName]errorMessage
+
+	// Overcommitted is true if share and isolation pools on non-binding numas require more than available,
+	// i.e. slack is negative and reclaim pool is left with reserved for reclaim only
+	Overcommitted bool
+
+	// TimedOut is true if assembling timed out, and the result is the last-known-good one served in place
+	TimedOut bool
+
+	// Generation increases monotonically with each assembled result, and consumers acknowledge the
+	// generation consumed so that the lag behind the advisor can be tracked
+	Generation uint64
+
+	// NumaClassifications records how each numa of the node is treated in assembling. pool entries of binding
+	// numas are keyed by their numa ids, while share, isolation and reclaim pool entries of non-binding numas
+	// are aggregated at FakedNUMAID (-1), i.e. spread over all non-binding numas; reclaim pool entries of
+	// non-binding numas with share pools pinned to are keyed by their numa ids as well
+	NumaClassifications map[int]NumaClassification // map[numaId]classification
+
+	// Reason tells what triggers the assembling of the result
+	Reason AssemblyReason
+}
+
+// AssemblyReason declares reasons triggering provision assembling
+type AssemblyReason string
+
+const (
+	// AssemblyReasonPeriodic is for assembling in periodic update without any change detected
+	AssemblyReasonPeriodic AssemblyReason = "periodic"
+
+	// AssemblyReasonDynamicConfigChange is for periodic update right after dynamic configuration changes
+	AssemblyReasonDynamicConfigChange AssemblyReason = "dynamic-config-change"
+
+	// AssemblyReasonTopologyChange is for periodic update in which non-binding numas change
+	AssemblyReasonTopologyChange AssemblyReason = "topology-change"
+
+	// AssemblyReasonRegionRefresh is for assembling triggered by refreshing a region on demand
+	AssemblyReasonRegionRefresh AssemblyReason = "region-refresh"
+)
+
+// NumaClassification declares how a numa is treated in provision assembling
+type NumaClassification string
+
+const (
+	// NumaClassificationBinding is for numas with numa binding (dedicated) pods
+	NumaClassificationBinding NumaClassification = "binding"
+
+	// NumaClassificationNonBinding is for numas shared by pools without numa binding
+	NumaClassificationNonBinding NumaClassification = "non-binding"
+)
+
+// RegionContribution conveys the requested and granted size of a region in provision assembling
+type RegionContribution struct {
+	PoolName      string
+	RequestedSize int
+	GrantedSize   int
+	// TrimmedSize equals requested size minus granted size, and it can be negative
+	// if the pool is raised above the requirement, e.g. by min pool size
+	TrimmedSize int
 }
 
 // ControlEssentials defines essential metrics for cpu advisor feedback control
@@ -18,6 +18,7 @@ package types
 
 import (
 	"reflect"
+	"time"
 
 	"k8s.io/apimachinery/pkg/util/sets"
 
@@ -263,6 +264,81 @@ func (r *InternalCPUCalculationResult) SetPoolEntry(poolName string, numaID int,
 		r.PoolEntries[poolName] = make(map[int]int)
 	}
 	r.PoolEntries[poolName][numaID] = poolSize
+	r.stampPoolEntry(poolName, numaID, r.TimeStamp)
+}
+
+// SetPoolEntryExplicitly sets pool entry even if pool size is zero (negative size is regarded as zero),
+// to distinguish an empty pool entry from a non-existing one
+func (r *InternalCPUCalculationResult) SetPoolEntryExplicitly(poolName string, numaID int, poolSize int) {
+	if poolSize < 0 {
+		poolSize = 0
+	}
+	if r.PoolEntries[poolName] == nil {
+		r.PoolEntries[poolName] = make(map[int]int)
+	}
+	r.PoolEntries[poolName][numaID] = poolSize
+	r.stampPoolEntry(poolName, numaID, r.TimeStamp)
+}
+
+// DeletePoolEntry deletes pool entry along with its timestamp
+func (r *InternalCPUCalculationResult) DeletePoolEntry(poolName string, numaID int) {
+	delete(r.PoolEntries[poolName], numaID)
+	delete(r.PoolEntryTimeStamps[poolName], numaID)
+}
+
+// GetPoolEntryTimeStamp returns the timestamp of the assembly refreshing the pool entry;
+// entries without timestamp are regarded as refreshed along with the result
+func (r *InternalCPUCalculationResult) GetPoolEntryTimeStamp(poolName string, numaID int) time.Time {
+	if ts, ok := r.PoolEntryTimeStamps[poolName][numaID]; ok {
+		return ts
+	}
+	return r.TimeStamp
+}
+
+// MergePoolEntries overwrites pool entries with those in the newer result, and keeps
+// entries missing from the newer result with their original timestamps
+func (r *InternalCPUCalculationResult) MergePoolEntries(newer InternalCPUCalculationResult) {
+	if r.PoolEntries == nil {
+		r.PoolEntries = make(map[string]map[int]int)
+	}
+	for poolName, entries := range newer.PoolEntries {
+		for numaID, poolSize := range entries {
+			if r.PoolEntries[poolName] == nil {
+				r.PoolEntries[poolName] = make(map[int]int)
+			}
+			r.PoolEntries[poolName][numaID] = poolSize
+			r.stampPoolEntry(poolName, numaID, newer.GetPoolEntryTimeStamp(poolName, numaID))
+		}
+	}
+}
+
+// PruneStalePoolEntries deletes pool entries not refreshed within ttl, and returns
+// the pruned entries as map[poolName][]numaID
+func (r *InternalCPUCalculationResult) PruneStalePoolEntries(now time.Time, ttl time.Duration) map[string][]int {
+	pruned := make(map[string][]int)
+	for poolName, entries := range r.PoolEntries {
+		for numaID := range entries {
+			if now.Sub(r.GetPoolEntryTimeStamp(poolName, numaID)) > ttl {
+				r.DeletePoolEntry(poolName, numaID)
+				pruned[poolName] = append(pruned[poolName], numaID)
+			}
+		}
+		if len(entries) == 0 {
+			delete(r.PoolEntries, poolName)
+			delete(r.PoolEntryTimeStamps, poolName)
+		}
+	}
+	return pruned
+}
+
+func (r *InternalCPUCalculationResult) stampPoolEntry(poolName string, numaID int, ts time.Time) {
+	if r.PoolEntryTimeStamps == nil {
+		r.PoolEntryTimeStamps = make(map[string]map[int]time.Time)
+	}
+	if r.PoolEntryTimeStamps[poolName] == nil {
+		r.PoolEntryTimeStamps[poolName] = make(map[int]time.Time)
+	}
+	r.PoolEntryTimeStamps[poolName][numaID] = ts
 }
 
 func (ck ControlKnob) Clone() ControlKnob {
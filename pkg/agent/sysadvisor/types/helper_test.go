@@ -19,11 +19,13 @@ package types
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"k8s.io/apimachinery/pkg/util/sets"
 
 	"github.com/kubewharf/katalyst-api/pkg/consts"
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
 	"github.com/kubewharf/katalyst-core/pkg/util/machine"
 )
 
@@ -63,3 +65,48 @@ func TestClonePodEntries(t *testing.T) {
 
 	assert.True(t, reflect.DeepEqual(copyPodEntries, podEntries))
 }
+
+func TestPruneStalePoolEntries(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	older := InternalCPUCalculationResult{
+		PoolEntries: make(map[string]map[int]int),
+		TimeStamp:   now.Add(-time.Minute),
+	}
+	older.SetPoolEntry(state.PoolNameShare, -1, 8)
+	older.SetPoolEntryExplicitly(state.PoolNameReclaim, 0, 4)
+	older.SetPoolEntryExplicitly(state.PoolNameReclaim, 1, 4)
+
+	// dedicated pod leaves numa 0, and its reclaim entry is absent from newer assembly
+	newer := InternalCPUCalculationResult{
+		PoolEntries: make(map[string]map[int]int),
+		TimeStamp:   now,
+	}
+	newer.SetPoolEntry(state.PoolNameShare, -1, 10)
+	newer.SetPoolEntryExplicitly(state.PoolNameReclaim, 1, 2)
+	assert.Equal(t, now, newer.GetPoolEntryTimeStamp(state.PoolNameReclaim, 1))
+
+	consumed := InternalCPUCalculationResult{}
+	consumed.MergePoolEntries(older)
+	consumed.MergePoolEntries(newer)
+	assert.Equal(t, map[string]map[int]int{
+		state.PoolNameShare:   {-1: 10},
+		state.PoolNameReclaim: {0: 4, 1: 2},
+	}, consumed.PoolEntries)
+	assert.Equal(t, now.Add(-time.Minute), consumed.GetPoolEntryTimeStamp(state.PoolNameReclaim, 0))
+
+	// nothing is pruned within ttl
+	assert.Empty(t, consumed.PruneStalePoolEntries(now, 2*time.Minute))
+	assert.Len(t, consumed.PoolEntries[state.PoolNameReclaim], 2)
+
+	// stale entry is pruned out of ttl
+	pruned := consumed.PruneStalePoolEntries(now, 30*time.Second)
+	assert.Equal(t, map[string][]int{state.PoolNameReclaim: {0}}, pruned)
+	assert.Equal(t, map[string]map[int]int{
+		state.PoolNameShare:   {-1: 10},
+		state.PoolNameReclaim: {1: 2},
+	}, consumed.PoolEntries)
+	_, ok := consumed.PoolEntryTimeStamps[state.PoolNameReclaim][0]
+	assert.False(t, ok)
+}
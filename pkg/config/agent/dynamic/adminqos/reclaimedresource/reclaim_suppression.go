@@ -0,0 +1,121 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package reclaimedresource
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ReclaimSuppressionWindow is a daily time window on some weekdays, during which reclaim is suppressed
+// even if it's enabled. Window with End before Start spans midnight, and weekdays refer to the day it starts.
+type ReclaimSuppressionWindow struct {
+	Weekdays map[time.Weekday]bool
+	// Start and End are offsets from midnight
+	Start time.Duration
+	End   time.Duration
+}
+
+var weekdayAbbreviations = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// ParseReclaimSuppressionWindow parses window formatted as '[<weekday>[-<weekday>]@]<HH:MM>-<HH:MM>',
+// e.g. 'mon-fri@09:00-18:00', 'sat@10:00-12:00' or '22:00-06:00' for every day
+func ParseReclaimSuppressionWindow(s string) (ReclaimSuppressionWindow, error) {
+	window := ReclaimSuppressionWindow{Weekdays: make(map[time.Weekday]bool)}
+
+	timeRange := s
+	if i := strings.Index(s, "@"); i >= 0 {
+		weekdays, err := parseWeekdayRange(strings.ToLower(s[:i]))
+		if err != nil {
+			return ReclaimSuppressionWindow{}, fmt.Errorf("illegal reclaim suppression window %q: %v", s, err)
+		}
+		window.Weekdays = weekdays
+		timeRange = s[i+1:]
+	} else {
+		for day := time.Sunday; day <= time.Saturday; day++ {
+			window.Weekdays[day] = true
+		}
+	}
+
+	bounds := strings.Split(timeRange, "-")
+	if len(bounds) != 2 {
+		return ReclaimSuppressionWindow{}, fmt.Errorf("illegal reclaim suppression window %q: time range must be <HH:MM>-<HH:MM>", s)
+	}
+	var err error
+	if window.Start, err = parseTimeOfDay(bounds[0]); err != nil {
+		return ReclaimSuppressionWindow{}, fmt.Errorf("illegal reclaim suppression window %q: %v", s, err)
+	}
+	if window.End, err = parseTimeOfDay(bounds[1]); err != nil {
+		return ReclaimSuppressionWindow{}, fmt.Errorf("illegal reclaim suppression window %q: %v", s, err)
+	}
+	if window.Start == window.End {
+		return ReclaimSuppressionWindow{}, fmt.Errorf("illegal reclaim suppression window %q: empty time range", s)
+	}
+	return window, nil
+}
+
+// Contains returns whether the given time falls into the window, in the location of the time
+func (w ReclaimSuppressionWindow) Contains(t time.Time) bool {
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if w.Start < w.End {
+		return w.Weekdays[t.Weekday()] && offset >= w.Start && offset < w.End
+	}
+
+	// window spanning midnight is split into the part on the starting day and that on the next day
+	if offset >= w.Start {
+		return w.Weekdays[t.Weekday()]
+	}
+	return offset < w.End && w.Weekdays[(t.Weekday()+6)%7]
+}
+
+func parseWeekdayRange(s string) (map[time.Weekday]bool, error) {
+	bounds := strings.Split(s, "-")
+	if len(bounds) > 2 {
+		return nil, fmt.Errorf("weekdays must be <weekday>[-<weekday>]")
+	}
+	first, ok := weekdayAbbreviations[bounds[0]]
+	if !ok {
+		return nil, fmt.Errorf("unknown weekday %q", bounds[0])
+	}
+	last := first
+	if len(bounds) == 2 {
+		if last, ok = weekdayAbbreviations[bounds[1]]; !ok {
+			return nil, fmt.Errorf("unknown weekday %q", bounds[1])
+		}
+	}
+
+	weekdays := make(map[time.Weekday]bool)
+	for day := first; ; day = (day + 1) % 7 {
+		weekdays[day] = true
+		if day == last {
+			break
+		}
+	}
+	return weekdays, nil
+}
+
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, fmt.Errorf("time of day must be HH:MM: %v", err)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package reclaimedresource
 
 import (
+	"time"
+
 	v1 "k8s.io/api/core/v1"
 
 	"github.com/kubewharf/katalyst-core/pkg/config/agent/dynamic/adminqos/reclaimedresource/cpuheadroom"
@@ -31,6 +33,9 @@ type ReclaimedResourceConfiguration struct {
 	ReservedResourceForAllocate     v1.ResourceList
 	MinReclaimedResourceForAllocate v1.ResourceList
 
+	// ReclaimSuppressionWindows are time windows during which reclaim is suppressed, layered over EnableReclaim
+	ReclaimSuppressionWindows []ReclaimSuppressionWindow
+
 	*cpuheadroom.CPUHeadroomConfiguration
 	*memoryheadroom.MemoryHeadroomConfiguration
 }
@@ -42,6 +47,16 @@ func NewReclaimedResourceConfiguration() *ReclaimedResourceConfiguration {
 	}
 }
 
+// ReclaimSuppressed returns whether the given time falls into any reclaim suppression window
+func (c *ReclaimedResourceConfiguration) ReclaimSuppressed(now time.Time) bool {
+	for _, window := range c.ReclaimSuppressionWindows {
+		if window.Contains(now) {
+			return true
+		}
+	}
+	return false
+}
+
 func (c *ReclaimedResourceConfiguration) ApplyConfiguration(conf *crd.DynamicConfigCRD) {
 	if aqc := conf.AdminQoSConfiguration; aqc != nil && aqc.Spec.Config.ReclaimedResourceConfig != nil {
 		config := aqc.Spec.Config.ReclaimedResourceConfig
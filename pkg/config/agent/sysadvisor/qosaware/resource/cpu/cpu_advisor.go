@@ -17,6 +17,9 @@ limitations under the License.
 package cpu
 
 import (
+	"time"
+
+	"github.com/kubewharf/katalyst-core/pkg/agent/qrm-plugins/cpu/dynamicpolicy/state"
 	"github.com/kubewharf/katalyst-core/pkg/agent/sysadvisor/types"
 	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu/headroom"
 	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu/provision"
@@ -30,22 +33,81 @@ type CPUAdvisorConfiguration struct {
 	ProvisionAssembler types.CPUProvisionAssemblerName
 	HeadroomAssembler  types.CPUHeadroomAssemblerName
 
+	// ProvisionCircuitBreakerThreshold is the number of consecutive assembling failures to freeze
+	// the last-known-good provision result, and zero disables the circuit breaker; the circuit is
+	// tested for recovery after ProvisionCircuitBreakerCooldown
+	ProvisionCircuitBreakerThreshold int
+	ProvisionCircuitBreakerCooldown  time.Duration
+
+	// HeadroomConfidenceWindow is the duration for headroom confidence to recover after regions are
+	// created or topology changes, and to decay after provision stops being assembled successfully
+	HeadroomConfidenceWindow time.Duration
+
+	// ReclaimPoolName is the name of reclaim pool in provision results and pool info from cpu plugin,
+	// so that node pools following different reclaim conventions downstream are supported
+	ReclaimPoolName string
+
+	// ProvisionForcePushInterval is the interval to push provision results to cpu server as heartbeats
+	// even if pool entries are unchanged since the last push, and zero means pushing every result
+	ProvisionForcePushInterval time.Duration
+
+	// ReclaimEventThreshold is the total reclaim pool size below which a warning event is emitted on the node,
+	// and zero disables it; events of the same reason are emitted at most once per ReclaimEventInterval
+	ReclaimEventThreshold int
+	ReclaimEventInterval  time.Duration
+
+	// ReclaimClassQuotas caps reclaimed cores allocated by each reclaim workload class, which is identified by
+	// the value of pod label ReclaimClassLabelKey; classes not specified are only capped by unallocated headroom
+	ReclaimClassLabelKey string
+	ReclaimClassQuotas   map[string]int
+
+	// EvictionRiskWindow is the number of recent samples of share and isolation usage on non-binding numas,
+	// whose volatility is taken into account in estimating eviction risk of reclaimed workloads
+	EvictionRiskWindow int
+
+	// HeadroomTrendRetention is the duration of headroom history retained to estimate headroom trend,
+	// which bounds the window of trend queries
+	HeadroomTrendRetention time.Duration
+
+	// ProvisionAssemblyTimeout bounds each provision assembling, and on timeout the in-flight one is cancelled
+	// while the last-known-good result is served in place; zero means no timeout
+	ProvisionAssemblyTimeout time.Duration
+
+	// ReclaimHeadroomChunkSize is the minimum viable cores of reclaimed workloads, and advertised reclaim headroom
+	// is rounded down to a multiple of it, so that sub-viable slices are never admitted; zero means no rounding
+	ReclaimHeadroomChunkSize int
+
+	// EnableThrottleAwareHeadroom scales headroom by the ratio of effective cpu capacity to the nominal one from
+	// metaserver, so that headroom reflects real compute available under cpufreq scaling or thermal throttling;
+	// headroom is kept as it is if the ratio is unavailable
+	EnableThrottleAwareHeadroom bool
+
+	// EnableResultDiffLogging logs pool entries added, removed and changed between consecutive provision results
+	// for audit trails, and results without any change are not logged
+	EnableResultDiffLogging bool
+
 	*headroom.CPUHeadroomPolicyConfiguration
 	*provision.CPUProvisionPolicyConfiguration
 	*region.CPURegionConfiguration
 	*CPUIsolationConfiguration
+	*CPUProvisionAssemblerConfiguration
+	*CPUHeadroomAssemblerConfiguration
 }
 
 // NewCPUAdvisorConfiguration creates new cpu advisor configurations
 func NewCPUAdvisorConfiguration() *CPUAdvisorConfiguration {
 	return &CPUAdvisorConfiguration{
-		ProvisionPolicies:               map[types.QoSRegionType][]types.CPUProvisionPolicyName{},
-		HeadroomPolicies:                map[types.QoSRegionType][]types.CPUHeadroomPolicyName{},
-		ProvisionAssembler:              types.CPUProvisionAssemblerCommon,
-		HeadroomAssembler:               types.CPUHeadroomAssemblerCommon,
-		CPUHeadroomPolicyConfiguration:  headroom.NewCPUHeadroomPolicyConfiguration(),
-		CPUProvisionPolicyConfiguration: provision.NewCPUProvisionPolicyConfiguration(),
-		CPURegionConfiguration:          region.NewCPURegionConfiguration(),
-		CPUIsolationConfiguration:       NewCPUIsolationConfiguration(),
+		ProvisionPolicies:                  map[types.QoSRegionType][]types.CPUProvisionPolicyName{},
+		HeadroomPolicies:                   map[types.QoSRegionType][]types.CPUHeadroomPolicyName{},
+		ProvisionAssembler:                 types.CPUProvisionAssemblerCommon,
+		HeadroomAssembler:                  types.CPUHeadroomAssemblerCommon,
+		ReclaimPoolName:                    state.PoolNameReclaim,
+		ReclaimClassQuotas:                 map[string]int{},
+		CPUHeadroomPolicyConfiguration:     headroom.NewCPUHeadroomPolicyConfiguration(),
+		CPUProvisionPolicyConfiguration:    provision.NewCPUProvisionPolicyConfiguration(),
+		CPURegionConfiguration:             region.NewCPURegionConfiguration(),
+		CPUIsolationConfiguration:          NewCPUIsolationConfiguration(),
+		CPUProvisionAssemblerConfiguration: NewCPUProvisionAssemblerConfiguration(),
+		CPUHeadroomAssemblerConfiguration:  NewCPUHeadroomAssemblerConfiguration(),
 	}
 }
@@ -0,0 +1,31 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+// CPUHeadroomAssemblerConfiguration stores configurations of cpu headroom assembler
+type CPUHeadroomAssemblerConfiguration struct {
+	// SpikeBufferWindowSize is the number of recent node cpu utilization samples kept to estimate
+	// its volatility, and a spike buffer scaled by SpikeBufferSensitivity times the standard deviation
+	// of the samples is subtracted from cpu headroom; zero window size disables the buffer
+	SpikeBufferWindowSize  int
+	SpikeBufferSensitivity float64
+}
+
+// NewCPUHeadroomAssemblerConfiguration creates new cpu headroom assembler configurations
+func NewCPUHeadroomAssemblerConfiguration() *CPUHeadroomAssemblerConfiguration {
+	return &CPUHeadroomAssemblerConfiguration{}
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cpu
+
+import (
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+)
+
+// CPUProvisionAssemblerConfiguration stores configurations of cpu provision assembler
+type CPUProvisionAssemblerConfiguration struct {
+	// SharePoolMinSizes defines the min size for each share pool,
+	// key indicates the owner pool name and val indicates the min size of it
+	SharePoolMinSizes map[string]int
+
+	// SharePoolPriorities defines the priority for each share pool, key indicates the owner pool name;
+	// under contention, pools with higher priority are satisfied first, and those with lower priority
+	// absorb the shortfall. pools not specified are of priority 0
+	SharePoolPriorities map[string]int
+
+	// ReclaimRampUpStep and ReclaimRampUpRatio limit the growth of each reclaim pool entry
+	// between consecutive updates, and the larger one takes effect if both are set, while growth of
+	// at least one core is always allowed; shrinking is not limited, and zero values mean no limitation
+	ReclaimRampUpStep  int
+	ReclaimRampUpRatio float64
+
+	// ErrorOnCapacityOvercommit returns error for assembling if the sum of all pool entries
+	// exceeds node capacity; otherwise, only metrics and logs are emitted
+	ErrorOnCapacityOvercommit bool
+
+	// RegionWarmUpWindow is the duration after a region is first seen, during which the
+	// provision of share and isolation regions is replaced by RegionWarmUpSize;
+	// zero value means regions' provision is trusted immediately
+	RegionWarmUpWindow time.Duration
+	RegionWarmUpSize   int
+
+	// EnablePoolSizeDriftCheck compares share and isolation pool sizes from assembling against
+	// those recorded in metacache, and emits metrics if they differ beyond PoolSizeDriftThreshold
+	EnablePoolSizeDriftCheck bool
+	PoolSizeDriftThreshold   int
+
+	// ReclaimCeiling is the max total cores of reclaim pool across all numas, and reclaim pool
+	// entries are scaled down proportionally if exceeding it; zero value means no ceiling
+	ReclaimCeiling int
+
+	// ExcludedReclaimNumas are numas never offered for reclaim, and their capacity is treated as
+	// fully reserved; they can still host share and isolation pools
+	ExcludedReclaimNumas []int
+
+	// SharePoolGrowthCooldownCycles is the number of consecutive cycles a share pool requirement
+	// must stay elevated before the pool grows; shrinking is immediate, and zero value means
+	// share pools grow immediately
+	SharePoolGrowthCooldownCycles int
+
+	// SharePoolGrowStep and SharePoolShrinkStep limit how many cores each share pool can grow or shrink between
+	// consecutive cycles, so that share pools may grow quickly to meet rising demand but shrink slowly to avoid
+	// donating cores to reclaim pool only to take them back moments later; zero values mean no limitation
+	SharePoolGrowStep   int
+	SharePoolShrinkStep int
+
+	// EnableReclaimPressureFeedback adjusts reclaim pool of non-binding numas by the pressure of reclaim
+	// pool, i.e. 1-min load of reclaim cgroup per reclaim core. If pressure stays above
+	// ReclaimPressureHighThreshold for ReclaimPressureSustainedCycles, growth of reclaim pool is held back
+	// and kept in share pool as buffer; if pressure stays below ReclaimPressureLowThreshold and share pool
+	// usage ratio is below SharePoolIdleRatio, at most ReclaimPressureAdjustStep idle cores of share pool
+	// are moved to reclaim pool in each cycle.
+	EnableReclaimPressureFeedback  bool
+	ReclaimPressureHighThreshold   float64
+	ReclaimPressureLowThreshold    float64
+	ReclaimPressureSustainedCycles int
+	ReclaimPressureAdjustStep      int
+	SharePoolIdleRatio             float64
+
+	// ReservePoolGrowthStep limits the growth of reserve pool on each numa between consecutive updates,
+	// so that reclaim pool shrinks gradually when reserve pool is enlarged; shrinking is not limited,
+	// and zero value means no limitation
+	ReservePoolGrowthStep int
+
+	// DisableNonBindingReclaim suppresses reclaim pool entry of non-binding numas, so that share and
+	// isolation pools on them keep all the slack as buffer; reclaim pool entries of binding numas
+	// are not affected
+	DisableNonBindingReclaim bool
+
+	// EnablePartialAssembly skips regions failing in assembling instead of failing the whole assembly;
+	// capacity of failed regions is treated as fully reserved, i.e. never donated to reclaim pool
+	EnablePartialAssembly bool
+
+	// EnableSocketReclaimView rolls up reclaim pool entries of numas into their sockets after each assembling,
+	// for consumers packing reclaimed workloads per socket; reclaim pool entries of numas are kept intact
+	EnableSocketReclaimView bool
+
+	// RegionProvisionStalenessThreshold is the duration provision of a region may keep unchanged, although
+	// the region is refreshed in each assembling, before it's reported as stale; zero value disables the check
+	RegionProvisionStalenessThreshold time.Duration
+
+	// EnableReservedForReclaimHandOff hands off reserved for reclaim unsatisfiable on saturated dedicated numas
+	// to non-binding numas allowed to reclaim, so that reserved reclaim guarantee is preserved node-wide
+	EnableReservedForReclaimHandOff bool
+
+	// CriticalIsolationRegions are names of isolation regions (without numa binding) always keeping their
+	// upper sizes under contention, and shortfall is absorbed by share pools, the other isolation regions
+	// and reclaim pool; names of non-exclusive isolation regions are their origin owner pool names
+	CriticalIsolationRegions sets.String
+
+	// RegionGroups are named groups of share pools regulated against a combined budget rather than the
+	// global share-and-isolate budget; each group is allocated its budget first, and pools in the group
+	// are regulated within it, while pools not in any group share what is left
+	RegionGroups map[string]RegionGroup
+
+	// SharePoolNUMAAffinity pins share pools (by owner pool name) to the given non-binding numa, so that
+	// they are carved out of the numa instead of the share-and-isolate budget, and the numa gets its own
+	// reclaim pool entry; pinning to numas with numa binding pods is ignored
+	SharePoolNUMAAffinity map[string]int
+
+	// ReservedForReclaimPercentage expresses reserved for reclaim of each numa as the percentage of its capacity,
+	// which is rounded up if ReservedForReclaimRoundUp is set and rounded down otherwise; ReservedForReclaimOverrides
+	// are absolute values of given numas overriding the percentage. zero percentage means reserved for reclaim
+	// from dynamic configuration is kept as is, except numas overridden
+	ReservedForReclaimPercentage float64
+	ReservedForReclaimRoundUp    bool
+	ReservedForReclaimOverrides  map[int]int
+
+	// ReservePoolComposition composes reserve pool of named sub-reserves (e.g. system daemons and kubelet/runtime),
+	// key indicates the sub-reserve name and val indicates its size on each numa; the effective reserve pool is
+	// the sum of them instead of the reserve pool in metacache, and empty value keeps the reserve pool as is
+	ReservePoolComposition map[string]int
+
+	// EnablePodScaledReservePool scales reserve pool with per-pod kernel and runtime overhead: reserve pool on each
+	// numa is PodScaledReservePoolBase plus PodScaledReservePoolPerPod times the number of pods on node (rounded up),
+	// capped at PodScaledReservePoolMax unless it's zero; it replaces reserve pool from metacache and composition
+	EnablePodScaledReservePool bool
+	PodScaledReservePoolBase   int
+	PodScaledReservePoolPerPod float64
+	PodScaledReservePoolMax    int
+
+	// BootReservePoolSize raises reserve pool on each numa to at least the size in boot phase, since reserve pool
+	// in metacache may not be initialized yet on freshly booted nodes while system services are still coming up;
+	// boot phase ends once the node has BootPhaseMinPods healthy pods or provision assembler has been up for
+	// BootPhaseMinUptime, whichever comes first, and it never comes back. zero size disables the guard, and
+	// zero thresholds are ignored, so boot phase ends immediately if neither is configured
+	BootReservePoolSize int
+	BootPhaseMinPods    int
+	BootPhaseMinUptime  time.Duration
+
+	// EnableReclaimFairness rebalances reclaim pool entries of binding numas (e.g. donated by dedicated regions of
+	// different tenants), so that batch jobs don't pile onto the most generous numa: entries exceeding the least one
+	// by more than ReclaimFairnessMaxSkew are trimmed down to it. Entries can't be raised beyond what their numas
+	// are able to donate, so only generous ones are trimmed, and never below reserved for reclaim of the numas
+	EnableReclaimFairness  bool
+	ReclaimFairnessMaxSkew int
+
+	// EnableSharePoolForecast pre-shrinks reclaim pool by forecasting share pool requirement SharePoolForecastHorizon
+	// cycles ahead from the recent SharePoolForecastWindow provision values, with the trend estimated by
+	// SharePoolForecastModel; the forecast only raises share pools above the reactive requirement, never lowers them
+	EnableSharePoolForecast   bool
+	SharePoolForecastModel    string
+	SharePoolForecastWindow   int
+	SharePoolForecastHorizon  int
+	SharePoolForecastEMAAlpha float64
+
+	// ReclaimReservationName, ReclaimReservationSize and ReclaimReservationPreferredNumas define a named reclaim
+	// reservation guaranteed regardless of share pool pressure, e.g. for a high-priority batch job; it's carved out
+	// of numas available resource first, preferred numas before others, and zero size disables the reservation
+	ReclaimReservationName           string
+	ReclaimReservationSize           int
+	ReclaimReservationPreferredNumas []int
+
+	// ReclaimCriticalReservationSize is cores of reclaim pool protected for system-critical batch, e.g. log shippers
+	// and node problem detectors, as reserve pool protects system cpu; it's carved out of numas available resource
+	// before any other reclaim reservation, skipping numas being drained, kept in reclaim pool however general
+	// reclaim is squeezed, and never regarded as donatable headroom. zero size disables the reservation
+	ReclaimCriticalReservationSize int
+
+	// IsolationContentionMargin adds hysteresis to isolation contention on non-binding numas: isolation regions
+	// turn to lower sizes only if shares plus isolation upper sizes exceed available by more than the margin, and
+	// turn back to upper sizes only if they drop below available by at least the margin; zero means no hysteresis
+	IsolationContentionMargin int
+
+	// EnableUtilizationWeightedRegulation shrinks share pools on non-binding numas under contention inversely to
+	// their recent utilization instead of proportionally, so that busy pools keep more of their requirement and
+	// idle ones absorb most of the shortfall; pools without utilization are regarded as fully utilized
+	EnableUtilizationWeightedRegulation bool
+
+	// ReclaimSmoothingTimeConstant is the time constant of a low-pass filter applied to the final reclaim pool
+	// entries, so that advertised reclaim capacity grows smoothly when share pools oscillate; shrinking passes
+	// through the filter immediately for safety, and zero means no smoothing
+	ReclaimSmoothingTimeConstant time.Duration
+}
+
+const (
+	// SharePoolForecastModelEMA estimates the trend by exponential moving average of changes between cycles
+	SharePoolForecastModelEMA = "ema"
+	// SharePoolForecastModelLinear estimates the trend by least squares linear fit of provision values
+	SharePoolForecastModelLinear = "linear"
+)
+
+// RegionGroup is a set of share pools (by owner pool name) sharing a combined budget
+type RegionGroup struct {
+	OwnerPoolNames sets.String
+	Budget         int
+}
+
+// NewCPUProvisionAssemblerConfiguration creates new cpu provision assembler configurations
+func NewCPUProvisionAssemblerConfiguration() *CPUProvisionAssemblerConfiguration {
+	return &CPUProvisionAssemblerConfiguration{
+		SharePoolMinSizes:           map[string]int{},
+		SharePoolPriorities:         map[string]int{},
+		CriticalIsolationRegions:    sets.NewString(),
+		RegionGroups:                map[string]RegionGroup{},
+		SharePoolNUMAAffinity:       map[string]int{},
+		ReservedForReclaimOverrides: map[int]int{},
+		ReservePoolComposition:      map[string]int{},
+		SharePoolForecastModel:      SharePoolForecastModelEMA,
+	}
+}
@@ -18,10 +18,12 @@ package headroom
 
 type MemoryHeadroomPolicyConfiguration struct {
 	*MemoryPolicyCanonicalConfiguration
+	*MemoryPolicyNUMAAwareConfiguration
 }
 
 func NewMemoryHeadroomPolicyConfiguration() *MemoryHeadroomPolicyConfiguration {
 	return &MemoryHeadroomPolicyConfiguration{
 		MemoryPolicyCanonicalConfiguration: NewMemoryPolicyCanonicalConfiguration(),
+		MemoryPolicyNUMAAwareConfiguration: NewMemoryPolicyNUMAAwareConfiguration(),
 	}
 }
@@ -0,0 +1,28 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package headroom
+
+type MemoryPolicyNUMAAwareConfiguration struct {
+	// InterleaveNUMAs are numas that reclaimed workloads allocate memory from by interleaving; if set, headroom
+	// of these numas is bounded by the one with the least headroom, since interleaved allocations draw from
+	// all of them evenly. Empty value means reclaimed workloads are not interleaved.
+	InterleaveNUMAs []int
+}
+
+func NewMemoryPolicyNUMAAwareConfiguration() *MemoryPolicyNUMAAwareConfiguration {
+	return &MemoryPolicyNUMAAwareConfiguration{}
+}
@@ -17,6 +17,8 @@ limitations under the License.
 package resource
 
 import (
+	"time"
+
 	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/cpu"
 	"github.com/kubewharf/katalyst-core/pkg/config/agent/sysadvisor/qosaware/resource/memory"
 )
@@ -24,6 +26,12 @@ import (
 // ResourceAdvisorConfiguration stores configurations of resource advisors in qos aware plugin
 type ResourceAdvisorConfiguration struct {
 	ResourceAdvisors []string
+	// AbsentAdvisorAsZeroHeadroom regards headroom of resources without active advisors as zero,
+	// instead of returning error
+	AbsentAdvisorAsZeroHeadroom bool
+	// ResourceUpdateIntervals specifies update interval of each sub advisor, so that heavy computation
+	// can be run less often; sub advisors not specified are updated every sync period
+	ResourceUpdateIntervals map[string]time.Duration
 
 	*cpu.CPUAdvisorConfiguration
 	*memory.MemoryAdvisorConfiguration
@@ -33,6 +41,7 @@ type ResourceAdvisorConfiguration struct {
 func NewResourceAdvisorConfiguration() *ResourceAdvisorConfiguration {
 	return &ResourceAdvisorConfiguration{
 		ResourceAdvisors:           []string{},
+		ResourceUpdateIntervals:    make(map[string]time.Duration),
 		CPUAdvisorConfiguration:    cpu.NewCPUAdvisorConfiguration(),
 		MemoryAdvisorConfiguration: memory.NewMemoryAdvisorConfiguration(),
 	}
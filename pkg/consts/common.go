@@ -45,6 +45,16 @@ const (
 	EventActionContainerStopping = "ContainerStopping"
 )
 
+// const variables for cpu reclaim identifier in event.
+const (
+	EventReasonCPUContentionStarted = "CPUContentionStarted"
+	EventReasonCPUContentionEnded   = "CPUContentionEnded"
+	EventReasonReclaimPoolShrunk    = "ReclaimPoolShrunk"
+	EventReasonReclaimPoolRecovered = "ReclaimPoolRecovered"
+
+	EventActionCPUProvisioning = "CPUProvisioning"
+)
+
 // KeySeparator : to split parts of a key
 const KeySeparator = "/"
 
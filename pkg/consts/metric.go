@@ -33,6 +33,10 @@ const (
 	MetricLoad1MinSystem  = "cpu.load.1min.system"
 	MetricLoad5MinSystem  = "cpu.load.5min.system"
 	MetricLoad15MinSystem = "cpu.load.15min.system"
+
+	// MetricCPUEffectiveCapacityRatioSystem is the ratio of effective cpu capacity to the nominal one, derived
+	// from allowed and hardware max cpufreq of all cpus, which is reduced by thermal or power capping
+	MetricCPUEffectiveCapacityRatioSystem = "cpu.effective.capacity.ratio.system"
 )
 
 // System memory metrics
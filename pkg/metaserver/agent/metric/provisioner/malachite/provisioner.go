@@ -59,6 +59,7 @@ func NewMalachiteMetricsProvisioner(metricStore *utilmetric.MetricStore, emitter
 		conf:                   conf,
 		metricsNotifierManager: metricsNotifierManager,
 		externalMetricManager:  externalMetricManager,
+		cpuSysFsDir:            defaultCPUSysFsDir,
 	}
 }
 
@@ -70,6 +71,9 @@ type MalachiteMetricsProvisioner struct {
 	metricsNotifierManager types.MetricsNotifierManager
 	externalMetricManager  types.ExternalMetricManager
 
+	// cpuSysFsDir is where cpufreq is read from, since malachite never reports it
+	cpuSysFsDir string
+
 	startOnce sync.Once
 	emitter   metrics.MetricEmitter
 
@@ -135,6 +139,7 @@ func (m *MalachiteMetricsProvisioner) updateSystemStats() {
 		m.processSystemComputeData(systemComputeData)
 		m.processSystemCPUComputeData(systemComputeData)
 	}
+	m.processSystemCPUFreqData()
 
 	systemMemoryData, err := m.malachiteClient.GetSystemMemoryStats()
 	if err != nil {
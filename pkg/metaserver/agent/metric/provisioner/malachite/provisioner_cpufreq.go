@@ -0,0 +1,84 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package malachite
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"k8s.io/klog/v2"
+
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	utilmetric "github.com/kubewharf/katalyst-core/pkg/util/metric"
+)
+
+const (
+	// defaultCPUSysFsDir is where cpufreq of each cpu is exposed, e.g. cpu0/cpufreq/scaling_max_freq
+	defaultCPUSysFsDir = "/sys/devices/system/cpu"
+
+	// cpuFreqLimitFile is the max frequency currently allowed, which is lowered by thermal or power capping,
+	// and cpuFreqMaxFile is the max frequency the hardware supports
+	cpuFreqLimitFile = "scaling_max_freq"
+	cpuFreqMaxFile   = "cpuinfo_max_freq"
+)
+
+// processSystemCPUFreqData sets the ratio of allowed max frequency to the hardware max one summed up over all
+// cpus exposing cpufreq, which drops under thermal or power capping; current frequency is not used since it also
+// drops on idle cpus. nothing is set if no cpu exposes cpufreq, e.g. in virtual machines, so that consumers
+// regard it as unavailable instead of no compute at all
+func (m *MalachiteMetricsProvisioner) processSystemCPUFreqData() {
+	cpuDirs, err := filepath.Glob(filepath.Join(m.cpuSysFsDir, "cpu[0-9]*"))
+	if err != nil {
+		klog.Errorf("[malachite] list cpus under %v failed, err %v", m.cpuSysFsDir, err)
+		return
+	}
+
+	var limitFreq, maxFreq float64
+	for _, cpuDir := range cpuDirs {
+		cpuLimitFreq, err := readCPUFreq(filepath.Join(cpuDir, "cpufreq", cpuFreqLimitFile))
+		if err != nil {
+			klog.V(4).Infof("[malachite] skip cpu %v without allowed max frequency: %v", cpuDir, err)
+			continue
+		}
+		cpuMaxFreq, err := readCPUFreq(filepath.Join(cpuDir, "cpufreq", cpuFreqMaxFile))
+		if err != nil || cpuMaxFreq <= 0 {
+			klog.V(4).Infof("[malachite] skip cpu %v without valid hardware max frequency %v: %v", cpuDir, cpuMaxFreq, err)
+			continue
+		}
+		limitFreq += cpuLimitFreq
+		maxFreq += cpuMaxFreq
+	}
+	if maxFreq <= 0 {
+		return
+	}
+
+	updateTime := time.Now()
+	m.metricStore.SetNodeMetric(consts.MetricCPUEffectiveCapacityRatioSystem,
+		utilmetric.MetricData{Value: limitFreq / maxFreq, Time: &updateTime})
+}
+
+// readCPUFreq reads frequency in kHz from the cpufreq file
+func readCPUFreq(path string) (float64, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseFloat(strings.TrimSpace(string(content)), 64)
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2022 The Katalyst Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package malachite
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kubewharf/katalyst-core/pkg/consts"
+	utilmetric "github.com/kubewharf/katalyst-core/pkg/util/metric"
+)
+
+func TestProcessSystemCPUFreqData(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		// cpuFreqs are allowed max and hardware max frequencies of each cpu, and nil means no cpufreq exposed
+		cpuFreqs  map[string][]string
+		wantRatio float64
+		wantSet   bool
+	}{
+		{
+			name: "ratio summed up over all cpus",
+			cpuFreqs: map[string][]string{
+				"cpu0": {"1000000", "2000000"},
+				"cpu1": {"2000000\n", "2000000\n"},
+			},
+			wantRatio: 0.75,
+			wantSet:   true,
+		},
+		{
+			name: "cpus without valid cpufreq skipped",
+			cpuFreqs: map[string][]string{
+				"cpu0": {"1500000", "2000000"},
+				"cpu1": nil,
+				"cpu2": {"1000000", "0"},
+				"cpu3": {"invalid", "2000000"},
+			},
+			wantRatio: 0.75,
+			wantSet:   true,
+		},
+		{
+			name: "no cpu exposing cpufreq",
+			cpuFreqs: map[string][]string{
+				"cpu0": nil,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			for cpu, freqs := range tt.cpuFreqs {
+				cpuDir := filepath.Join(dir, cpu)
+				if freqs == nil {
+					require.NoError(t, os.MkdirAll(cpuDir, 0755))
+					continue
+				}
+				require.NoError(t, os.MkdirAll(filepath.Join(cpuDir, "cpufreq"), 0755))
+				require.NoError(t, os.WriteFile(filepath.Join(cpuDir, "cpufreq", cpuFreqLimitFile), []byte(freqs[0]), 0644))
+				require.NoError(t, os.WriteFile(filepath.Join(cpuDir, "cpufreq", cpuFreqMaxFile), []byte(freqs[1]), 0644))
+			}
+			// entries other than cpus are ignored
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, "cpufreq"), 0755))
+
+			store := utilmetric.NewMetricStore()
+			m := &MalachiteMetricsProvisioner{metricStore: store, cpuSysFsDir: dir}
+			m.processSystemCPUFreqData()
+
+			data, err := store.GetNodeMetric(consts.MetricCPUEffectiveCapacityRatioSystem)
+			if !tt.wantSet {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.wantRatio, data.Value, 1e-9)
+		})
+	}
+}